@@ -0,0 +1,102 @@
+package approval
+
+import (
+	"asset-management/internal/ledger"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/shopspring/decimal"
+	"strings"
+	"time"
+)
+
+// ErrRequesterRequired is returned for outflows above an approval threshold that do not say who
+// requested them, since the requester may not approve their own request.
+var ErrRequesterRequired = errors.New("requested_by is required for outflows that need approval")
+
+// DefaultRequiredApprovals is the number of distinct approvers a request needs unless configured
+// otherwise. Together with the requester, that makes four eyes.
+const DefaultRequiredApprovals = 1
+
+const (
+	StatusAwaitingApproval = "AWAITING_APPROVAL"
+	StatusApproved         = "APPROVED"
+	StatusRejected         = "REJECTED"
+	StatusCancelled        = "CANCELLED" // The wallet was swept before a decision was made
+)
+
+const (
+	OperationWithdraw          = ledger.OperationWithdraw
+	OperationScheduledTransfer = ledger.OperationScheduledTransfer
+)
+
+const (
+	DecisionApprove = "APPROVE"
+	DecisionReject  = "REJECT"
+)
+
+// Request is a withdrawal or scheduled transfer that exceeds an approval threshold. A withdrawal is
+// made once approved; a scheduled transfer becomes PENDING, so it is published when it is due. Its
+// amount is held in the wallet until then.
+type Request struct {
+	ID                     int             `json:"id" example:"12"`                                     // Approval request ID
+	Operation              string          `json:"operation" example:"WITHDRAW"`                        // WITHDRAW or SCHEDULED_TRANSFER
+	WalletAddress          string          `json:"wallet_address" example:"0x123abc456def"`             // Wallet the funds leave
+	Network                string          `json:"network" example:"Ethereum"`                          // Blockchain network
+	Asset                  string          `json:"asset" example:"ETH"`                                 // Asset symbol
+	Amount                 decimal.Decimal `json:"amount" swaggertype:"string" example:"15000"`         // Amount to move
+	ScheduledTransactionID *int            `json:"scheduled_transaction_id,omitempty" example:"7"`      // Scheduled transfer awaiting approval
+	RuleID                 int             `json:"rule_id" example:"3"`                                 // APPROVAL_THRESHOLD rule the amount exceeds
+	RequestedBy            string          `json:"requested_by" example:"alice@example.com"`            // Who requested the outflow
+	Status                 string          `json:"status" example:"AWAITING_APPROVAL"`                  // AWAITING_APPROVAL, APPROVED, REJECTED or CANCELLED
+	Reason                 string          `json:"reason,omitempty" example:"beneficiary not verified"` // Why the request was rejected
+	CreatedAt              time.Time       `json:"created_at" example:"2024-10-29T10:15:00Z"`           // Time the outflow was requested
+	DecidedAt              *time.Time      `json:"decided_at,omitempty" example:"2024-10-29T11:00:00Z"` // Time the request was approved, rejected or cancelled
+	Decisions              []Decision      `json:"decisions"`                                           // Decisions made so far, oldest first
+}
+
+type Decision struct {
+	Approver  string    `json:"approver" example:"bob@example.com"`        // Who decided
+	Decision  string    `json:"decision" example:"APPROVE"`                // APPROVE or REJECT
+	Comment   string    `json:"comment,omitempty" example:"checked"`       // Comment or rejection reason
+	CreatedAt time.Time `json:"created_at" example:"2024-10-29T11:00:00Z"` // Time of the decision
+}
+
+// Columns lists the approval_requests columns read by Scan, in order.
+const Columns = `approval_id, operation, wallet_address, network, asset, amount, scheduled_transaction_id, rule_id, requested_by,
+        status, COALESCE(reason, ''), created_at, decided_at`
+
+// Scan reads a row selected with Columns. Decisions are read separately.
+func Scan(row interface{ Scan(dest ...any) error }) (Request, error) {
+	r := Request{Decisions: []Decision{}}
+	err := row.Scan(&r.ID, &r.Operation, &r.WalletAddress, &r.Network, &r.Asset, &r.Amount, &r.ScheduledTransactionID, &r.RuleID,
+		&r.RequestedBy, &r.Status, &r.Reason, &r.CreatedAt, &r.DecidedAt)
+	return r, err
+}
+
+// Identity returns the form requesters and approvers are stored and compared in, so that
+// "Alice@example.com " and "alice@example.com" are the same person.
+func Identity(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
+
+// Open records a request awaiting approval in tx and returns its ID. The caller holds the lock on
+// the wallet's balance, so the amount is held before anything else can spend it.
+func Open(ctx context.Context, tx *sql.Tx, r Request) (int, error) {
+	requestedBy := Identity(r.RequestedBy)
+	if requestedBy == "" {
+		return 0, ErrRequesterRequired
+	}
+
+	var id int
+	err := tx.QueryRowContext(ctx, `
+        INSERT INTO approval_requests (operation, wallet_address, network, asset, amount, scheduled_transaction_id, rule_id, requested_by)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+        RETURNING approval_id`, r.Operation, r.WalletAddress, r.Network, r.Asset, r.Amount, r.ScheduledTransactionID, r.RuleID,
+		requestedBy).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open approval request: %w", err)
+	}
+	return id, nil
+}
@@ -0,0 +1,30 @@
+package approval_test
+
+import (
+	"asset-management/internal/approval"
+	"context"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestOpen_RequesterRequired(t *testing.T) {
+	// The requester is checked before the transaction is used
+	id, err := approval.Open(context.Background(), nil, approval.Request{
+		Operation:     approval.OperationWithdraw,
+		WalletAddress: "0x123",
+		Network:       "Ethereum",
+		Asset:         "ETH",
+		Amount:        decimal.RequireFromString("15000"),
+		RequestedBy:   "  ",
+	})
+
+	assert.ErrorIs(t, err, approval.ErrRequesterRequired)
+	assert.Zero(t, id)
+}
+
+func TestIdentity(t *testing.T) {
+	assert.Equal(t, "alice@x.com", approval.Identity(" Alice@X.com\t"))
+	assert.Equal(t, approval.Identity("alice@x.com"), approval.Identity("ALICE@x.com"))
+	assert.Empty(t, approval.Identity("  "))
+}
@@ -0,0 +1,73 @@
+package funds
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/shopspring/decimal"
+)
+
+// ErrFundsHeld reports that the balance covers an amount, but not once the funds held by
+// scheduled transfers and withdrawals awaiting approval are set aside.
+var ErrFundsHeld = errors.New("insufficient available balance")
+
+// Funds is the balance of one asset in a wallet. Scheduled transfers that are AWAITING_APPROVAL,
+// PENDING or PUBLISHED hold their amount in the sender's wallet until they are processed, fail or
+// are cancelled, and so do withdrawals awaiting approval; the rest of the balance is available.
+type Funds struct {
+	Balance decimal.Decimal
+	Held    decimal.Decimal
+}
+
+func (f Funds) Available() decimal.Decimal {
+	return f.Balance.Sub(f.Held)
+}
+
+// Cover returns ErrInsufficientBalance or ErrFundsHeld if amount exceeds the available funds.
+func (f Funds) Cover(amount decimal.Decimal, asset string) error {
+	switch {
+	case f.Balance.LessThan(amount):
+		return ErrInsufficientBalance
+	case f.Available().LessThan(amount):
+		return fmt.Errorf("%w: %s %s of the balance is held by scheduled transactions or withdrawals awaiting approval", ErrFundsHeld, f.Held, asset)
+	}
+	return nil
+}
+
+// Lock locks the wallet's balance of asset until tx ends and returns it with the amount held.
+// Every change to holds or balances locks the balance first, so the result stays true until then.
+// A wallet without a balance of asset has no funds.
+func Lock(ctx context.Context, tx *sql.Tx, walletAddress, network, asset string) (*Funds, error) {
+	var f Funds
+	err := tx.QueryRowContext(ctx, `
+        SELECT balance FROM balance
+        WHERE wallet_address = $1 AND network = $2 AND asset = $3 FOR UPDATE`, walletAddress, network, asset).Scan(&f.Balance)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to lock balance of %s: %w", walletAddress, err)
+	}
+
+	if f.Held, err = Held(ctx, tx, walletAddress, network, asset); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// Held returns the amount of asset held in the wallet by scheduled transfers and withdrawals awaiting
+// approval. The caller should hold the lock on the wallet's balance.
+func Held(ctx context.Context, tx *sql.Tx, walletAddress, network, asset string) (decimal.Decimal, error) {
+	var held decimal.Decimal
+	err := tx.QueryRowContext(ctx, `
+        SELECT COALESCE(SUM(amount), 0) FROM (
+            SELECT amount FROM scheduled_transactions
+            WHERE from_wallet_address = $1 AND network = $2 AND asset = $3 AND status IN ('AWAITING_APPROVAL', 'PENDING', 'PUBLISHED')
+            UNION ALL
+            SELECT amount FROM approval_requests
+            WHERE wallet_address = $1 AND network = $2 AND asset = $3 AND operation = 'WITHDRAW' AND status = 'AWAITING_APPROVAL'
+        ) held`,
+		walletAddress, network, asset).Scan(&held)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("failed to sum funds held in %s: %w", walletAddress, err)
+	}
+	return held, nil
+}
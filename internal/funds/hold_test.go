@@ -0,0 +1,21 @@
+package funds_test
+
+import (
+	"asset-management/internal/funds"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestFunds_Cover(t *testing.T) {
+	f := funds.Funds{Balance: decimal.RequireFromString("100"), Held: decimal.RequireFromString("70")}
+
+	assert.Equal(t, "30", f.Available().String())
+	assert.NoError(t, f.Cover(decimal.RequireFromString("30"), "ETH"))
+
+	err := f.Cover(decimal.RequireFromString("40"), "ETH")
+	assert.ErrorIs(t, err, funds.ErrFundsHeld)
+	assert.EqualError(t, err, "insufficient available balance: 70 ETH of the balance is held by scheduled transactions or withdrawals awaiting approval")
+
+	assert.ErrorIs(t, f.Cover(decimal.RequireFromString("101"), "ETH"), funds.ErrInsufficientBalance)
+}
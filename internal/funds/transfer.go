@@ -0,0 +1,112 @@
+package funds
+
+import (
+	"asset-management/internal/ledger"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/shopspring/decimal"
+)
+
+var ErrInsufficientBalance = errors.New("insufficient balance in sender's wallet")
+
+type Transfer struct {
+	From      string
+	To        string
+	Network   string
+	Asset     string
+	Amount    decimal.Decimal
+	Operation string // Ledger operation, e.g. ledger.OperationTransfer
+	Reference string // Ledger reference of the originating request
+
+	RespectHolds bool // Refuse to move funds held by scheduled transfers; false when a transfer spends its own hold
+
+	// Check, if set, vets the transfer against the sender's funds once they cover the amount, with both
+	// balances locked, e.g. against withdrawal limits. It only runs when RespectHolds is set.
+	Check func(sender Funds) error
+}
+
+type Result struct {
+	FromBalance decimal.Decimal
+	ToBalance   decimal.Decimal
+}
+
+// Move debits the sender and credits the receiver inside tx and records the movement in the ledger.
+// Both balance rows are locked first, in a fixed order so that opposite transfers between the same
+// two wallets cannot deadlock. The caller owns tx and decides whether to commit or roll back.
+func Move(ctx context.Context, tx *sql.Tx, t Transfer) (*Result, error) {
+	first, second := t.From, t.To
+	if second < first {
+		first, second = second, first
+	}
+
+	// Lock balance records for both wallets
+	if _, err := tx.ExecContext(ctx, `
+        SELECT balance FROM balance 
+        WHERE wallet_address = $1 AND network = $2 AND asset = $3 FOR UPDATE`, first, t.Network, t.Asset); err != nil {
+		return nil, fmt.Errorf("failed to lock balance of %s: %w", first, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+        SELECT balance FROM balance 
+        WHERE wallet_address = $1 AND network = $2 AND asset = $3 FOR UPDATE`, second, t.Network, t.Asset); err != nil {
+		return nil, fmt.Errorf("failed to lock balance of %s: %w", second, err)
+	}
+
+	if t.RespectHolds {
+		sender, err := Lock(ctx, tx, t.From, t.Network, t.Asset)
+		if err != nil {
+			return nil, err
+		}
+		if err := sender.Cover(t.Amount, t.Asset); err != nil {
+			return nil, err
+		}
+		if t.Check != nil {
+			if err := t.Check(*sender); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Deduct from sender's balance
+	var result Result
+	err := tx.QueryRowContext(ctx, `
+        UPDATE balance SET balance = balance - $1 
+        WHERE wallet_address = $2 AND network = $3 AND asset = $4 AND balance >= $1
+        RETURNING balance`, t.Amount, t.From, t.Network, t.Asset).Scan(&result.FromBalance)
+	if err == sql.ErrNoRows {
+		return nil, ErrInsufficientBalance
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to deduct from sender's balance: %w", err)
+	}
+
+	// Add to receiver's balance
+	err = tx.QueryRowContext(ctx, `
+    INSERT INTO balance (wallet_address, network, asset, balance) 
+    VALUES ($1, $2, $3, $4) 
+    ON CONFLICT (wallet_address, network, asset) DO UPDATE 
+    SET balance = balance.balance + EXCLUDED.balance
+    RETURNING balance`, t.To, t.Network, t.Asset, t.Amount).Scan(&result.ToBalance)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add to receiver's balance: %w", err)
+	}
+
+	// Record the transfer in the ledger
+	err = ledger.Record(ctx, tx, ledger.Movement{
+		Operation:   t.Operation,
+		Reference:   t.Reference,
+		Network:     t.Network,
+		Asset:       t.Asset,
+		Amount:      t.Amount,
+		From:        t.From,
+		FromBalance: ledger.Balance(result.FromBalance),
+		To:          t.To,
+		ToBalance:   ledger.Balance(result.ToBalance),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,78 @@
+package ledger
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+// ExternalAccount is the counter account used for funds entering or leaving the system
+// (deposits and withdrawals), so that every movement is recorded as a balanced pair.
+const ExternalAccount = "EXTERNAL"
+
+const (
+	EntryTypeDebit  = "DEBIT"
+	EntryTypeCredit = "CREDIT"
+)
+
+const (
+	OperationDeposit           = "DEPOSIT"
+	OperationWithdraw          = "WITHDRAW"
+	OperationScheduledTransfer = "SCHEDULED_TRANSFER"
+	OperationTransfer          = "TRANSFER"
+	OperationSweep             = "SWEEP" // Funds moved out of a wallet before it is deleted
+)
+
+type Entry struct {
+	ID            int64               `json:"id" example:"42"`                                      // Ledger entry ID
+	WalletAddress string              `json:"wallet_address" example:"0x123abc456def"`              // Wallet the entry belongs to
+	Network       string              `json:"network" example:"Ethereum"`                           // Blockchain network
+	Asset         string              `json:"asset" example:"ETH"`                                  // Asset symbol
+	EntryType     string              `json:"entry_type" example:"CREDIT"`                          // DEBIT or CREDIT
+	Operation     string              `json:"operation" example:"DEPOSIT"`                          // Operation that caused the movement
+	Counterparty  string              `json:"counterparty" example:"EXTERNAL"`                      // Other side of the movement
+	Amount        decimal.Decimal     `json:"amount" swaggertype:"string" example:"100.50"`         // Amount moved
+	BalanceAfter  decimal.NullDecimal `json:"balance_after" swaggertype:"string" example:"1500.75"` // Balance after the movement, null for the external account
+	Reference     string              `json:"reference" example:"scheduled_transaction:1"`          // Originating request or scheduled transaction
+	CreatedAt     time.Time           `json:"created_at" example:"2024-10-29T10:15:00Z"`            // Time the entry was recorded
+}
+
+// Movement describes funds moving from one account to another. From is debited and To is credited.
+type Movement struct {
+	Operation   string
+	Reference   string
+	Network     string
+	Asset       string
+	Amount      decimal.Decimal
+	From        string
+	FromBalance decimal.NullDecimal
+	To          string
+	ToBalance   decimal.NullDecimal
+}
+
+// Balance wraps a resulting wallet balance for use in a Movement.
+func Balance(b decimal.Decimal) decimal.NullDecimal {
+	return decimal.NewNullDecimal(b)
+}
+
+// Record writes the debit and credit entries of a movement. It must run in the same
+// transaction as the balance change it describes.
+func Record(ctx context.Context, tx *sql.Tx, m Movement) error {
+	query := `
+		INSERT INTO ledger_entries (wallet_address, network, asset, entry_type, operation, counterparty, amount, balance_after, reference)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+
+	if _, err := tx.ExecContext(ctx, query,
+		m.From, m.Network, m.Asset, EntryTypeDebit, m.Operation, m.To, m.Amount, m.FromBalance, m.Reference); err != nil {
+		return fmt.Errorf("failed to record debit entry: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, query,
+		m.To, m.Network, m.Asset, EntryTypeCredit, m.Operation, m.From, m.Amount, m.ToBalance, m.Reference); err != nil {
+		return fmt.Errorf("failed to record credit entry: %w", err)
+	}
+
+	return nil
+}
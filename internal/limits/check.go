@@ -0,0 +1,107 @@
+package limits
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Columns lists the withdrawal_limits columns read by Scan, in order.
+const Columns = `limit_id, scope, network, wallet_address, asset, kind, value, created_at, updated_at`
+
+// Scan reads a row selected with Columns.
+func Scan(row interface{ Scan(dest ...any) error }) (Rule, error) {
+	var r Rule
+	err := row.Scan(&r.ID, &r.Scope, &r.Network, &r.WalletAddress, &r.Asset, &r.Kind, &r.Value, &r.CreatedAt, &r.UpdatedAt)
+	return r, err
+}
+
+// Check returns a Violation if the outflow breaks any rule that applies to it. It must run in the
+// transaction that moves or holds the funds, after locking the wallet's balance of the asset, so
+// that outflows of that asset are checked one at a time.
+//
+// Usage counts withdrawals and immediate transfers when they are made, withdrawals waiting for
+// approval, and scheduled transfers when they are requested, so a transfer counts once however far
+// ahead it is scheduled; each occurrence of a recurring transfer counts when it is scheduled.
+// Transfers that were cancelled or failed do not count, nor does the transaction an outflow replaces.
+func Check(ctx context.Context, tx *sql.Tx, o Outflow) error {
+	rules, err := load(ctx, tx, o)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range Applicable(rules, o) {
+		var u Usage
+		if window := Window(r.Kind); window > 0 {
+			if u, err = usage(ctx, tx, o, r.Asset, window); err != nil {
+				return err
+			}
+		}
+		if err := r.Evaluate(o, u); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ApprovalThreshold returns the rule that makes the outflow wait for approval, or nil if it needs none.
+func ApprovalThreshold(ctx context.Context, tx *sql.Tx, o Outflow) (*Rule, error) {
+	rules, err := load(ctx, tx, o)
+	if err != nil {
+		return nil, err
+	}
+	return Threshold(Applicable(rules, o), o), nil
+}
+
+func load(ctx context.Context, tx *sql.Tx, o Outflow) ([]Rule, error) {
+	rows, err := tx.QueryContext(ctx, `
+        SELECT `+Columns+`
+        FROM withdrawal_limits
+        WHERE (scope = 'GLOBAL' OR (scope = 'NETWORK' AND network = $2) OR (scope = 'WALLET' AND network = $2 AND wallet_address = $1))
+          AND (asset = '' OR asset = $3)`, o.WalletAddress, o.Network, o.Asset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load withdrawal limits: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []Rule
+	for rows.Next() {
+		r, err := Scan(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan withdrawal limit: %w", err)
+		}
+		rules = append(rules, r)
+	}
+	return rules, rows.Err()
+}
+
+// usage sums the wallet's outflows of asset, or of every asset if it is empty, over the window. Ledger
+// entries recorded under the wallet's aliases count too.
+func usage(ctx context.Context, tx *sql.Tx, o Outflow, asset string, window time.Duration) (Usage, error) {
+	var u Usage
+	err := tx.QueryRowContext(ctx, `
+        SELECT COALESCE(SUM(amount), 0), COUNT(*) FROM (
+            SELECT amount FROM ledger_entries
+            WHERE (wallet_address, network) IN (
+                    SELECT $1::VARCHAR, $2::VARCHAR
+                    UNION ALL SELECT wallet_address, network FROM wallet_aliases WHERE canonical_address = $1 AND canonical_network = $2)
+              AND ($3 = '' OR asset = $3)
+              AND entry_type = 'DEBIT' AND operation IN ('WITHDRAW', 'TRANSFER')
+              AND created_at > NOW() - make_interval(secs => $4)
+            UNION ALL
+            SELECT amount FROM approval_requests
+            WHERE wallet_address = $1 AND network = $2 AND ($3 = '' OR asset = $3)
+              AND operation = 'WITHDRAW' AND status = 'AWAITING_APPROVAL'
+              AND created_at > NOW() - make_interval(secs => $4)
+            UNION ALL
+            SELECT amount FROM scheduled_transactions
+            WHERE from_wallet_address = $1 AND network = $2 AND ($3 = '' OR asset = $3)
+              AND status NOT IN ('CANCELLED', 'FAILED') AND scheduled_transaction_id <> $5
+              AND created_at > NOW() - make_interval(secs => $4)
+        ) outflows`, o.WalletAddress, o.Network, asset, window.Seconds(), o.Replaces).Scan(&u.Volume, &u.Count)
+	if err != nil {
+		return Usage{}, fmt.Errorf("failed to measure outflows of %s: %w", o.WalletAddress, err)
+	}
+	return u, nil
+}
@@ -0,0 +1,204 @@
+package limits
+
+import (
+	"asset-management/internal/funds"
+	"errors"
+	"fmt"
+	"github.com/shopspring/decimal"
+	"sort"
+	"time"
+)
+
+// ErrLimitExceeded is wrapped by every Violation.
+var ErrLimitExceeded = errors.New("withdrawal limit exceeded")
+
+// Scopes select the wallets a rule applies to. Usage is always measured per wallet, so a NETWORK
+// rule limits each wallet on that network rather than the network as a whole.
+const (
+	ScopeGlobal  = "GLOBAL"
+	ScopeNetwork = "NETWORK"
+	ScopeWallet  = "WALLET"
+)
+
+const (
+	KindMaxAmount    = "MAX_AMOUNT"     // Largest single withdrawal or scheduled transfer
+	KindVolume24h    = "VOLUME_24H"     // Total outflow over the last 24 hours
+	KindVolume30d    = "VOLUME_30D"     // Total outflow over the last 30 days
+	KindCountPerHour = "COUNT_PER_HOUR" // Number of outflows over the last hour
+	KindMinReserve   = "MIN_RESERVE"    // Available balance that must remain after the outflow
+
+	// KindApprovalThreshold does not refuse outflows: larger ones wait for approval instead of leaving at once.
+	KindApprovalThreshold = "APPROVAL_THRESHOLD"
+)
+
+// Kinds lists every rule kind.
+var Kinds = []string{KindMaxAmount, KindVolume24h, KindVolume30d, KindCountPerHour, KindMinReserve, KindApprovalThreshold}
+
+// Rule is a withdrawal limit. Rules of every kind but COUNT_PER_HOUR are amounts of one asset; a
+// COUNT_PER_HOUR rule without an asset counts outflows of every asset.
+type Rule struct {
+	ID            int             `json:"id" example:"3"`                                    // Rule ID
+	Scope         string          `json:"scope" example:"NETWORK"`                           // GLOBAL, NETWORK or WALLET
+	Network       string          `json:"network,omitempty" example:"Ethereum"`              // Network of NETWORK and WALLET rules
+	WalletAddress string          `json:"wallet_address,omitempty" example:"0x123abc456def"` // Wallet of WALLET rules
+	Asset         string          `json:"asset,omitempty" example:"ETH"`                     // Asset the rule applies to
+	Kind          string          `json:"kind" example:"VOLUME_24H"`                         // Kind of limit
+	Value         decimal.Decimal `json:"value" swaggertype:"string" example:"10"`           // Amount, or number of outflows for COUNT_PER_HOUR
+	CreatedAt     time.Time       `json:"created_at" example:"2024-10-29T10:15:00Z"`         // Time the rule was created
+	UpdatedAt     time.Time       `json:"updated_at" example:"2024-10-29T10:15:00Z"`         // Time the value last changed
+}
+
+// IsAmount reports whether the rule's value is an amount of its asset.
+func (r Rule) IsAmount() bool {
+	return r.Kind != KindCountPerHour
+}
+
+func (r Rule) specificity() int {
+	switch r.Scope {
+	case ScopeWallet:
+		return 2
+	case ScopeNetwork:
+		return 1
+	}
+	return 0
+}
+
+func (r Rule) String() string {
+	switch r.Scope {
+	case ScopeWallet:
+		return fmt.Sprintf("rule %d for wallet %s on %s", r.ID, r.WalletAddress, r.Network)
+	case ScopeNetwork:
+		return fmt.Sprintf("rule %d for network %s", r.ID, r.Network)
+	}
+	return fmt.Sprintf("global rule %d", r.ID)
+}
+
+// Outflow is a withdrawal or scheduled transfer about to leave a wallet. Funds is the wallet's
+// balance of the asset before it leaves.
+type Outflow struct {
+	WalletAddress string
+	Network       string
+	Asset         string
+	Amount        decimal.Decimal
+	Funds         funds.Funds
+	Replaces      int // Scheduled transaction whose amount the outflow changes, left out of usage
+}
+
+// Usage is what a wallet has already withdrawn or scheduled within a rule's window.
+type Usage struct {
+	Volume decimal.Decimal
+	Count  int
+}
+
+// Violation reports the rule an outflow breaks.
+type Violation struct {
+	Rule   Rule
+	Detail string
+}
+
+func (v *Violation) Error() string {
+	return fmt.Sprintf("%s: %s allows %s", ErrLimitExceeded, v.Rule, v.Detail)
+}
+
+func (v *Violation) Unwrap() error {
+	return ErrLimitExceeded
+}
+
+// Window returns the period a rule measures usage over, or zero if it looks at the outflow alone.
+func Window(kind string) time.Duration {
+	switch kind {
+	case KindVolume24h:
+		return 24 * time.Hour
+	case KindVolume30d:
+		return 30 * 24 * time.Hour
+	case KindCountPerHour:
+		return time.Hour
+	}
+	return 0
+}
+
+// Applicable returns the rules that apply to an outflow, ordered by ID. Of the rules of one kind and
+// asset, only the most specific applies, so a wallet rule overrides a network rule, which overrides
+// a global one.
+func Applicable(rules []Rule, o Outflow) []Rule {
+	chosen := map[[2]string]Rule{}
+	for _, r := range rules {
+		if !r.matches(o) {
+			continue
+		}
+		key := [2]string{r.Kind, r.Asset}
+		if current, ok := chosen[key]; !ok || r.specificity() > current.specificity() {
+			chosen[key] = r
+		}
+	}
+
+	applicable := make([]Rule, 0, len(chosen))
+	for _, r := range chosen {
+		applicable = append(applicable, r)
+	}
+	sort.Slice(applicable, func(i, j int) bool { return applicable[i].ID < applicable[j].ID })
+	return applicable
+}
+
+func (r Rule) matches(o Outflow) bool {
+	if r.Asset != "" && r.Asset != o.Asset {
+		return false
+	}
+	switch r.Scope {
+	case ScopeGlobal:
+		return true
+	case ScopeNetwork:
+		return r.Network == o.Network
+	case ScopeWallet:
+		return r.Network == o.Network && r.WalletAddress == o.WalletAddress
+	}
+	return false
+}
+
+// Threshold returns the APPROVAL_THRESHOLD rule among the applicable ones that the outflow exceeds,
+// or nil if it needs no approval.
+func Threshold(applicable []Rule, o Outflow) *Rule {
+	for _, r := range applicable {
+		if r.Kind == KindApprovalThreshold && o.Amount.GreaterThan(r.Value) {
+			return &r
+		}
+	}
+	return nil
+}
+
+// Evaluate returns a Violation if the outflow breaks the rule, given the usage in the rule's window.
+// APPROVAL_THRESHOLD rules are never broken.
+func (r Rule) Evaluate(o Outflow, u Usage) error {
+	switch r.Kind {
+	case KindMaxAmount:
+		if o.Amount.GreaterThan(r.Value) {
+			return &Violation{Rule: r, Detail: fmt.Sprintf("at most %s %s per transaction", r.Value, r.Asset)}
+		}
+	case KindVolume24h, KindVolume30d:
+		if u.Volume.Add(o.Amount).GreaterThan(r.Value) {
+			return &Violation{Rule: r, Detail: fmt.Sprintf("at most %s %s per %s, %s %s already used",
+				r.Value, r.Asset, period(r.Kind), u.Volume, r.Asset)}
+		}
+	case KindCountPerHour:
+		if decimal.NewFromInt(int64(u.Count + 1)).GreaterThan(r.Value) {
+			what := "outflows"
+			if r.Asset != "" {
+				what = r.Asset + " outflows"
+			}
+			return &Violation{Rule: r, Detail: fmt.Sprintf("at most %s %s per hour, %d already made", r.Value, what, u.Count)}
+		}
+	case KindMinReserve:
+		if remaining := o.Funds.Available().Sub(o.Amount); remaining.LessThan(r.Value) {
+			return &Violation{Rule: r, Detail: fmt.Sprintf("no less than %s %s to remain available, %s %s would remain",
+				r.Value, r.Asset, remaining, r.Asset)}
+		}
+	}
+	return nil
+}
+
+func period(kind string) string {
+	if kind == KindVolume30d {
+		return "30 days"
+	}
+	return "24 hours"
+}
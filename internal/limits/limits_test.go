@@ -0,0 +1,128 @@
+package limits_test
+
+import (
+	"asset-management/internal/funds"
+	"asset-management/internal/limits"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var outflow = limits.Outflow{
+	WalletAddress: "0x123",
+	Network:       "Ethereum",
+	Asset:         "ETH",
+	Amount:        decimal.RequireFromString("4"),
+	Funds:         funds.Funds{Balance: decimal.RequireFromString("20"), Held: decimal.RequireFromString("5")},
+}
+
+func rule(id int, scope, network, wallet, asset, kind, value string) limits.Rule {
+	return limits.Rule{ID: id, Scope: scope, Network: network, WalletAddress: wallet, Asset: asset, Kind: kind,
+		Value: decimal.RequireFromString(value)}
+}
+
+func TestApplicable_MostSpecificRuleWins(t *testing.T) {
+	rules := []limits.Rule{
+		rule(1, limits.ScopeGlobal, "", "", "ETH", limits.KindMaxAmount, "1"),
+		rule(2, limits.ScopeNetwork, "Ethereum", "", "ETH", limits.KindMaxAmount, "2"),
+		rule(3, limits.ScopeWallet, "Ethereum", "0x123", "ETH", limits.KindMaxAmount, "3"),
+		rule(4, limits.ScopeGlobal, "", "", "ETH", limits.KindVolume24h, "100"),
+		rule(5, limits.ScopeNetwork, "Ethereum", "", "", limits.KindCountPerHour, "10"),
+		rule(6, limits.ScopeWallet, "Ethereum", "0x456", "ETH", limits.KindVolume24h, "1"),
+		rule(7, limits.ScopeNetwork, "Tron", "", "ETH", limits.KindMinReserve, "1"),
+		rule(8, limits.ScopeGlobal, "", "", "USDT", limits.KindMaxAmount, "1"),
+	}
+
+	var ids []int
+	for _, r := range limits.Applicable(rules, outflow) {
+		ids = append(ids, r.ID)
+	}
+	assert.Equal(t, []int{3, 4, 5}, ids)
+}
+
+func TestThreshold(t *testing.T) {
+	applicable := []limits.Rule{
+		rule(1, limits.ScopeGlobal, "", "", "ETH", limits.KindMaxAmount, "3"),
+		rule(2, limits.ScopeWallet, "Ethereum", "0x123", "ETH", limits.KindApprovalThreshold, "3.5"),
+	}
+
+	threshold := limits.Threshold(applicable, outflow)
+	if assert.NotNil(t, threshold) {
+		assert.Equal(t, 2, threshold.ID)
+	}
+
+	// Amounts up to the threshold need no approval
+	applicable[1].Value = decimal.RequireFromString("4")
+	assert.Nil(t, limits.Threshold(applicable, outflow))
+}
+
+func TestRule_Evaluate(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  limits.Rule
+		usage limits.Usage
+		err   string
+	}{
+		{
+			name: "max amount",
+			rule: rule(1, limits.ScopeGlobal, "", "", "ETH", limits.KindMaxAmount, "3.5"),
+			err:  "withdrawal limit exceeded: global rule 1 allows at most 3.5 ETH per transaction",
+		},
+		{
+			name: "max amount reached exactly",
+			rule: rule(1, limits.ScopeGlobal, "", "", "ETH", limits.KindMaxAmount, "4"),
+		},
+		{
+			name:  "daily volume",
+			rule:  rule(2, limits.ScopeWallet, "Ethereum", "0x123", "ETH", limits.KindVolume24h, "10"),
+			usage: limits.Usage{Volume: decimal.RequireFromString("7"), Count: 2},
+			err:   "withdrawal limit exceeded: rule 2 for wallet 0x123 on Ethereum allows at most 10 ETH per 24 hours, 7 ETH already used",
+		},
+		{
+			name:  "monthly volume",
+			rule:  rule(3, limits.ScopeNetwork, "Ethereum", "", "ETH", limits.KindVolume30d, "10"),
+			usage: limits.Usage{Volume: decimal.RequireFromString("6")},
+		},
+		{
+			name:  "count per hour",
+			rule:  rule(4, limits.ScopeNetwork, "Ethereum", "", "", limits.KindCountPerHour, "3"),
+			usage: limits.Usage{Volume: decimal.RequireFromString("1"), Count: 3},
+			err:   "withdrawal limit exceeded: rule 4 for network Ethereum allows at most 3 outflows per hour, 3 already made",
+		},
+		{
+			name:  "count per hour below limit",
+			rule:  rule(4, limits.ScopeNetwork, "Ethereum", "", "ETH", limits.KindCountPerHour, "3"),
+			usage: limits.Usage{Count: 2},
+		},
+		{
+			name: "minimum reserve",
+			rule: rule(5, limits.ScopeGlobal, "", "", "ETH", limits.KindMinReserve, "12"),
+			err:  "withdrawal limit exceeded: global rule 5 allows no less than 12 ETH to remain available, 11 ETH would remain",
+		},
+		{
+			name: "approval threshold",
+			rule: rule(6, limits.ScopeGlobal, "", "", "ETH", limits.KindApprovalThreshold, "1"),
+		},
+		{
+			name: "minimum reserve kept",
+			rule: rule(5, limits.ScopeGlobal, "", "", "ETH", limits.KindMinReserve, "11"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.rule.Evaluate(outflow, tt.usage)
+			if tt.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.EqualError(t, err, tt.err)
+			assert.ErrorIs(t, err, limits.ErrLimitExceeded)
+
+			var violation *limits.Violation
+			assert.ErrorAs(t, err, &violation)
+			assert.Equal(t, tt.rule.ID, violation.Rule.ID)
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package network
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+)
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+var errBase58Checksum = errors.New("checksum mismatch")
+
+// decodeBase58 decodes the Bitcoin base58 alphabet; leading '1's stand for zero bytes.
+func decodeBase58(s string) ([]byte, bool) {
+	value := new(big.Int)
+	radix := big.NewInt(58)
+	for _, c := range []byte(s) {
+		digit := bytes.IndexByte([]byte(base58Alphabet), c)
+		if digit < 0 {
+			return nil, false
+		}
+		value.Mul(value, radix)
+		value.Add(value, big.NewInt(int64(digit)))
+	}
+
+	zeros := 0
+	for zeros < len(s) && s[zeros] == '1' {
+		zeros++
+	}
+	return append(make([]byte, zeros), value.Bytes()...), true
+}
+
+// decodeBase58Check decodes a base58 string ending in the first four bytes of its payload's double SHA-256.
+func decodeBase58Check(s string) ([]byte, error) {
+	decoded, ok := decodeBase58(s)
+	if !ok || len(decoded) < 5 {
+		return nil, errors.New("not base58")
+	}
+
+	payload, checksum := decoded[:len(decoded)-4], decoded[len(decoded)-4:]
+	first := sha256.Sum256(payload)
+	second := sha256.Sum256(first[:])
+	if !bytes.Equal(second[:4], checksum) {
+		return nil, errBase58Checksum
+	}
+	return payload, nil
+}
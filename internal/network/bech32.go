@@ -0,0 +1,109 @@
+package network
+
+import (
+	"errors"
+	"strings"
+)
+
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+type bech32Encoding int
+
+const (
+	bech32 bech32Encoding = iota + 1
+	bech32m
+)
+
+// Checksum constants of BIP-173 and BIP-350.
+const (
+	bech32Const  = 1
+	bech32mConst = 0x2bc830a3
+)
+
+// decodeBech32 returns the human-readable part and the 5-bit data of a bech32 or bech32m string,
+// without the checksum.
+func decodeBech32(s string) (string, []byte, bech32Encoding, error) {
+	if len(s) > 90 {
+		return "", nil, 0, errors.New("too long")
+	}
+	if strings.ToLower(s) != s && strings.ToUpper(s) != s {
+		return "", nil, 0, errors.New("mixed case")
+	}
+	s = strings.ToLower(s)
+
+	separator := strings.LastIndexByte(s, '1')
+	if separator < 1 || separator+7 > len(s) {
+		return "", nil, 0, errors.New("invalid separator position")
+	}
+
+	hrp := s[:separator]
+	data := make([]byte, 0, len(s)-separator-1)
+	for _, c := range []byte(s[separator+1:]) {
+		value := strings.IndexByte(bech32Charset, c)
+		if value < 0 {
+			return "", nil, 0, errors.New("invalid character")
+		}
+		data = append(data, byte(value))
+	}
+
+	var encoding bech32Encoding
+	switch bech32Polymod(append(bech32ExpandHRP(hrp), data...)) {
+	case bech32Const:
+		encoding = bech32
+	case bech32mConst:
+		encoding = bech32m
+	default:
+		return "", nil, 0, errors.New("checksum mismatch")
+	}
+	return hrp, data[:len(data)-6], encoding, nil
+}
+
+func bech32Polymod(values []byte) uint32 {
+	generator := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	checksum := uint32(1)
+	for _, v := range values {
+		top := checksum >> 25
+		checksum = (checksum&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>i)&1 == 1 {
+				checksum ^= generator[i]
+			}
+		}
+	}
+	return checksum
+}
+
+func bech32ExpandHRP(hrp string) []byte {
+	expanded := make([]byte, 0, len(hrp)*2+1)
+	for _, c := range []byte(hrp) {
+		expanded = append(expanded, c>>5)
+	}
+	expanded = append(expanded, 0)
+	for _, c := range []byte(hrp) {
+		expanded = append(expanded, c&31)
+	}
+	return expanded
+}
+
+// convertBits regroups data from fromBits-bit to toBits-bit values.
+func convertBits(data []byte, fromBits, toBits uint, pad bool) ([]byte, bool) {
+	var acc, bits uint
+	maxValue := uint(1)<<toBits - 1
+	var result []byte
+	for _, value := range data {
+		acc = acc<<fromBits | uint(value)
+		bits += fromBits
+		for bits >= toBits {
+			bits -= toBits
+			result = append(result, byte(acc>>bits&maxValue))
+		}
+	}
+	if pad {
+		if bits > 0 {
+			result = append(result, byte(acc<<(toBits-bits)&maxValue))
+		}
+	} else if bits >= fromBits || acc<<(toBits-bits)&maxValue != 0 {
+		return nil, false
+	}
+	return result, true
+}
@@ -0,0 +1,53 @@
+package network
+
+import (
+	"strings"
+)
+
+// Version bytes of mainnet Base58Check addresses.
+const (
+	bitcoinP2PKH = 0x00
+	bitcoinP2SH  = 0x05
+)
+
+// NormalizeBitcoin accepts mainnet P2PKH and P2SH addresses, which are case-sensitive and kept as they
+// are, and SegWit addresses, which are lowercased.
+func NormalizeBitcoin(address string) (string, error) {
+	if strings.HasPrefix(strings.ToLower(address), "bc1") {
+		return normalizeSegwit(address)
+	}
+
+	payload, err := decodeBase58Check(address)
+	if err != nil {
+		return "", invalid("bitcoin address %q: %v", address, err)
+	}
+	if len(payload) != 21 || (payload[0] != bitcoinP2PKH && payload[0] != bitcoinP2SH) {
+		return "", invalid("bitcoin address %q is not a mainnet P2PKH or P2SH address", address)
+	}
+	return address, nil
+}
+
+// normalizeSegwit checks a BIP-173 (witness version 0) or BIP-350 (versions 1 to 16) address.
+func normalizeSegwit(address string) (string, error) {
+	hrp, data, encoding, err := decodeBech32(address)
+	if err != nil {
+		return "", invalid("bitcoin address %q: %v", address, err)
+	}
+	if hrp != "bc" || len(data) == 0 {
+		return "", invalid("bitcoin address %q is not a mainnet SegWit address", address)
+	}
+
+	version := data[0]
+	program, ok := convertBits(data[1:], 5, 8, false)
+	switch {
+	case !ok || version > 16 || len(program) < 2 || len(program) > 40:
+		return "", invalid("bitcoin address %q has an invalid witness program", address)
+	case version == 0 && len(program) != 20 && len(program) != 32:
+		return "", invalid("bitcoin address %q has an invalid witness program length", address)
+	case version == 0 && encoding != bech32:
+		return "", invalid("bitcoin address %q must use bech32 for witness version 0", address)
+	case version > 0 && encoding != bech32m:
+		return "", invalid("bitcoin address %q must use bech32m for witness version %d", address, version)
+	}
+	return strings.ToLower(address), nil
+}
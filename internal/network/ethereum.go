@@ -0,0 +1,46 @@
+package network
+
+import (
+	"encoding/hex"
+	"golang.org/x/crypto/sha3"
+	"strings"
+)
+
+// NormalizeEthereum accepts 0x-prefixed 20-byte hex addresses and returns them lowercased. Mixed-case
+// addresses carry an EIP-55 checksum, which must match.
+func NormalizeEthereum(address string) (string, error) {
+	if len(address) != 42 || (address[:2] != "0x" && address[:2] != "0X") {
+		return "", invalid("ethereum address %q must be 0x followed by 40 hex digits", address)
+	}
+
+	digits := address[2:]
+	if _, err := hex.DecodeString(digits); err != nil {
+		return "", invalid("ethereum address %q must be 0x followed by 40 hex digits", address)
+	}
+
+	lower := strings.ToLower(digits)
+	if digits != lower && digits != strings.ToUpper(digits) && digits != eip55(lower) {
+		return "", invalid("ethereum address %q has an invalid EIP-55 checksum", address)
+	}
+	return "0x" + lower, nil
+}
+
+// eip55 returns the checksummed form of 40 lowercase hex digits: a letter is uppercased when the
+// matching nibble of the Keccak-256 hash of the digits is 8 or more.
+func eip55(lower string) string {
+	hash := sha3.NewLegacyKeccak256()
+	hash.Write([]byte(lower))
+	sum := hash.Sum(nil)
+
+	checksummed := []byte(lower)
+	for i, c := range checksummed {
+		nibble := sum[i/2] >> 4
+		if i%2 == 1 {
+			nibble = sum[i/2] & 0x0f
+		}
+		if c >= 'a' && nibble >= 8 {
+			checksummed[i] = c - 'a' + 'A'
+		}
+	}
+	return string(checksummed)
+}
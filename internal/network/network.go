@@ -0,0 +1,121 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var (
+	ErrUnsupportedNetwork = errors.New("unsupported network")
+	ErrInvalidAddress     = errors.New("invalid address")
+)
+
+// Validator checks the addresses of one network.
+type Validator interface {
+	// Normalize returns the canonical form of address, or an error wrapping ErrInvalidAddress.
+	Normalize(address string) (string, error)
+}
+
+// ValidatorFunc adapts a function to Validator.
+type ValidatorFunc func(address string) (string, error)
+
+func (f ValidatorFunc) Normalize(address string) (string, error) {
+	return f(address)
+}
+
+// Network struct
+// @Description A network wallets can be created on
+type Network struct {
+	Name          string   `json:"name" example:"Ethereum"`                                                 // Canonical name, stored with the wallets
+	Aliases       []string `json:"aliases" example:"ETH"`                                                   // Other names accepted for the network, ignoring case
+	AddressFormat string   `json:"address_format" example:"0x-prefixed hex, EIP-55 checksum if mixed case"` // Accepted addresses
+}
+
+// Registry maps network names and aliases, ignoring case, to the validator of their addresses.
+type Registry struct {
+	networks   []Network
+	names      map[string]string // Lowercase name or alias to canonical name
+	validators map[string]Validator
+}
+
+func NewRegistry() *Registry {
+	return &Registry{names: map[string]string{}, validators: map[string]Validator{}}
+}
+
+// Register adds a network. Registering a name or alias twice panics, since it would make lookups ambiguous.
+func (r *Registry) Register(network Network, validator Validator) {
+	for _, name := range append([]string{network.Name}, network.Aliases...) {
+		key := strings.ToLower(name)
+		if _, taken := r.names[key]; taken {
+			panic(fmt.Sprintf("network %q is already registered", name))
+		}
+		r.names[key] = network.Name
+	}
+	r.networks = append(r.networks, network)
+	r.validators[network.Name] = validator
+}
+
+// Networks returns the registered networks ordered by name.
+func (r *Registry) Networks() []Network {
+	networks := append([]Network(nil), r.networks...)
+	sort.Slice(networks, func(i, j int) bool { return networks[i].Name < networks[j].Name })
+	return networks
+}
+
+// Name returns the canonical name of a network given by name or alias.
+func (r *Registry) Name(network string) (string, error) {
+	name, ok := r.names[strings.ToLower(strings.TrimSpace(network))]
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnsupportedNetwork, network)
+	}
+	return name, nil
+}
+
+// Normalize validates address on network and returns both in canonical form.
+func (r *Registry) Normalize(network, address string) (string, string, error) {
+	name, err := r.Name(network)
+	if err != nil {
+		return "", "", err
+	}
+
+	canonical, err := r.validators[name].Normalize(strings.TrimSpace(address))
+	if err != nil {
+		return "", "", err
+	}
+	return name, canonical, nil
+}
+
+// Canonical returns network and address in canonical form, or both unchanged if they do not validate,
+// so that rows keyed before validation was introduced stay reachable.
+func (r *Registry) Canonical(network, address string) (string, string) {
+	name, canonical, err := r.Normalize(network, address)
+	if err != nil {
+		return network, address
+	}
+	return name, canonical
+}
+
+// CanonicalName resolves network aliases such as ETH, leaving empty and unknown networks unchanged.
+func (r *Registry) CanonicalName(network string) string {
+	name, err := r.Name(network)
+	if err != nil {
+		return network
+	}
+	return name
+}
+
+// Default returns a registry with every network supported out of the box.
+func Default() *Registry {
+	r := NewRegistry()
+	r.Register(Network{Name: "Bitcoin", Aliases: []string{"BTC"}, AddressFormat: "Base58Check (P2PKH, P2SH) or bech32/bech32m (bc1...)"}, ValidatorFunc(NormalizeBitcoin))
+	r.Register(Network{Name: "Ethereum", Aliases: []string{"ETH"}, AddressFormat: "0x-prefixed hex, EIP-55 checksum if mixed case"}, ValidatorFunc(NormalizeEthereum))
+	r.Register(Network{Name: "Solana", Aliases: []string{"SOL"}, AddressFormat: "Base58 encoded 32-byte public key"}, ValidatorFunc(NormalizeSolana))
+	r.Register(Network{Name: "Tron", Aliases: []string{"TRX"}, AddressFormat: "Base58Check starting with T"}, ValidatorFunc(NormalizeTron))
+	return r
+}
+
+func invalid(format string, args ...any) error {
+	return fmt.Errorf("%w: "+format, append([]any{ErrInvalidAddress}, args...)...)
+}
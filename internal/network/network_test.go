@@ -0,0 +1,97 @@
+package network
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize_Valid(t *testing.T) {
+	tests := []struct {
+		network, address           string
+		wantNetwork, wantCanonical string
+	}{
+		{"Bitcoin", "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa", "Bitcoin", "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"},
+		{"btc", "3J98t1WpEZ73CNmQviecrnyiWrnqRhWNLy", "Bitcoin", "3J98t1WpEZ73CNmQviecrnyiWrnqRhWNLy"},
+		{"Bitcoin", "BC1QW508D6QEJXTDG4Y5R3ZARVARY0C5XW7KV8F3T4", "Bitcoin", "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4"},
+		{"Bitcoin", "bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0", "Bitcoin", "bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0"},
+		{"ethereum", "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", "Ethereum", "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"},
+		{"ETH", "0x5AAEB6053F3E94C9B9A09F33669435E7EF1BEAED", "Ethereum", "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"},
+		{"Tron", "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t", "Tron", "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t"},
+		{"SOL", "TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA", "Solana", "TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA"},
+		{"Solana", "11111111111111111111111111111111", "Solana", "11111111111111111111111111111111"},
+	}
+
+	for _, tt := range tests {
+		network, address, err := Default().Normalize(tt.network, tt.address)
+
+		assert.NoError(t, err, tt.address)
+		assert.Equal(t, tt.wantNetwork, network)
+		assert.Equal(t, tt.wantCanonical, address)
+	}
+}
+
+func TestNormalize_InvalidAddress(t *testing.T) {
+	tests := []struct{ network, address string }{
+		{"Bitcoin", "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNb"},          // Bad checksum
+		{"Bitcoin", "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t5"},  // Bad checksum
+		{"Bitcoin", "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7KV8F3T4"},  // Mixed case
+		{"Bitcoin", "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t"},          // Tron version byte
+		{"Ethereum", "0x5aaeb6053F3E94C9b9A09f33669435E7Ef1BeAed"}, // Bad EIP-55 checksum
+		{"Ethereum", "0x123"}, // Too short
+		{"Ethereum", "0xZaAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"}, // Not hex
+		{"Tron", "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6u"},             // Bad checksum
+		{"Tron", "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"},             // Bitcoin version byte
+		{"Solana", "0OIl0OIl0OIl0OIl0OIl0OIl0OIl0OIl"},             // Not base58
+		{"Solana", "1111111111111111111111111111111"},              // Too short
+		{"Solana", ""},
+	}
+
+	for _, tt := range tests {
+		_, _, err := Default().Normalize(tt.network, tt.address)
+
+		assert.True(t, errors.Is(err, ErrInvalidAddress), "%s %q: got %v", tt.network, tt.address, err)
+	}
+}
+
+func TestNormalize_UnsupportedNetwork(t *testing.T) {
+	_, _, err := Default().Normalize("Dogecoin", "DH5yaieqoZN36fDVciNyRueRGvGLR3mr7L")
+
+	assert.True(t, errors.Is(err, ErrUnsupportedNetwork))
+}
+
+func TestCanonical(t *testing.T) {
+	r := Default()
+
+	networkName, address := r.Canonical("eth", "0x5AAEB6053F3E94C9B9A09F33669435E7EF1BEAED")
+	assert.Equal(t, "Ethereum", networkName)
+	assert.Equal(t, "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", address)
+
+	// Pairs that do not validate are left as they are
+	networkName, address = r.Canonical("ETH", "0x123")
+	assert.Equal(t, "ETH", networkName)
+	assert.Equal(t, "0x123", address)
+
+	assert.Equal(t, "Tron", r.CanonicalName("trx"))
+	assert.Equal(t, "Dogecoin", r.CanonicalName("Dogecoin"))
+	assert.Equal(t, "", r.CanonicalName(""))
+}
+
+func TestRegister_DuplicateAliasPanics(t *testing.T) {
+	r := NewRegistry()
+	r.Register(Network{Name: "Ethereum", Aliases: []string{"ETH"}}, ValidatorFunc(NormalizeEthereum))
+
+	assert.Panics(t, func() {
+		r.Register(Network{Name: "Ether", Aliases: []string{"eth"}}, ValidatorFunc(NormalizeEthereum))
+	})
+}
+
+func TestNetworks_SortedByName(t *testing.T) {
+	var names []string
+	for _, n := range Default().Networks() {
+		names = append(names, n.Name)
+	}
+
+	assert.Equal(t, []string{"Bitcoin", "Ethereum", "Solana", "Tron"}, names)
+}
@@ -0,0 +1,18 @@
+package network
+
+// NormalizeSolana accepts base58 encoded 32-byte public keys. They carry no checksum and are
+// case-sensitive, so only their encoding and length can be checked.
+func NormalizeSolana(address string) (string, error) {
+	if len(address) < 32 || len(address) > 44 {
+		return "", invalid("solana address %q must be 32 to 44 base58 characters", address)
+	}
+
+	key, ok := decodeBase58(address)
+	if !ok {
+		return "", invalid("solana address %q is not base58", address)
+	}
+	if len(key) != 32 {
+		return "", invalid("solana address %q does not encode a 32-byte public key", address)
+	}
+	return address, nil
+}
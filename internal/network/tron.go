@@ -0,0 +1,16 @@
+package network
+
+// tronPrefix is the version byte of Tron mainnet addresses, which makes them start with T.
+const tronPrefix = 0x41
+
+// NormalizeTron accepts Base58Check Tron addresses. They are case-sensitive and kept as they are.
+func NormalizeTron(address string) (string, error) {
+	payload, err := decodeBase58Check(address)
+	if err != nil {
+		return "", invalid("tron address %q: %v", address, err)
+	}
+	if len(payload) != 21 || payload[0] != tronPrefix {
+		return "", invalid("tron address %q is not a mainnet address", address)
+	}
+	return address, nil
+}
@@ -0,0 +1,77 @@
+package outbox
+
+import (
+	"asset-management/pkg/event"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/segmentio/kafka-go"
+	"time"
+)
+
+// Record is a row of the event_outbox table. Services using gorm migrate it from this struct, the
+// others create it with sql.CreateEventOutboxTable; both produce the same table.
+type Record struct {
+	EventID              string     `gorm:"column:event_id;type:uuid;primaryKey"`
+	EventType            string     `gorm:"column:event_type;not null"`
+	MessageKey           string     `gorm:"column:message_key;not null"`
+	Envelope             string     `gorm:"column:envelope;type:jsonb;not null"`
+	OccurredAt           time.Time  `gorm:"column:occurred_at;not null;index:idx_event_outbox_unpublished,where:published_at IS NULL"`
+	PublishedAt          *time.Time `gorm:"column:published_at"`
+	PublishAttempts      int        `gorm:"column:publish_attempts;not null;default:0"`
+	LastPublishAttemptAt *time.Time `gorm:"column:last_publish_attempt_at"`
+}
+
+func (Record) TableName() string {
+	return "event_outbox"
+}
+
+// NewRecord wraps payload in an envelope of the given type and prepares it for the outbox. The event
+// is published with key as its message key, so events of the same key keep their order.
+func NewRecord(eventType string, payload any, key string) (Record, error) {
+	envelope, err := event.New(eventType, event.LifecycleVersion, payload, time.Now())
+	if err != nil {
+		return Record{}, err
+	}
+
+	value, err := json.Marshal(envelope)
+	if err != nil {
+		return Record{}, fmt.Errorf("failed to serialize event %s: %w", envelope.ID, err)
+	}
+
+	return Record{
+		EventID:    envelope.ID,
+		EventType:  eventType,
+		MessageKey: key,
+		Envelope:   string(value),
+		OccurredAt: envelope.OccurredAt,
+	}, nil
+}
+
+// Write adds an event to the outbox within tx, so it is published if and only if tx commits.
+func Write(ctx context.Context, tx *sql.Tx, eventType string, payload any, key string) error {
+	record, err := NewRecord(eventType, payload, key)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+        INSERT INTO event_outbox (event_id, event_type, message_key, envelope, occurred_at)
+        VALUES ($1, $2, $3, $4, $5)`,
+		record.EventID, record.EventType, record.MessageKey, record.Envelope, record.OccurredAt)
+	if err != nil {
+		return fmt.Errorf("failed to write %s event to outbox: %w", eventType, err)
+	}
+
+	return nil
+}
+
+// Message returns the Kafka message of the record, with the event headers.
+func (r Record) Message() (kafka.Message, error) {
+	var envelope event.Envelope
+	if err := json.Unmarshal([]byte(r.Envelope), &envelope); err != nil {
+		return kafka.Message{}, fmt.Errorf("failed to parse event %s: %w", r.EventID, err)
+	}
+	return envelope.Message(r.MessageKey)
+}
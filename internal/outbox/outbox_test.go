@@ -0,0 +1,23 @@
+package outbox_test
+
+import (
+	"asset-management/internal/outbox"
+	"asset-management/pkg/event"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRecord_Message(t *testing.T) {
+	record, err := outbox.NewRecord(event.TypeWalletCreated, event.WalletCreated{Address: "0x123", Network: "Ethereum"}, "0x123")
+	assert.NoError(t, err)
+	assert.Equal(t, event.TypeWalletCreated, record.EventType)
+
+	msg, err := record.Message()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "0x123", string(msg.Key))
+	assert.Equal(t, record.EventID, event.Header(msg, event.HeaderEventID))
+	assert.Equal(t, event.TypeWalletCreated, event.Header(msg, event.HeaderEventType))
+	assert.Equal(t, "1", event.Header(msg, event.HeaderSchemaVersion))
+	assert.JSONEq(t, record.Envelope, string(msg.Value))
+}
@@ -0,0 +1,91 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/lib/pq"
+)
+
+type Repository interface {
+	// Claim locks up to limit unpublished events, oldest first, and hands them to publish. They are
+	// marked published only if publish succeeds. It returns the number of events published.
+	Claim(limit int, publish func([]Record) error) (int, error)
+}
+
+type postgresRepository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &postgresRepository{db: db}
+}
+
+func (r *postgresRepository) Claim(limit int, publish func([]Record) error) (int, error) {
+	ctx := context.Background()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// SKIP LOCKED lets several relays run without publishing the same event twice
+	rows, err := tx.QueryContext(ctx, `
+        SELECT event_id, event_type, message_key, envelope, occurred_at, publish_attempts
+        FROM event_outbox
+        WHERE published_at IS NULL
+        ORDER BY occurred_at, event_id
+        LIMIT $1
+        FOR UPDATE SKIP LOCKED`, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim outbox events: %w", err)
+	}
+
+	var records []Record
+	var ids []string
+	for rows.Next() {
+		var record Record
+		if err := rows.Scan(&record.EventID, &record.EventType, &record.MessageKey, &record.Envelope, &record.OccurredAt, &record.PublishAttempts); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan outbox event: %w", err)
+		}
+		records = append(records, record)
+		ids = append(ids, record.EventID)
+	}
+	if err := rows.Close(); err != nil {
+		return 0, fmt.Errorf("failed to close rows: %w", err)
+	}
+
+	if len(records) == 0 {
+		return 0, nil
+	}
+
+	if publishErr := publish(records); publishErr != nil {
+		// Keep the events for the next run, but remember that this attempt failed
+		if _, err := tx.ExecContext(ctx, `
+            UPDATE event_outbox
+            SET publish_attempts = publish_attempts + 1, last_publish_attempt_at = CURRENT_TIMESTAMP
+            WHERE event_id = ANY($1::uuid[])`, pq.Array(ids)); err != nil {
+			return 0, fmt.Errorf("failed to record publish attempt: %w", err)
+		}
+		if err := tx.Commit(); err != nil {
+			return 0, fmt.Errorf("failed to record publish attempt: %w", err)
+		}
+		return 0, publishErr
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+        UPDATE event_outbox
+        SET published_at = CURRENT_TIMESTAMP,
+            publish_attempts = publish_attempts + 1, last_publish_attempt_at = CURRENT_TIMESTAMP
+        WHERE event_id = ANY($1::uuid[])`, pq.Array(ids)); err != nil {
+		return 0, fmt.Errorf("failed to mark events as published: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit published events: %w", err)
+	}
+
+	return len(records), nil
+}
@@ -0,0 +1,61 @@
+package outbox_test
+
+import (
+	"asset-management/internal/outbox"
+	"asset-management/pkg/event"
+	"asset-management/services/asset-api/util"
+	"context"
+	"errors"
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPostgresRepository_Claim(t *testing.T) {
+	db, cleanup := util.SetupTestContainer(t)
+	defer cleanup()
+
+	repo := outbox.NewRepository(db)
+
+	tx, err := db.BeginTx(context.Background(), nil)
+	assert.NoError(t, err)
+	for _, address := range []string{"0x1", "0x2", "0x3"} {
+		assert.NoError(t, outbox.Write(context.Background(), tx, event.TypeWalletCreated, event.WalletCreated{Address: address}, address))
+	}
+	assert.NoError(t, tx.Commit())
+
+	// A failed publish keeps the events and counts the attempt
+	count, err := repo.Claim(2, func([]outbox.Record) error { return errors.New("broker unavailable") })
+	assert.ErrorContains(t, err, "broker unavailable")
+	assert.Zero(t, count)
+
+	var attempted int
+	err = db.QueryRow(`SELECT COUNT(*) FROM event_outbox WHERE publish_attempts = 1 AND published_at IS NULL`).Scan(&attempted)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, attempted)
+
+	// Events are published oldest first
+	var keys []string
+	count, err = repo.Claim(2, func(records []outbox.Record) error {
+		for _, record := range records {
+			keys = append(keys, record.MessageKey)
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, []string{"0x1", "0x2"}, keys)
+
+	count, err = repo.Claim(2, func(records []outbox.Record) error { return nil })
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	count, err = repo.Claim(2, func(records []outbox.Record) error { return nil })
+	assert.NoError(t, err)
+	assert.Zero(t, count)
+
+	var unpublished int
+	err = db.QueryRow(`SELECT COUNT(*) FROM event_outbox WHERE published_at IS NULL`).Scan(&unpublished)
+	assert.NoError(t, err)
+	assert.Zero(t, unpublished)
+}
@@ -1,22 +1,54 @@
 package schedule
 
 import (
+	"github.com/shopspring/decimal"
 	"time"
 )
 
 type ScheduledTransaction struct {
-	ID            int       `json:"id" example:"1"`                                // Transaction ID
-	FromWallet    string    `json:"from_wallet" example:"wallet_123"`              // Sender's wallet address
-	ToWallet      string    `json:"to_wallet" example:"wallet_456"`                // Recipient's wallet address
-	Network       string    `json:"network" example:"Ethereum"`                    // Blockchain network (e.g., Ethereum)
-	Amount        float64   `json:"amount" example:"250.75"`                       // Amount to be transferred
-	ScheduledTime time.Time `json:"scheduled_time" example:"2024-10-30T15:04:05Z"` // Scheduled time for transaction
-	Status        string    `json:"status" example:"PENDING"`                      // Transaction status (e.g., pending, completed)
-	CreatedAt     time.Time `json:"created_at" example:"2024-10-29T10:15:00Z"`     // Time when the transaction was created
+	ID            int             `json:"id" example:"1"`                                // Transaction ID
+	FromWallet    string          `json:"from_wallet" example:"wallet_123"`              // Sender's wallet address
+	ToWallet      string          `json:"to_wallet" example:"wallet_456"`                // Recipient's wallet address
+	Network       string          `json:"network" example:"Ethereum"`                    // Blockchain network (e.g., Ethereum)
+	Asset         string          `json:"asset" example:"ETH"`                           // Asset symbol (e.g., ETH, USDT)
+	Amount        decimal.Decimal `json:"amount" swaggertype:"string" example:"250.75"`  // Amount to be transferred
+	ScheduledTime time.Time       `json:"scheduled_time" example:"2024-10-30T15:04:05Z"` // Scheduled time for transaction
+	Status        string          `json:"status" example:"PENDING"`                      // Transaction status (e.g., pending, completed)
+	CreatedAt     time.Time       `json:"created_at" example:"2024-10-29T10:15:00Z"`     // Time when the transaction was created
+
+	PublishedAt     *time.Time `json:"published_at,omitempty" example:"2024-10-30T15:04:06Z"`   // Time the event was confirmed by Kafka
+	PublishAttempts int        `json:"publish_attempts" example:"1"`                            // Number of times publishing was attempted
+	FailureReason   string     `json:"failure_reason,omitempty" example:"insufficient balance"` // Why processing failed, set with StatusFailed
+	Recurrence
+	SeriesID         *int `json:"series_id,omitempty" example:"1"` // ID of the first occurrence of a recurring series
+	OccurrenceNumber int  `json:"occurrence_number" example:"1"`   // 1-based position within the series
+}
+
+// Recurrence describes how a scheduled transaction repeats. A zero value means it runs once.
+type Recurrence struct {
+	Rule           string     `json:"recurrence,omitempty" example:"0 9 * * MON"`              // Cron expression or RRULE
+	Until          *time.Time `json:"recurrence_end,omitempty" example:"2025-12-31T00:00:00Z"` // No occurrence is scheduled after this time
+	MaxOccurrences *int       `json:"max_occurrences,omitempty" example:"12"`                  // Maximum number of occurrences in the series
+	Start          *time.Time `json:"-"`                                                       // First occurrence's original time, which the rule is anchored on
+}
+
+// Columns lists the scheduled_transactions columns read by Scan, in order.
+const Columns = `scheduled_transaction_id, from_wallet_address, to_wallet_address, network, asset, amount, scheduled_time, status, created_at,
+        published_at, publish_attempts, COALESCE(failure_reason, ''), COALESCE(recurrence, ''), recurrence_end, max_occurrences, recurrence_start, series_id, occurrence_number`
+
+// Scan reads a row selected with Columns.
+func Scan(row interface{ Scan(dest ...any) error }) (ScheduledTransaction, error) {
+	var txn ScheduledTransaction
+	err := row.Scan(&txn.ID, &txn.FromWallet, &txn.ToWallet, &txn.Network, &txn.Asset, &txn.Amount, &txn.ScheduledTime, &txn.Status, &txn.CreatedAt,
+		&txn.PublishedAt, &txn.PublishAttempts, &txn.FailureReason, &txn.Rule, &txn.Until, &txn.MaxOccurrences, &txn.Start, &txn.SeriesID, &txn.OccurrenceNumber)
+	return txn, err
 }
 
 const (
-	StatusPending   = "PENDING"
-	StatusCompleted = "COMPLETED"
-	StatusFailed    = "FAILED"
+	StatusAwaitingApproval = "AWAITING_APPROVAL" // Above an approval threshold, becomes PENDING once approved
+	StatusPending          = "PENDING"
+	StatusPublished        = "PUBLISHED" // Event written to Kafka, waiting to be processed
+	StatusCompleted        = "COMPLETED"
+	StatusFailed           = "FAILED"
+	StatusCancelled        = "CANCELLED"
 )
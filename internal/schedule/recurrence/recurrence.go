@@ -0,0 +1,278 @@
+package recurrence
+
+import (
+	"fmt"
+	"github.com/robfig/cron/v3"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule returns the first fire time strictly after the given time, or the zero time if there is none.
+type Schedule interface {
+	Next(time.Time) time.Time
+}
+
+// Parse parses a recurrence expression anchored at start.
+//
+// Two forms are accepted:
+//   - a standard five-field cron expression or descriptor, e.g. "0 9 * * MON" or "@daily"
+//   - an RFC 5545 RRULE subset, e.g. "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=1"
+//     (optionally prefixed with "RRULE:"), supporting FREQ=DAILY|WEEKLY|MONTHLY, INTERVAL,
+//     BYDAY, BYMONTHDAY, BYSETPOS, BYHOUR and BYMINUTE.
+//
+// The end of a series is not part of the expression; it is given by the schedule's
+// recurrence end and maximum occurrences instead, so COUNT and UNTIL are rejected.
+func Parse(expr string, start time.Time) (Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	upper := strings.ToUpper(expr)
+	if strings.HasPrefix(upper, "RRULE:") || strings.HasPrefix(upper, "FREQ=") {
+		return parseRule(strings.TrimPrefix(upper, "RRULE:"), start)
+	}
+
+	schedule, err := cron.ParseStandard(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cron expression: %w", err)
+	}
+	return schedule, nil
+}
+
+const (
+	freqDaily   = "DAILY"
+	freqWeekly  = "WEEKLY"
+	freqMonthly = "MONTHLY"
+
+	// maxPeriods bounds the search for the next occurrence, e.g. a MONTHLY rule on
+	// BYMONTHDAY=31 skips the months that are too short.
+	maxPeriods = 10000
+)
+
+var weekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+type rule struct {
+	freq       string
+	interval   int
+	byDay      []time.Weekday
+	byMonthDay []int
+	bySetPos   []int
+	byHour     []int
+	byMinute   []int
+	start      time.Time
+}
+
+func parseRule(expr string, start time.Time) (*rule, error) {
+	r := &rule{interval: 1, start: start}
+
+	for _, part := range strings.Split(expr, ";") {
+		if part == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return nil, fmt.Errorf("invalid RRULE part %q", part)
+		}
+
+		var err error
+		switch name {
+		case "FREQ":
+			if value != freqDaily && value != freqWeekly && value != freqMonthly {
+				return nil, fmt.Errorf("unsupported RRULE FREQ %q", value)
+			}
+			r.freq = value
+		case "INTERVAL":
+			r.interval, err = strconv.Atoi(value)
+			if err != nil || r.interval < 1 {
+				return nil, fmt.Errorf("invalid RRULE INTERVAL %q", value)
+			}
+		case "BYDAY":
+			for _, day := range strings.Split(value, ",") {
+				weekday, ok := weekdays[day]
+				if !ok {
+					return nil, fmt.Errorf("invalid RRULE BYDAY %q", day)
+				}
+				r.byDay = append(r.byDay, weekday)
+			}
+		case "BYMONTHDAY":
+			r.byMonthDay, err = parseInts(name, value, -31, 31, false)
+		case "BYSETPOS":
+			r.bySetPos, err = parseInts(name, value, -366, 366, false)
+		case "BYHOUR":
+			r.byHour, err = parseInts(name, value, 0, 23, true)
+		case "BYMINUTE":
+			r.byMinute, err = parseInts(name, value, 0, 59, true)
+		case "COUNT", "UNTIL":
+			return nil, fmt.Errorf("RRULE %s is not supported, use max_occurrences or recurrence_end instead", name)
+		default:
+			return nil, fmt.Errorf("unsupported RRULE part %q", name)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if r.freq == "" {
+		return nil, fmt.Errorf("RRULE FREQ is required")
+	}
+
+	// Unset parts default to the corresponding part of the start time
+	if r.freq == freqWeekly && len(r.byDay) == 0 {
+		r.byDay = []time.Weekday{start.Weekday()}
+	}
+	if r.freq == freqMonthly && len(r.byDay) == 0 && len(r.byMonthDay) == 0 {
+		r.byMonthDay = []int{start.Day()}
+	}
+	if len(r.byHour) == 0 {
+		r.byHour = []int{start.Hour()}
+	}
+	if len(r.byMinute) == 0 {
+		r.byMinute = []int{start.Minute()}
+	}
+	sort.Ints(r.byHour)
+	sort.Ints(r.byMinute)
+
+	return r, nil
+}
+
+func parseInts(name, value string, min, max int, allowZero bool) ([]int, error) {
+	var result []int
+	for _, item := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(item)
+		if err != nil || n < min || n > max || (n == 0 && !allowZero) {
+			return nil, fmt.Errorf("invalid RRULE %s %q", name, item)
+		}
+		result = append(result, n)
+	}
+	return result, nil
+}
+
+func (r *rule) Next(after time.Time) time.Time {
+	period := r.skip(r.periodStart(r.start), r.periodStart(after.In(r.start.Location())))
+	for i := 0; i < maxPeriods; i++ {
+		for _, candidate := range r.candidates(period) {
+			if candidate.After(after) && !candidate.Before(r.start) {
+				return candidate
+			}
+		}
+		period = r.advance(period)
+	}
+	return time.Time{}
+}
+
+// periodStart returns midnight of the first day of the day, week (starting Monday) or month containing t.
+func (r *rule) periodStart(t time.Time) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	switch r.freq {
+	case freqWeekly:
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case freqMonthly:
+		return day.AddDate(0, 0, 1-day.Day())
+	default:
+		return day
+	}
+}
+
+// skip returns the last period of the rule starting no later than target, or period if target is
+// before it. Earlier periods end before target, so they hold no occurrence after it.
+func (r *rule) skip(period, target time.Time) time.Time {
+	if !target.After(period) {
+		return period
+	}
+	from := time.Date(period.Year(), period.Month(), period.Day(), 0, 0, 0, 0, time.UTC)
+	to := time.Date(target.Year(), target.Month(), target.Day(), 0, 0, 0, 0, time.UTC)
+	days := int(to.Sub(from).Hours() / 24)
+	switch r.freq {
+	case freqWeekly:
+		return period.AddDate(0, 0, days/7/r.interval*r.interval*7)
+	case freqMonthly:
+		months := (to.Year()-from.Year())*12 + int(to.Month()-from.Month())
+		return period.AddDate(0, months/r.interval*r.interval, 0)
+	default:
+		return period.AddDate(0, 0, days/r.interval*r.interval)
+	}
+}
+
+func (r *rule) advance(period time.Time) time.Time {
+	switch r.freq {
+	case freqWeekly:
+		return period.AddDate(0, 0, 7*r.interval)
+	case freqMonthly:
+		return period.AddDate(0, r.interval, 0)
+	default:
+		return period.AddDate(0, 0, r.interval)
+	}
+}
+
+// candidates returns the sorted occurrences within the period starting at period.
+func (r *rule) candidates(period time.Time) []time.Time {
+	days := 1
+	switch r.freq {
+	case freqWeekly:
+		days = 7
+	case freqMonthly:
+		days = period.AddDate(0, 1, -1).Day()
+	}
+
+	var result []time.Time
+	for i := 0; i < days; i++ {
+		day := period.AddDate(0, 0, i)
+		if !r.matchesDay(day) {
+			continue
+		}
+		for _, hour := range r.byHour {
+			for _, minute := range r.byMinute {
+				result = append(result, time.Date(day.Year(), day.Month(), day.Day(), hour, minute, r.start.Second(), 0, day.Location()))
+			}
+		}
+	}
+
+	if len(r.bySetPos) == 0 {
+		return result
+	}
+
+	var selected []time.Time
+	for _, pos := range r.bySetPos {
+		index := pos - 1
+		if pos < 0 {
+			index = len(result) + pos
+		}
+		if index >= 0 && index < len(result) {
+			selected = append(selected, result[index])
+		}
+	}
+	sort.Slice(selected, func(i, j int) bool { return selected[i].Before(selected[j]) })
+	return selected
+}
+
+func (r *rule) matchesDay(day time.Time) bool {
+	if len(r.byDay) > 0 && !containsWeekday(r.byDay, day.Weekday()) {
+		return false
+	}
+	if len(r.byMonthDay) > 0 {
+		daysInMonth := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, day.Location()).Day()
+		for _, monthDay := range r.byMonthDay {
+			if monthDay == day.Day() || daysInMonth+monthDay+1 == day.Day() {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func containsWeekday(weekdays []time.Weekday, weekday time.Weekday) bool {
+	for _, w := range weekdays {
+		if w == weekday {
+			return true
+		}
+	}
+	return false
+}
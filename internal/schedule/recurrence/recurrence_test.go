@@ -0,0 +1,110 @@
+package recurrence_test
+
+import (
+	"asset-management/internal/schedule/recurrence"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day, hour, minute int) time.Time {
+	return time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+}
+
+func TestParse_Cron(t *testing.T) {
+	start := date(2024, time.November, 4, 9, 0) // Monday
+
+	schedule, err := recurrence.Parse("0 9 * * MON", start)
+	assert.NoError(t, err)
+
+	assert.Equal(t, date(2024, time.November, 11, 9, 0), schedule.Next(start))
+}
+
+func TestParse_InvalidCron(t *testing.T) {
+	_, err := recurrence.Parse("every monday", time.Now())
+	assert.Error(t, err)
+}
+
+func TestParse_RuleWeeklyWithInterval(t *testing.T) {
+	start := date(2024, time.November, 4, 9, 0) // Monday
+
+	schedule, err := recurrence.Parse("RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR", start)
+	assert.NoError(t, err)
+
+	next := schedule.Next(start)
+	assert.Equal(t, date(2024, time.November, 8, 9, 0), next)
+	assert.Equal(t, date(2024, time.November, 18, 9, 0), schedule.Next(next))
+}
+
+func TestParse_RuleLongAfterStart(t *testing.T) {
+	start := date(2024, time.November, 4, 9, 0) // Monday
+
+	biweekly, err := recurrence.Parse("FREQ=WEEKLY;INTERVAL=2", start)
+	assert.NoError(t, err)
+	// The weeks in between stay aligned on the start
+	assert.Equal(t, date(2054, time.November, 23, 9, 0), biweekly.Next(date(2054, time.November, 9, 9, 0)))
+
+	// More periods after the start than the search for the next occurrence covers
+	daily, err := recurrence.Parse("FREQ=DAILY", start)
+	assert.NoError(t, err)
+	assert.Equal(t, date(2060, time.January, 1, 9, 0), daily.Next(date(2060, time.January, 1, 0, 0)))
+
+	quarterly, err := recurrence.Parse("FREQ=MONTHLY;INTERVAL=3", start)
+	assert.NoError(t, err)
+	assert.Equal(t, date(2080, time.February, 4, 9, 0), quarterly.Next(date(2080, time.January, 1, 0, 0)))
+}
+
+func TestParse_RuleFirstBusinessDayOfMonth(t *testing.T) {
+	start := date(2024, time.November, 1, 9, 0) // Friday
+
+	schedule, err := recurrence.Parse("FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=1", start)
+	assert.NoError(t, err)
+
+	next := schedule.Next(start)
+	assert.Equal(t, date(2024, time.December, 2, 9, 0), next) // December 1st is a Sunday
+	assert.Equal(t, date(2025, time.January, 1, 9, 0), schedule.Next(next))
+}
+
+func TestParse_RuleLastDayOfMonth(t *testing.T) {
+	start := date(2024, time.January, 31, 18, 30)
+
+	schedule, err := recurrence.Parse("FREQ=MONTHLY;BYMONTHDAY=-1", start)
+	assert.NoError(t, err)
+
+	assert.Equal(t, date(2024, time.February, 29, 18, 30), schedule.Next(start))
+}
+
+func TestParse_RuleMonthDaySkipsShortMonths(t *testing.T) {
+	start := date(2024, time.January, 31, 0, 0)
+
+	schedule, err := recurrence.Parse("FREQ=MONTHLY", start)
+	assert.NoError(t, err)
+
+	assert.Equal(t, date(2024, time.March, 31, 0, 0), schedule.Next(start))
+}
+
+func TestParse_RuleDailyByHour(t *testing.T) {
+	start := date(2024, time.November, 4, 9, 0)
+
+	schedule, err := recurrence.Parse("FREQ=DAILY;BYHOUR=9,17;BYMINUTE=0", start)
+	assert.NoError(t, err)
+
+	next := schedule.Next(start)
+	assert.Equal(t, date(2024, time.November, 4, 17, 0), next)
+	assert.Equal(t, date(2024, time.November, 5, 9, 0), schedule.Next(next))
+}
+
+func TestParse_RuleErrors(t *testing.T) {
+	for _, expr := range []string{
+		"FREQ=YEARLY",
+		"FREQ=DAILY;COUNT=3",
+		"FREQ=WEEKLY;BYDAY=XX",
+		"FREQ=MONTHLY;BYMONTHDAY=0",
+		"FREQ=DAILY;INTERVAL=0",
+		"INTERVAL=2",
+		"FREQ=DAILY;FOO=1",
+	} {
+		_, err := recurrence.Parse(expr, time.Now())
+		assert.Error(t, err, expr)
+	}
+}
@@ -1,13 +1,33 @@
 package scheduled_next
 
 import (
+	"asset-management/internal/funds"
+	"asset-management/internal/limits"
+	"asset-management/internal/outbox"
 	"asset-management/internal/schedule"
+	"asset-management/pkg/event"
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"github.com/lib/pq"
+	"sort"
+	"time"
 )
 
+// Occurrence is a follow-up of a recurring scheduled transaction to insert once it has been published.
+type Occurrence struct {
+	Previous      schedule.ScheduledTransaction
+	ScheduledTime time.Time
+}
+
+// PublishFunc publishes the claimed transactions and returns the occurrences that follow them.
+// The transactions are only marked as published when it returns no error.
+type PublishFunc func(transactions []schedule.ScheduledTransaction) ([]Occurrence, error)
+
 type NextRepository interface {
 	GetNextMinuteTransactions() ([]schedule.ScheduledTransaction, error)
+	ClaimDue(limit int, publish PublishFunc) (int, error)
 }
 
 type postgresNextRepository struct {
@@ -18,13 +38,14 @@ func NewNextRepository(db *sql.DB) NextRepository {
 	return &postgresNextRepository{db: db}
 }
 
+// GetNextMinuteTransactions previews the pending transactions that are due now or within the next minute.
 func (r *postgresNextRepository) GetNextMinuteTransactions() ([]schedule.ScheduledTransaction, error) {
 	rows, err := r.db.Query(`
-        SELECT scheduled_transaction_id, from_wallet_address, to_wallet_address, network, amount, scheduled_time, status, created_at
+        SELECT ` + schedule.Columns + `
         FROM scheduled_transactions
-        WHERE scheduled_time >= (NOW() AT TIME ZONE 'Europe/Istanbul' - INTERVAL '5 minute')
-          AND scheduled_time < (NOW() AT TIME ZONE 'Europe/Istanbul' + INTERVAL '5 minute')
-          AND status = 'PENDING'`)
+        WHERE scheduled_time < (NOW() AT TIME ZONE 'Europe/Istanbul' + INTERVAL '1 minute')
+          AND status = 'PENDING'
+        ORDER BY scheduled_time`)
 
 	if err != nil {
 		return nil, err
@@ -32,8 +53,8 @@ func (r *postgresNextRepository) GetNextMinuteTransactions() ([]schedule.Schedul
 
 	var transactions []schedule.ScheduledTransaction
 	for rows.Next() {
-		var txn schedule.ScheduledTransaction
-		if err := rows.Scan(&txn.ID, &txn.FromWallet, &txn.ToWallet, &txn.Network, &txn.Amount, &txn.ScheduledTime, &txn.Status, &txn.CreatedAt); err != nil {
+		txn, err := schedule.Scan(rows)
+		if err != nil {
 			return nil, err
 		}
 		transactions = append(transactions, txn)
@@ -45,3 +66,173 @@ func (r *postgresNextRepository) GetNextMinuteTransactions() ([]schedule.Schedul
 
 	return transactions, nil
 }
+
+// ClaimDue locks up to limit pending transactions whose scheduled time has passed, however long ago,
+// skipping rows locked by another publisher, and hands them to publish while the lock is held.
+// If publish succeeds the rows are marked PUBLISHED and the returned occurrences are inserted;
+// otherwise they stay PENDING. Either way the publish attempt is counted. It returns the number of
+// transactions published.
+func (r *postgresNextRepository) ClaimDue(limit int, publish PublishFunc) (int, error) {
+	ctx := context.Background()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+        SELECT `+schedule.Columns+`
+        FROM scheduled_transactions
+        WHERE status = 'PENDING'
+          AND scheduled_time <= NOW() AT TIME ZONE 'Europe/Istanbul'
+        ORDER BY scheduled_time
+        LIMIT $1
+        FOR UPDATE SKIP LOCKED`, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim due transactions: %w", err)
+	}
+
+	var transactions []schedule.ScheduledTransaction
+	var ids []int64
+	for rows.Next() {
+		txn, err := schedule.Scan(rows)
+		if err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan due transaction: %w", err)
+		}
+		transactions = append(transactions, txn)
+		ids = append(ids, int64(txn.ID))
+	}
+	if err := rows.Close(); err != nil {
+		return 0, fmt.Errorf("failed to close rows: %w", err)
+	}
+
+	if len(transactions) == 0 {
+		return 0, nil
+	}
+
+	occurrences, publishErr := publish(transactions)
+	if publishErr != nil {
+		// Keep the rows PENDING for the next run, but remember that this attempt failed
+		if _, err := tx.ExecContext(ctx, `
+            UPDATE scheduled_transactions
+            SET publish_attempts = publish_attempts + 1, last_publish_attempt_at = CURRENT_TIMESTAMP
+            WHERE scheduled_transaction_id = ANY($1)`, pq.Array(ids)); err != nil {
+			return 0, fmt.Errorf("failed to record publish attempt: %w", err)
+		}
+		if err := tx.Commit(); err != nil {
+			return 0, fmt.Errorf("failed to record publish attempt: %w", err)
+		}
+		return 0, publishErr
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+        UPDATE scheduled_transactions
+        SET status = 'PUBLISHED', published_at = CURRENT_TIMESTAMP,
+            publish_attempts = publish_attempts + 1, last_publish_attempt_at = CURRENT_TIMESTAMP
+        WHERE scheduled_transaction_id = ANY($1)`, pq.Array(ids)); err != nil {
+		return 0, fmt.Errorf("failed to mark transactions as published: %w", err)
+	}
+
+	// Lock the senders' balances in a stable order, so that concurrent publishers cannot deadlock
+	sort.SliceStable(occurrences, func(i, j int) bool {
+		a, b := occurrences[i].Previous, occurrences[j].Previous
+		if a.FromWallet != b.FromWallet {
+			return a.FromWallet < b.FromWallet
+		}
+		if a.Network != b.Network {
+			return a.Network < b.Network
+		}
+		return a.Asset < b.Asset
+	})
+	for _, occurrence := range occurrences {
+		if err := insertOccurrence(ctx, tx, occurrence); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit published transactions: %w", err)
+	}
+
+	return len(transactions), nil
+}
+
+// insertOccurrence inserts the occurrence following occurrence.Previous in its series.
+// An occurrence that already exists is left untouched. A PENDING occurrence holds its amount, so it
+// is inserted as FAILED, which ends the series, if the sender's available funds do not cover it or
+// it breaks a withdrawal limit; a scheduled_transfer.failed event tells why.
+func insertOccurrence(ctx context.Context, tx *sql.Tx, occurrence Occurrence) error {
+	txn := occurrence.Previous
+	seriesID := txn.ID
+	if txn.SeriesID != nil {
+		seriesID = *txn.SeriesID
+	}
+
+	var exists bool
+	err := tx.QueryRowContext(ctx, `
+        SELECT EXISTS (SELECT 1 FROM scheduled_transactions WHERE series_id = $1 AND occurrence_number = $2)`,
+		seriesID, txn.OccurrenceNumber+1).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to look up next occurrence: %w", err)
+	}
+	if exists {
+		return nil
+	}
+
+	status, reason := schedule.StatusPending, ""
+	err = vet(ctx, tx, txn)
+	switch {
+	case errors.Is(err, funds.ErrInsufficientBalance), errors.Is(err, funds.ErrFundsHeld), errors.Is(err, limits.ErrLimitExceeded):
+		status, reason = schedule.StatusFailed, err.Error()
+	case err != nil:
+		return err
+	}
+
+	var id int
+	err = tx.QueryRowContext(ctx, `
+        INSERT INTO scheduled_transactions (from_wallet_address, to_wallet_address, network, asset, amount, scheduled_time, status,
+                                            recurrence, recurrence_end, max_occurrences, recurrence_start, series_id, occurrence_number,
+                                            failure_reason)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, NULLIF($14, ''))
+        RETURNING scheduled_transaction_id`,
+		txn.FromWallet, txn.ToWallet, txn.Network, txn.Asset, txn.Amount, occurrence.ScheduledTime, status,
+		txn.Rule, txn.Until, txn.MaxOccurrences, txn.Start, seriesID, txn.OccurrenceNumber+1, reason).Scan(&id)
+	if err != nil {
+		return fmt.Errorf("failed to insert next occurrence: %w", err)
+	}
+
+	if status == schedule.StatusFailed {
+		return outbox.Write(ctx, tx, event.TypeScheduledTransferFailed, event.ScheduledTransferFailed{
+			TransactionID: id,
+			FromWallet:    txn.FromWallet,
+			ToWallet:      txn.ToWallet,
+			Network:       txn.Network,
+			Asset:         txn.Asset,
+			Amount:        txn.Amount,
+			Reason:        reason,
+		}, txn.FromWallet)
+	}
+	return nil
+}
+
+// vet locks the sender's balance and checks that the available funds cover another occurrence of txn
+// within the withdrawal limits. Approving the first occurrence approved the series, so approval
+// thresholds do not apply.
+func vet(ctx context.Context, tx *sql.Tx, txn schedule.ScheduledTransaction) error {
+	sender, err := funds.Lock(ctx, tx, txn.FromWallet, txn.Network, txn.Asset)
+	if err != nil {
+		return err
+	}
+	if err := sender.Cover(txn.Amount, txn.Asset); err != nil {
+		return err
+	}
+	return limits.Check(ctx, tx, limits.Outflow{
+		WalletAddress: txn.FromWallet,
+		Network:       txn.Network,
+		Asset:         txn.Asset,
+		Amount:        txn.Amount,
+		Funds:         *sender,
+	})
+}
@@ -1,9 +1,12 @@
 package scheduled_next_test
 
 import (
+	"asset-management/internal/schedule"
 	"asset-management/internal/schedule/scheduled_next"
 	"asset-management/services/asset-api/util"
+	"errors"
 	_ "github.com/lib/pq"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"testing"
 	"time"
@@ -18,9 +21,9 @@ func TestPostgresNextRepository_GetNextMinuteTransactions_Success(t *testing.T)
 	// Insert a transaction scheduled for the next minute
 	scheduledTime := time.Now().Add(30 * time.Second) // Set the scheduled time from code
 	_, err := db.Exec(`
-		INSERT INTO scheduled_transactions (from_wallet_address, to_wallet_address, network, amount, scheduled_time, status)
-		VALUES ($1, $2, $3, $4, $5, $6)`,
-		"wallet123", "wallet456", "mainnet", 100.50, scheduledTime, "PENDING",
+		INSERT INTO scheduled_transactions (from_wallet_address, to_wallet_address, network, asset, amount, scheduled_time, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		"wallet123", "wallet456", "mainnet", "ETH", "100.50", scheduledTime, "PENDING",
 	)
 	assert.NoError(t, err)
 
@@ -44,3 +47,150 @@ func TestPostgresNextRepository_GetNextMinuteTransactions_EmptyResult(t *testing
 	assert.NoError(t, err)
 	assert.Empty(t, transactions)
 }
+
+func TestPostgresNextRepository_ClaimDue_MarksPublished(t *testing.T) {
+	db, cleanup := util.SetupTestContainer(t)
+	defer cleanup()
+
+	repo := scheduled_next.NewNextRepository(db)
+	// The published occurrence is still held, so the next one needs as much again
+	assert.NoError(t, util.InsertBalance(db, "wallet123", "mainnet", "ETH", decimal.RequireFromString("201")))
+
+	// Overdue by far more than the publisher interval, and recurring
+	var id int
+	err := db.QueryRow(`
+		INSERT INTO scheduled_transactions (from_wallet_address, to_wallet_address, network, asset, amount, scheduled_time, status, recurrence, recurrence_start)
+		VALUES ($1, $2, $3, $4, $5, NOW() AT TIME ZONE 'Europe/Istanbul' - INTERVAL '3 days', $6, $7, NOW() AT TIME ZONE 'Europe/Istanbul' - INTERVAL '3 days')
+		RETURNING scheduled_transaction_id`,
+		"wallet123", "wallet456", "mainnet", "ETH", "100.50", "PENDING", "@daily",
+	).Scan(&id)
+	assert.NoError(t, err)
+
+	count, err := repo.ClaimDue(10, func(transactions []schedule.ScheduledTransaction) ([]scheduled_next.Occurrence, error) {
+		assert.Len(t, transactions, 1)
+		next := scheduled_next.Occurrence{Previous: transactions[0], ScheduledTime: transactions[0].ScheduledTime.Add(24 * time.Hour)}
+		// Returning the same occurrence twice must insert it once
+		return []scheduled_next.Occurrence{next, next}, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	var status string
+	var attempts int
+	var publishedAt *time.Time
+	err = db.QueryRow(`SELECT status, publish_attempts, published_at FROM scheduled_transactions WHERE scheduled_transaction_id = $1`, id).
+		Scan(&status, &attempts, &publishedAt)
+	assert.NoError(t, err)
+	assert.Equal(t, "PUBLISHED", status)
+	assert.Equal(t, 1, attempts)
+	assert.NotNil(t, publishedAt)
+
+	var seriesID, occurrenceNumber int
+	err = db.QueryRow(`SELECT series_id, occurrence_number FROM scheduled_transactions WHERE scheduled_transaction_id <> $1`, id).Scan(&seriesID, &occurrenceNumber)
+	assert.NoError(t, err)
+	assert.Equal(t, id, seriesID)
+	assert.Equal(t, 2, occurrenceNumber)
+
+	// The published row is not claimed again; the new occurrence is still two days overdue
+	count, err = repo.ClaimDue(10, func(transactions []schedule.ScheduledTransaction) ([]scheduled_next.Occurrence, error) {
+		assert.Len(t, transactions, 1)
+		assert.Equal(t, 2, transactions[0].OccurrenceNumber)
+		// The series keeps its start
+		assert.NotNil(t, transactions[0].Start)
+		return nil, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestPostgresNextRepository_ClaimDue_PublishFailure(t *testing.T) {
+	db, cleanup := util.SetupTestContainer(t)
+	defer cleanup()
+
+	repo := scheduled_next.NewNextRepository(db)
+
+	var id int
+	err := db.QueryRow(`
+		INSERT INTO scheduled_transactions (from_wallet_address, to_wallet_address, network, asset, amount, scheduled_time, status)
+		VALUES ($1, $2, $3, $4, $5, NOW() AT TIME ZONE 'Europe/Istanbul' - INTERVAL '1 minute', $6) RETURNING scheduled_transaction_id`,
+		"wallet123", "wallet456", "mainnet", "ETH", "100.50", "PENDING",
+	).Scan(&id)
+	assert.NoError(t, err)
+
+	count, err := repo.ClaimDue(10, func([]schedule.ScheduledTransaction) ([]scheduled_next.Occurrence, error) {
+		return nil, errors.New("kafka unavailable")
+	})
+	assert.EqualError(t, err, "kafka unavailable")
+	assert.Equal(t, 0, count)
+
+	var status string
+	var attempts int
+	err = db.QueryRow(`SELECT status, publish_attempts FROM scheduled_transactions WHERE scheduled_transaction_id = $1`, id).Scan(&status, &attempts)
+	assert.NoError(t, err)
+	assert.Equal(t, "PENDING", status)
+	assert.Equal(t, 1, attempts)
+}
+
+func TestPostgresNextRepository_ClaimDue_SkipsLockedRows(t *testing.T) {
+	db, cleanup := util.SetupTestContainer(t)
+	defer cleanup()
+
+	repo := scheduled_next.NewNextRepository(db)
+
+	_, err := db.Exec(`
+		INSERT INTO scheduled_transactions (from_wallet_address, to_wallet_address, network, asset, amount, scheduled_time, status)
+		VALUES ($1, $2, $3, $4, $5, NOW() AT TIME ZONE 'Europe/Istanbul' - INTERVAL '1 minute', $6)`,
+		"wallet123", "wallet456", "mainnet", "ETH", "100.50", "PENDING",
+	)
+	assert.NoError(t, err)
+
+	// While one publisher holds the row, a concurrent one claims nothing
+	count, err := repo.ClaimDue(10, func(transactions []schedule.ScheduledTransaction) ([]scheduled_next.Occurrence, error) {
+		concurrent, err := repo.ClaimDue(10, func([]schedule.ScheduledTransaction) ([]scheduled_next.Occurrence, error) {
+			t.Error("locked row must not be claimed twice")
+			return nil, nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 0, concurrent)
+		return nil, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestPostgresNextRepository_ClaimDue_OccurrenceNotCovered(t *testing.T) {
+	db, cleanup := util.SetupTestContainer(t)
+	defer cleanup()
+
+	repo := scheduled_next.NewNextRepository(db)
+	// Enough for the published occurrence, but not for the next one as well
+	assert.NoError(t, util.InsertBalance(db, "wallet123", "mainnet", "ETH", decimal.RequireFromString("150")))
+
+	var id int
+	err := db.QueryRow(`
+		INSERT INTO scheduled_transactions (from_wallet_address, to_wallet_address, network, asset, amount, scheduled_time, status, recurrence)
+		VALUES ($1, $2, $3, $4, $5, NOW() AT TIME ZONE 'Europe/Istanbul' - INTERVAL '1 minute', $6, $7) RETURNING scheduled_transaction_id`,
+		"wallet123", "wallet456", "mainnet", "ETH", "100.50", "PENDING", "@daily",
+	).Scan(&id)
+	assert.NoError(t, err)
+
+	count, err := repo.ClaimDue(10, func(transactions []schedule.ScheduledTransaction) ([]scheduled_next.Occurrence, error) {
+		return []scheduled_next.Occurrence{{Previous: transactions[0], ScheduledTime: transactions[0].ScheduledTime.Add(24 * time.Hour)}}, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	// The next occurrence fails at once and holds nothing
+	var next int
+	var status, reason string
+	err = db.QueryRow(`SELECT scheduled_transaction_id, status, failure_reason FROM scheduled_transactions WHERE series_id = $1`, id).
+		Scan(&next, &status, &reason)
+	assert.NoError(t, err)
+	assert.Equal(t, "FAILED", status)
+	assert.Contains(t, reason, "insufficient available balance")
+
+	var eventType string
+	err = db.QueryRow(`SELECT event_type FROM event_outbox WHERE (envelope->'payload'->>'transaction_id')::INT = $1`, next).Scan(&eventType)
+	assert.NoError(t, err)
+	assert.Equal(t, "scheduled_transfer.failed", eventType)
+}
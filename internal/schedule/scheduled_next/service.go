@@ -1,9 +1,14 @@
 package scheduled_next
 
-import "asset-management/internal/schedule"
+import (
+	"asset-management/internal/schedule"
+	"asset-management/internal/schedule/recurrence"
+	"github.com/rs/zerolog/log"
+)
 
 type NextService interface {
 	GetNextMinuteTransactions() ([]schedule.ScheduledTransaction, error)
+	PublishDue(batchSize int, publish func([]schedule.ScheduledTransaction) error) (int, error)
 }
 
 type nextService struct {
@@ -17,3 +22,68 @@ func NewNextService(repo NextRepository) NextService {
 func (s *nextService) GetNextMinuteTransactions() ([]schedule.ScheduledTransaction, error) {
 	return s.repo.GetNextMinuteTransactions()
 }
+
+// PublishDue hands every due pending transaction to publish, in batches of batchSize, and schedules
+// the next occurrence of the recurring ones that were published. It stops at the first failed batch
+// and returns the number of transactions published.
+func (s *nextService) PublishDue(batchSize int, publish func([]schedule.ScheduledTransaction) error) (int, error) {
+	published := 0
+	for {
+		count, err := s.repo.ClaimDue(batchSize, func(transactions []schedule.ScheduledTransaction) ([]Occurrence, error) {
+			if err := publish(transactions); err != nil {
+				return nil, err
+			}
+			return nextOccurrences(transactions), nil
+		})
+		published += count
+		if err != nil {
+			return published, err
+		}
+		if count < batchSize {
+			return published, nil
+		}
+	}
+}
+
+// nextOccurrences returns the occurrence following each recurring transaction, unless its series
+// has reached its end date or maximum occurrences. The rule is followed from the series' start, so
+// rescheduling an occurrence moves no other; occurrences it was moved past are skipped.
+func nextOccurrences(transactions []schedule.ScheduledTransaction) []Occurrence {
+	var occurrences []Occurrence
+	for _, txn := range transactions {
+		if txn.Rule == "" {
+			continue
+		}
+
+		if txn.MaxOccurrences != nil && txn.OccurrenceNumber >= *txn.MaxOccurrences {
+			continue
+		}
+
+		// Series created without a start follow the rule from the current occurrence
+		start, steps := txn.ScheduledTime, 1
+		if txn.Start != nil {
+			start, steps = *txn.Start, txn.OccurrenceNumber
+		}
+
+		rule, err := recurrence.Parse(txn.Rule, start)
+		if err != nil {
+			// Rules are validated on creation, so this only happens for rows written by hand
+			log.Error().Err(err).Int("transaction_id", txn.ID).Msg("Invalid recurrence, series stopped")
+			continue
+		}
+
+		next := start
+		for i := 0; i < steps && !next.IsZero(); i++ {
+			next = rule.Next(next)
+		}
+		for !next.IsZero() && !next.After(txn.ScheduledTime) {
+			next = rule.Next(next)
+		}
+		if next.IsZero() || (txn.Until != nil && next.After(*txn.Until)) {
+			continue
+		}
+
+		occurrences = append(occurrences, Occurrence{Previous: txn, ScheduledTime: next})
+	}
+	return occurrences
+}
@@ -4,9 +4,11 @@ import (
 	"asset-management/internal/schedule"
 	"asset-management/internal/schedule/scheduled_next"
 	"errors"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"testing"
+	"time"
 )
 
 type MockNextRepository struct {
@@ -21,12 +23,17 @@ func (m *MockNextRepository) GetNextMinuteTransactions() ([]schedule.ScheduledTr
 	return nil, args.Error(1)
 }
 
+func (m *MockNextRepository) ClaimDue(limit int, publish scheduled_next.PublishFunc) (int, error) {
+	args := m.Called(limit, publish)
+	return args.Int(0), args.Error(1)
+}
+
 func TestNextService_GetNextMinuteTransactions_Success(t *testing.T) {
 	mockRepo := new(MockNextRepository)
 	service := scheduled_next.NewNextService(mockRepo)
 
 	transactions := []schedule.ScheduledTransaction{
-		{ID: 1, FromWallet: "wallet123", ToWallet: "wallet456", Network: "mainnet", Amount: 100.50},
+		{ID: 1, FromWallet: "wallet123", ToWallet: "wallet456", Network: "mainnet", Amount: decimal.RequireFromString("100.50")},
 	}
 	mockRepo.On("GetNextMinuteTransactions").Return(transactions, nil)
 
@@ -50,3 +57,112 @@ func TestNextService_GetNextMinuteTransactions_Error(t *testing.T) {
 	assert.Equal(t, "repository error", err.Error())
 	mockRepo.AssertExpectations(t)
 }
+
+// claimFake hands out batches of due transactions and records what publish asked to insert.
+type claimFake struct {
+	batches     [][]schedule.ScheduledTransaction
+	occurrences []scheduled_next.Occurrence
+}
+
+func (f *claimFake) GetNextMinuteTransactions() ([]schedule.ScheduledTransaction, error) {
+	return nil, nil
+}
+
+func (f *claimFake) ClaimDue(limit int, publish scheduled_next.PublishFunc) (int, error) {
+	if len(f.batches) == 0 {
+		return 0, nil
+	}
+	batch := f.batches[0]
+	f.batches = f.batches[1:]
+
+	occurrences, err := publish(batch)
+	if err != nil {
+		return 0, err
+	}
+	f.occurrences = append(f.occurrences, occurrences...)
+	return len(batch), nil
+}
+
+func TestNextService_PublishDue_Batches(t *testing.T) {
+	fake := &claimFake{batches: [][]schedule.ScheduledTransaction{
+		{{ID: 1}, {ID: 2}},
+		{{ID: 3}},
+	}}
+	service := scheduled_next.NewNextService(fake)
+
+	var published []int
+	count, err := service.PublishDue(2, func(transactions []schedule.ScheduledTransaction) error {
+		for _, txn := range transactions {
+			published = append(published, txn.ID)
+		}
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count)
+	assert.Equal(t, []int{1, 2, 3}, published)
+}
+
+func TestNextService_PublishDue_PublishError(t *testing.T) {
+	fake := &claimFake{batches: [][]schedule.ScheduledTransaction{{{ID: 1}}}}
+	service := scheduled_next.NewNextService(fake)
+
+	count, err := service.PublishDue(10, func([]schedule.ScheduledTransaction) error {
+		return errors.New("kafka unavailable")
+	})
+
+	assert.EqualError(t, err, "kafka unavailable")
+	assert.Equal(t, 0, count)
+	assert.Empty(t, fake.occurrences)
+}
+
+func TestNextService_PublishDue_NextOccurrences(t *testing.T) {
+	maxOccurrences := 3
+	until := time.Date(2024, 11, 10, 0, 0, 0, 0, time.UTC)
+	monday := time.Date(2024, 11, 4, 9, 0, 0, 0, time.UTC)
+	firstOfMonth := time.Date(2024, 11, 1, 9, 0, 0, 0, time.UTC)
+
+	weekly := schedule.ScheduledTransaction{ID: 1, ScheduledTime: monday, OccurrenceNumber: 1,
+		Recurrence: schedule.Recurrence{Rule: "0 9 * * MON"}}
+	businessDay := schedule.ScheduledTransaction{ID: 2, ScheduledTime: firstOfMonth, OccurrenceNumber: 1,
+		Recurrence: schedule.Recurrence{Rule: "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=1"}}
+	once := schedule.ScheduledTransaction{ID: 3, ScheduledTime: monday, OccurrenceNumber: 1}
+	lastByCount := schedule.ScheduledTransaction{ID: 4, ScheduledTime: monday, OccurrenceNumber: 3,
+		Recurrence: schedule.Recurrence{Rule: "@daily", MaxOccurrences: &maxOccurrences}}
+	lastByDate := schedule.ScheduledTransaction{ID: 5, ScheduledTime: monday, OccurrenceNumber: 1,
+		Recurrence: schedule.Recurrence{Rule: "0 9 * * MON", Until: &until}}
+
+	fake := &claimFake{batches: [][]schedule.ScheduledTransaction{{weekly, businessDay, once, lastByCount, lastByDate}}}
+	service := scheduled_next.NewNextService(fake)
+
+	_, err := service.PublishDue(10, func([]schedule.ScheduledTransaction) error { return nil })
+
+	assert.NoError(t, err)
+	assert.Equal(t, []scheduled_next.Occurrence{
+		{Previous: weekly, ScheduledTime: time.Date(2024, 11, 11, 9, 0, 0, 0, time.UTC)},
+		{Previous: businessDay, ScheduledTime: time.Date(2024, 12, 2, 9, 0, 0, 0, time.UTC)},
+	}, fake.occurrences)
+}
+
+func TestNextService_PublishDue_RescheduledOccurrence(t *testing.T) {
+	start := time.Date(2024, 11, 4, 9, 0, 0, 0, time.UTC) // Monday
+	weekly := schedule.Recurrence{Rule: "FREQ=WEEKLY", Start: &start}
+
+	// The second occurrence, due on November 11, was moved
+	later := schedule.ScheduledTransaction{ID: 2, ScheduledTime: time.Date(2024, 11, 13, 15, 0, 0, 0, time.UTC), OccurrenceNumber: 2, Recurrence: weekly}
+	earlier := schedule.ScheduledTransaction{ID: 3, ScheduledTime: time.Date(2024, 11, 10, 8, 0, 0, 0, time.UTC), OccurrenceNumber: 2, Recurrence: weekly}
+	pastNext := schedule.ScheduledTransaction{ID: 4, ScheduledTime: time.Date(2024, 11, 20, 9, 0, 0, 0, time.UTC), OccurrenceNumber: 2, Recurrence: weekly}
+
+	fake := &claimFake{batches: [][]schedule.ScheduledTransaction{{later, earlier, pastNext}}}
+	service := scheduled_next.NewNextService(fake)
+
+	_, err := service.PublishDue(10, func([]schedule.ScheduledTransaction) error { return nil })
+
+	assert.NoError(t, err)
+	assert.Equal(t, []scheduled_next.Occurrence{
+		{Previous: later, ScheduledTime: time.Date(2024, 11, 18, 9, 0, 0, 0, time.UTC)},
+		{Previous: earlier, ScheduledTime: time.Date(2024, 11, 18, 9, 0, 0, 0, time.UTC)},
+		// The occurrence it was moved past is skipped
+		{Previous: pastNext, ScheduledTime: time.Date(2024, 11, 25, 9, 0, 0, 0, time.UTC)},
+	}, fake.occurrences)
+}
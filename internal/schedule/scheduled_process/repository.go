@@ -1,15 +1,34 @@
 package scheduled_process
 
 import (
+	"asset-management/internal/funds"
+	"asset-management/internal/ledger"
+	"asset-management/internal/outbox"
 	"asset-management/internal/schedule"
+	"asset-management/pkg/event"
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"github.com/lib/pq"
 	"github.com/rs/zerolog/log"
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+// ErrNotProcessable is returned for transactions that are neither due nor failed, e.g. because they
+// still await approval. Processing them would move funds nobody agreed to move.
+var ErrNotProcessable = errors.New("scheduled transaction cannot be processed in its current status")
+
+const (
+	maxSerializationRetries = 10
+	serializationRetryDelay = 10 * time.Millisecond
 )
 
 type ProcessRepository interface {
+	Get(scheduledTransactionID int) (schedule.ScheduledTransaction, error)
 	Process(scheduledTransactionID int) error
+	MarkFailed(scheduledTransactionID int, reason string) error
 }
 
 type postgresProcessRepository struct {
@@ -20,8 +39,46 @@ func NewProcessRepository(db *sql.DB) ProcessRepository {
 	return &postgresProcessRepository{db: db}
 }
 
+func (r *postgresProcessRepository) Get(scheduledTransactionID int) (schedule.ScheduledTransaction, error) {
+	txn, err := schedule.Scan(r.db.QueryRow(`
+        SELECT `+schedule.Columns+`
+        FROM scheduled_transactions
+        WHERE scheduled_transaction_id = $1`, scheduledTransactionID))
+	if err != nil {
+		return txn, fmt.Errorf("failed to fetch scheduled transaction: %w", err)
+	}
+	return txn, nil
+}
+
+// Process runs the transfer in a SERIALIZABLE transaction. Concurrent transfers touching the same
+// wallets can make Postgres abort it with a serialization failure; such attempts are retried from the
+// start, since the transfer itself did not fail.
 func (r *postgresProcessRepository) Process(scheduledTransactionID int) error {
-	ctx := context.Background()
+	var err error
+	for attempt := 1; attempt <= maxSerializationRetries; attempt++ {
+		err = r.process(context.Background(), scheduledTransactionID)
+		if !isSerializationFailure(err) {
+			return err
+		}
+
+		log.Warn().Err(err).Int("scheduledTransactionID", scheduledTransactionID).Int("attempt", attempt).Msg("Serialization failure, retrying transaction")
+		time.Sleep(time.Duration(attempt) * serializationRetryDelay)
+	}
+
+	return err
+}
+
+// isSerializationFailure reports whether err is a serialization failure (40001) or a deadlock (40P01),
+// after which Postgres expects the transaction to be retried.
+func isSerializationFailure(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	return pqErr.Code == "40001" || pqErr.Code == "40P01"
+}
+
+func (r *postgresProcessRepository) process(ctx context.Context, scheduledTransactionID int) error {
 
 	// Check if the transaction is already completed
 	var status string
@@ -42,7 +99,7 @@ func (r *postgresProcessRepository) Process(scheduledTransactionID int) error {
 	// Begin transaction
 	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %v", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
 
 	// Define rollback function
@@ -53,76 +110,116 @@ func (r *postgresProcessRepository) Process(scheduledTransactionID int) error {
 	}
 
 	// Retrieve scheduled transaction details
-	var fromWallet, toWallet, network string
-	var amount float64
+	var fromWallet, toWallet, network, asset, lockedStatus string
+	var amount decimal.Decimal
 
 	err = tx.QueryRowContext(ctx, `
-        SELECT from_wallet_address, to_wallet_address, network, amount 
+        SELECT from_wallet_address, to_wallet_address, network, asset, amount, status 
         FROM scheduled_transactions 
         WHERE scheduled_transaction_id = $1 FOR UPDATE`, scheduledTransactionID).
-		Scan(&fromWallet, &toWallet, &network, &amount)
+		Scan(&fromWallet, &toWallet, &network, &asset, &amount, &lockedStatus)
 	if err != nil {
 		rollback()
-		return fmt.Errorf("failed to fetch scheduled transaction: %v", err)
+		return fmt.Errorf("failed to fetch scheduled transaction: %w", err)
 	}
 
-	// Lock balance records for both from_wallet and to_wallet
-	_, err = tx.ExecContext(ctx, `
-        SELECT balance FROM balance 
-        WHERE wallet_address = $1 AND network = $2 FOR UPDATE`, fromWallet, network)
+	// Re-check under the row lock: the transaction may have been cancelled or processed meanwhile
+	switch lockedStatus {
+	case schedule.StatusCompleted, schedule.StatusCancelled:
+		rollback()
+		log.Info().Int("scheduledTransactionID", scheduledTransactionID).Str("status", lockedStatus).Msg("Transaction already finalized, skipping")
+		return nil
+	case schedule.StatusPending, schedule.StatusPublished, schedule.StatusFailed:
+	default:
+		rollback()
+		return fmt.Errorf("%w: %s", ErrNotProcessable, lockedStatus)
+	}
+
+	// Move the funds between both wallets, locking their balances
+	reference := fmt.Sprintf("scheduled_transaction:%d", scheduledTransactionID)
+	result, err := funds.Move(ctx, tx, funds.Transfer{
+		From:      fromWallet,
+		To:        toWallet,
+		Network:   network,
+		Asset:     asset,
+		Amount:    amount,
+		Operation: ledger.OperationScheduledTransfer,
+		Reference: reference,
+
+		// A failed transaction no longer holds its amount, so a replay must leave what others hold
+		RespectHolds: lockedStatus == schedule.StatusFailed,
+	})
 	if err != nil {
 		rollback()
-		return fmt.Errorf("failed to lock sender's balance: %v", err)
+		return err
 	}
 
-	_, err = tx.ExecContext(ctx, `
-        SELECT balance FROM balance 
-        WHERE wallet_address = $1 AND network = $2 FOR UPDATE`, toWallet, network)
+	err = outbox.Write(ctx, tx, event.TypeScheduledTransferCompleted, event.ScheduledTransferCompleted{
+		TransactionID: scheduledTransactionID,
+		TransferCompleted: event.TransferCompleted{
+			Reference:   reference,
+			FromWallet:  fromWallet,
+			ToWallet:    toWallet,
+			Network:     network,
+			Asset:       asset,
+			Amount:      amount,
+			FromBalance: result.FromBalance,
+			ToBalance:   result.ToBalance,
+		},
+	}, fromWallet)
 	if err != nil {
 		rollback()
-		return fmt.Errorf("failed to lock receiver's balance: %v", err)
+		return err
 	}
 
-	// Deduct from sender's balance
-	res, err := tx.ExecContext(ctx, `
-        UPDATE balance SET balance = balance - $1 
-        WHERE wallet_address = $2 AND network = $3 AND balance >= $1`, amount, fromWallet, network)
+	// Update the scheduled transaction status to COMPLETED
+	_, err = tx.ExecContext(ctx, `
+        UPDATE scheduled_transactions SET status = 'COMPLETED', failure_reason = NULL 
+        WHERE scheduled_transaction_id = $1`, scheduledTransactionID)
 	if err != nil {
 		rollback()
-		return fmt.Errorf("failed to deduct from sender's balance: %v", err)
+		return fmt.Errorf("failed to update scheduled transaction status: %w", err)
 	}
 
-	rowsAffected, err := res.RowsAffected()
-	if err != nil || rowsAffected == 0 {
+	// Commit the transaction
+	if err := tx.Commit(); err != nil {
 		rollback()
-		return fmt.Errorf("insufficient balance in sender's wallet")
+		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	// Add to receiver's balance
-	_, err = tx.ExecContext(ctx, `
-    INSERT INTO balance (wallet_address, network, balance) 
-    VALUES ($1, $2, $3) 
-    ON CONFLICT (wallet_address, network) DO UPDATE 
-    SET balance = balance.balance + EXCLUDED.balance`, toWallet, network, amount)
+	return nil
+}
 
+// MarkFailed moves a transaction that could not be processed to FAILED and records why.
+// Transactions that were completed or cancelled in the meantime, or that were never due, are left untouched.
+func (r *postgresProcessRepository) MarkFailed(scheduledTransactionID int, reason string) error {
+	ctx := context.Background()
+
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		rollback()
-		return fmt.Errorf("failed to add to receiver's balance: %v", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer tx.Rollback()
 
-	// Update the scheduled transaction status to COMPLETED
-	_, err = tx.ExecContext(ctx, `
-        UPDATE scheduled_transactions SET status = 'COMPLETED' 
-        WHERE scheduled_transaction_id = $1`, scheduledTransactionID)
+	failed := event.ScheduledTransferFailed{TransactionID: scheduledTransactionID, Reason: reason}
+	err = tx.QueryRowContext(ctx, `
+        UPDATE scheduled_transactions SET status = 'FAILED', failure_reason = $2
+        WHERE scheduled_transaction_id = $1 AND status IN ('PENDING', 'PUBLISHED', 'FAILED')
+        RETURNING from_wallet_address, to_wallet_address, network, asset, amount`, scheduledTransactionID, reason).
+		Scan(&failed.FromWallet, &failed.ToWallet, &failed.Network, &failed.Asset, &failed.Amount)
+	if err == sql.ErrNoRows {
+		return nil
+	}
 	if err != nil {
-		rollback()
-		return fmt.Errorf("failed to update scheduled transaction status: %v", err)
+		return fmt.Errorf("failed to mark transaction as failed: %v", err)
+	}
+
+	if err := outbox.Write(ctx, tx, event.TypeScheduledTransferFailed, failed, failed.FromWallet); err != nil {
+		return err
 	}
 
-	// Commit the transaction
 	if err := tx.Commit(); err != nil {
-		rollback()
-		return fmt.Errorf("failed to commit transaction: %v", err)
+		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
 	return nil
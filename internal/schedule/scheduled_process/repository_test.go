@@ -1,10 +1,15 @@
 package scheduled_process_test
 
 import (
+	"asset-management/internal/funds"
 	"asset-management/internal/schedule/scheduled_process"
 	"asset-management/services/asset-api/util"
+	"context"
+	"fmt"
 	_ "github.com/lib/pq"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
+	"sync"
 	"testing"
 	"time"
 )
@@ -16,18 +21,18 @@ func TestPostgresProcessRepository_Process_EarlyExitCompletedTransaction(t *test
 	repo := scheduled_process.NewProcessRepository(db)
 
 	// Insert balance records for sender and receiver
-	_, err := db.Exec(`INSERT INTO balance (wallet_address, network, balance) VALUES ($1, $2, $3)`,
-		"wallet123", "mainnet", 200.0)
+	_, err := db.Exec(`INSERT INTO balance (wallet_address, network, asset, balance) VALUES ($1, $2, $3, $4)`,
+		"wallet123", "mainnet", "ETH", "200.0")
 	assert.NoError(t, err)
 
-	_, err = db.Exec(`INSERT INTO balance (wallet_address, network, balance) VALUES ($1, $2, $3)`,
-		"wallet456", "mainnet", 100.0)
+	_, err = db.Exec(`INSERT INTO balance (wallet_address, network, asset, balance) VALUES ($1, $2, $3, $4)`,
+		"wallet456", "mainnet", "ETH", "100.0")
 	assert.NoError(t, err)
 
 	// Insert a completed scheduled transaction record
-	_, err = db.Exec(`INSERT INTO scheduled_transactions (scheduled_transaction_id, from_wallet_address, to_wallet_address, network, amount, scheduled_time, status)
-					  VALUES ($1, $2, $3, $4, $5, $6, $7)`,
-		123, "wallet123", "wallet456", "mainnet", 50.0, time.Now().Add(10*time.Minute), "COMPLETED")
+	_, err = db.Exec(`INSERT INTO scheduled_transactions (scheduled_transaction_id, from_wallet_address, to_wallet_address, network, asset, amount, scheduled_time, status)
+					  VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		123, "wallet123", "wallet456", "mainnet", "ETH", "50.0", time.Now().Add(10*time.Minute), "COMPLETED")
 	assert.NoError(t, err)
 
 	// Process the transaction, expecting an early exit
@@ -35,14 +40,14 @@ func TestPostgresProcessRepository_Process_EarlyExitCompletedTransaction(t *test
 	assert.NoError(t, err)
 
 	// Verify the balances remain unchanged
-	var senderBalance, receiverBalance float64
-	err = db.QueryRow(`SELECT balance FROM balance WHERE wallet_address = $1 AND network = $2`, "wallet123", "mainnet").Scan(&senderBalance)
+	var senderBalance, receiverBalance decimal.Decimal
+	err = db.QueryRow(`SELECT balance FROM balance WHERE wallet_address = $1 AND network = $2 AND asset = $3`, "wallet123", "mainnet", "ETH").Scan(&senderBalance)
 	assert.NoError(t, err)
-	assert.Equal(t, 200.0, senderBalance)
+	assert.Equal(t, "200", senderBalance.String())
 
-	err = db.QueryRow(`SELECT balance FROM balance WHERE wallet_address = $1 AND network = $2`, "wallet456", "mainnet").Scan(&receiverBalance)
+	err = db.QueryRow(`SELECT balance FROM balance WHERE wallet_address = $1 AND network = $2 AND asset = $3`, "wallet456", "mainnet", "ETH").Scan(&receiverBalance)
 	assert.NoError(t, err)
-	assert.Equal(t, 100.0, receiverBalance)
+	assert.Equal(t, "100", receiverBalance.String())
 
 	// Verify the transaction status remains as COMPLETED
 	var status string
@@ -58,18 +63,18 @@ func TestPostgresProcessRepository_Process_Success(t *testing.T) {
 	repo := scheduled_process.NewProcessRepository(db)
 
 	// Insert balance records for sender and receiver
-	_, err := db.Exec(`INSERT INTO balance (wallet_address, network, balance) VALUES ($1, $2, $3)`,
-		"wallet123", "mainnet", 200.0)
+	_, err := db.Exec(`INSERT INTO balance (wallet_address, network, asset, balance) VALUES ($1, $2, $3, $4)`,
+		"wallet123", "mainnet", "ETH", "200.0")
 	assert.NoError(t, err)
 
-	_, err = db.Exec(`INSERT INTO balance (wallet_address, network, balance) VALUES ($1, $2, $3)`,
-		"wallet456", "mainnet", 100.0)
+	_, err = db.Exec(`INSERT INTO balance (wallet_address, network, asset, balance) VALUES ($1, $2, $3, $4)`,
+		"wallet456", "mainnet", "ETH", "100.0")
 	assert.NoError(t, err)
 
 	// Insert scheduled transaction record with a scheduled_time
-	_, err = db.Exec(`INSERT INTO scheduled_transactions (scheduled_transaction_id, from_wallet_address, to_wallet_address, network, amount, scheduled_time, status)
-					  VALUES ($1, $2, $3, $4, $5, $6, $7)`,
-		123, "wallet123", "wallet456", "mainnet", 50.0, time.Now().Add(10*time.Minute), "PENDING")
+	_, err = db.Exec(`INSERT INTO scheduled_transactions (scheduled_transaction_id, from_wallet_address, to_wallet_address, network, asset, amount, scheduled_time, status)
+					  VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		123, "wallet123", "wallet456", "mainnet", "ETH", "50.0", time.Now().Add(10*time.Minute), "PENDING")
 	assert.NoError(t, err)
 
 	// Process the transaction
@@ -77,20 +82,84 @@ func TestPostgresProcessRepository_Process_Success(t *testing.T) {
 	assert.NoError(t, err)
 
 	// Verify the sender's and receiver's updated balances
-	var senderBalance, receiverBalance float64
-	err = db.QueryRow(`SELECT balance FROM balance WHERE wallet_address = $1 AND network = $2`, "wallet123", "mainnet").Scan(&senderBalance)
+	var senderBalance, receiverBalance decimal.Decimal
+	err = db.QueryRow(`SELECT balance FROM balance WHERE wallet_address = $1 AND network = $2 AND asset = $3`, "wallet123", "mainnet", "ETH").Scan(&senderBalance)
 	assert.NoError(t, err)
-	assert.Equal(t, 150.0, senderBalance)
+	assert.Equal(t, "150", senderBalance.String())
 
-	err = db.QueryRow(`SELECT balance FROM balance WHERE wallet_address = $1 AND network = $2`, "wallet456", "mainnet").Scan(&receiverBalance)
+	err = db.QueryRow(`SELECT balance FROM balance WHERE wallet_address = $1 AND network = $2 AND asset = $3`, "wallet456", "mainnet", "ETH").Scan(&receiverBalance)
 	assert.NoError(t, err)
-	assert.Equal(t, 150.0, receiverBalance)
+	assert.Equal(t, "150", receiverBalance.String())
 
 	// Verify transaction status update
 	var status string
 	err = db.QueryRow(`SELECT status FROM scheduled_transactions WHERE scheduled_transaction_id = $1`, 123).Scan(&status)
 	assert.NoError(t, err)
 	assert.Equal(t, "COMPLETED", status)
+
+	// Verify the transfer was recorded as a debit/credit pair in the ledger
+	var entryCount int
+	err = db.QueryRow(`SELECT COUNT(*) FROM ledger_entries WHERE reference = $1`, "scheduled_transaction:123").Scan(&entryCount)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, entryCount)
+
+	// Verify the completion event was written to the outbox
+	var eventType, transactionID string
+	err = db.QueryRow(`SELECT event_type, envelope->'payload'->>'transaction_id' FROM event_outbox`).Scan(&eventType, &transactionID)
+	assert.NoError(t, err)
+	assert.Equal(t, "scheduled_transfer.completed", eventType)
+	assert.Equal(t, "123", transactionID)
+}
+
+func TestPostgresProcessRepository_Process_ConcurrentTransfersToSameWallet(t *testing.T) {
+	db, cleanup := util.SetupTestContainer(t)
+	defer cleanup()
+
+	repo := scheduled_process.NewProcessRepository(db)
+
+	_, err := db.Exec(`INSERT INTO balance (wallet_address, network, asset, balance) VALUES ($1, $2, $3, $4)`,
+		"receiver", "mainnet", "ETH", "0")
+	assert.NoError(t, err)
+
+	// Five senders pay the same receiver at once, so their SERIALIZABLE transactions conflict
+	const senders = 5
+	for i := 1; i <= senders; i++ {
+		wallet := fmt.Sprintf("sender%d", i)
+		_, err = db.Exec(`INSERT INTO balance (wallet_address, network, asset, balance) VALUES ($1, $2, $3, $4)`,
+			wallet, "mainnet", "ETH", "100.0")
+		assert.NoError(t, err)
+
+		_, err = db.Exec(`INSERT INTO scheduled_transactions (scheduled_transaction_id, from_wallet_address, to_wallet_address, network, asset, amount, scheduled_time, status)
+					  VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+			i, wallet, "receiver", "mainnet", "ETH", "10.0", time.Now(), "PENDING")
+		assert.NoError(t, err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, senders)
+	for i := 1; i <= senders; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			errs[id-1] = repo.Process(id)
+		}(i)
+	}
+	wg.Wait()
+
+	// Serialization failures are retried, so every transfer lands
+	for _, err := range errs {
+		assert.NoError(t, err)
+	}
+
+	var receiverBalance decimal.Decimal
+	err = db.QueryRow(`SELECT balance FROM balance WHERE wallet_address = $1`, "receiver").Scan(&receiverBalance)
+	assert.NoError(t, err)
+	assert.Equal(t, "50", receiverBalance.String())
+
+	var completed int
+	err = db.QueryRow(`SELECT COUNT(*) FROM scheduled_transactions WHERE status = 'COMPLETED'`).Scan(&completed)
+	assert.NoError(t, err)
+	assert.Equal(t, senders, completed)
 }
 
 func TestPostgresProcessRepository_Process_InsufficientBalance(t *testing.T) {
@@ -100,14 +169,14 @@ func TestPostgresProcessRepository_Process_InsufficientBalance(t *testing.T) {
 	repo := scheduled_process.NewProcessRepository(db)
 
 	// Insert balance record for sender with insufficient balance
-	_, err := db.Exec(`INSERT INTO balance (wallet_address, network, balance) VALUES ($1, $2, $3)`,
-		"wallet123", "mainnet", 30.0)
+	_, err := db.Exec(`INSERT INTO balance (wallet_address, network, asset, balance) VALUES ($1, $2, $3, $4)`,
+		"wallet123", "mainnet", "ETH", "30.0")
 	assert.NoError(t, err)
 
 	// Insert scheduled transaction record with a scheduled_time
-	_, err = db.Exec(`INSERT INTO scheduled_transactions (scheduled_transaction_id, from_wallet_address, to_wallet_address, network, amount, scheduled_time, status)
-					  VALUES ($1, $2, $3, $4, $5, $6, $7)`,
-		123, "wallet123", "wallet456", "mainnet", 50.0, time.Now().Add(10*time.Minute), "PENDING")
+	_, err = db.Exec(`INSERT INTO scheduled_transactions (scheduled_transaction_id, from_wallet_address, to_wallet_address, network, asset, amount, scheduled_time, status)
+					  VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		123, "wallet123", "wallet456", "mainnet", "ETH", "50.0", time.Now().Add(10*time.Minute), "PENDING")
 	assert.NoError(t, err)
 
 	// Process the transaction
@@ -121,3 +190,160 @@ func TestPostgresProcessRepository_Process_InsufficientBalance(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "PENDING", status)
 }
+
+func TestPostgresProcessRepository_Process_Holds(t *testing.T) {
+	db, cleanup := util.SetupTestContainer(t)
+	defer cleanup()
+
+	repo := scheduled_process.NewProcessRepository(db)
+
+	_, err := db.Exec(`INSERT INTO balance (wallet_address, network, asset, balance) VALUES ($1, $2, $3, $4)`,
+		"wallet123", "mainnet", "ETH", "100.0")
+	assert.NoError(t, err)
+
+	// 124 and 125 hold 80 of the 100 ETH; 126 failed earlier and holds nothing anymore
+	for _, row := range []struct {
+		id     int
+		amount string
+		status string
+	}{
+		{124, "50.0", "PUBLISHED"},
+		{125, "30.0", "PENDING"},
+		{126, "40.0", "FAILED"},
+	} {
+		_, err = db.Exec(`INSERT INTO scheduled_transactions (scheduled_transaction_id, from_wallet_address, to_wallet_address, network, asset, amount, scheduled_time, status)
+						  VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+			row.id, "wallet123", "wallet456", "mainnet", "ETH", row.amount, time.Now(), row.status)
+		assert.NoError(t, err)
+	}
+
+	// A replay of the failed transaction cannot take what the others hold
+	assert.ErrorIs(t, repo.Process(126), funds.ErrFundsHeld)
+
+	// A held transaction spends its own hold
+	assert.NoError(t, repo.Process(124))
+	assert.NoError(t, repo.Process(125))
+}
+
+func TestPostgresProcessRepository_Process_SkipsCancelledTransaction(t *testing.T) {
+	db, cleanup := util.SetupTestContainer(t)
+	defer cleanup()
+
+	repo := scheduled_process.NewProcessRepository(db)
+
+	_, err := db.Exec(`INSERT INTO balance (wallet_address, network, asset, balance) VALUES ($1, $2, $3, $4)`,
+		"wallet123", "mainnet", "ETH", "200.0")
+	assert.NoError(t, err)
+
+	// Insert a cancelled scheduled transaction record
+	_, err = db.Exec(`INSERT INTO scheduled_transactions (scheduled_transaction_id, from_wallet_address, to_wallet_address, network, asset, amount, scheduled_time, status)
+					  VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		124, "wallet123", "wallet456", "mainnet", "ETH", "50.0", time.Now(), "CANCELLED")
+	assert.NoError(t, err)
+
+	err = repo.Process(124)
+	assert.NoError(t, err)
+
+	// Verify no funds moved
+	var senderBalance decimal.Decimal
+	err = db.QueryRow(`SELECT balance FROM balance WHERE wallet_address = $1 AND network = $2 AND asset = $3`, "wallet123", "mainnet", "ETH").Scan(&senderBalance)
+	assert.NoError(t, err)
+	assert.Equal(t, "200", senderBalance.String())
+
+	var entries int
+	err = db.QueryRow(`SELECT COUNT(*) FROM ledger_entries`).Scan(&entries)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, entries)
+}
+
+func TestPostgresProcessRepository_Process_RejectsAwaitingApproval(t *testing.T) {
+	db, cleanup := util.SetupTestContainer(t)
+	defer cleanup()
+
+	repo := scheduled_process.NewProcessRepository(db)
+
+	_, err := db.Exec(`INSERT INTO balance (wallet_address, network, asset, balance) VALUES ($1, $2, $3, $4)`,
+		"wallet123", "mainnet", "ETH", "200.0")
+	assert.NoError(t, err)
+
+	_, err = db.Exec(`INSERT INTO scheduled_transactions (scheduled_transaction_id, from_wallet_address, to_wallet_address, network, asset, amount, scheduled_time, status)
+					  VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		127, "wallet123", "wallet456", "mainnet", "ETH", "150.0", time.Now(), "AWAITING_APPROVAL")
+	assert.NoError(t, err)
+
+	assert.ErrorIs(t, repo.Process(127), scheduled_process.ErrNotProcessable)
+	// Nor can it be failed, which would release its hold
+	assert.NoError(t, repo.MarkFailed(127, "not approved"))
+
+	var senderBalance decimal.Decimal
+	err = db.QueryRow(`SELECT balance FROM balance WHERE wallet_address = $1 AND network = $2 AND asset = $3`, "wallet123", "mainnet", "ETH").Scan(&senderBalance)
+	assert.NoError(t, err)
+	assert.Equal(t, "200", senderBalance.String())
+
+	var status string
+	err = db.QueryRow(`SELECT status FROM scheduled_transactions WHERE scheduled_transaction_id = $1`, 127).Scan(&status)
+	assert.NoError(t, err)
+	assert.Equal(t, "AWAITING_APPROVAL", status)
+
+	// The amount is still held
+	tx, err := db.Begin()
+	assert.NoError(t, err)
+	defer tx.Rollback()
+	held, err := funds.Held(context.Background(), tx, "wallet123", "mainnet", "ETH")
+	assert.NoError(t, err)
+	assert.Equal(t, "150", held.String())
+}
+
+func TestPostgresProcessRepository_MarkFailed(t *testing.T) {
+	db, cleanup := util.SetupTestContainer(t)
+	defer cleanup()
+
+	repo := scheduled_process.NewProcessRepository(db)
+
+	_, err := db.Exec(`INSERT INTO scheduled_transactions (scheduled_transaction_id, from_wallet_address, to_wallet_address, network, asset, amount, scheduled_time, status)
+					  VALUES ($1, $2, $3, $4, $5, $6, $7, $8), ($9, $2, $3, $4, $5, $6, $7, $10)`,
+		125, "wallet123", "wallet456", "mainnet", "ETH", "50.0", time.Now(), "PUBLISHED", 126, "COMPLETED")
+	assert.NoError(t, err)
+
+	assert.NoError(t, repo.MarkFailed(125, "insufficient balance in sender's wallet"))
+	assert.NoError(t, repo.MarkFailed(126, "late failure"))
+
+	var status, reason string
+	err = db.QueryRow(`SELECT status, failure_reason FROM scheduled_transactions WHERE scheduled_transaction_id = $1`, 125).Scan(&status, &reason)
+	assert.NoError(t, err)
+	assert.Equal(t, "FAILED", status)
+	assert.Equal(t, "insufficient balance in sender's wallet", reason)
+
+	// A completed transaction is never moved back to FAILED
+	err = db.QueryRow(`SELECT status FROM scheduled_transactions WHERE scheduled_transaction_id = $1`, 126).Scan(&status)
+	assert.NoError(t, err)
+	assert.Equal(t, "COMPLETED", status)
+
+	// Only the transaction that failed reports an event
+	var eventType, transactionID string
+	err = db.QueryRow(`SELECT event_type, envelope->'payload'->>'transaction_id' FROM event_outbox`).Scan(&eventType, &transactionID)
+	assert.NoError(t, err)
+	assert.Equal(t, "scheduled_transfer.failed", eventType)
+	assert.Equal(t, "125", transactionID)
+}
+
+func TestPostgresProcessRepository_Get(t *testing.T) {
+	db, cleanup := util.SetupTestContainer(t)
+	defer cleanup()
+
+	repo := scheduled_process.NewProcessRepository(db)
+
+	_, err := db.Exec(`INSERT INTO scheduled_transactions (scheduled_transaction_id, from_wallet_address, to_wallet_address, network, asset, amount, scheduled_time, status)
+					  VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		123, "wallet123", "wallet456", "mainnet", "ETH", "50.0", time.Now(), "PUBLISHED")
+	assert.NoError(t, err)
+
+	txn, err := repo.Get(123)
+	assert.NoError(t, err)
+	assert.Equal(t, "wallet123", txn.FromWallet)
+	assert.Equal(t, "mainnet", txn.Network)
+	assert.Equal(t, "PUBLISHED", txn.Status)
+
+	_, err = repo.Get(999)
+	assert.Error(t, err)
+}
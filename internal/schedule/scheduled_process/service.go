@@ -1,26 +1,55 @@
 package scheduled_process
 
 import (
+	"asset-management/internal/schedule"
 	"fmt"
 )
 
 type ProcessService interface {
 	Process(scheduledTransactionID int) error
+	MarkFailed(scheduledTransactionID int, reason string) error
+}
+
+// WalletChecker reports whether funds may leave a wallet, e.g. because it is not frozen, and whether
+// they may enter one, e.g. because it is not being deleted.
+type WalletChecker interface {
+	CanDebit(walletAddress, network string) error
+	CanCredit(walletAddress, network string) error
 }
 
 type processService struct {
-	repo ProcessRepository
+	repo    ProcessRepository
+	wallets WalletChecker
 }
 
-func NewProcessService(repo ProcessRepository) ProcessService {
-	return &processService{repo: repo}
+func NewProcessService(repo ProcessRepository, wallets WalletChecker) ProcessService {
+	return &processService{repo: repo, wallets: wallets}
 }
 
 func (s *processService) Process(scheduledTransactionID int) error {
-	err := s.repo.Process(scheduledTransactionID)
+	txn, err := s.repo.Get(scheduledTransactionID)
+	if err != nil {
+		return fmt.Errorf("failed to process transaction: %w", err)
+	}
+
+	// Finalized transactions are skipped by the repository, whatever the wallet's state
+	if txn.Status != schedule.StatusCompleted && txn.Status != schedule.StatusCancelled {
+		if err := s.wallets.CanDebit(txn.FromWallet, txn.Network); err != nil {
+			return fmt.Errorf("failed to process transaction: source wallet cannot be debited: %w", err)
+		}
+		if err := s.wallets.CanCredit(txn.ToWallet, txn.Network); err != nil {
+			return fmt.Errorf("failed to process transaction: destination wallet cannot be credited: %w", err)
+		}
+	}
+
+	err = s.repo.Process(scheduledTransactionID)
 	if err != nil {
 		return fmt.Errorf("failed to process transaction: %w", err)
 	}
 
 	return nil
 }
+
+func (s *processService) MarkFailed(scheduledTransactionID int, reason string) error {
+	return s.repo.MarkFailed(scheduledTransactionID, reason)
+}
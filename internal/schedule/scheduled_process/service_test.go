@@ -1,6 +1,7 @@
 package scheduled_process
 
 import (
+	"asset-management/internal/schedule"
 	"errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -11,16 +12,46 @@ type MockProcessRepository struct {
 	mock.Mock
 }
 
+func (m *MockProcessRepository) Get(scheduledTransactionID int) (schedule.ScheduledTransaction, error) {
+	args := m.Called(scheduledTransactionID)
+	return args.Get(0).(schedule.ScheduledTransaction), args.Error(1)
+}
+
 func (m *MockProcessRepository) Process(scheduledTransactionID int) error {
 	args := m.Called(scheduledTransactionID)
 	return args.Error(0)
 }
 
+func (m *MockProcessRepository) MarkFailed(scheduledTransactionID int, reason string) error {
+	args := m.Called(scheduledTransactionID, reason)
+	return args.Error(0)
+}
+
+type MockWalletChecker struct {
+	mock.Mock
+}
+
+func (m *MockWalletChecker) CanDebit(walletAddress, network string) error {
+	args := m.Called(walletAddress, network)
+	return args.Error(0)
+}
+
+func (m *MockWalletChecker) CanCredit(walletAddress, network string) error {
+	args := m.Called(walletAddress, network)
+	return args.Error(0)
+}
+
+var pendingTransaction = schedule.ScheduledTransaction{ID: 123, FromWallet: "wallet_123", ToWallet: "wallet_456", Network: "Ethereum", Status: schedule.StatusPublished}
+
 func TestProcessService_Process_Success(t *testing.T) {
 	mockRepo := new(MockProcessRepository)
-	service := NewProcessService(mockRepo)
+	mockWallets := new(MockWalletChecker)
+	service := NewProcessService(mockRepo, mockWallets)
 
 	// Mock successful repository response
+	mockRepo.On("Get", 123).Return(pendingTransaction, nil)
+	mockWallets.On("CanDebit", "wallet_123", "Ethereum").Return(nil)
+	mockWallets.On("CanCredit", "wallet_456", "Ethereum").Return(nil)
 	mockRepo.On("Process", 123).Return(nil)
 
 	err := service.Process(123)
@@ -28,13 +59,18 @@ func TestProcessService_Process_Success(t *testing.T) {
 	// Assertions
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
+	mockWallets.AssertExpectations(t)
 }
 
 func TestProcessService_Process_Failure(t *testing.T) {
 	mockRepo := new(MockProcessRepository)
-	service := NewProcessService(mockRepo)
+	mockWallets := new(MockWalletChecker)
+	service := NewProcessService(mockRepo, mockWallets)
 
 	// Mock repository error
+	mockRepo.On("Get", 123).Return(pendingTransaction, nil)
+	mockWallets.On("CanDebit", "wallet_123", "Ethereum").Return(nil)
+	mockWallets.On("CanCredit", "wallet_456", "Ethereum").Return(nil)
 	mockRepo.On("Process", 123).Return(errors.New("repository error"))
 
 	err := service.Process(123)
@@ -44,3 +80,65 @@ func TestProcessService_Process_Failure(t *testing.T) {
 	assert.Equal(t, "failed to process transaction: repository error", err.Error())
 	mockRepo.AssertExpectations(t)
 }
+
+func TestProcessService_Process_FrozenSource(t *testing.T) {
+	mockRepo := new(MockProcessRepository)
+	mockWallets := new(MockWalletChecker)
+	service := NewProcessService(mockRepo, mockWallets)
+
+	frozen := errors.New("wallet is frozen")
+	mockRepo.On("Get", 123).Return(pendingTransaction, nil)
+	mockWallets.On("CanDebit", "wallet_123", "Ethereum").Return(frozen)
+
+	err := service.Process(123)
+
+	// The funds are not moved
+	assert.ErrorIs(t, err, frozen)
+	mockRepo.AssertNotCalled(t, "Process", 123)
+}
+
+func TestProcessService_Process_DeletingDestination(t *testing.T) {
+	mockRepo := new(MockProcessRepository)
+	mockWallets := new(MockWalletChecker)
+	service := NewProcessService(mockRepo, mockWallets)
+
+	deleting := errors.New("wallet is being deleted")
+	mockRepo.On("Get", 123).Return(pendingTransaction, nil)
+	mockWallets.On("CanDebit", "wallet_123", "Ethereum").Return(nil)
+	mockWallets.On("CanCredit", "wallet_456", "Ethereum").Return(deleting)
+
+	err := service.Process(123)
+
+	// Funds do not arrive in a wallet whose holdings are being settled
+	assert.ErrorIs(t, err, deleting)
+	mockRepo.AssertNotCalled(t, "Process", 123)
+}
+
+func TestProcessService_Process_AlreadyCompleted(t *testing.T) {
+	mockRepo := new(MockProcessRepository)
+	mockWallets := new(MockWalletChecker)
+	service := NewProcessService(mockRepo, mockWallets)
+
+	completed := pendingTransaction
+	completed.Status = schedule.StatusCompleted
+	mockRepo.On("Get", 123).Return(completed, nil)
+	mockRepo.On("Process", 123).Return(nil)
+
+	err := service.Process(123)
+
+	// A redelivered message does not depend on the wallet service
+	assert.NoError(t, err)
+	mockWallets.AssertNotCalled(t, "CanDebit", "wallet_123", "Ethereum")
+}
+
+func TestProcessService_MarkFailed(t *testing.T) {
+	mockRepo := new(MockProcessRepository)
+	service := NewProcessService(mockRepo, new(MockWalletChecker))
+
+	mockRepo.On("MarkFailed", 123, "insufficient balance in sender's wallet").Return(nil)
+
+	err := service.MarkFailed(123, "insufficient balance in sender's wallet")
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
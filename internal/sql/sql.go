@@ -1,12 +1,31 @@
 package sql
 
+// legacyAsset is the asset of rows stored before balances were kept per asset: the native asset of
+// their network, whose symbol is also its alias, or the network itself when it is not known.
+const legacyAsset = `CASE UPPER(network)
+            WHEN 'BITCOIN' THEN 'BTC' WHEN 'ETHEREUM' THEN 'ETH' WHEN 'SOLANA' THEN 'SOL' WHEN 'TRON' THEN 'TRX'
+            ELSE LEFT(UPPER(network), 50) END`
+
 const CreateBalanceTableSQL = `
 CREATE TABLE IF NOT EXISTS balance (
 	wallet_address VARCHAR(255) NOT NULL,
 	network VARCHAR(100) NOT NULL,
+	asset VARCHAR(50) NOT NULL,
 	balance NUMERIC(30, 10) NOT NULL DEFAULT 0 CHECK (balance >= 0),
-	UNIQUE (wallet_address, network)
+	UNIQUE (wallet_address, network, asset)
 );
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'balance' AND column_name = 'asset') THEN
+        ALTER TABLE balance ADD COLUMN asset VARCHAR(50);
+        UPDATE balance SET asset = ` + legacyAsset + `;
+        ALTER TABLE balance ALTER COLUMN asset SET NOT NULL;
+        ALTER TABLE balance DROP CONSTRAINT IF EXISTS balance_wallet_address_network_key;
+        ALTER TABLE balance ADD CONSTRAINT balance_wallet_address_network_asset_key UNIQUE (wallet_address, network, asset);
+    END IF;
+END
+$$;
 `
 
 const CreateScheduledTransactionsTable = `
@@ -15,9 +34,199 @@ CREATE TABLE IF NOT EXISTS scheduled_transactions (
     from_wallet_address VARCHAR(255) NOT NULL,
     to_wallet_address VARCHAR(255) NOT NULL,
     network VARCHAR(100) NOT NULL,
+    asset VARCHAR(50) NOT NULL,
     amount NUMERIC(30, 10) NOT NULL CHECK (amount > 0),
     scheduled_time TIMESTAMP NOT NULL,
-    status VARCHAR(50) DEFAULT 'PENDING' CHECK (status IN ('PENDING', 'COMPLETED', 'FAILED')),
+    status VARCHAR(50) DEFAULT 'PENDING' CHECK (status IN ('AWAITING_APPROVAL', 'PENDING', 'PUBLISHED', 'COMPLETED', 'FAILED', 'CANCELLED')),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    published_at TIMESTAMP,
+    publish_attempts INT NOT NULL DEFAULT 0,
+    last_publish_attempt_at TIMESTAMP,
+    failure_reason TEXT,
+    recurrence VARCHAR(255),
+    recurrence_end TIMESTAMP,
+    max_occurrences INT CHECK (max_occurrences > 0),
+    recurrence_start TIMESTAMP,
+    series_id INT REFERENCES scheduled_transactions (scheduled_transaction_id),
+    occurrence_number INT NOT NULL DEFAULT 1,
+    UNIQUE (series_id, occurrence_number)
+);
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'scheduled_transactions' AND column_name = 'asset') THEN
+        ALTER TABLE scheduled_transactions ADD COLUMN asset VARCHAR(50);
+        UPDATE scheduled_transactions SET asset = ` + legacyAsset + `;
+        ALTER TABLE scheduled_transactions ALTER COLUMN asset SET NOT NULL;
+    END IF;
+
+    -- Tables created before statuses were added keep the check they were created with
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'scheduled_transactions_status_check'
+                   AND pg_get_constraintdef(oid) LIKE '%AWAITING_APPROVAL%') THEN
+        ALTER TABLE scheduled_transactions DROP CONSTRAINT IF EXISTS scheduled_transactions_status_check;
+        ALTER TABLE scheduled_transactions ADD CONSTRAINT scheduled_transactions_status_check
+            CHECK (status IN ('AWAITING_APPROVAL', 'PENDING', 'PUBLISHED', 'COMPLETED', 'FAILED', 'CANCELLED'));
+    END IF;
+END
+$$;
+
+ALTER TABLE scheduled_transactions
+    ADD COLUMN IF NOT EXISTS published_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS publish_attempts INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS last_publish_attempt_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS failure_reason TEXT,
+    ADD COLUMN IF NOT EXISTS recurrence VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS recurrence_end TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS max_occurrences INT CHECK (max_occurrences > 0),
+    ADD COLUMN IF NOT EXISTS recurrence_start TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS series_id INT REFERENCES scheduled_transactions (scheduled_transaction_id),
+    ADD COLUMN IF NOT EXISTS occurrence_number INT NOT NULL DEFAULT 1;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'scheduled_transactions_series_id_occurrence_number_key') THEN
+        ALTER TABLE scheduled_transactions ADD CONSTRAINT scheduled_transactions_series_id_occurrence_number_key
+            UNIQUE (series_id, occurrence_number);
+    END IF;
+END
+$$;
+
+CREATE INDEX IF NOT EXISTS idx_scheduled_transactions_due ON scheduled_transactions (scheduled_time)
+    WHERE status = 'PENDING';
+
+CREATE INDEX IF NOT EXISTS idx_scheduled_transactions_from ON scheduled_transactions (from_wallet_address, network, scheduled_transaction_id);
+CREATE INDEX IF NOT EXISTS idx_scheduled_transactions_to ON scheduled_transactions (to_wallet_address, network, scheduled_transaction_id);
+`
+
+const CreateLedgerEntriesTable = `
+CREATE TABLE IF NOT EXISTS ledger_entries (
+    entry_id BIGSERIAL PRIMARY KEY,
+    wallet_address VARCHAR(255) NOT NULL,
+    network VARCHAR(100) NOT NULL,
+    asset VARCHAR(50) NOT NULL,
+    entry_type VARCHAR(10) NOT NULL CHECK (entry_type IN ('DEBIT', 'CREDIT')),
+    operation VARCHAR(50) NOT NULL,
+    counterparty VARCHAR(255) NOT NULL,
+    amount NUMERIC(30, 10) NOT NULL CHECK (amount > 0),
+    balance_after NUMERIC(30, 10),
+    reference VARCHAR(255) NOT NULL,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
+
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_wallet ON ledger_entries (wallet_address, network, entry_id);
+
+CREATE OR REPLACE FUNCTION ledger_entries_immutable() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'ledger entries are immutable';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS ledger_entries_no_change ON ledger_entries;
+CREATE TRIGGER ledger_entries_no_change
+    BEFORE UPDATE OR DELETE ON ledger_entries
+    FOR EACH ROW EXECUTE FUNCTION ledger_entries_immutable();
+`
+
+const CreateIdempotencyKeysTable = `
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    idempotency_key VARCHAR(255) NOT NULL,
+    scope VARCHAR(255) NOT NULL,
+    fingerprint CHAR(64) NOT NULL,
+    status_code INT,
+    content_type VARCHAR(255),
+    response_body BYTEA,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    claimed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP,
+    PRIMARY KEY (idempotency_key, scope)
+);
+
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS claimed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;
+`
+
+// CreateEventOutboxTable matches outbox.Record, which wallet-api migrates with gorm.
+const CreateEventOutboxTable = `
+CREATE TABLE IF NOT EXISTS event_outbox (
+    event_id UUID PRIMARY KEY,
+    event_type TEXT NOT NULL,
+    message_key TEXT NOT NULL,
+    envelope JSONB NOT NULL,
+    occurred_at TIMESTAMPTZ NOT NULL,
+    published_at TIMESTAMPTZ,
+    publish_attempts BIGINT NOT NULL DEFAULT 0,
+    last_publish_attempt_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_event_outbox_unpublished ON event_outbox (occurred_at) WHERE published_at IS NULL;
+`
+
+// CreateWithdrawalLimitsTable stores limits.Rule. Network, wallet address and asset are empty when
+// a rule does not narrow them down.
+const CreateWithdrawalLimitsTable = `
+CREATE TABLE IF NOT EXISTS withdrawal_limits (
+    limit_id SERIAL PRIMARY KEY,
+    scope VARCHAR(20) NOT NULL CHECK (scope IN ('GLOBAL', 'NETWORK', 'WALLET')),
+    network VARCHAR(100) NOT NULL DEFAULT '',
+    wallet_address VARCHAR(255) NOT NULL DEFAULT '',
+    asset VARCHAR(50) NOT NULL DEFAULT '',
+    kind VARCHAR(50) NOT NULL CHECK (kind IN ('MAX_AMOUNT', 'VOLUME_24H', 'VOLUME_30D', 'COUNT_PER_HOUR', 'MIN_RESERVE', 'APPROVAL_THRESHOLD')),
+    value NUMERIC(30, 10) NOT NULL CHECK (value >= 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT withdrawal_limits_rule UNIQUE (scope, network, wallet_address, asset, kind)
+);
+`
+
+// CreateApprovalRequestsTable stores approval.Request and its decisions. Each approver decides once.
+const CreateApprovalRequestsTable = `
+CREATE TABLE IF NOT EXISTS approval_requests (
+    approval_id SERIAL PRIMARY KEY,
+    operation VARCHAR(50) NOT NULL CHECK (operation IN ('WITHDRAW', 'SCHEDULED_TRANSFER')),
+    wallet_address VARCHAR(255) NOT NULL,
+    network VARCHAR(100) NOT NULL,
+    asset VARCHAR(50) NOT NULL,
+    amount NUMERIC(30, 10) NOT NULL CHECK (amount > 0),
+    scheduled_transaction_id INT UNIQUE REFERENCES scheduled_transactions (scheduled_transaction_id),
+    rule_id INT NOT NULL,
+    requested_by VARCHAR(255) NOT NULL,
+    status VARCHAR(50) NOT NULL DEFAULT 'AWAITING_APPROVAL' CHECK (status IN ('AWAITING_APPROVAL', 'APPROVED', 'REJECTED', 'CANCELLED')),
+    reason TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    decided_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_approval_requests_awaiting ON approval_requests (wallet_address, network, asset)
+    WHERE status = 'AWAITING_APPROVAL';
+
+CREATE TABLE IF NOT EXISTS approval_decisions (
+    approval_id INT NOT NULL REFERENCES approval_requests (approval_id),
+    approver VARCHAR(255) NOT NULL,
+    decision VARCHAR(10) NOT NULL CHECK (decision IN ('APPROVE', 'REJECT')),
+    comment TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (approval_id, approver)
+);
+`
+
+// CreateWalletAliasesTable maps the network and address spellings ledger entries were recorded under
+// before wallets were keyed by their canonical form, so that the canonical wallet's ledger finds them.
+const CreateWalletAliasesTable = `
+CREATE TABLE IF NOT EXISTS wallet_aliases (
+    network VARCHAR(100) NOT NULL,
+    wallet_address VARCHAR(255) NOT NULL,
+    canonical_network VARCHAR(100) NOT NULL,
+    canonical_address VARCHAR(255) NOT NULL,
+    PRIMARY KEY (network, wallet_address)
+);
+
+CREATE INDEX IF NOT EXISTS idx_wallet_aliases_canonical ON wallet_aliases (canonical_address, canonical_network);
+`
+
+// CreateSchemaMigrationsTable records the one-off data migrations that have been applied, so that they
+// run once rather than on every start.
+const CreateSchemaMigrationsTable = `
+CREATE TABLE IF NOT EXISTS schema_migrations (
+    version VARCHAR(255) PRIMARY KEY,
+    applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
 `
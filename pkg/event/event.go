@@ -0,0 +1,98 @@
+// Package event defines the contract of the events published to Kafka. Other services, including ones
+// outside this repository, depend on it: fields may be added, but never renamed, removed or retyped
+// without a new schema version.
+package event
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+	"strconv"
+	"time"
+)
+
+// Headers set on every event message, so consumers can route and filter without decoding the value.
+const (
+	HeaderEventID       = "event-id"
+	HeaderEventType     = "event-type"
+	HeaderSchemaVersion = "schema-version"
+	HeaderOccurredAt    = "occurred-at" // RFC 3339
+	HeaderTraceParent   = "traceparent" // W3C trace context
+	HeaderContentType   = "content-type"
+)
+
+const contentType = "application/json"
+
+var (
+	ErrUnsupportedVersion = errors.New("unsupported event schema version")
+	ErrUnexpectedType     = errors.New("unexpected event type")
+)
+
+// Envelope wraps every event payload.
+type Envelope struct {
+	ID            string          `json:"id" example:"5f0c7a1e-8f3a-4c47-9a41-8c3c6f0e2b11"`                                       // Unique per event, for deduplication
+	Type          string          `json:"type" example:"scheduled_transaction.due"`                                                // What happened
+	SchemaVersion int             `json:"schema_version" example:"2"`                                                              // Version of the payload's schema
+	OccurredAt    time.Time       `json:"occurred_at" example:"2024-10-30T15:04:05Z"`                                              // When it happened
+	TraceParent   string          `json:"traceparent,omitempty" example:"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"` // W3C trace context
+	Payload       json.RawMessage `json:"payload" swaggertype:"object"`                                                            // Type specific content
+}
+
+// New wraps payload in an envelope with a new ID and trace.
+func New(eventType string, schemaVersion int, payload any, occurredAt time.Time) (Envelope, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return Envelope{}, fmt.Errorf("failed to serialize %s payload: %w", eventType, err)
+	}
+
+	return Envelope{
+		ID:            uuid.NewString(),
+		Type:          eventType,
+		SchemaVersion: schemaVersion,
+		OccurredAt:    occurredAt.UTC(),
+		TraceParent:   NewTraceParent(),
+		Payload:       raw,
+	}, nil
+}
+
+// Message serializes the envelope into a Kafka message with the given key and the event headers.
+func (e Envelope) Message(key string) (kafka.Message, error) {
+	value, err := json.Marshal(e)
+	if err != nil {
+		return kafka.Message{}, fmt.Errorf("failed to serialize event %s: %w", e.ID, err)
+	}
+
+	headers := []kafka.Header{
+		{Key: HeaderEventID, Value: []byte(e.ID)},
+		{Key: HeaderEventType, Value: []byte(e.Type)},
+		{Key: HeaderSchemaVersion, Value: []byte(strconv.Itoa(e.SchemaVersion))},
+		{Key: HeaderOccurredAt, Value: []byte(e.OccurredAt.Format(time.RFC3339Nano))},
+		{Key: HeaderContentType, Value: []byte(contentType)},
+	}
+	if e.TraceParent != "" {
+		headers = append(headers, kafka.Header{Key: HeaderTraceParent, Value: []byte(e.TraceParent)})
+	}
+
+	return kafka.Message{Key: []byte(key), Value: value, Headers: headers, Time: e.OccurredAt}, nil
+}
+
+// NewTraceParent starts a new W3C trace and returns its sampled root span as a traceparent value.
+func NewTraceParent() string {
+	var ids [24]byte
+	_, _ = rand.Read(ids[:])
+	return "00-" + hex.EncodeToString(ids[:16]) + "-" + hex.EncodeToString(ids[16:]) + "-01"
+}
+
+// Header returns the value of a message header, or an empty string if it is not set.
+func Header(msg kafka.Message, key string) string {
+	for _, h := range msg.Headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,92 @@
+package event
+
+import "github.com/shopspring/decimal"
+
+// Lifecycle events report state changes after they are committed. They are written to an outbox in the
+// same database transaction as the change and relayed to the events topic, keyed by the wallet address.
+const (
+	TypeDepositCompleted           = "deposit.completed"
+	TypeWithdrawalCompleted        = "withdrawal.completed"
+	TypeTransferCompleted          = "transfer.completed"
+	TypeScheduledTransferCompleted = "scheduled_transfer.completed"
+	TypeScheduledTransferFailed    = "scheduled_transfer.failed"
+	TypeWalletCreated              = "wallet.created"
+	TypeWalletDeleted              = "wallet.deleted"
+	TypeWalletFrozen               = "wallet.frozen"
+	TypeWalletUnfrozen             = "wallet.unfrozen"
+	TypeWalletRestored             = "wallet.restored"
+)
+
+// LifecycleVersion is the schema version of every lifecycle event payload.
+const LifecycleVersion = 1
+
+type DepositCompleted struct {
+	Reference     string          `json:"reference" example:"deposit:3f1c..."`            // Ledger reference of the deposit
+	WalletAddress string          `json:"wallet_address" example:"wallet_123"`            // Credited wallet
+	Network       string          `json:"network" example:"Ethereum"`                     // Blockchain network
+	Asset         string          `json:"asset" example:"ETH"`                            // Asset symbol
+	Amount        decimal.Decimal `json:"amount" swaggertype:"string" example:"100.50"`   // Amount deposited
+	Balance       decimal.Decimal `json:"balance" swaggertype:"string" example:"1500.75"` // Balance after the deposit
+}
+
+type WithdrawalCompleted struct {
+	Reference     string          `json:"reference" example:"withdraw:3f1c..."`           // Ledger reference of the withdrawal
+	WalletAddress string          `json:"wallet_address" example:"wallet_123"`            // Debited wallet
+	Network       string          `json:"network" example:"Ethereum"`                     // Blockchain network
+	Asset         string          `json:"asset" example:"ETH"`                            // Asset symbol
+	Amount        decimal.Decimal `json:"amount" swaggertype:"string" example:"100.50"`   // Amount withdrawn
+	Balance       decimal.Decimal `json:"balance" swaggertype:"string" example:"1400.25"` // Balance after the withdrawal
+}
+
+type TransferCompleted struct {
+	Reference   string          `json:"reference" example:"transfer:3f1c..."`               // Ledger reference of the transfer
+	FromWallet  string          `json:"from_wallet" example:"wallet_123"`                   // Debited wallet
+	ToWallet    string          `json:"to_wallet" example:"wallet_456"`                     // Credited wallet
+	Network     string          `json:"network" example:"Ethereum"`                         // Blockchain network
+	Asset       string          `json:"asset" example:"ETH"`                                // Asset symbol
+	Amount      decimal.Decimal `json:"amount" swaggertype:"string" example:"100.50"`       // Amount transferred
+	FromBalance decimal.Decimal `json:"from_balance" swaggertype:"string" example:"400.25"` // Sender's balance after the transfer
+	ToBalance   decimal.Decimal `json:"to_balance" swaggertype:"string" example:"900.75"`   // Receiver's balance after the transfer
+}
+
+type ScheduledTransferCompleted struct {
+	TransactionID int `json:"transaction_id" example:"1"` // Scheduled transaction ID
+	TransferCompleted
+}
+
+type ScheduledTransferFailed struct {
+	TransactionID int             `json:"transaction_id" example:"1"`                   // Scheduled transaction ID
+	FromWallet    string          `json:"from_wallet" example:"wallet_123"`             // Sender's wallet
+	ToWallet      string          `json:"to_wallet" example:"wallet_456"`               // Receiver's wallet
+	Network       string          `json:"network" example:"Ethereum"`                   // Blockchain network
+	Asset         string          `json:"asset" example:"ETH"`                          // Asset symbol
+	Amount        decimal.Decimal `json:"amount" swaggertype:"string" example:"100.50"` // Amount that was not transferred
+	Reason        string          `json:"reason" example:"insufficient balance"`        // Why the transfer failed
+}
+
+type WalletCreated struct {
+	Address string `json:"address" example:"wallet_123"` // Wallet address
+	Network string `json:"network" example:"Ethereum"`   // Blockchain network
+}
+
+type WalletDeleted struct {
+	Address string `json:"address" example:"wallet_123"` // Wallet address
+	Network string `json:"network" example:"Ethereum"`   // Blockchain network
+
+	Reason string `json:"reason,omitempty" example:"Created by mistake"` // Why the wallet was deleted, if given
+}
+
+// WalletRestored is the payload of TypeWalletRestored events.
+type WalletRestored struct {
+	Address string `json:"address" example:"wallet_123"` // Wallet address
+	Network string `json:"network" example:"Ethereum"`   // Blockchain network
+}
+
+// WalletStatusChanged is the payload of TypeWalletFrozen and TypeWalletUnfrozen events.
+type WalletStatusChanged struct {
+	Address string `json:"address" example:"wallet_123"`              // Wallet address
+	Network string `json:"network" example:"Ethereum"`                // Blockchain network
+	Status  string `json:"status" example:"frozen"`                   // New status
+	Reason  string `json:"reason" example:"Suspected key compromise"` // Why the status changed
+	Actor   string `json:"actor" example:"ops@example.com"`           // Who changed it
+}
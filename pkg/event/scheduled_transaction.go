@@ -0,0 +1,99 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/segmentio/kafka-go"
+	"github.com/shopspring/decimal"
+	"strconv"
+	"time"
+)
+
+// TypeScheduledTransactionDue is published when a scheduled transaction is due and must be executed.
+const TypeScheduledTransactionDue = "scheduled_transaction.due"
+
+// Schema versions of TypeScheduledTransactionDue. Version 1 is the bare transaction JSON published before
+// events had an envelope; it is still accepted so that messages written by older publishers are processed.
+const (
+	ScheduledTransactionDueV1 = 1
+	ScheduledTransactionDueV2 = 2
+
+	ScheduledTransactionDueVersion = ScheduledTransactionDueV2 // Version written by the publisher
+)
+
+// ScheduledTransactionDue is the payload of a TypeScheduledTransactionDue event.
+type ScheduledTransactionDue struct {
+	TransactionID    int             `json:"transaction_id" example:"1"`                    // Scheduled transaction ID
+	FromWallet       string          `json:"from_wallet" example:"wallet_123"`              // Sender's wallet address
+	ToWallet         string          `json:"to_wallet" example:"wallet_456"`                // Recipient's wallet address
+	Network          string          `json:"network" example:"Ethereum"`                    // Blockchain network
+	Asset            string          `json:"asset" example:"ETH"`                           // Asset symbol
+	Amount           decimal.Decimal `json:"amount" swaggertype:"string" example:"250.75"`  // Amount to be transferred
+	ScheduledTime    time.Time       `json:"scheduled_time" example:"2024-10-30T15:04:05Z"` // Time the transaction was due
+	SeriesID         *int            `json:"series_id,omitempty" example:"1"`               // First occurrence of a recurring series
+	OccurrenceNumber int             `json:"occurrence_number,omitempty" example:"1"`       // 1-based position within the series
+}
+
+// scheduledTransactionDueV1 is the bare transaction JSON of schema version 1.
+type scheduledTransactionDueV1 struct {
+	ID            int             `json:"id"`
+	FromWallet    string          `json:"from_wallet"`
+	ToWallet      string          `json:"to_wallet"`
+	Network       string          `json:"network"`
+	Asset         string          `json:"asset"`
+	Amount        decimal.Decimal `json:"amount"`
+	ScheduledTime time.Time       `json:"scheduled_time"`
+}
+
+// NewScheduledTransactionDue wraps payload in an envelope of the current schema version.
+func NewScheduledTransactionDue(payload ScheduledTransactionDue, occurredAt time.Time) (Envelope, error) {
+	return New(TypeScheduledTransactionDue, ScheduledTransactionDueVersion, payload, occurredAt)
+}
+
+// DecodeScheduledTransactionDue reads a TypeScheduledTransactionDue event of any supported schema version.
+// Messages without a schema-version header are version 1.
+func DecodeScheduledTransactionDue(msg kafka.Message) (ScheduledTransactionDue, error) {
+	version := ScheduledTransactionDueV1
+	if value := Header(msg, HeaderSchemaVersion); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return ScheduledTransactionDue{}, fmt.Errorf("%w: %q", ErrUnsupportedVersion, value)
+		}
+		version = parsed
+	}
+
+	switch version {
+	case ScheduledTransactionDueV1:
+		var v1 scheduledTransactionDueV1
+		if err := json.Unmarshal(msg.Value, &v1); err != nil {
+			return ScheduledTransactionDue{}, fmt.Errorf("failed to parse version 1 event: %w", err)
+		}
+		return ScheduledTransactionDue{
+			TransactionID: v1.ID,
+			FromWallet:    v1.FromWallet,
+			ToWallet:      v1.ToWallet,
+			Network:       v1.Network,
+			Asset:         v1.Asset,
+			Amount:        v1.Amount,
+			ScheduledTime: v1.ScheduledTime,
+		}, nil
+
+	case ScheduledTransactionDueV2:
+		var envelope Envelope
+		if err := json.Unmarshal(msg.Value, &envelope); err != nil {
+			return ScheduledTransactionDue{}, fmt.Errorf("failed to parse event envelope: %w", err)
+		}
+		if envelope.Type != TypeScheduledTransactionDue {
+			return ScheduledTransactionDue{}, fmt.Errorf("%w: %q", ErrUnexpectedType, envelope.Type)
+		}
+
+		var payload ScheduledTransactionDue
+		if err := json.Unmarshal(envelope.Payload, &payload); err != nil {
+			return ScheduledTransactionDue{}, fmt.Errorf("failed to parse %s payload: %w", envelope.Type, err)
+		}
+		return payload, nil
+
+	default:
+		return ScheduledTransactionDue{}, fmt.Errorf("%w: %d", ErrUnsupportedVersion, version)
+	}
+}
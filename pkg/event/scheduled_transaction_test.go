@@ -0,0 +1,96 @@
+package event_test
+
+import (
+	"asset-management/pkg/event"
+	"encoding/json"
+	"github.com/segmentio/kafka-go"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestScheduledTransactionDue_RoundTrip(t *testing.T) {
+	seriesID := 7
+	payload := event.ScheduledTransactionDue{
+		TransactionID:    42,
+		FromWallet:       "wallet_123",
+		ToWallet:         "wallet_456",
+		Network:          "Ethereum",
+		Asset:            "ETH",
+		Amount:           decimal.RequireFromString("250.75"),
+		ScheduledTime:    time.Date(2024, 10, 30, 15, 4, 5, 0, time.UTC),
+		SeriesID:         &seriesID,
+		OccurrenceNumber: 3,
+	}
+	occurredAt := time.Date(2024, 10, 30, 15, 4, 6, 0, time.UTC)
+
+	envelope, err := event.NewScheduledTransactionDue(payload, occurredAt)
+	assert.NoError(t, err)
+	msg, err := envelope.Message("wallet_123")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "wallet_123", string(msg.Key))
+	assert.Equal(t, envelope.ID, event.Header(msg, event.HeaderEventID))
+	assert.Equal(t, event.TypeScheduledTransactionDue, event.Header(msg, event.HeaderEventType))
+	assert.Equal(t, "2", event.Header(msg, event.HeaderSchemaVersion))
+	assert.Equal(t, "2024-10-30T15:04:06Z", event.Header(msg, event.HeaderOccurredAt))
+	assert.Equal(t, "application/json", event.Header(msg, event.HeaderContentType))
+	assert.Regexp(t, regexp.MustCompile(`^00-[0-9a-f]{32}-[0-9a-f]{16}-01$`), event.Header(msg, event.HeaderTraceParent))
+
+	var decodedEnvelope event.Envelope
+	assert.NoError(t, json.Unmarshal(msg.Value, &decodedEnvelope))
+	assert.Equal(t, envelope.TraceParent, decodedEnvelope.TraceParent)
+	assert.True(t, occurredAt.Equal(decodedEnvelope.OccurredAt))
+
+	decoded, err := event.DecodeScheduledTransactionDue(msg)
+	assert.NoError(t, err)
+	assert.Equal(t, 42, decoded.TransactionID)
+	assert.True(t, payload.Amount.Equal(decoded.Amount))
+	assert.Equal(t, &seriesID, decoded.SeriesID)
+	assert.Equal(t, 3, decoded.OccurrenceNumber)
+}
+
+func TestDecodeScheduledTransactionDue_Version1(t *testing.T) {
+	// Bare transaction JSON, as published before events had an envelope
+	msg := kafka.Message{Value: []byte(`{"id":42,"from_wallet":"wallet_123","to_wallet":"wallet_456","network":"Ethereum","asset":"ETH","amount":"250.75","scheduled_time":"2024-10-30T15:04:05Z","status":"PENDING"}`)}
+
+	decoded, err := event.DecodeScheduledTransactionDue(msg)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 42, decoded.TransactionID)
+	assert.Equal(t, "wallet_456", decoded.ToWallet)
+	assert.Equal(t, "250.75", decoded.Amount.String())
+}
+
+func TestDecodeScheduledTransactionDue_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		msg     kafka.Message
+		wantErr error
+	}{
+		{
+			name:    "future version",
+			msg:     kafka.Message{Value: []byte(`{}`), Headers: []kafka.Header{{Key: event.HeaderSchemaVersion, Value: []byte("3")}}},
+			wantErr: event.ErrUnsupportedVersion,
+		},
+		{
+			name:    "malformed version",
+			msg:     kafka.Message{Value: []byte(`{}`), Headers: []kafka.Header{{Key: event.HeaderSchemaVersion, Value: []byte("v2")}}},
+			wantErr: event.ErrUnsupportedVersion,
+		},
+		{
+			name:    "other event type",
+			msg:     kafka.Message{Value: []byte(`{"type":"wallet.created","payload":{}}`), Headers: []kafka.Header{{Key: event.HeaderSchemaVersion, Value: []byte("2")}}},
+			wantErr: event.ErrUnexpectedType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := event.DecodeScheduledTransactionDue(tt.msg)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
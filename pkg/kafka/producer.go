@@ -14,8 +14,11 @@ func NewProducer(brokerAddress, topic string) *Producer {
 	writer := &kafka.Writer{
 		Addr:      kafka.TCP(brokerAddress),
 		Topic:     topic,
-		Balancer:  &kafka.LeastBytes{},
 		Transport: &kafka.Transport{ClientID: "json-producer", DialTimeout: 10 * time.Second},
+		// Messages with the same key, e.g. the transfers of one wallet, go to the same partition and stay in order
+		Balancer: &kafka.Hash{},
+		// Wait for all in-sync replicas, so a successful write means the messages are stored
+		RequiredAcks: kafka.RequireAll,
 	}
 	return &Producer{Writer: writer}
 }
@@ -0,0 +1,30 @@
+package kafka_test
+
+import (
+	kafka2 "asset-management/pkg/kafka"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestNewProducer_EqualKeysShareAPartition(t *testing.T) {
+	producer := kafka2.NewProducer("localhost:9092", "transactions")
+	balancer := producer.Writer.Balancer
+	partitions := []int{0, 1, 2, 3, 4, 5, 6, 7}
+
+	keys := []string{"wallet_123", "wallet_456", "wallet_789"}
+	first := map[string]int{}
+	for round := 0; round < 10; round++ {
+		for _, key := range keys {
+			// Values of different sizes would move keys between partitions under a load-based balancer
+			msg := kafka.Message{Key: []byte(key), Value: []byte(strings.Repeat("x", round*100))}
+			partition := balancer.Balance(msg, partitions...)
+			if round == 0 {
+				first[key] = partition
+				continue
+			}
+			assert.Equal(t, first[key], partition, "key %s", key)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package approval
+
+import (
+	approval2 "asset-management/internal/approval"
+	"asset-management/internal/funds"
+	"asset-management/services/asset-api/dto"
+	"asset-management/services/asset-api/wallet"
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"strconv"
+)
+
+type Controller interface {
+	List(ctx *fiber.Ctx) error
+	Get(ctx *fiber.Ctx) error
+	Approve(ctx *fiber.Ctx) error
+	Reject(ctx *fiber.Ctx) error
+}
+
+type ApproveRequest struct {
+	Approver string `json:"approver" example:"bob@example.com"` // Who approves, other than the requester
+	Comment  string `json:"comment,omitempty" example:"beneficiary verified"`
+}
+
+type RejectRequest struct {
+	Approver string `json:"approver" example:"bob@example.com"`        // Who rejects, other than the requester
+	Reason   string `json:"reason" example:"beneficiary not verified"` // Why the request is rejected
+}
+
+type controller struct {
+	service Service
+}
+
+func NewController(service Service) Controller {
+	return &controller{service: service}
+}
+
+// List godoc
+// @Summary      List approval requests
+// @Description  Lists withdrawals and scheduled transfers that exceeded an approval threshold, oldest first
+// @Tags         approvals
+// @Produce      json
+// @Param        status query string false "Status" Enums(AWAITING_APPROVAL, APPROVED, REJECTED, CANCELLED)
+// @Param        wallet_address query string false "Wallet the funds leave"
+// @Param        network query string false "Network"
+// @Param        scheduled_transaction_id query int false "Scheduled transaction ID"
+// @Success      200  {array}   approval2.Request
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /approvals [get]
+func (c *controller) List(ctx *fiber.Ctx) error {
+	filter := ListFilter{
+		Status:        ctx.Query("status"),
+		WalletAddress: ctx.Query("wallet_address"),
+		Network:       ctx.Query("network"),
+	}
+	if raw := ctx.Query("scheduled_transaction_id"); raw != "" {
+		id, err := strconv.Atoi(raw)
+		if err != nil || id <= 0 {
+			return ctx.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Message: "Invalid scheduled transaction ID"})
+		}
+		filter.ScheduledTransactionID = id
+	}
+
+	requests, err := c.service.List(filter)
+	if err != nil {
+		return ctx.Status(errorStatus(err)).JSON(dto.ErrorResponse{Message: err.Error()})
+	}
+
+	return ctx.JSON(requests)
+}
+
+// Get godoc
+// @Summary      Get an approval request
+// @Description  Returns an approval request with the decisions made so far
+// @Tags         approvals
+// @Produce      json
+// @Param        id path int true "Approval request ID"
+// @Success      200  {object}  approval2.Request
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /approvals/{id} [get]
+func (c *controller) Get(ctx *fiber.Ctx) error {
+	id, err := strconv.Atoi(ctx.Params("id"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Message: "Invalid approval request ID"})
+	}
+
+	request, err := c.service.Get(id)
+	return respond(ctx, request, err)
+}
+
+// Approve godoc
+// @Summary      Approve a request
+// @Description  Records an approval. Once enough approvers other than the requester agreed, the withdrawal is made or the scheduled transfer becomes PENDING.
+// @Tags         approvals
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Approval request ID"
+// @Param        decision body ApproveRequest true "Approver"
+// @Success      200  {object}  approval2.Request
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      403  {object}  dto.ErrorResponse "Approver is the requester, or the source wallet is frozen"
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      409  {object}  dto.ErrorResponse "Request already decided, approver already decided on it, or the source wallet is being deleted"
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /approvals/{id}/approve [post]
+func (c *controller) Approve(ctx *fiber.Ctx) error {
+	id, err := strconv.Atoi(ctx.Params("id"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Message: "Invalid approval request ID"})
+	}
+
+	var req ApproveRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Message: "Invalid request payload"})
+	}
+
+	request, err := c.service.Approve(id, req.Approver, req.Comment)
+	return respond(ctx, request, err)
+}
+
+// Reject godoc
+// @Summary      Reject a request
+// @Description  Rejects a request. A withdrawal's amount is released; a scheduled transfer is cancelled.
+// @Tags         approvals
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Approval request ID"
+// @Param        decision body RejectRequest true "Approver and reason"
+// @Success      200  {object}  approval2.Request
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      403  {object}  dto.ErrorResponse "Approver is the requester"
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      409  {object}  dto.ErrorResponse "Request already decided, or approver already decided on it"
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /approvals/{id}/reject [post]
+func (c *controller) Reject(ctx *fiber.Ctx) error {
+	id, err := strconv.Atoi(ctx.Params("id"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Message: "Invalid approval request ID"})
+	}
+
+	var req RejectRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Message: "Invalid request payload"})
+	}
+
+	request, err := c.service.Reject(id, req.Approver, req.Reason)
+	return respond(ctx, request, err)
+}
+
+// respond writes the request, or the error that kept it from being read or decided.
+func respond(ctx *fiber.Ctx, request *approval2.Request, err error) error {
+	if err != nil {
+		return ctx.Status(errorStatus(err)).JSON(dto.ErrorResponse{Message: err.Error()})
+	}
+	return ctx.JSON(request)
+}
+
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrInvalidDecision), errors.Is(err, ErrInvalidFilter),
+		errors.Is(err, funds.ErrInsufficientBalance), errors.Is(err, funds.ErrFundsHeld):
+		return fiber.StatusBadRequest
+	case errors.Is(err, ErrSelfApproval), errors.Is(err, wallet.ErrWalletFrozen):
+		return fiber.StatusForbidden
+	case errors.Is(err, ErrNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, ErrNotAwaiting), errors.Is(err, ErrAlreadyDecided), errors.Is(err, wallet.ErrWalletDeleting):
+		return fiber.StatusConflict
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
@@ -0,0 +1,190 @@
+package approval_test
+
+import (
+	approval2 "asset-management/internal/approval"
+	"asset-management/internal/funds"
+	"asset-management/services/asset-api/approval"
+	"asset-management/services/asset-api/dto"
+	"asset-management/services/asset-api/wallet"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type mockService struct{ mock.Mock }
+
+func (m *mockService) List(filter approval.ListFilter) ([]approval2.Request, error) {
+	args := m.Called(filter)
+	if requests, ok := args.Get(0).([]approval2.Request); ok {
+		return requests, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockService) Get(id int) (*approval2.Request, error) {
+	args := m.Called(id)
+	if request, ok := args.Get(0).(*approval2.Request); ok {
+		return request, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockService) Approve(id int, approver, comment string) (*approval2.Request, error) {
+	args := m.Called(id, approver, comment)
+	if request, ok := args.Get(0).(*approval2.Request); ok {
+		return request, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockService) Reject(id int, approver, reason string) (*approval2.Request, error) {
+	args := m.Called(id, approver, reason)
+	if request, ok := args.Get(0).(*approval2.Request); ok {
+		return request, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func setupApp(service approval.Service) *fiber.App {
+	app := fiber.New()
+	controller := approval.NewController(service)
+	app.Get("/approvals", controller.List)
+	app.Get("/approvals/:id", controller.Get)
+	app.Post("/approvals/:id/approve", controller.Approve)
+	app.Post("/approvals/:id/reject", controller.Reject)
+	return app
+}
+
+func request(method, target string, body interface{}) *http.Request {
+	var payload bytes.Buffer
+	if body != nil {
+		_ = json.NewEncoder(&payload).Encode(body)
+	}
+	req := httptest.NewRequest(method, target, &payload)
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func TestApprovalController_List(t *testing.T) {
+	service := new(mockService)
+	service.On("List", approval.ListFilter{Status: approval2.StatusAwaitingApproval, ScheduledTransactionID: 7}).
+		Return([]approval2.Request{{ID: 12, Status: approval2.StatusAwaitingApproval, Decisions: []approval2.Decision{}}}, nil)
+
+	resp, err := setupApp(service).Test(request(http.MethodGet, "/approvals?status=AWAITING_APPROVAL&scheduled_transaction_id=7", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var requests []map[string]interface{}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&requests))
+	assert.Len(t, requests, 1)
+	assert.Equal(t, "AWAITING_APPROVAL", requests[0]["status"])
+	assert.NotContains(t, requests[0], "decided_at")
+	service.AssertExpectations(t)
+}
+
+func TestApprovalController_Approve(t *testing.T) {
+	service := new(mockService)
+	service.On("Approve", 12, "bob", "checked").Return(&approval2.Request{ID: 12, Status: approval2.StatusApproved}, nil)
+
+	resp, err := setupApp(service).Test(request(http.MethodPost, "/approvals/12/approve", approval.ApproveRequest{Approver: "bob", Comment: "checked"}))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var response approval2.Request
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	assert.Equal(t, approval2.StatusApproved, response.Status)
+	service.AssertExpectations(t)
+}
+
+func TestApprovalController_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		req    *http.Request
+		setup  func(s *mockService)
+		status int
+	}{
+		{
+			name:   "invalid ID",
+			req:    request(http.MethodGet, "/approvals/abc", nil),
+			setup:  func(s *mockService) {},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "invalid scheduled transaction ID",
+			req:    request(http.MethodGet, "/approvals?scheduled_transaction_id=x", nil),
+			setup:  func(s *mockService) {},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "unknown request",
+			req:    request(http.MethodGet, "/approvals/9", nil),
+			setup:  func(s *mockService) { s.On("Get", 9).Return(nil, approval.ErrNotFound) },
+			status: http.StatusNotFound,
+		},
+		{
+			name: "missing reason",
+			req:  request(http.MethodPost, "/approvals/9/reject", approval.RejectRequest{Approver: "bob"}),
+			setup: func(s *mockService) {
+				s.On("Reject", 9, "bob", "").Return(nil, approval.ErrInvalidDecision)
+			},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "self approval",
+			req:    request(http.MethodPost, "/approvals/9/approve", approval.ApproveRequest{Approver: "alice"}),
+			setup:  func(s *mockService) { s.On("Approve", 9, "alice", "").Return(nil, approval.ErrSelfApproval) },
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "frozen wallet",
+			req:    request(http.MethodPost, "/approvals/9/approve", approval.ApproveRequest{Approver: "bob"}),
+			setup:  func(s *mockService) { s.On("Approve", 9, "bob", "").Return(nil, wallet.ErrWalletFrozen) },
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "already decided",
+			req:    request(http.MethodPost, "/approvals/9/approve", approval.ApproveRequest{Approver: "bob"}),
+			setup:  func(s *mockService) { s.On("Approve", 9, "bob", "").Return(nil, approval.ErrAlreadyDecided) },
+			status: http.StatusConflict,
+		},
+		{
+			name:   "not awaiting approval",
+			req:    request(http.MethodPost, "/approvals/9/reject", approval.RejectRequest{Approver: "bob", Reason: "no"}),
+			setup:  func(s *mockService) { s.On("Reject", 9, "bob", "no").Return(nil, approval.ErrNotAwaiting) },
+			status: http.StatusConflict,
+		},
+		{
+			name:   "insufficient balance",
+			req:    request(http.MethodPost, "/approvals/9/approve", approval.ApproveRequest{Approver: "bob"}),
+			setup:  func(s *mockService) { s.On("Approve", 9, "bob", "").Return(nil, funds.ErrInsufficientBalance) },
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "list fails",
+			req:    request(http.MethodGet, "/approvals", nil),
+			setup:  func(s *mockService) { s.On("List", approval.ListFilter{}).Return(nil, errors.New("db down")) },
+			status: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := new(mockService)
+			tt.setup(service)
+
+			resp, err := setupApp(service).Test(tt.req)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, resp.StatusCode)
+
+			var response dto.ErrorResponse
+			assert.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+			assert.NotEmpty(t, response.Message)
+		})
+	}
+}
@@ -0,0 +1,273 @@
+package approval
+
+import (
+	approval2 "asset-management/internal/approval"
+	"asset-management/internal/funds"
+	"asset-management/internal/schedule"
+	"asset-management/services/asset-api/withdraw"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrNotFound       = errors.New("approval request not found")
+	ErrNotAwaiting    = errors.New("approval request is no longer awaiting approval")
+	ErrSelfApproval   = errors.New("requesters may not decide on their own requests")
+	ErrAlreadyDecided = errors.New("approver has already decided on this request")
+)
+
+// ListFilter selects approval requests. Empty fields match every request.
+type ListFilter struct {
+	Status                 string
+	WalletAddress          string
+	Network                string
+	ScheduledTransactionID int
+}
+
+type Repository interface {
+	List(filter ListFilter) ([]approval2.Request, error)
+	Get(id int) (*approval2.Request, error)
+	Approve(id int, approver, comment string, required int) (*approval2.Request, error)
+	Reject(id int, approver, reason string) (*approval2.Request, error)
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+// List returns the requests matching the filter, oldest first, without their decisions.
+func (r *repository) List(filter ListFilter) ([]approval2.Request, error) {
+	var conditions []string
+	var args []any
+	where := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.Status != "" {
+		where("status = $%d", filter.Status)
+	}
+	if filter.WalletAddress != "" {
+		where("wallet_address = $%d", filter.WalletAddress)
+	}
+	if filter.Network != "" {
+		where("network = $%d", filter.Network)
+	}
+	if filter.ScheduledTransactionID != 0 {
+		where("scheduled_transaction_id = $%d", filter.ScheduledTransactionID)
+	}
+
+	query := `SELECT ` + approval2.Columns + ` FROM approval_requests`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, ` AND `)
+	}
+	query += ` ORDER BY approval_id`
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query approval requests: %w", err)
+	}
+	defer rows.Close()
+
+	requests := []approval2.Request{}
+	for rows.Next() {
+		request, err := approval2.Scan(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan approval request: %w", err)
+		}
+		requests = append(requests, request)
+	}
+	return requests, rows.Err()
+}
+
+// Get returns a request with its decisions.
+func (r *repository) Get(id int) (*approval2.Request, error) {
+	ctx := context.Background()
+
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	request, err := read(ctx, tx, id, "")
+	if err != nil {
+		return nil, err
+	}
+	return request, tx.Commit()
+}
+
+// Approve records the approver's decision. Once required approvers agreed, a withdrawal is made and a
+// scheduled transfer becomes PENDING, so the publisher picks it up when it is due. Later occurrences of
+// an approved recurring transfer need no further approval.
+func (r *repository) Approve(id int, approver, comment string, required int) (*approval2.Request, error) {
+	return r.decide(id, approver, approval2.DecisionApprove, comment, func(ctx context.Context, tx *sql.Tx, request *approval2.Request) error {
+		var approvals int
+		err := tx.QueryRowContext(ctx, `
+            SELECT COUNT(*) FROM approval_decisions WHERE approval_id = $1 AND decision = 'APPROVE'`, id).Scan(&approvals)
+		if err != nil {
+			return fmt.Errorf("failed to count approvals: %w", err)
+		}
+		if approvals < required {
+			return nil
+		}
+
+		// Approve first, so the request no longer holds the amount it is about to move
+		if err := setStatus(ctx, tx, id, approval2.StatusApproved, ""); err != nil {
+			return err
+		}
+
+		switch request.Operation {
+		case approval2.OperationWithdraw:
+			sender, err := funds.Lock(ctx, tx, request.WalletAddress, request.Network, request.Asset)
+			if err != nil {
+				return err
+			}
+			if err := sender.Cover(request.Amount, request.Asset); err != nil {
+				return err
+			}
+			return withdraw.Debit(ctx, tx, request.WalletAddress, request.Network, request.Asset, request.Amount,
+				fmt.Sprintf("approval:%d", id))
+		case approval2.OperationScheduledTransfer:
+			return setScheduledStatus(ctx, tx, request, schedule.StatusPending, nil)
+		default:
+			return fmt.Errorf("unknown operation %q", request.Operation)
+		}
+	})
+}
+
+// Reject records the approver's decision and rejects the request. A withdrawal's hold is released and
+// a scheduled transfer is cancelled.
+func (r *repository) Reject(id int, approver, reason string) (*approval2.Request, error) {
+	return r.decide(id, approver, approval2.DecisionReject, reason, func(ctx context.Context, tx *sql.Tx, request *approval2.Request) error {
+		if err := setStatus(ctx, tx, id, approval2.StatusRejected, reason); err != nil {
+			return err
+		}
+		if request.Operation == approval2.OperationScheduledTransfer {
+			failure := "approval rejected: " + reason
+			return setScheduledStatus(ctx, tx, request, schedule.StatusCancelled, &failure)
+		}
+		return nil
+	})
+}
+
+// decide locks the request, records the decision and then lets apply act on it, all in one database
+// transaction. The request is locked before the scheduled transaction and balance it touches, in the
+// same order a sweep locks them.
+func (r *repository) decide(id int, approver, decision, comment string,
+	apply func(ctx context.Context, tx *sql.Tx, request *approval2.Request) error) (*approval2.Request, error) {
+	ctx := context.Background()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	request, err := read(ctx, tx, id, "FOR UPDATE")
+	if err != nil {
+		return nil, err
+	}
+	if request.Status != approval2.StatusAwaitingApproval {
+		return nil, ErrNotAwaiting
+	}
+	// Requests opened before identities were normalized may still hold the requester as typed
+	approver = approval2.Identity(approver)
+	if approval2.Identity(request.RequestedBy) == approver {
+		return nil, ErrSelfApproval
+	}
+
+	result, err := tx.ExecContext(ctx, `
+        INSERT INTO approval_decisions (approval_id, approver, decision, comment)
+        VALUES ($1, $2, $3, NULLIF($4, ''))
+        ON CONFLICT (approval_id, approver) DO NOTHING`, id, approver, decision, comment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record decision: %w", err)
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return nil, fmt.Errorf("failed to record decision: %w", err)
+	} else if n == 0 {
+		return nil, ErrAlreadyDecided
+	}
+
+	if err := apply(ctx, tx, request); err != nil {
+		return nil, err
+	}
+
+	if request, err = read(ctx, tx, id, ""); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit decision: %w", err)
+	}
+	return request, nil
+}
+
+// read returns the request with its decisions, with the given locking clause.
+func read(ctx context.Context, tx *sql.Tx, id int, locking string) (*approval2.Request, error) {
+	request, err := approval2.Scan(tx.QueryRowContext(ctx, `
+        SELECT `+approval2.Columns+` FROM approval_requests WHERE approval_id = $1 `+locking, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query approval request: %w", err)
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+        SELECT approver, decision, COALESCE(comment, ''), created_at FROM approval_decisions
+        WHERE approval_id = $1
+        ORDER BY created_at, approver`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query decisions: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var d approval2.Decision
+		if err := rows.Scan(&d.Approver, &d.Decision, &d.Comment, &d.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan decision: %w", err)
+		}
+		request.Decisions = append(request.Decisions, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read decisions: %w", err)
+	}
+	return &request, nil
+}
+
+func setStatus(ctx context.Context, tx *sql.Tx, id int, status, reason string) error {
+	_, err := tx.ExecContext(ctx, `
+        UPDATE approval_requests SET status = $2, reason = NULLIF($3, ''), decided_at = NOW()
+        WHERE approval_id = $1`, id, status, reason)
+	if err != nil {
+		return fmt.Errorf("failed to update approval request: %w", err)
+	}
+	return nil
+}
+
+// setScheduledStatus moves the request's scheduled transfer out of AWAITING_APPROVAL.
+func setScheduledStatus(ctx context.Context, tx *sql.Tx, request *approval2.Request, status string, failureReason *string) error {
+	if request.ScheduledTransactionID == nil {
+		return fmt.Errorf("approval request %d has no scheduled transaction", request.ID)
+	}
+
+	result, err := tx.ExecContext(ctx, `
+        UPDATE scheduled_transactions SET status = $2, failure_reason = COALESCE($3, failure_reason)
+        WHERE scheduled_transaction_id = $1 AND status = 'AWAITING_APPROVAL'`, *request.ScheduledTransactionID, status, failureReason)
+	if err != nil {
+		return fmt.Errorf("failed to update scheduled transaction: %w", err)
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to update scheduled transaction: %w", err)
+	} else if n == 0 {
+		return ErrNotAwaiting
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package approval_test
+
+import (
+	approval2 "asset-management/internal/approval"
+	"asset-management/internal/funds"
+	"asset-management/internal/schedule"
+	"asset-management/services/asset-api/approval"
+	"asset-management/services/asset-api/scheduled"
+	"asset-management/services/asset-api/util"
+	"asset-management/services/asset-api/withdraw"
+	"context"
+	"database/sql"
+	"fmt"
+	_ "github.com/lib/pq"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func setupThreshold(t *testing.T, db *sql.DB) {
+	assert.NoError(t, util.InsertBalance(db, "0x123", "Ethereum", "ETH", decimal.RequireFromString("100")))
+	_, err := db.Exec(`
+		INSERT INTO withdrawal_limits (scope, network, wallet_address, asset, kind, value)
+		VALUES ('GLOBAL', '', '', 'ETH', 'APPROVAL_THRESHOLD', 50)`)
+	assert.NoError(t, err)
+}
+
+func TestRepository_ApproveWithdrawal(t *testing.T) {
+	db, cleanup := util.SetupTestContainer(t)
+	defer cleanup()
+	setupThreshold(t, db)
+
+	id, err := withdraw.NewRepository(db).Withdraw("0x123", "Ethereum", "ETH", decimal.RequireFromString("60"), "alice")
+	assert.NoError(t, err)
+
+	repo := approval.NewRepository(db)
+
+	_, err = repo.Approve(id, "alice", "", 2)
+	assert.ErrorIs(t, err, approval.ErrSelfApproval)
+	_, err = repo.Approve(id, " Alice ", "", 2)
+	assert.ErrorIs(t, err, approval.ErrSelfApproval)
+
+	// The first of two approvals changes nothing
+	request, err := repo.Approve(id, "bob", "checked", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, approval2.StatusAwaitingApproval, request.Status)
+	if assert.Len(t, request.Decisions, 1) {
+		assert.Equal(t, "checked", request.Decisions[0].Comment)
+	}
+
+	_, err = repo.Approve(id, "BOB", "", 2)
+	assert.ErrorIs(t, err, approval.ErrAlreadyDecided)
+
+	request, err = repo.Approve(id, "carol", "", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, approval2.StatusApproved, request.Status)
+	assert.NotNil(t, request.DecidedAt)
+
+	var balance decimal.Decimal
+	err = db.QueryRow(`SELECT balance FROM balance WHERE wallet_address = '0x123' AND asset = 'ETH'`).Scan(&balance)
+	assert.NoError(t, err)
+	assert.Equal(t, "40", balance.String())
+
+	var entries int
+	err = db.QueryRow(`SELECT COUNT(*) FROM ledger_entries WHERE reference = $1 AND operation = 'WITHDRAW'`, fmt.Sprintf("approval:%d", id)).Scan(&entries)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, entries)
+
+	_, err = repo.Reject(id, "dave", "too late")
+	assert.ErrorIs(t, err, approval.ErrNotAwaiting)
+}
+
+func TestRepository_RejectScheduledTransfer(t *testing.T) {
+	db, cleanup := util.SetupTestContainer(t)
+	defer cleanup()
+	setupThreshold(t, db)
+
+	transfer := &schedule.ScheduledTransaction{
+		FromWallet:    "0x123",
+		ToWallet:      "0x456",
+		Network:       "Ethereum",
+		Asset:         "ETH",
+		Amount:        decimal.RequireFromString("80"),
+		ScheduledTime: time.Now().Add(time.Hour),
+		Status:        schedule.StatusPending,
+	}
+	scheduledID, err := scheduled.NewCreateRepository(db).Create(transfer, "alice")
+	assert.NoError(t, err)
+
+	repo := approval.NewRepository(db)
+	requests, err := repo.List(approval.ListFilter{ScheduledTransactionID: scheduledID})
+	assert.NoError(t, err)
+	if !assert.Len(t, requests, 1) {
+		return
+	}
+
+	request, err := repo.Reject(requests[0].ID, "bob", "beneficiary not verified")
+	assert.NoError(t, err)
+	assert.Equal(t, approval2.StatusRejected, request.Status)
+	assert.Equal(t, "beneficiary not verified", request.Reason)
+
+	txn, err := scheduled.NewQueryRepository(db).Get(scheduledID)
+	assert.NoError(t, err)
+	assert.Equal(t, schedule.StatusCancelled, txn.Status)
+
+	// The hold is released
+	tx, err := db.Begin()
+	assert.NoError(t, err)
+	defer tx.Rollback()
+	held, err := funds.Held(context.Background(), tx, "0x123", "Ethereum", "ETH")
+	assert.NoError(t, err)
+	assert.True(t, held.IsZero())
+}
+
+func TestRepository_ApproveScheduledTransfer(t *testing.T) {
+	db, cleanup := util.SetupTestContainer(t)
+	defer cleanup()
+	setupThreshold(t, db)
+
+	scheduledID, err := scheduled.NewCreateRepository(db).Create(&schedule.ScheduledTransaction{
+		FromWallet:    "0x123",
+		ToWallet:      "0x456",
+		Network:       "Ethereum",
+		Asset:         "ETH",
+		Amount:        decimal.RequireFromString("80"),
+		ScheduledTime: time.Now().Add(time.Hour),
+		Status:        schedule.StatusPending,
+	}, "alice")
+	assert.NoError(t, err)
+
+	repo := approval.NewRepository(db)
+	requests, err := repo.List(approval.ListFilter{Status: approval2.StatusAwaitingApproval, WalletAddress: "0x123"})
+	assert.NoError(t, err)
+	if !assert.Len(t, requests, 1) {
+		return
+	}
+
+	_, err = repo.Approve(requests[0].ID, "bob", "", 1)
+	assert.NoError(t, err)
+
+	txn, err := scheduled.NewQueryRepository(db).Get(scheduledID)
+	assert.NoError(t, err)
+	assert.Equal(t, schedule.StatusPending, txn.Status)
+
+	_, err = repo.Get(999)
+	assert.ErrorIs(t, err, approval.ErrNotFound)
+}
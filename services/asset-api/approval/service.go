@@ -0,0 +1,87 @@
+package approval
+
+import (
+	approval2 "asset-management/internal/approval"
+	"asset-management/services/asset-api/wallet"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrInvalidDecision wraps every validation error of a decision.
+	ErrInvalidDecision = errors.New("invalid decision")
+	ErrInvalidFilter   = errors.New("invalid filter")
+)
+
+type Service interface {
+	List(filter ListFilter) ([]approval2.Request, error)
+	Get(id int) (*approval2.Request, error)
+	Approve(id int, approver, comment string) (*approval2.Request, error)
+	Reject(id int, approver, reason string) (*approval2.Request, error)
+}
+
+type service struct {
+	repository        Repository
+	walletValidator   wallet.ValidationAdapter
+	requiredApprovals int
+}
+
+// NewService returns a service that executes a request once requiredApprovals approvers other than
+// the requester approved it.
+func NewService(repository Repository, wv wallet.ValidationAdapter, requiredApprovals int) Service {
+	return &service{repository: repository, walletValidator: wv, requiredApprovals: requiredApprovals}
+}
+
+func (s *service) List(filter ListFilter) ([]approval2.Request, error) {
+	switch filter.Status {
+	case "", approval2.StatusAwaitingApproval, approval2.StatusApproved, approval2.StatusRejected, approval2.StatusCancelled:
+	default:
+		return nil, fmt.Errorf("%w: unknown status %q", ErrInvalidFilter, filter.Status)
+	}
+
+	// Requests are stored under canonical networks and addresses
+	if filter.WalletAddress != "" {
+		_, filter.WalletAddress = wallet.Canonical(filter.Network, filter.WalletAddress)
+	}
+	filter.Network = wallet.CanonicalNetwork(filter.Network)
+	return s.repository.List(filter)
+}
+
+func (s *service) Get(id int) (*approval2.Request, error) {
+	return s.repository.Get(id)
+}
+
+// Approve records an approval. Funds leave a withdrawal's wallet as soon as the last approval is
+// recorded, so the wallet must still allow debits.
+func (s *service) Approve(id int, approver, comment string) (*approval2.Request, error) {
+	approver = approval2.Identity(approver)
+	if approver == "" {
+		return nil, fmt.Errorf("%w: approver is required", ErrInvalidDecision)
+	}
+
+	request, err := s.repository.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if request.Operation == approval2.OperationWithdraw {
+		if err := s.walletValidator.CanDebit(request.WalletAddress, request.Network); err != nil {
+			return nil, fmt.Errorf("wallet validation failed: %w", err)
+		}
+	}
+
+	return s.repository.Approve(id, approver, comment, s.requiredApprovals)
+}
+
+func (s *service) Reject(id int, approver, reason string) (*approval2.Request, error) {
+	approver = approval2.Identity(approver)
+	if approver == "" {
+		return nil, fmt.Errorf("%w: approver is required", ErrInvalidDecision)
+	}
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, fmt.Errorf("%w: reason is required", ErrInvalidDecision)
+	}
+
+	return s.repository.Reject(id, approver, reason)
+}
@@ -0,0 +1,138 @@
+package approval_test
+
+import (
+	approval2 "asset-management/internal/approval"
+	"asset-management/services/asset-api/approval"
+	"asset-management/services/asset-api/wallet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+)
+
+type mockRepository struct{ mock.Mock }
+
+func (m *mockRepository) List(filter approval.ListFilter) ([]approval2.Request, error) {
+	args := m.Called(filter)
+	if requests, ok := args.Get(0).([]approval2.Request); ok {
+		return requests, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockRepository) Get(id int) (*approval2.Request, error) {
+	args := m.Called(id)
+	if request, ok := args.Get(0).(*approval2.Request); ok {
+		return request, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockRepository) Approve(id int, approver, comment string, required int) (*approval2.Request, error) {
+	args := m.Called(id, approver, comment, required)
+	if request, ok := args.Get(0).(*approval2.Request); ok {
+		return request, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockRepository) Reject(id int, approver, reason string) (*approval2.Request, error) {
+	args := m.Called(id, approver, reason)
+	if request, ok := args.Get(0).(*approval2.Request); ok {
+		return request, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+type mockValidationAdapter struct{ mock.Mock }
+
+func (m *mockValidationAdapter) One(walletAddress, network string) error {
+	args := m.Called(walletAddress, network)
+	return args.Error(0)
+}
+
+func (m *mockValidationAdapter) CanDebit(walletAddress, network string) error {
+	args := m.Called(walletAddress, network)
+	return args.Error(0)
+}
+
+func (m *mockValidationAdapter) CanCredit(walletAddress, network string) error {
+	args := m.Called(walletAddress, network)
+	return args.Error(0)
+}
+
+func (m *mockValidationAdapter) Both(from, to, network string) error {
+	args := m.Called(from, to, network)
+	return args.Error(0)
+}
+
+var awaiting = &approval2.Request{
+	ID:            12,
+	Operation:     approval2.OperationWithdraw,
+	WalletAddress: "0x123",
+	Network:       "Ethereum",
+	Status:        approval2.StatusAwaitingApproval,
+}
+
+func TestApprovalService_Approve(t *testing.T) {
+	repo := new(mockRepository)
+	validator := new(mockValidationAdapter)
+	repo.On("Get", 12).Return(awaiting, nil)
+	validator.On("CanDebit", "0x123", "Ethereum").Return(nil)
+	repo.On("Approve", 12, "bob", "checked", 2).Return(&approval2.Request{ID: 12, Status: approval2.StatusApproved}, nil)
+
+	request, err := approval.NewService(repo, validator, 2).Approve(12, " Bob ", "checked")
+
+	assert.NoError(t, err)
+	assert.Equal(t, approval2.StatusApproved, request.Status)
+	repo.AssertExpectations(t)
+	validator.AssertExpectations(t)
+}
+
+func TestApprovalService_Approve_FrozenWallet(t *testing.T) {
+	repo := new(mockRepository)
+	validator := new(mockValidationAdapter)
+	repo.On("Get", 12).Return(awaiting, nil)
+	validator.On("CanDebit", "0x123", "Ethereum").Return(wallet.ErrWalletFrozen)
+
+	_, err := approval.NewService(repo, validator, 1).Approve(12, "bob", "")
+
+	assert.ErrorIs(t, err, wallet.ErrWalletFrozen)
+	repo.AssertNotCalled(t, "Approve", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestApprovalService_Approve_ScheduledTransfer(t *testing.T) {
+	repo := new(mockRepository)
+	validator := new(mockValidationAdapter)
+	scheduledID := 7
+	repo.On("Get", 13).Return(&approval2.Request{ID: 13, Operation: approval2.OperationScheduledTransfer, ScheduledTransactionID: &scheduledID}, nil)
+	repo.On("Approve", 13, "bob", "", 1).Return(&approval2.Request{ID: 13}, nil)
+
+	// The consumer checks the wallets when the transfer is processed
+	_, err := approval.NewService(repo, validator, 1).Approve(13, "bob", "")
+
+	assert.NoError(t, err)
+	validator.AssertNotCalled(t, "CanDebit", mock.Anything, mock.Anything)
+}
+
+func TestApprovalService_InvalidDecision(t *testing.T) {
+	repo := new(mockRepository)
+	service := approval.NewService(repo, new(mockValidationAdapter), 1)
+
+	_, err := service.Approve(12, " ", "")
+	assert.EqualError(t, err, "invalid decision: approver is required")
+
+	_, err = service.Reject(12, "bob", "")
+	assert.EqualError(t, err, "invalid decision: reason is required")
+	assert.ErrorIs(t, err, approval.ErrInvalidDecision)
+
+	repo.AssertNotCalled(t, "Reject", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestApprovalService_List_InvalidStatus(t *testing.T) {
+	repo := new(mockRepository)
+
+	_, err := approval.NewService(repo, new(mockValidationAdapter), 1).List(approval.ListFilter{Status: "PENDING"})
+
+	assert.ErrorIs(t, err, approval.ErrInvalidFilter)
+	repo.AssertNotCalled(t, "List", mock.Anything)
+}
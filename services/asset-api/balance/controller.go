@@ -0,0 +1,80 @@
+package balance
+
+import (
+	"asset-management/services/asset-api/dto"
+	"errors"
+	"github.com/gofiber/fiber/v2"
+)
+
+type Controller interface {
+	GetBalance(ctx *fiber.Ctx) error
+	Query(ctx *fiber.Ctx) error
+}
+
+type QueryRequest struct {
+	Wallets []WalletRef `json:"wallets"`
+}
+
+type QueryResponse struct {
+	Balances []WalletBalance `json:"balances"`
+}
+
+type controller struct {
+	service Service
+}
+
+func NewController(service Service) Controller {
+	return &controller{service: service}
+}
+
+// GetBalance godoc
+// @Summary      Get wallet balance
+// @Description  Returns the balance of every asset held by a wallet and its pending scheduled outflows
+// @Tags         balance
+// @Produce      json
+// @Param        network path string true "Wallet network"
+// @Param        address path string true "Wallet address"
+// @Success      200  {object}  WalletBalance
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /balance/{network}/{address} [get]
+func (c *controller) GetBalance(ctx *fiber.Ctx) error {
+	balance, err := c.service.GetBalance(ctx.Params("address"), ctx.Params("network"))
+	if err != nil {
+		return ctx.Status(errorStatus(err)).JSON(dto.ErrorResponse{Message: err.Error()})
+	}
+
+	return ctx.JSON(balance)
+}
+
+// Query godoc
+// @Summary      Query balances of several wallets
+// @Description  Returns balances and pending scheduled outflows for a batch of wallets
+// @Tags         balance
+// @Accept       json
+// @Produce      json
+// @Param        queryRequest body QueryRequest true "Wallets to query"
+// @Success      200  {object}  QueryResponse
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /balance/query [post]
+func (c *controller) Query(ctx *fiber.Ctx) error {
+	var req QueryRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Message: "Invalid request payload"})
+	}
+
+	balances, err := c.service.Query(req.Wallets)
+	if err != nil {
+		return ctx.Status(errorStatus(err)).JSON(dto.ErrorResponse{Message: err.Error()})
+	}
+
+	return ctx.JSON(QueryResponse{Balances: balances})
+}
+
+func errorStatus(err error) int {
+	if errors.Is(err, ErrInvalidQuery) {
+		return fiber.StatusBadRequest
+	}
+	return fiber.StatusInternalServerError
+}
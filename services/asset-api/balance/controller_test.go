@@ -0,0 +1,121 @@
+package balance_test
+
+import (
+	"asset-management/services/asset-api/balance"
+	"asset-management/services/asset-api/dto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type mockService struct{ mock.Mock }
+
+func (m *mockService) GetBalance(walletAddress, network string) (*balance.WalletBalance, error) {
+	args := m.Called(walletAddress, network)
+	if b, ok := args.Get(0).(*balance.WalletBalance); ok {
+		return b, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockService) Query(wallets []balance.WalletRef) ([]balance.WalletBalance, error) {
+	args := m.Called(wallets)
+	if b, ok := args.Get(0).([]balance.WalletBalance); ok {
+		return b, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func setupApp(service balance.Service) *fiber.App {
+	controller := balance.NewController(service)
+	app := fiber.New()
+	app.Get("/balance/:network/:address", controller.GetBalance)
+	app.Post("/balance/query", controller.Query)
+	return app
+}
+
+func TestBalanceController_GetBalance_Success(t *testing.T) {
+	service := new(mockService)
+	service.On("GetBalance", "0x123", "Ethereum").Return(&balance.WalletBalance{
+		WalletAddress: "0x123",
+		Network:       "Ethereum",
+		Assets: []balance.AssetBalance{
+			{Asset: "ETH", Balance: decimal.RequireFromString("10.5"), PendingOutflow: decimal.RequireFromString("2")},
+		},
+	}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/balance/Ethereum/0x123", nil)
+	resp, err := setupApp(service).Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var response balance.WalletBalance
+	_ = json.NewDecoder(resp.Body).Decode(&response)
+	assert.Len(t, response.Assets, 1)
+	assert.Equal(t, "10.5", response.Assets[0].Balance.String())
+	assert.Equal(t, "2", response.Assets[0].PendingOutflow.String())
+}
+
+func TestBalanceController_GetBalance_Error(t *testing.T) {
+	service := new(mockService)
+	service.On("GetBalance", "0x123", "Ethereum").Return(nil, errors.New("failed to retrieve balance"))
+
+	req := httptest.NewRequest(http.MethodGet, "/balance/Ethereum/0x123", nil)
+	resp, err := setupApp(service).Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+
+	var response dto.ErrorResponse
+	_ = json.NewDecoder(resp.Body).Decode(&response)
+	assert.Equal(t, "failed to retrieve balance", response.Message)
+}
+
+func TestBalanceController_Query_Invalid(t *testing.T) {
+	service := new(mockService)
+	service.On("Query", []balance.WalletRef{}).Return(nil, fmt.Errorf("%w: at least one wallet is required", balance.ErrInvalidQuery))
+
+	req := httptest.NewRequest(http.MethodPost, "/balance/query", strings.NewReader(`{"wallets":[]}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := setupApp(service).Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestBalanceController_Query_Success(t *testing.T) {
+	service := new(mockService)
+	wallets := []balance.WalletRef{{WalletAddress: "0x123", Network: "Ethereum"}, {WalletAddress: "0x456", Network: "Ethereum"}}
+	service.On("Query", wallets).Return([]balance.WalletBalance{
+		{WalletAddress: "0x123", Network: "Ethereum", Assets: []balance.AssetBalance{}},
+		{WalletAddress: "0x456", Network: "Ethereum", Assets: []balance.AssetBalance{}},
+	}, nil)
+
+	body := `{"wallets":[{"wallet_address":"0x123","network":"Ethereum"},{"wallet_address":"0x456","network":"Ethereum"}]}`
+	req := httptest.NewRequest(http.MethodPost, "/balance/query", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := setupApp(service).Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var response balance.QueryResponse
+	_ = json.NewDecoder(resp.Body).Decode(&response)
+	assert.Len(t, response.Balances, 2)
+	service.AssertExpectations(t)
+}
+
+func TestBalanceController_Query_InvalidPayload(t *testing.T) {
+	service := new(mockService)
+
+	req := httptest.NewRequest(http.MethodPost, "/balance/query", strings.NewReader("invalid json"))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := setupApp(service).Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
@@ -0,0 +1,63 @@
+package balance
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+type Repository interface {
+	GetBalances(walletAddress, network string) ([]AssetBalance, error)
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+// GetBalances returns the balance of every asset held by a wallet together with the amount still
+// waiting to leave it, i.e. held by scheduled transactions not yet processed and withdrawals awaiting
+// approval. The pending outflow is the amount funds.Held enforces, so the two always agree.
+func (r *repository) GetBalances(walletAddress, network string) ([]AssetBalance, error) {
+	rows, err := r.db.Query(`
+        SELECT COALESCE(b.asset, p.asset), COALESCE(b.balance, 0), COALESCE(p.pending_outflow, 0)
+        FROM (
+            SELECT asset, balance
+            FROM balance
+            WHERE wallet_address = $1 AND network = $2
+        ) b
+        FULL OUTER JOIN (
+            SELECT asset, SUM(amount) AS pending_outflow
+            FROM (
+                SELECT asset, amount FROM scheduled_transactions
+                WHERE from_wallet_address = $1 AND network = $2 AND status IN ('AWAITING_APPROVAL', 'PENDING', 'PUBLISHED')
+                UNION ALL
+                SELECT asset, amount FROM approval_requests
+                WHERE wallet_address = $1 AND network = $2 AND operation = 'WITHDRAW' AND status = 'AWAITING_APPROVAL'
+            ) outflows
+            GROUP BY asset
+        ) p ON b.asset = p.asset
+        ORDER BY 1`, walletAddress, network)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query balances: %w", err)
+	}
+
+	balances := []AssetBalance{}
+	for rows.Next() {
+		var b AssetBalance
+		if err := rows.Scan(&b.Asset, &b.Balance, &b.PendingOutflow); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan balance: %w", err)
+		}
+		b.Available = b.Balance.Sub(b.PendingOutflow)
+		balances = append(balances, b)
+	}
+
+	if err := rows.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close rows: %w", err)
+	}
+
+	return balances, nil
+}
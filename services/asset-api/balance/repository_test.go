@@ -0,0 +1,60 @@
+package balance_test
+
+import (
+	"asset-management/services/asset-api/balance"
+	"asset-management/services/asset-api/util"
+	_ "github.com/lib/pq"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRepository_GetBalances(t *testing.T) {
+	db, cleanup := util.SetupTestContainer(t)
+	defer cleanup()
+
+	assert.NoError(t, util.InsertBalance(db, "0x123", "Ethereum", "ETH", decimal.RequireFromString("100")))
+	assert.NoError(t, util.InsertBalance(db, "0x123", "Ethereum", "USDT", decimal.RequireFromString("5")))
+
+	// Outflows not yet processed are summed per asset, completed ones are ignored
+	for _, row := range []struct {
+		asset, amount, status string
+	}{
+		{"ETH", "10", "PENDING"},
+		{"ETH", "15", "PENDING"},
+		{"ETH", "5", "PUBLISHED"},
+		{"ETH", "20", "AWAITING_APPROVAL"},
+		{"ETH", "99", "COMPLETED"},
+		{"DAI", "3", "PENDING"},
+	} {
+		_, err := db.Exec(`
+			INSERT INTO scheduled_transactions (from_wallet_address, to_wallet_address, network, asset, amount, scheduled_time, status)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+			"0x123", "0x456", "Ethereum", row.asset, row.amount, time.Now().Add(time.Hour), row.status)
+		assert.NoError(t, err)
+	}
+
+	// Withdrawals awaiting approval are held too
+	_, err := db.Exec(`
+		INSERT INTO approval_requests (operation, wallet_address, network, asset, amount, rule_id, requested_by)
+		VALUES ('WITHDRAW', '0x123', 'Ethereum', 'ETH', 7, 1, 'alice')`)
+	assert.NoError(t, err)
+
+	balances, err := balance.NewRepository(db).GetBalances("0x123", "Ethereum")
+	assert.NoError(t, err)
+	assert.Len(t, balances, 3)
+
+	assert.Equal(t, "DAI", balances[0].Asset)
+	assert.True(t, balances[0].Balance.IsZero())
+	assert.Equal(t, "3", balances[0].PendingOutflow.String())
+
+	assert.Equal(t, "ETH", balances[1].Asset)
+	assert.Equal(t, "100", balances[1].Balance.String())
+	assert.Equal(t, "57", balances[1].PendingOutflow.String())
+	assert.Equal(t, "43", balances[1].Available.String())
+
+	assert.Equal(t, "USDT", balances[2].Asset)
+	assert.True(t, balances[2].PendingOutflow.IsZero())
+	assert.Equal(t, "5", balances[2].Available.String())
+}
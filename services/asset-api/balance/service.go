@@ -0,0 +1,79 @@
+package balance
+
+import (
+	"asset-management/services/asset-api/wallet"
+	"errors"
+	"fmt"
+	"github.com/shopspring/decimal"
+)
+
+// MaxBatchSize limits the number of wallets accepted by a single batch query.
+const MaxBatchSize = 100
+
+// ErrInvalidQuery wraps every validation error of a balance query.
+var ErrInvalidQuery = errors.New("invalid balance query")
+
+type AssetBalance struct {
+	Asset          string          `json:"asset" example:"ETH"`                                   // Asset symbol
+	Balance        decimal.Decimal `json:"balance" swaggertype:"string" example:"1500.75"`        // Current balance
+	PendingOutflow decimal.Decimal `json:"pending_outflow" swaggertype:"string" example:"250.00"` // Held by scheduled transfers not yet processed and withdrawals awaiting approval
+	Available      decimal.Decimal `json:"available" swaggertype:"string" example:"1250.75"`      // Balance less the pending outflow, which withdrawals, transfers and new schedules may use
+}
+
+type WalletBalance struct {
+	WalletAddress string         `json:"wallet_address" example:"0x123abc456def"`
+	Network       string         `json:"network" example:"Ethereum"`
+	Assets        []AssetBalance `json:"assets"`
+}
+
+type WalletRef struct {
+	WalletAddress string `json:"wallet_address" example:"0x123abc456def"`
+	Network       string `json:"network" example:"Ethereum"`
+}
+
+type Service interface {
+	GetBalance(walletAddress, network string) (*WalletBalance, error)
+	Query(wallets []WalletRef) ([]WalletBalance, error)
+}
+
+type service struct {
+	repository Repository
+}
+
+func NewService(repository Repository) Service {
+	return &service{repository: repository}
+}
+
+func (s *service) GetBalance(walletAddress, network string) (*WalletBalance, error) {
+	if walletAddress == "" || network == "" {
+		return nil, fmt.Errorf("%w: wallet address and network are required", ErrInvalidQuery)
+	}
+	network, walletAddress = wallet.Canonical(network, walletAddress)
+
+	assets, err := s.repository.GetBalances(walletAddress, network)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve balance: %w", err)
+	}
+
+	return &WalletBalance{WalletAddress: walletAddress, Network: network, Assets: assets}, nil
+}
+
+func (s *service) Query(wallets []WalletRef) ([]WalletBalance, error) {
+	if len(wallets) == 0 {
+		return nil, fmt.Errorf("%w: at least one wallet is required", ErrInvalidQuery)
+	}
+	if len(wallets) > MaxBatchSize {
+		return nil, fmt.Errorf("%w: at most %d wallets can be queried at once", ErrInvalidQuery, MaxBatchSize)
+	}
+
+	balances := make([]WalletBalance, 0, len(wallets))
+	for _, w := range wallets {
+		b, err := s.GetBalance(w.WalletAddress, w.Network)
+		if err != nil {
+			return nil, err
+		}
+		balances = append(balances, *b)
+	}
+
+	return balances, nil
+}
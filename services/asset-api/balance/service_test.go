@@ -0,0 +1,78 @@
+package balance_test
+
+import (
+	"asset-management/services/asset-api/balance"
+	"errors"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+)
+
+type mockRepository struct{ mock.Mock }
+
+func (m *mockRepository) GetBalances(walletAddress, network string) ([]balance.AssetBalance, error) {
+	args := m.Called(walletAddress, network)
+	if b, ok := args.Get(0).([]balance.AssetBalance); ok {
+		return b, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func TestBalanceService_GetBalance_Success(t *testing.T) {
+	repo := new(mockRepository)
+	assets := []balance.AssetBalance{{Asset: "ETH", Balance: decimal.RequireFromString("1"), PendingOutflow: decimal.Zero}}
+	repo.On("GetBalances", "0x123", "Ethereum").Return(assets, nil)
+
+	result, err := balance.NewService(repo).GetBalance("0x123", "Ethereum")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "0x123", result.WalletAddress)
+	assert.Equal(t, assets, result.Assets)
+}
+
+func TestBalanceService_GetBalance_InvalidInput(t *testing.T) {
+	repo := new(mockRepository)
+
+	result, err := balance.NewService(repo).GetBalance("", "Ethereum")
+
+	assert.ErrorIs(t, err, balance.ErrInvalidQuery)
+	assert.Nil(t, result)
+	repo.AssertNotCalled(t, "GetBalances")
+}
+
+func TestBalanceService_Query_Success(t *testing.T) {
+	repo := new(mockRepository)
+	repo.On("GetBalances", "0x123", "Ethereum").Return([]balance.AssetBalance{}, nil)
+	repo.On("GetBalances", "0x456", "Bitcoin").Return([]balance.AssetBalance{}, nil)
+
+	result, err := balance.NewService(repo).Query([]balance.WalletRef{
+		{WalletAddress: "0x123", Network: "Ethereum"},
+		{WalletAddress: "0x456", Network: "Bitcoin"},
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, result, 2)
+	assert.Equal(t, "Bitcoin", result[1].Network)
+	repo.AssertExpectations(t)
+}
+
+func TestBalanceService_Query_Empty(t *testing.T) {
+	repo := new(mockRepository)
+
+	result, err := balance.NewService(repo).Query(nil)
+
+	assert.ErrorIs(t, err, balance.ErrInvalidQuery)
+	assert.Nil(t, result)
+}
+
+func TestBalanceService_Query_RepositoryError(t *testing.T) {
+	repo := new(mockRepository)
+	repo.On("GetBalances", "0x123", "Ethereum").Return(nil, errors.New("db down"))
+
+	result, err := balance.NewService(repo).Query([]balance.WalletRef{{WalletAddress: "0x123", Network: "Ethereum"}})
+
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, balance.ErrInvalidQuery)
+	assert.Nil(t, result)
+}
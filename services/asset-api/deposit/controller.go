@@ -2,7 +2,10 @@ package deposit
 
 import (
 	"asset-management/services/asset-api/dto"
+	"asset-management/services/asset-api/wallet"
+	"errors"
 	"github.com/gofiber/fiber/v2"
+	"github.com/shopspring/decimal"
 )
 
 type Controller interface {
@@ -10,13 +13,14 @@ type Controller interface {
 }
 
 type Request struct {
-	WalletAddress string  `json:"wallet_address" example:"0x123abc456def"`
-	Network       string  `json:"network" example:"Ethereum"`
-	Amount        float64 `json:"amount" example:"100.50"`
+	WalletAddress string          `json:"wallet_address" example:"0x123abc456def"`
+	Network       string          `json:"network" example:"Ethereum"`
+	Asset         string          `json:"asset" example:"ETH"`
+	Amount        decimal.Decimal `json:"amount" swaggertype:"string" example:"100.50"`
 }
 
 type Response struct {
-	NewBalance float64 `json:"new_balance" example:"1500.75"`
+	NewBalance decimal.Decimal `json:"new_balance" swaggertype:"string" example:"1500.75"`
 }
 
 type controller struct {
@@ -34,8 +38,10 @@ func NewController(service Service) Controller {
 // @Accept       json
 // @Produce      json
 // @Param        depositRequest body Request true "Deposit request payload"
+// @Param        Idempotency-Key header string false "Key that makes retries of this request safe"
 // @Success      200  {object}  Response
 // @Failure      400  {object}  dto.ErrorResponse
+// @Failure      409  {object}  dto.ErrorResponse "Wallet is being deleted, or Idempotency-Key reused with a different payload or still in progress"
 // @Router       /deposit [post]
 func (c *controller) Deposit(ctx *fiber.Ctx) error {
 	var req Request
@@ -43,8 +49,11 @@ func (c *controller) Deposit(ctx *fiber.Ctx) error {
 		return ctx.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Message: "Invalid request payload"})
 	}
 
-	newBalance, err := c.service.Deposit(req.WalletAddress, req.Network, req.Amount)
+	newBalance, err := c.service.Deposit(req.WalletAddress, req.Network, req.Asset, req.Amount)
 	if err != nil {
+		if errors.Is(err, wallet.ErrWalletDeleting) {
+			return ctx.Status(fiber.StatusConflict).JSON(dto.ErrorResponse{Message: err.Error()})
+		}
 		return ctx.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Message: err.Error()})
 	}
 
@@ -4,8 +4,10 @@ package deposit_test
 
 import (
 	"asset-management/services/asset-api/deposit"
+	"encoding/json"
 	"errors"
 	"github.com/gofiber/fiber/v2"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"net/http"
@@ -17,27 +19,30 @@ import (
 // Mock service
 type mockService struct{ mock.Mock }
 
-func (m *mockService) Deposit(walletAddress, network string, amount float64) (float64, error) {
-	args := m.Called(walletAddress, network, amount)
-	return args.Get(0).(float64), args.Error(1)
+func (m *mockService) Deposit(walletAddress, network, asset string, amount decimal.Decimal) (decimal.Decimal, error) {
+	args := m.Called(walletAddress, network, asset, amount)
+	return args.Get(0).(decimal.Decimal), args.Error(1)
 }
 
 func TestDepositController_Success(t *testing.T) {
 	service := new(mockService)
-	service.On("Deposit", "0x123abc456def", "Ethereum", 100.50).Return(1500.75, nil)
+	service.On("Deposit", "0x123abc456def", "Ethereum", "ETH", decimal.RequireFromString("100.50")).Return(decimal.RequireFromString("1500.75"), nil)
 
 	controller := deposit.NewController(service)
 
 	app := fiber.New()
 	app.Post("/deposit", controller.Deposit)
 
-	req := httptest.NewRequest(http.MethodPost, "/deposit", strings.NewReader(`{"wallet_address":"0x123abc456def","network":"Ethereum","amount":100.50}`))
+	req := httptest.NewRequest(http.MethodPost, "/deposit", strings.NewReader(`{"wallet_address":"0x123abc456def","network":"Ethereum","asset":"ETH","amount":"100.50"}`))
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, _ := app.Test(req, -1)
 
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
 
+	var body map[string]interface{}
+	_ = json.NewDecoder(resp.Body).Decode(&body)
+	assert.Equal(t, "1500.75", body["new_balance"])
 }
 
 func TestDepositController_InvalidPayload(t *testing.T) {
@@ -56,14 +61,14 @@ func TestDepositController_InvalidPayload(t *testing.T) {
 
 func TestDepositController_ServiceError(t *testing.T) {
 	service := new(mockService)
-	service.On("Deposit", "0x123abc456def", "Ethereum", 100.50).Return(0.0, errors.New("deposit error"))
+	service.On("Deposit", "0x123abc456def", "Ethereum", "ETH", decimal.RequireFromString("100.50")).Return(decimal.Zero, errors.New("deposit error"))
 
 	controller := deposit.NewController(service)
 
 	app := fiber.New()
 	app.Post("/deposit", controller.Deposit)
 
-	req := httptest.NewRequest(http.MethodPost, "/deposit", strings.NewReader(`{"wallet_address":"0x123abc456def","network":"Ethereum","amount":100.50}`))
+	req := httptest.NewRequest(http.MethodPost, "/deposit", strings.NewReader(`{"wallet_address":"0x123abc456def","network":"Ethereum","asset":"ETH","amount":"100.50"}`))
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, _ := app.Test(req, -1)
@@ -1,12 +1,18 @@
 package deposit
 
 import (
+	"asset-management/internal/ledger"
+	"asset-management/internal/outbox"
+	"asset-management/pkg/event"
+	"context"
 	"database/sql"
 	"fmt"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
 type Repository interface {
-	Deposit(walletAddress, network string, amount float64) (float64, error)
+	Deposit(walletAddress, network, asset string, amount decimal.Decimal) (decimal.Decimal, error)
 }
 
 type repository struct {
@@ -17,14 +23,16 @@ func NewRepository(db *sql.DB) Repository {
 	return &repository{db: db}
 }
 
-func (r *repository) Deposit(walletAddress, network string, amount float64) (float64, error) {
-	if amount <= 0 {
-		return 0, fmt.Errorf("deposit amount must be positive")
+func (r *repository) Deposit(walletAddress, network, asset string, amount decimal.Decimal) (decimal.Decimal, error) {
+	if !amount.IsPositive() {
+		return decimal.Zero, fmt.Errorf("deposit amount must be positive")
 	}
+	ctx := context.Background()
+
 	// Start a new transaction
-	tx, err := r.db.Begin()
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		return 0, fmt.Errorf("failed to start transaction: %w", err)
+		return decimal.Zero, fmt.Errorf("failed to start transaction: %w", err)
 	}
 	defer func() {
 		if err != nil {
@@ -36,17 +44,45 @@ func (r *repository) Deposit(walletAddress, network string, amount float64) (flo
 
 	// Use UPSERT to insert or update the balance atomically
 	upsertQuery := `
-		INSERT INTO balance (wallet_address, network, balance)
-		VALUES ($1, $2, $3)
-		ON CONFLICT (wallet_address, network) 
+		INSERT INTO balance (wallet_address, network, asset, balance)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (wallet_address, network, asset) 
 		DO UPDATE SET balance = balance.balance + EXCLUDED.balance
 		RETURNING balance;
 	`
 
-	var newBalance float64
-	err = tx.QueryRow(upsertQuery, walletAddress, network, amount).Scan(&newBalance)
+	var newBalance decimal.Decimal
+	err = tx.QueryRowContext(ctx, upsertQuery, walletAddress, network, asset, amount).Scan(&newBalance)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("failed to upsert balance: %w", err)
+	}
+
+	// Record the movement in the ledger within the same transaction
+	reference := "deposit:" + uuid.NewString()
+	err = ledger.Record(ctx, tx, ledger.Movement{
+		Operation: ledger.OperationDeposit,
+		Reference: reference,
+		Network:   network,
+		Asset:     asset,
+		Amount:    amount,
+		From:      ledger.ExternalAccount,
+		To:        walletAddress,
+		ToBalance: ledger.Balance(newBalance),
+	})
+	if err != nil {
+		return decimal.Zero, err
+	}
+
+	err = outbox.Write(ctx, tx, event.TypeDepositCompleted, event.DepositCompleted{
+		Reference:     reference,
+		WalletAddress: walletAddress,
+		Network:       network,
+		Asset:         asset,
+		Amount:        amount,
+		Balance:       newBalance,
+	}, walletAddress)
 	if err != nil {
-		return 0, fmt.Errorf("failed to upsert balance: %w", err)
+		return decimal.Zero, err
 	}
 
 	return newBalance, nil
@@ -5,6 +5,7 @@ import (
 	"asset-management/services/asset-api/util"
 	"fmt"
 	_ "github.com/lib/pq"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
@@ -19,39 +20,71 @@ func TestRepository_Deposit(t *testing.T) {
 		name          string
 		walletAddress string
 		network       string
-		amount        float64
+		asset         string
+		amount        decimal.Decimal
 		expectedErr   error
-		expectedBal   float64
+		expectedBal   decimal.Decimal
 	}{
 		{
 			name:          "Initial deposit",
 			walletAddress: "wallet_1",
 			network:       "network_1",
-			amount:        100.0,
+			asset:         "ETH",
+			amount:        decimal.RequireFromString("100.0"),
 			expectedErr:   nil,
-			expectedBal:   100.0,
+			expectedBal:   decimal.RequireFromString("100.0"),
 		},
 		{
 			name:          "Deposit to existing balance",
 			walletAddress: "wallet_1",
 			network:       "network_1",
-			amount:        50.0,
+			asset:         "ETH",
+			amount:        decimal.RequireFromString("50.0"),
 			expectedErr:   nil,
-			expectedBal:   150.0, // 100 + 50
+			expectedBal:   decimal.RequireFromString("150.0"), // 100 + 50
 		},
 		{
 			name:          "New deposit different wallet",
 			walletAddress: "wallet_2",
 			network:       "network_1",
-			amount:        200.0,
+			asset:         "ETH",
+			amount:        decimal.RequireFromString("200.0"),
 			expectedErr:   nil,
-			expectedBal:   200.0,
+			expectedBal:   decimal.RequireFromString("200.0"),
+		},
+		{
+			name:          "Separate balance for another asset",
+			walletAddress: "wallet_1",
+			network:       "network_1",
+			asset:         "USDT",
+			amount:        decimal.RequireFromString("25.0"),
+			expectedErr:   nil,
+			expectedBal:   decimal.RequireFromString("25.0"),
+		},
+		{
+			name:          "Fractional deposit",
+			walletAddress: "wallet_3",
+			network:       "network_1",
+			asset:         "ETH",
+			amount:        decimal.RequireFromString("0.1"),
+			expectedErr:   nil,
+			expectedBal:   decimal.RequireFromString("0.1"),
+		},
+		{
+			name:          "Fractional deposits add up exactly",
+			walletAddress: "wallet_3",
+			network:       "network_1",
+			asset:         "ETH",
+			amount:        decimal.RequireFromString("0.2"),
+			expectedErr:   nil,
+			expectedBal:   decimal.RequireFromString("0.3"),
 		},
 		{
 			name:          "Invalid deposit amount (negative)",
 			walletAddress: "wallet_1",
 			network:       "network_1",
-			amount:        -100.0,
+			asset:         "ETH",
+			amount:        decimal.RequireFromString("-100.0"),
 			expectedErr:   fmt.Errorf("deposit amount must be positive"),
 		},
 	}
@@ -59,14 +92,32 @@ func TestRepository_Deposit(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 
-			balance, err := repo.Deposit(tt.walletAddress, tt.network, tt.amount)
+			balance, err := repo.Deposit(tt.walletAddress, tt.network, tt.asset, tt.amount)
 			if tt.expectedErr != nil {
 				assert.Error(t, err)
 				assert.Contains(t, err.Error(), tt.expectedErr.Error())
 			} else {
 				assert.NoError(t, err)
-				assert.Equal(t, tt.expectedBal, balance)
+				assert.True(t, tt.expectedBal.Equal(balance), "expected %s, got %s", tt.expectedBal, balance)
 			}
 		})
 	}
 }
+
+func TestRepository_Deposit_WritesEvent(t *testing.T) {
+	db, cleanup := util.SetupTestContainer(t)
+	defer cleanup()
+
+	repo := deposit.NewRepository(db)
+
+	_, err := repo.Deposit("wallet_1", "network_1", "ETH", decimal.RequireFromString("100.0"))
+	assert.NoError(t, err)
+
+	// The event is written to the outbox in the same transaction as the balance
+	var eventType, key, balance string
+	err = db.QueryRow(`SELECT event_type, message_key, envelope->'payload'->>'balance' FROM event_outbox`).Scan(&eventType, &key, &balance)
+	assert.NoError(t, err)
+	assert.Equal(t, "deposit.completed", eventType)
+	assert.Equal(t, "wallet_1", key)
+	assert.Equal(t, "100", balance)
+}
@@ -4,6 +4,7 @@ import (
 	"asset-management/services/asset-api/wallet"
 	"errors"
 	"fmt"
+	"github.com/shopspring/decimal"
 )
 
 type service struct {
@@ -12,27 +13,28 @@ type service struct {
 }
 
 type Service interface {
-	Deposit(walletAddress, network string, amount float64) (float64, error)
+	Deposit(walletAddress, network, asset string, amount decimal.Decimal) (decimal.Decimal, error)
 }
 
 func NewService(adapter wallet.ValidationAdapter, depositRepository Repository) Service {
 	return &service{validationAdapter: adapter, depositRepository: depositRepository}
 }
-func (s *service) Deposit(walletAddress, network string, amount float64) (float64, error) {
+func (s *service) Deposit(walletAddress, network, asset string, amount decimal.Decimal) (decimal.Decimal, error) {
 	// Validate input
-	if walletAddress == "" || network == "" || amount <= 0 {
-		return 0, errors.New("invalid input parameters")
+	if walletAddress == "" || network == "" || asset == "" || !amount.IsPositive() {
+		return decimal.Zero, errors.New("invalid input parameters")
 	}
+	network, walletAddress = wallet.Canonical(network, walletAddress)
 
-	err := s.validationAdapter.One(walletAddress, network)
+	err := s.validationAdapter.CanCredit(walletAddress, network)
 	if err != nil {
-		return 0, fmt.Errorf("wallet validation failed: %w", err)
+		return decimal.Zero, fmt.Errorf("wallet validation failed: %w", err)
 	}
 
 	// Perform the deposit transaction
-	newBalance, err := s.depositRepository.Deposit(walletAddress, network, amount)
+	newBalance, err := s.depositRepository.Deposit(walletAddress, network, asset, amount)
 	if err != nil {
-		return 0, fmt.Errorf("deposit transaction failed: %w", err)
+		return decimal.Zero, fmt.Errorf("deposit transaction failed: %w", err)
 	}
 
 	return newBalance, nil
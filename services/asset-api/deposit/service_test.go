@@ -5,6 +5,7 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -18,9 +19,9 @@ func (m *mockValidationAdapter) Both(from, to, network string) error {
 	return args.Error(0)
 }
 
-func (m *mockRepository) Deposit(walletAddress, network string, amount float64) (float64, error) {
-	args := m.Called(walletAddress, network, amount)
-	return args.Get(0).(float64), args.Error(1)
+func (m *mockRepository) Deposit(walletAddress, network, asset string, amount decimal.Decimal) (decimal.Decimal, error) {
+	args := m.Called(walletAddress, network, asset, amount)
+	return args.Get(0).(decimal.Decimal), args.Error(1)
 }
 
 func (m *mockValidationAdapter) One(walletAddress, network string) error {
@@ -28,18 +29,43 @@ func (m *mockValidationAdapter) One(walletAddress, network string) error {
 	return args.Error(0)
 }
 
+func (m *mockValidationAdapter) CanDebit(walletAddress, network string) error {
+	args := m.Called(walletAddress, network)
+	return args.Error(0)
+}
+
+func (m *mockValidationAdapter) CanCredit(walletAddress, network string) error {
+	args := m.Called(walletAddress, network)
+	return args.Error(0)
+}
+
 func TestDepositService_ValidDeposit(t *testing.T) {
 	adapter := new(mockValidationAdapter)
 	repo := new(mockRepository)
 
-	adapter.On("One", "0x123abc456def", "Ethereum").Return(nil)
-	repo.On("Deposit", "0x123abc456def", "Ethereum", 100.50).Return(1500.75, nil)
+	adapter.On("CanCredit", "0x123abc456def", "Ethereum").Return(nil)
+	repo.On("Deposit", "0x123abc456def", "Ethereum", "ETH", decimal.RequireFromString("100.50")).Return(decimal.RequireFromString("1500.75"), nil)
+
+	service := deposit.NewService(adapter, repo)
+	newBalance, err := service.Deposit("0x123abc456def", "Ethereum", "ETH", decimal.RequireFromString("100.50"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "1500.75", newBalance.String())
+}
+
+func TestDepositService_CanonicalWallet(t *testing.T) {
+	adapter := new(mockValidationAdapter)
+	repo := new(mockRepository)
+
+	// Funds are keyed by the network and address wallet-api stores, however the caller spells them
+	adapter.On("CanCredit", "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", "Ethereum").Return(nil)
+	repo.On("Deposit", "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", "Ethereum", "ETH", decimal.RequireFromString("1")).Return(decimal.RequireFromString("1"), nil)
 
 	service := deposit.NewService(adapter, repo)
-	newBalance, err := service.Deposit("0x123abc456def", "Ethereum", 100.50)
+	_, err := service.Deposit("0x5AAEB6053F3E94C9B9A09F33669435E7EF1BEAED", "eth", "ETH", decimal.RequireFromString("1"))
 
 	assert.NoError(t, err)
-	assert.Equal(t, 1500.75, newBalance)
+	repo.AssertExpectations(t)
 }
 
 func TestDepositService_InvalidInput(t *testing.T) {
@@ -47,35 +73,35 @@ func TestDepositService_InvalidInput(t *testing.T) {
 	repo := new(mockRepository)
 
 	service := deposit.NewService(adapter, repo)
-	newBalance, err := service.Deposit("", "Ethereum", 100.50)
+	newBalance, err := service.Deposit("", "Ethereum", "ETH", decimal.RequireFromString("100.50"))
 
 	assert.Error(t, err)
-	assert.Equal(t, 0.0, newBalance)
+	assert.True(t, newBalance.IsZero())
 }
 
 func TestDepositService_ValidationError(t *testing.T) {
 	adapter := new(mockValidationAdapter)
 	repo := new(mockRepository)
 
-	adapter.On("One", "0x123abc456def", "Ethereum").Return(errors.New("wallet validation failed"))
+	adapter.On("CanCredit", "0x123abc456def", "Ethereum").Return(errors.New("wallet validation failed"))
 
 	service := deposit.NewService(adapter, repo)
-	newBalance, err := service.Deposit("0x123abc456def", "Ethereum", 100.50)
+	newBalance, err := service.Deposit("0x123abc456def", "Ethereum", "ETH", decimal.RequireFromString("100.50"))
 
 	assert.Error(t, err)
-	assert.Equal(t, 0.0, newBalance)
+	assert.True(t, newBalance.IsZero())
 }
 
 func TestDepositService_RepositoryError(t *testing.T) {
 	adapter := new(mockValidationAdapter)
 	repo := new(mockRepository)
 
-	adapter.On("One", "0x123abc456def", "Ethereum").Return(nil)
-	repo.On("Deposit", "0x123abc456def", "Ethereum", 100.50).Return(0.0, errors.New("repository error"))
+	adapter.On("CanCredit", "0x123abc456def", "Ethereum").Return(nil)
+	repo.On("Deposit", "0x123abc456def", "Ethereum", "ETH", decimal.RequireFromString("100.50")).Return(decimal.Zero, errors.New("repository error"))
 
 	service := deposit.NewService(adapter, repo)
-	newBalance, err := service.Deposit("0x123abc456def", "Ethereum", 100.50)
+	newBalance, err := service.Deposit("0x123abc456def", "Ethereum", "ETH", decimal.RequireFromString("100.50"))
 
 	assert.Error(t, err)
-	assert.Equal(t, 0.0, newBalance)
+	assert.True(t, newBalance.IsZero())
 }
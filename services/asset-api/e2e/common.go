@@ -11,6 +11,16 @@ func (m *MockValidationAdapter) One(walletAddress, network string) error {
 	return args.Error(0)
 }
 
+func (m *MockValidationAdapter) CanDebit(walletAddress, network string) error {
+	args := m.Called(walletAddress, network)
+	return args.Error(0)
+}
+
+func (m *MockValidationAdapter) CanCredit(walletAddress, network string) error {
+	args := m.Called(walletAddress, network)
+	return args.Error(0)
+}
+
 func (m *MockValidationAdapter) Both(from, to, network string) error {
 	args := m.Called(from, to, network)
 	return args.Error(0)
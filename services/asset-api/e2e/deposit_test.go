@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"github.com/gofiber/fiber/v2"
 	_ "github.com/lib/pq"
+	"github.com/shopspring/decimal"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -28,7 +29,7 @@ func TestDepositEndpoint(t *testing.T) {
 	app.Post("/deposit", controller.Deposit)
 
 	// Seed database with initial balance
-	_, err := db.Exec("INSERT INTO balance (wallet_address, network, balance) VALUES ($1, $2, $3)", "0x123abc456def", "Ethereum", 1000.00)
+	_, err := db.Exec("INSERT INTO balance (wallet_address, network, asset, balance) VALUES ($1, $2, $3, $4)", "0x123abc456def", "Ethereum", "ETH", "1000.00")
 	if err != nil {
 		t.Fatalf("failed to seed database: %s", err)
 	}
@@ -50,12 +51,13 @@ func TestDepositEndpoint(t *testing.T) {
 	// 1. Successful Deposit with Valid Wallet
 	t.Run("Successful Deposit", func(t *testing.T) {
 		// Mock successful validation
-		mockValidation.On("One", "0x123abc456def", "Ethereum").Return(nil)
+		mockValidation.On("CanCredit", "0x123abc456def", "Ethereum").Return(nil)
 
 		reqBody := deposit.Request{
 			WalletAddress: "0x123abc456def",
 			Network:       "Ethereum",
-			Amount:        100.50,
+			Asset:         "ETH",
+			Amount:        decimal.RequireFromString("100.50"),
 		}
 		resp := sendRequest(t, reqBody, http.StatusOK)
 
@@ -65,23 +67,24 @@ func TestDepositEndpoint(t *testing.T) {
 			t.Fatalf("failed to decode response: %s", err)
 		}
 
-		expectedNewBalance := 1100.50
-		if response.NewBalance != expectedNewBalance {
-			t.Errorf("expected new balance %f but got %f", expectedNewBalance, response.NewBalance)
+		expectedNewBalance := decimal.RequireFromString("1100.50")
+		if !response.NewBalance.Equal(expectedNewBalance) {
+			t.Errorf("expected new balance %s but got %s", expectedNewBalance, response.NewBalance)
 		}
 
-		mockValidation.AssertCalled(t, "One", "0x123abc456def", "Ethereum")
+		mockValidation.AssertCalled(t, "CanCredit", "0x123abc456def", "Ethereum")
 	})
 
 	// 2. Wallet Validation Failure
 	t.Run("Wallet Validation Failure", func(t *testing.T) {
 		// Mock validation failure
-		mockValidation.On("One", "0xinvalidwallet", "Ethereum").Return(fmt.Errorf("wallet not found"))
+		mockValidation.On("CanCredit", "0xinvalidwallet", "Ethereum").Return(fmt.Errorf("wallet not found"))
 
 		reqBody := deposit.Request{
 			WalletAddress: "0xinvalidwallet",
 			Network:       "Ethereum",
-			Amount:        100.50,
+			Asset:         "ETH",
+			Amount:        decimal.RequireFromString("100.50"),
 		}
 		resp := sendRequest(t, reqBody, http.StatusBadRequest)
 
@@ -94,17 +97,18 @@ func TestDepositEndpoint(t *testing.T) {
 			t.Errorf("expected error 'wallet validation failed: wallet not found', got %s", response.Message)
 		}
 
-		mockValidation.AssertCalled(t, "One", "0xinvalidwallet", "Ethereum")
+		mockValidation.AssertCalled(t, "CanCredit", "0xinvalidwallet", "Ethereum")
 	})
 
 	// 3. Invalid Network
 	t.Run("Invalid Network", func(t *testing.T) {
-		mockValidation.On("One", "0x123abc456def", "").Return(nil)
+		mockValidation.On("CanCredit", "0x123abc456def", "").Return(nil)
 
 		reqBody := deposit.Request{
 			WalletAddress: "0x123abc456def",
 			Network:       "",
-			Amount:        100.50,
+			Asset:         "ETH",
+			Amount:        decimal.RequireFromString("100.50"),
 		}
 		resp := sendRequest(t, reqBody, http.StatusBadRequest)
 
@@ -117,6 +121,6 @@ func TestDepositEndpoint(t *testing.T) {
 			t.Errorf("expected error 'invalid input parameters', got %s", response.Message)
 		}
 
-		mockValidation.AssertNotCalled(t, "One", "0x123abc456def", "")
+		mockValidation.AssertNotCalled(t, "CanCredit", "0x123abc456def", "")
 	})
 }
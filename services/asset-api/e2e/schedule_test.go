@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"errors"
 	"github.com/gofiber/fiber/v2"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"net/http"
 	"net/http/httptest"
@@ -17,6 +18,9 @@ import (
 func setupE2EApp(t *testing.T) (*fiber.App, func(), *MockValidationAdapter) {
 	// Setup PostgreSQL test container and initialize schema
 	db, cleanup := util.SetupTestContainer(t)
+	if err := util.InsertBalance(db, "wallet123", "mainnet", "ETH", decimal.RequireFromString("1000")); err != nil {
+		t.Fatal(err)
+	}
 
 	// Create repository, mock validation adapter, and service
 	repo := scheduled.NewCreateRepository(db)
@@ -43,7 +47,8 @@ func TestE2E_CreateTransaction_Success(t *testing.T) {
 		From:          "wallet123",
 		To:            "wallet456",
 		Network:       "mainnet",
-		Amount:        100.50,
+		Asset:         "ETH",
+		Amount:        decimal.RequireFromString("100.50"),
 		ScheduledTime: "2023-12-31T12:00:00Z",
 	}
 	reqBody, _ := json.Marshal(reqPayload)
@@ -58,9 +63,10 @@ func TestE2E_CreateTransaction_Success(t *testing.T) {
 	assert.Equal(t, http.StatusCreated, resp.StatusCode)
 
 	// Parse and verify the response
-	var response map[string]int
+	var response map[string]interface{}
 	json.NewDecoder(resp.Body).Decode(&response)
 	assert.NotZero(t, response["transaction_id"])
+	assert.Equal(t, "PENDING", response["status"])
 
 	// Assert that the mock validation was called as expected
 	mockValidator.AssertExpectations(t)
@@ -75,7 +81,8 @@ func TestE2E_CreateTransaction_InvalidAmount(t *testing.T) {
 		From:          "wallet123",
 		To:            "wallet456",
 		Network:       "mainnet",
-		Amount:        0, // Invalid amount
+		Asset:         "ETH",
+		Amount:        decimal.Zero, // Invalid amount
 		ScheduledTime: "2023-12-31T12:00:00Z",
 	}
 	reqBody, _ := json.Marshal(reqPayload)
@@ -103,7 +110,8 @@ func TestE2E_CreateTransaction_InvalidScheduledTimeFormat(t *testing.T) {
 		From:          "wallet123",
 		To:            "wallet456",
 		Network:       "mainnet",
-		Amount:        100.50,
+		Asset:         "ETH",
+		Amount:        decimal.RequireFromString("100.50"),
 		ScheduledTime: "invalid-time-format", // Invalid time format
 	}
 	reqBody, _ := json.Marshal(reqPayload)
@@ -134,7 +142,8 @@ func TestE2E_CreateTransaction_ValidationFailed(t *testing.T) {
 		From:          "wallet123",
 		To:            "wallet456",
 		Network:       "mainnet",
-		Amount:        100.50,
+		Asset:         "ETH",
+		Amount:        decimal.RequireFromString("100.50"),
 		ScheduledTime: "2023-12-31T12:00:00Z",
 	}
 	reqBody, _ := json.Marshal(reqPayload)
@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"errors"
 	"github.com/gofiber/fiber/v2"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"net/http"
@@ -25,6 +26,16 @@ func (m *MockValidationAdapter) One(walletAddress, network string) error {
 	return args.Error(0)
 }
 
+func (m *MockValidationAdapter) CanDebit(walletAddress, network string) error {
+	args := m.Called(walletAddress, network)
+	return args.Error(0)
+}
+
+func (m *MockValidationAdapter) CanCredit(walletAddress, network string) error {
+	args := m.Called(walletAddress, network)
+	return args.Error(0)
+}
+
 func (m *MockValidationAdapter) Both(from, to, network string) error {
 	args := m.Called(from, to, network)
 	return args.Error(0)
@@ -53,16 +64,17 @@ func TestE2E_Withdraw_Success_WithMock(t *testing.T) {
 	app, db, cleanup, mockValidation := setup(t)
 	defer cleanup()
 
-	err := util.InsertBalance(db, "0x123abc456def", "Ethereum", 500.00)
+	err := util.InsertBalance(db, "0x123abc456def", "Ethereum", "ETH", decimal.RequireFromString("500.00"))
 	assert.NoError(t, err)
 	// Mock successful wallet validation
-	mockValidation.On("One", "0x123abc456def", "Ethereum").Return(nil)
+	mockValidation.On("CanDebit", "0x123abc456def", "Ethereum").Return(nil)
 
 	// Define withdraw request payload
 	reqPayload := withdraw.Request{
 		WalletAddress: "0x123abc456def",
 		Network:       "Ethereum",
-		Amount:        100.50,
+		Asset:         "ETH",
+		Amount:        decimal.RequireFromString("100.50"),
 	}
 	reqBody, _ := json.Marshal(reqPayload)
 
@@ -76,12 +88,12 @@ func TestE2E_Withdraw_Success_WithMock(t *testing.T) {
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
 
 	// Verify the updated balance
-	var newBalance float64
+	var newBalance decimal.Decimal
 	err = db.QueryRow(`
 		SELECT balance FROM balance 
-		WHERE wallet_address = $1 AND network = $2`, "0x123abc456def", "Ethereum").Scan(&newBalance)
+		WHERE wallet_address = $1 AND network = $2 AND asset = $3`, "0x123abc456def", "Ethereum", "ETH").Scan(&newBalance)
 	assert.NoError(t, err)
-	assert.Equal(t, 399.50, newBalance)
+	assert.Equal(t, "399.5", newBalance.String())
 
 	// Assert that the mock was called as expected
 	mockValidation.AssertExpectations(t)
@@ -91,16 +103,17 @@ func TestE2E_Withdraw_InsufficientBalance_WithMock(t *testing.T) {
 	app, db, cleanup, mockValidation := setup(t)
 	defer cleanup()
 
-	_ = util.InsertBalance(db, "0x123abc456def", "Ethereum", 50.00)
+	_ = util.InsertBalance(db, "0x123abc456def", "Ethereum", "ETH", decimal.RequireFromString("50.00"))
 
 	// Mock successful wallet validation
-	mockValidation.On("One", "0x123abc456def", "Ethereum").Return(nil)
+	mockValidation.On("CanDebit", "0x123abc456def", "Ethereum").Return(nil)
 
 	// Define withdraw request payload with an amount greater than the balance
 	reqPayload := withdraw.Request{
 		WalletAddress: "0x123abc456def",
 		Network:       "Ethereum",
-		Amount:        100.50,
+		Asset:         "ETH",
+		Amount:        decimal.RequireFromString("100.50"),
 	}
 	reqBody, _ := json.Marshal(reqPayload)
 
@@ -129,13 +142,14 @@ func TestE2E_Withdraw_WalletNotFound_WithMock(t *testing.T) {
 	defer cleanup()
 
 	// Mock wallet validation to simulate wallet not found
-	mockValidation.On("One", "0xUnknownWallet", "Ethereum").Return(errors.New("wallet not found"))
+	mockValidation.On("CanDebit", "0xUnknownWallet", "Ethereum").Return(errors.New("wallet not found"))
 
 	// Define withdraw request payload for a wallet that doesn’t exist in the database
 	reqPayload := withdraw.Request{
 		WalletAddress: "0xUnknownWallet",
 		Network:       "Ethereum",
-		Amount:        100.50,
+		Asset:         "ETH",
+		Amount:        decimal.RequireFromString("100.50"),
 	}
 	reqBody, _ := json.Marshal(reqPayload)
 
@@ -0,0 +1,64 @@
+package holdings
+
+import (
+	"asset-management/services/asset-api/dto"
+	"github.com/gofiber/fiber/v2"
+)
+
+type Controller interface {
+	GetHoldings(ctx *fiber.Ctx) error
+	Sweep(ctx *fiber.Ctx) error
+}
+
+type controller struct {
+	service Service
+}
+
+func NewController(service Service) Controller {
+	return &controller{service: service}
+}
+
+// GetHoldings godoc
+// @Summary      Get wallet holdings
+// @Description  Returns the non-zero balances of a wallet and the scheduled transactions from or to it that were not processed yet. The wallet service refuses to delete a wallet with holdings.
+// @Tags         holdings
+// @Produce      json
+// @Param        network path string true "Wallet network"
+// @Param        address path string true "Wallet address"
+// @Success      200  {object}  Holdings
+// @Failure      400  {object}  dto.ErrorResponse
+// @Router       /wallet/{network}/{address}/holdings [get]
+func (c *controller) GetHoldings(ctx *fiber.Ctx) error {
+	holdings, err := c.service.Get(ctx.Params("address"), ctx.Params("network"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Message: err.Error()})
+	}
+
+	return ctx.JSON(holdings)
+}
+
+// Sweep godoc
+// @Summary      Sweep a wallet
+// @Description  Cancels the scheduled transactions from or to a wallet that were not processed yet and moves all of its funds to sweep_to, so that it can be deleted
+// @Tags         holdings
+// @Accept       json
+// @Produce      json
+// @Param        network path string true "Wallet network"
+// @Param        address path string true "Wallet address"
+// @Param        sweepRequest body SweepRequest true "Wallet receiving the funds"
+// @Success      200  {object}  SweepResponse
+// @Failure      400  {object}  dto.ErrorResponse
+// @Router       /wallet/{network}/{address}/sweep [post]
+func (c *controller) Sweep(ctx *fiber.Ctx) error {
+	var req SweepRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Message: "Invalid request payload"})
+	}
+
+	response, err := c.service.Sweep(ctx.Params("address"), ctx.Params("network"), req.SweepTo)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Message: err.Error()})
+	}
+
+	return ctx.JSON(response)
+}
@@ -0,0 +1,82 @@
+package holdings_test
+
+import (
+	"asset-management/services/asset-api/dto"
+	"asset-management/services/asset-api/holdings"
+	"encoding/json"
+	"github.com/gofiber/fiber/v2"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type mockService struct{ mock.Mock }
+
+func (m *mockService) Get(walletAddress, network string) (*holdings.Holdings, error) {
+	args := m.Called(walletAddress, network)
+	if h, ok := args.Get(0).(*holdings.Holdings); ok {
+		return h, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockService) Sweep(walletAddress, network, sweepTo string) (*holdings.SweepResponse, error) {
+	args := m.Called(walletAddress, network, sweepTo)
+	if r, ok := args.Get(0).(*holdings.SweepResponse); ok {
+		return r, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func setupApp(service holdings.Service) *fiber.App {
+	controller := holdings.NewController(service)
+	app := fiber.New()
+	app.Get("/wallet/:network/:address/holdings", controller.GetHoldings)
+	app.Post("/wallet/:network/:address/sweep", controller.Sweep)
+	return app
+}
+
+func TestHoldingsController_GetHoldings(t *testing.T) {
+	service := new(mockService)
+	expected := &holdings.Holdings{
+		WalletAddress:       "0x123",
+		Network:             "Ethereum",
+		Balances:            []holdings.AssetAmount{{Asset: "ETH", Amount: decimal.RequireFromString("1.5")}},
+		PendingTransactions: []int{12},
+	}
+	service.On("Get", "0x123", "Ethereum").Return(expected, nil)
+
+	resp, _ := setupApp(service).Test(httptest.NewRequest(http.MethodGet, "/wallet/Ethereum/0x123/holdings", nil))
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	var body holdings.Holdings
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, []int{12}, body.PendingTransactions)
+	assert.Equal(t, "1.5", body.Balances[0].Amount.String())
+}
+
+func TestHoldingsController_Sweep(t *testing.T) {
+	service := new(mockService)
+	service.On("Sweep", "0x123", "Ethereum", "0x456").Return(&holdings.SweepResponse{SweptTo: "0x456"}, nil)
+	service.On("Sweep", "0x123", "Ethereum", "").Return(nil, holdings.ErrSweepTargetRequired)
+	app := setupApp(service)
+
+	sweep := func(body string) *http.Response {
+		req := httptest.NewRequest(http.MethodPost, "/wallet/Ethereum/0x123/sweep", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+		return resp
+	}
+
+	assert.Equal(t, http.StatusOK, sweep(`{"sweep_to":"0x456"}`).StatusCode)
+
+	resp := sweep(`{}`)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	var errorResponse dto.ErrorResponse
+	_ = json.NewDecoder(resp.Body).Decode(&errorResponse)
+	assert.Equal(t, holdings.ErrSweepTargetRequired.Error(), errorResponse.Message)
+}
@@ -0,0 +1,198 @@
+package holdings
+
+import (
+	"asset-management/internal/funds"
+	"asset-management/internal/ledger"
+	"asset-management/internal/limits"
+	"asset-management/internal/outbox"
+	"asset-management/pkg/event"
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type Repository interface {
+	Get(walletAddress, network string) (*Holdings, error)
+	Sweep(walletAddress, network, sweepTo string) (*SweepResponse, error)
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+func (r *repository) Get(walletAddress, network string) (*Holdings, error) {
+	ctx := context.Background()
+
+	balances, err := queryBalances(ctx, r.db, walletAddress, network, "")
+	if err != nil {
+		return nil, err
+	}
+
+	pending := []int{}
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT scheduled_transaction_id FROM scheduled_transactions
+        WHERE network = $2 AND (from_wallet_address = $1 OR to_wallet_address = $1) AND status IN ('AWAITING_APPROVAL', 'PENDING', 'PUBLISHED')
+        ORDER BY scheduled_transaction_id`, walletAddress, network)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query scheduled transactions: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan scheduled transaction: %w", err)
+		}
+		pending = append(pending, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read scheduled transactions: %w", err)
+	}
+
+	return &Holdings{WalletAddress: walletAddress, Network: network, Balances: balances, PendingTransactions: pending}, nil
+}
+
+// queryBalances returns the non-zero balances of a wallet, with the given locking clause.
+func queryBalances(ctx context.Context, q queryer, walletAddress, network, locking string) ([]AssetAmount, error) {
+	rows, err := q.QueryContext(ctx, `
+        SELECT asset, balance FROM balance
+        WHERE wallet_address = $1 AND network = $2 AND balance <> 0
+        ORDER BY asset `+locking, walletAddress, network)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query balances: %w", err)
+	}
+	defer rows.Close()
+
+	balances := []AssetAmount{}
+	for rows.Next() {
+		var b AssetAmount
+		if err := rows.Scan(&b.Asset, &b.Amount); err != nil {
+			return nil, fmt.Errorf("failed to scan balance: %w", err)
+		}
+		balances = append(balances, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read balances: %w", err)
+	}
+	return balances, nil
+}
+
+// Sweep cancels the approval requests and scheduled transactions from or to the wallet that were not
+// processed yet and moves every balance to sweepTo, all in one database transaction. The consumer
+// re-checks the status under a row lock, so PUBLISHED transactions cancelled here are skipped. Approval
+// requests are locked before scheduled transactions, in the same order approving them does. A balance
+// above an approval threshold fails the sweep with ErrNeedsApproval.
+func (r *repository) Sweep(walletAddress, network, sweepTo string) (*SweepResponse, error) {
+	ctx := context.Background()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	response := &SweepResponse{Swept: []AssetAmount{}, CancelledTransactions: []int{}, CancelledApprovals: []int{}}
+	var cancelled pq.Int64Array
+	err = tx.QueryRowContext(ctx, `
+        WITH cancelled AS (
+            UPDATE approval_requests SET status = 'CANCELLED', decided_at = NOW()
+            WHERE status = 'AWAITING_APPROVAL' AND (
+                (wallet_address = $1 AND network = $2) OR scheduled_transaction_id IN (
+                    SELECT scheduled_transaction_id FROM scheduled_transactions
+                    WHERE network = $2 AND (from_wallet_address = $1 OR to_wallet_address = $1) AND status = 'AWAITING_APPROVAL'))
+            RETURNING approval_id
+        )
+        SELECT COALESCE(array_agg(approval_id ORDER BY approval_id), '{}') FROM cancelled`, walletAddress, network).
+		Scan(&cancelled)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel approval requests: %w", err)
+	}
+	for _, id := range cancelled {
+		response.CancelledApprovals = append(response.CancelledApprovals, int(id))
+	}
+
+	err = tx.QueryRowContext(ctx, `
+        WITH cancelled AS (
+            UPDATE scheduled_transactions SET status = 'CANCELLED'
+            WHERE network = $2 AND (from_wallet_address = $1 OR to_wallet_address = $1) AND status IN ('AWAITING_APPROVAL', 'PENDING', 'PUBLISHED')
+            RETURNING scheduled_transaction_id
+        )
+        SELECT COALESCE(array_agg(scheduled_transaction_id ORDER BY scheduled_transaction_id), '{}') FROM cancelled`, walletAddress, network).
+		Scan(&cancelled)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel scheduled transactions: %w", err)
+	}
+	for _, id := range cancelled {
+		response.CancelledTransactions = append(response.CancelledTransactions, int(id))
+	}
+
+	// Lock the balances, so that no deposit lands between reading and moving them
+	balances, err := queryBalances(ctx, tx, walletAddress, network, "FOR UPDATE")
+	if err != nil {
+		return nil, err
+	}
+
+	if len(balances) > 0 {
+		response.Reference = "sweep:" + uuid.NewString()
+		response.SweptTo = sweepTo
+	}
+	for _, b := range balances {
+		if !b.Amount.IsPositive() {
+			return nil, fmt.Errorf("cannot sweep negative %s balance %s", b.Asset, b.Amount)
+		}
+		threshold, err := limits.ApprovalThreshold(ctx, tx, limits.Outflow{
+			WalletAddress: walletAddress,
+			Network:       network,
+			Asset:         b.Asset,
+			Amount:        b.Amount,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if threshold != nil {
+			return nil, fmt.Errorf("%w: %s allows at most %s %s", ErrNeedsApproval, threshold, threshold.Value, threshold.Asset)
+		}
+
+		result, err := funds.Move(ctx, tx, funds.Transfer{
+			From:      walletAddress,
+			To:        sweepTo,
+			Network:   network,
+			Asset:     b.Asset,
+			Amount:    b.Amount,
+			Operation: ledger.OperationSweep,
+			Reference: response.Reference,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		err = outbox.Write(ctx, tx, event.TypeTransferCompleted, event.TransferCompleted{
+			Reference:   response.Reference,
+			FromWallet:  walletAddress,
+			ToWallet:    sweepTo,
+			Network:     network,
+			Asset:       b.Asset,
+			Amount:      b.Amount,
+			FromBalance: result.FromBalance,
+			ToBalance:   result.ToBalance,
+		}, walletAddress)
+		if err != nil {
+			return nil, err
+		}
+		response.Swept = append(response.Swept, b)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
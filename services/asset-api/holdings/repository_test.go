@@ -0,0 +1,91 @@
+package holdings_test
+
+import (
+	"asset-management/services/asset-api/holdings"
+	"asset-management/services/asset-api/util"
+	_ "github.com/lib/pq"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRepository_Sweep(t *testing.T) {
+	db, cleanup := util.SetupTestContainer(t)
+	defer cleanup()
+
+	assert.NoError(t, util.InsertBalance(db, "0x123", "Ethereum", "ETH", decimal.RequireFromString("1.5")))
+	assert.NoError(t, util.InsertBalance(db, "0x123", "Ethereum", "USDT", decimal.RequireFromString("0")))
+	for _, row := range []struct{ from, to, status string }{
+		{"0x123", "0x456", "PENDING"},
+		{"0x789", "0x123", "PUBLISHED"},
+		{"0x123", "0x456", "COMPLETED"},
+		{"0x789", "0x123", "AWAITING_APPROVAL"},
+	} {
+		_, err := db.Exec(`
+			INSERT INTO scheduled_transactions (from_wallet_address, to_wallet_address, network, asset, amount, scheduled_time, status)
+			VALUES ($1, $2, 'Ethereum', 'ETH', 1, $3, $4)`, row.from, row.to, time.Now().Add(time.Hour), row.status)
+		assert.NoError(t, err)
+	}
+
+	// A withdrawal and a transfer to the wallet await approval
+	_, err := db.Exec(`
+		INSERT INTO approval_requests (operation, wallet_address, network, asset, amount, scheduled_transaction_id, rule_id, requested_by)
+		VALUES ('WITHDRAW', '0x123', 'Ethereum', 'ETH', 1, NULL, 1, 'alice'),
+		       ('SCHEDULED_TRANSFER', '0x789', 'Ethereum', 'ETH', 1, 4, 1, 'alice'),
+		       ('WITHDRAW', '0x789', 'Ethereum', 'ETH', 1, NULL, 1, 'alice')`)
+	assert.NoError(t, err)
+
+	repo := holdings.NewRepository(db)
+
+	// Zero balances and processed transactions do not count
+	before, err := repo.Get("0x123", "Ethereum")
+	assert.NoError(t, err)
+	if assert.Len(t, before.Balances, 1) {
+		assert.Equal(t, "ETH", before.Balances[0].Asset)
+	}
+	assert.Len(t, before.PendingTransactions, 3)
+
+	result, err := repo.Sweep("0x123", "Ethereum", "0xsafe")
+	assert.NoError(t, err)
+	assert.Equal(t, before.PendingTransactions, result.CancelledTransactions)
+	// The other wallet's own withdrawal is left alone
+	assert.Equal(t, []int{1, 2}, result.CancelledApprovals)
+	assert.Len(t, result.Swept, 1)
+
+	after, err := repo.Get("0x123", "Ethereum")
+	assert.NoError(t, err)
+	assert.True(t, after.Empty())
+
+	var swept decimal.Decimal
+	err = db.QueryRow(`SELECT balance FROM balance WHERE wallet_address = '0xsafe' AND asset = 'ETH'`).Scan(&swept)
+	assert.NoError(t, err)
+	assert.Equal(t, "1.5", swept.String())
+
+	var entries int
+	err = db.QueryRow(`SELECT COUNT(*) FROM ledger_entries WHERE reference = $1 AND operation = 'SWEEP'`, result.Reference).Scan(&entries)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, entries)
+}
+
+func TestRepository_Sweep_NeedsApproval(t *testing.T) {
+	db, cleanup := util.SetupTestContainer(t)
+	defer cleanup()
+
+	assert.NoError(t, util.InsertBalance(db, "0x123", "Ethereum", "ETH", decimal.RequireFromString("60")))
+	_, err := db.Exec(`
+		INSERT INTO withdrawal_limits (scope, network, wallet_address, asset, kind, value)
+		VALUES ('GLOBAL', '', '', 'ETH', 'APPROVAL_THRESHOLD', 50)`)
+	assert.NoError(t, err)
+
+	repo := holdings.NewRepository(db)
+	_, err = repo.Sweep("0x123", "Ethereum", "0xsafe")
+	assert.ErrorIs(t, err, holdings.ErrNeedsApproval)
+
+	// Nothing moved
+	after, err := repo.Get("0x123", "Ethereum")
+	assert.NoError(t, err)
+	if assert.Len(t, after.Balances, 1) {
+		assert.Equal(t, "60", after.Balances[0].Amount.String())
+	}
+}
@@ -0,0 +1,104 @@
+package holdings
+
+import (
+	"asset-management/services/asset-api/wallet"
+	"errors"
+	"fmt"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrSweepTargetRequired = errors.New("sweep_to is required to sweep a wallet that holds funds")
+	ErrSweepToSelf         = errors.New("sweep_to must be a different wallet")
+	// ErrNeedsApproval is returned when a balance exceeds an approval threshold. Such funds are
+	// withdrawn or transferred through an approval first.
+	ErrNeedsApproval = errors.New("balance exceeds an approval threshold, move it with an approved withdrawal or scheduled transfer first")
+)
+
+type AssetAmount struct {
+	Asset  string          `json:"asset" example:"ETH"`                           // Asset symbol
+	Amount decimal.Decimal `json:"amount" swaggertype:"string" example:"1500.75"` // Amount of the asset
+}
+
+// Holdings is what keeps a wallet from being deleted: funds and scheduled transactions not yet processed.
+type Holdings struct {
+	WalletAddress       string        `json:"wallet_address" example:"0x123abc456def"`
+	Network             string        `json:"network" example:"Ethereum"`
+	Balances            []AssetAmount `json:"balances"`                             // Non-zero balances
+	PendingTransactions []int         `json:"pending_transactions" example:"12,13"` // IDs of scheduled transactions from or to the wallet awaiting approval, PENDING or PUBLISHED
+}
+
+// Empty reports whether the wallet can be deleted without orphaning funds.
+func (h Holdings) Empty() bool {
+	return len(h.Balances) == 0 && len(h.PendingTransactions) == 0
+}
+
+type SweepRequest struct {
+	SweepTo string `json:"sweep_to" example:"0x789ghi012jkl"` // Wallet receiving the funds, required if the wallet holds any
+}
+
+type SweepResponse struct {
+	Reference             string        `json:"reference,omitempty" example:"sweep:3f1c..."` // Ledger reference of the moved funds
+	SweptTo               string        `json:"swept_to,omitempty" example:"0x789ghi012jkl"`
+	Swept                 []AssetAmount `json:"swept"`                                  // Funds moved to SweptTo
+	CancelledTransactions []int         `json:"cancelled_transactions" example:"12,13"` // Scheduled transactions cancelled
+	CancelledApprovals    []int         `json:"cancelled_approvals" example:"4"`        // Approval requests cancelled
+}
+
+type Service interface {
+	Get(walletAddress, network string) (*Holdings, error)
+	Sweep(walletAddress, network, sweepTo string) (*SweepResponse, error)
+}
+
+type service struct {
+	repository      Repository
+	walletValidator wallet.ValidationAdapter
+}
+
+func NewService(repository Repository, wv wallet.ValidationAdapter) Service {
+	return &service{repository: repository, walletValidator: wv}
+}
+
+func (s *service) Get(walletAddress, network string) (*Holdings, error) {
+	if walletAddress == "" || network == "" {
+		return nil, errors.New("invalid input parameters")
+	}
+	network, walletAddress = wallet.Canonical(network, walletAddress)
+
+	holdings, err := s.repository.Get(walletAddress, network)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve holdings: %w", err)
+	}
+	return holdings, nil
+}
+
+// Sweep cancels the wallet's pending scheduled transactions and moves all of its funds to sweepTo,
+// so that it can be deleted. The wallet itself may be frozen; sweepTo must be able to receive funds.
+func (s *service) Sweep(walletAddress, network, sweepTo string) (*SweepResponse, error) {
+	if sweepTo != "" {
+		_, sweepTo = wallet.Canonical(network, sweepTo)
+	}
+	network, walletAddress = wallet.Canonical(network, walletAddress)
+	holdings, err := s.Get(walletAddress, network)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(holdings.Balances) > 0 {
+		if sweepTo == "" {
+			return nil, ErrSweepTargetRequired
+		}
+		if sweepTo == walletAddress {
+			return nil, ErrSweepToSelf
+		}
+		if err := s.walletValidator.CanCredit(sweepTo, network); err != nil {
+			return nil, fmt.Errorf("sweep_to validation failed: %w", err)
+		}
+	}
+
+	response, err := s.repository.Sweep(walletAddress, network, sweepTo)
+	if err != nil {
+		return nil, fmt.Errorf("sweep failed: %w", err)
+	}
+	return response, nil
+}
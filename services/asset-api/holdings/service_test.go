@@ -0,0 +1,121 @@
+package holdings_test
+
+import (
+	"asset-management/services/asset-api/holdings"
+	"errors"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+)
+
+type mockRepository struct{ mock.Mock }
+
+func (m *mockRepository) Get(walletAddress, network string) (*holdings.Holdings, error) {
+	args := m.Called(walletAddress, network)
+	if h, ok := args.Get(0).(*holdings.Holdings); ok {
+		return h, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockRepository) Sweep(walletAddress, network, sweepTo string) (*holdings.SweepResponse, error) {
+	args := m.Called(walletAddress, network, sweepTo)
+	if r, ok := args.Get(0).(*holdings.SweepResponse); ok {
+		return r, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+type mockValidationAdapter struct{ mock.Mock }
+
+func (m *mockValidationAdapter) One(walletAddress, network string) error {
+	args := m.Called(walletAddress, network)
+	return args.Error(0)
+}
+
+func (m *mockValidationAdapter) CanDebit(walletAddress, network string) error {
+	args := m.Called(walletAddress, network)
+	return args.Error(0)
+}
+
+func (m *mockValidationAdapter) CanCredit(walletAddress, network string) error {
+	args := m.Called(walletAddress, network)
+	return args.Error(0)
+}
+
+func (m *mockValidationAdapter) Both(from, to, network string) error {
+	args := m.Called(from, to, network)
+	return args.Error(0)
+}
+
+var funded = &holdings.Holdings{
+	WalletAddress: "0x123",
+	Network:       "Ethereum",
+	Balances:      []holdings.AssetAmount{{Asset: "ETH", Amount: decimal.RequireFromString("1.5")}},
+}
+
+func TestHoldingsService_Get_InvalidInput(t *testing.T) {
+	repo := new(mockRepository)
+
+	_, err := holdings.NewService(repo, new(mockValidationAdapter)).Get("", "Ethereum")
+
+	assert.Error(t, err)
+	repo.AssertNotCalled(t, "Get")
+}
+
+func TestHoldingsService_Sweep_Success(t *testing.T) {
+	repo := new(mockRepository)
+	validator := new(mockValidationAdapter)
+	response := &holdings.SweepResponse{SweptTo: "0x456", Swept: funded.Balances}
+	repo.On("Get", "0x123", "Ethereum").Return(funded, nil)
+	validator.On("CanCredit", "0x456", "Ethereum").Return(nil)
+	repo.On("Sweep", "0x123", "Ethereum", "0x456").Return(response, nil)
+
+	result, err := holdings.NewService(repo, validator).Sweep("0x123", "Ethereum", "0x456")
+
+	assert.NoError(t, err)
+	assert.Equal(t, response, result)
+	repo.AssertExpectations(t)
+	validator.AssertExpectations(t)
+}
+
+func TestHoldingsService_Sweep_RequiresTarget(t *testing.T) {
+	repo := new(mockRepository)
+	repo.On("Get", "0x123", "Ethereum").Return(funded, nil)
+	service := holdings.NewService(repo, new(mockValidationAdapter))
+
+	_, err := service.Sweep("0x123", "Ethereum", "")
+	assert.ErrorIs(t, err, holdings.ErrSweepTargetRequired)
+
+	_, err = service.Sweep("0x123", "Ethereum", "0x123")
+	assert.ErrorIs(t, err, holdings.ErrSweepToSelf)
+
+	repo.AssertNotCalled(t, "Sweep")
+}
+
+func TestHoldingsService_Sweep_InvalidTarget(t *testing.T) {
+	repo := new(mockRepository)
+	validator := new(mockValidationAdapter)
+	repo.On("Get", "0x123", "Ethereum").Return(funded, nil)
+	validator.On("CanCredit", "0xunknown", "Ethereum").Return(errors.New("failed to validate wallet"))
+
+	_, err := holdings.NewService(repo, validator).Sweep("0x123", "Ethereum", "0xunknown")
+
+	assert.EqualError(t, err, "sweep_to validation failed: failed to validate wallet")
+	repo.AssertNotCalled(t, "Sweep")
+}
+
+func TestHoldingsService_Sweep_OnlySchedules(t *testing.T) {
+	repo := new(mockRepository)
+	validator := new(mockValidationAdapter)
+	repo.On("Get", "0x123", "Ethereum").Return(&holdings.Holdings{PendingTransactions: []int{12}}, nil)
+	repo.On("Sweep", "0x123", "Ethereum", "").Return(&holdings.SweepResponse{CancelledTransactions: []int{12}}, nil)
+
+	// Without funds no target is needed
+	result, err := holdings.NewService(repo, validator).Sweep("0x123", "Ethereum", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []int{12}, result.CancelledTransactions)
+	validator.AssertNotCalled(t, "CanCredit")
+}
@@ -0,0 +1,87 @@
+package idempotency
+
+import (
+	"asset-management/services/asset-api/dto"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	HeaderKey      = "Idempotency-Key"
+	HeaderReplayed = "Idempotent-Replayed"
+	maxKeyLength   = 255
+)
+
+// NewMiddleware makes the wrapped route idempotent for requests carrying an Idempotency-Key header.
+// The first request with a key runs normally and its response is stored; a replay with the same
+// body returns the stored response and a replay with a different body is rejected with 409.
+// Server errors release the key so the client can retry.
+func NewMiddleware(repository Repository) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		key := ctx.Get(HeaderKey)
+		if key == "" {
+			return ctx.Next()
+		}
+		if len(key) > maxKeyLength {
+			return ctx.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Message: "Idempotency-Key is too long"})
+		}
+
+		scope := ctx.Method() + " " + ctx.Path()
+		fingerprint := fingerprint(scope, ctx.Body())
+
+		record, claimed, err := repository.Claim(key, scope, fingerprint)
+		if err != nil {
+			log.Error().Err(err).Str("idempotency_key", key).Msg("Failed to claim idempotency key")
+			return ctx.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Message: "Failed to process idempotency key"})
+		}
+
+		if !claimed {
+			if record.Fingerprint != fingerprint {
+				return ctx.Status(fiber.StatusConflict).JSON(dto.ErrorResponse{Message: "Idempotency-Key was already used with a different request payload"})
+			}
+			if record.StatusCode == 0 {
+				return ctx.Status(fiber.StatusConflict).JSON(dto.ErrorResponse{Message: "A request with this Idempotency-Key is still being processed"})
+			}
+
+			ctx.Set(HeaderReplayed, "true")
+			if record.ContentType != "" {
+				ctx.Set(fiber.HeaderContentType, record.ContentType)
+			}
+			return ctx.Status(record.StatusCode).Send(record.ResponseBody)
+		}
+
+		if err := ctx.Next(); err != nil {
+			releaseKey(repository, record)
+			return err
+		}
+
+		status := ctx.Response().StatusCode()
+		if status >= fiber.StatusInternalServerError {
+			releaseKey(repository, record)
+			return nil
+		}
+
+		contentType := string(ctx.Response().Header.ContentType())
+		if err := repository.Complete(record, status, contentType, ctx.Response().Body()); err != nil {
+			log.Error().Err(err).Str("idempotency_key", key).Msg("Failed to store idempotent response")
+		}
+
+		return nil
+	}
+}
+
+func releaseKey(repository Repository, claim *Record) {
+	if err := repository.Release(claim); err != nil {
+		log.Error().Err(err).Str("idempotency_key", claim.Key).Msg("Failed to release idempotency key")
+	}
+}
+
+func fingerprint(scope string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(scope))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,178 @@
+package idempotency_test
+
+import (
+	"asset-management/services/asset-api/idempotency"
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type mockRepository struct{ mock.Mock }
+
+func (m *mockRepository) Claim(key, scope, fingerprint string) (*idempotency.Record, bool, error) {
+	args := m.Called(key, scope, fingerprint)
+	if record, ok := args.Get(0).(*idempotency.Record); ok {
+		return record, args.Bool(1), args.Error(2)
+	}
+	return nil, args.Bool(1), args.Error(2)
+}
+
+func (m *mockRepository) Complete(claim *idempotency.Record, statusCode int, contentType string, body []byte) error {
+	args := m.Called(claim.Key, claim.Scope, statusCode, contentType, body)
+	return args.Error(0)
+}
+
+func (m *mockRepository) Release(claim *idempotency.Record) error {
+	args := m.Called(claim.Key, claim.Scope)
+	return args.Error(0)
+}
+
+// claim is the record of a key the request has just claimed.
+var claim = &idempotency.Record{Key: "key-1", Scope: "POST /deposit"}
+
+func setupApp(repo idempotency.Repository, handler fiber.Handler) *fiber.App {
+	app := fiber.New()
+	app.Post("/deposit", idempotency.NewMiddleware(repo), handler)
+	return app
+}
+
+func newRequest(key, body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/deposit", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set(idempotency.HeaderKey, key)
+	}
+	return req
+}
+
+func TestMiddleware_NoKeyPassesThrough(t *testing.T) {
+	repo := new(mockRepository)
+	app := setupApp(repo, func(ctx *fiber.Ctx) error {
+		return ctx.SendStatus(fiber.StatusOK)
+	})
+
+	resp, err := app.Test(newRequest("", `{}`))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	repo.AssertNotCalled(t, "Claim", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestMiddleware_FirstRequestStoresResponse(t *testing.T) {
+	repo := new(mockRepository)
+	repo.On("Claim", "key-1", "POST /deposit", mock.Anything).Return(claim, true, nil)
+	repo.On("Complete", "key-1", "POST /deposit", http.StatusOK, "application/json", []byte(`{"new_balance":"10"}`)).Return(nil)
+
+	calls := 0
+	app := setupApp(repo, func(ctx *fiber.Ctx) error {
+		calls++
+		return ctx.JSON(fiber.Map{"new_balance": "10"})
+	})
+
+	resp, err := app.Test(newRequest("key-1", `{"amount":"10"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 1, calls)
+	repo.AssertExpectations(t)
+}
+
+func TestMiddleware_ReplayReturnsStoredResponse(t *testing.T) {
+	repo := new(mockRepository)
+	var fingerprint string
+	repo.On("Claim", "key-1", "POST /deposit", mock.Anything).
+		Run(func(args mock.Arguments) { fingerprint = args.String(2) }).
+		Return(claim, true, nil).Once()
+	repo.On("Complete", "key-1", "POST /deposit", http.StatusOK, "application/json", mock.Anything).Return(nil)
+
+	calls := 0
+	app := setupApp(repo, func(ctx *fiber.Ctx) error {
+		calls++
+		return ctx.JSON(fiber.Map{"new_balance": "10"})
+	})
+
+	_, err := app.Test(newRequest("key-1", `{"amount":"10"}`))
+	assert.NoError(t, err)
+
+	repo.On("Claim", "key-1", "POST /deposit", fingerprint).Return(&idempotency.Record{
+		Fingerprint:  fingerprint,
+		StatusCode:   http.StatusOK,
+		ContentType:  "application/json",
+		ResponseBody: []byte(`{"new_balance":"10"}`),
+	}, false, nil)
+
+	resp, err := app.Test(newRequest("key-1", `{"amount":"10"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "true", resp.Header.Get(idempotency.HeaderReplayed))
+	body, _ := io.ReadAll(resp.Body)
+	assert.JSONEq(t, `{"new_balance":"10"}`, string(body))
+	assert.Equal(t, 1, calls)
+}
+
+func TestMiddleware_DifferentPayloadConflicts(t *testing.T) {
+	repo := new(mockRepository)
+	repo.On("Claim", "key-1", "POST /deposit", mock.Anything).
+		Return(&idempotency.Record{Fingerprint: "other", StatusCode: http.StatusOK}, false, nil)
+
+	app := setupApp(repo, func(ctx *fiber.Ctx) error {
+		t.Fatal("handler must not run")
+		return nil
+	})
+
+	resp, err := app.Test(newRequest("key-1", `{"amount":"20"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+}
+
+// inFlightRepository reports every key as claimed by a request that has not finished yet.
+type inFlightRepository struct{ mockRepository }
+
+func (r *inFlightRepository) Claim(key, scope, fingerprint string) (*idempotency.Record, bool, error) {
+	return &idempotency.Record{Key: key, Scope: scope, Fingerprint: fingerprint}, false, nil
+}
+
+func TestMiddleware_InFlightDuplicateConflicts(t *testing.T) {
+	app := setupApp(new(inFlightRepository), func(ctx *fiber.Ctx) error {
+		t.Fatal("handler must not run")
+		return nil
+	})
+
+	resp, err := app.Test(newRequest("key-1", `{"amount":"10"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+}
+
+func TestMiddleware_ClaimError(t *testing.T) {
+	repo := new(mockRepository)
+	repo.On("Claim", "key-1", "POST /deposit", mock.Anything).Return(nil, false, errors.New("db down"))
+
+	app := setupApp(repo, func(ctx *fiber.Ctx) error {
+		t.Fatal("handler must not run")
+		return nil
+	})
+
+	resp, err := app.Test(newRequest("key-1", `{"amount":"10"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+}
+
+func TestMiddleware_ServerErrorReleasesKey(t *testing.T) {
+	repo := new(mockRepository)
+	repo.On("Claim", "key-1", "POST /deposit", mock.Anything).Return(claim, true, nil)
+	repo.On("Release", "key-1", "POST /deposit").Return(nil)
+
+	app := setupApp(repo, func(ctx *fiber.Ctx) error {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "boom"})
+	})
+
+	resp, err := app.Test(newRequest("key-1", `{"amount":"10"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	repo.AssertCalled(t, "Release", "key-1", "POST /deposit")
+	repo.AssertNotCalled(t, "Complete", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,92 @@
+package idempotency
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Record is a stored idempotency key. StatusCode is zero while the original request is still in flight.
+type Record struct {
+	Key          string
+	Scope        string
+	Fingerprint  string
+	ClaimedAt    time.Time // When the request that holds the key claimed it
+	StatusCode   int
+	ContentType  string
+	ResponseBody []byte
+}
+
+type Repository interface {
+	// Claim stores a new key for the scope. It returns the existing record and false when the key is
+	// held or completed, so only one of several concurrent duplicates gets to run. A claim is never taken
+	// over: the response is stored after the operation committed, so a request that crashed in between
+	// may have run, and running it again could move funds twice.
+	Claim(key, scope, fingerprint string) (*Record, bool, error)
+	// Complete and Release act on the key only while the claim is still held.
+	Complete(claim *Record, statusCode int, contentType string, body []byte) error
+	Release(claim *Record) error
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) Claim(key, scope, fingerprint string) (*Record, bool, error) {
+	var claimedAt time.Time
+	err := r.db.QueryRow(`
+        INSERT INTO idempotency_keys (idempotency_key, scope, fingerprint)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (idempotency_key, scope) DO NOTHING
+        RETURNING claimed_at`, key, scope, fingerprint).Scan(&claimedAt)
+	if err == nil {
+		return &Record{Key: key, Scope: scope, Fingerprint: fingerprint, ClaimedAt: claimedAt}, true, nil
+	} else if err != sql.ErrNoRows {
+		return nil, false, fmt.Errorf("failed to claim idempotency key: %w", err)
+	}
+
+	var record Record
+	var statusCode sql.NullInt64
+	var contentType sql.NullString
+	err = r.db.QueryRow(`
+        SELECT idempotency_key, scope, fingerprint, claimed_at, status_code, content_type, response_body
+        FROM idempotency_keys
+        WHERE idempotency_key = $1 AND scope = $2`, key, scope).
+		Scan(&record.Key, &record.Scope, &record.Fingerprint, &record.ClaimedAt, &statusCode, &contentType, &record.ResponseBody)
+	if err == sql.ErrNoRows {
+		// The claimant released the key in the meantime; let the caller retry with a fresh claim
+		return r.Claim(key, scope, fingerprint)
+	} else if err != nil {
+		return nil, false, fmt.Errorf("failed to load idempotency key: %w", err)
+	}
+
+	record.StatusCode = int(statusCode.Int64)
+	record.ContentType = contentType.String
+	return &record, false, nil
+}
+
+func (r *repository) Complete(claim *Record, statusCode int, contentType string, body []byte) error {
+	_, err := r.db.Exec(`
+        UPDATE idempotency_keys
+        SET status_code = $4, content_type = $5, response_body = $6, completed_at = CURRENT_TIMESTAMP
+        WHERE idempotency_key = $1 AND scope = $2 AND claimed_at = $3 AND status_code IS NULL`,
+		claim.Key, claim.Scope, claim.ClaimedAt, statusCode, contentType, body)
+	if err != nil {
+		return fmt.Errorf("failed to store idempotent response: %w", err)
+	}
+	return nil
+}
+
+func (r *repository) Release(claim *Record) error {
+	_, err := r.db.Exec(`
+        DELETE FROM idempotency_keys
+        WHERE idempotency_key = $1 AND scope = $2 AND claimed_at = $3 AND status_code IS NULL`, claim.Key, claim.Scope, claim.ClaimedAt)
+	if err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package idempotency_test
+
+import (
+	"asset-management/services/asset-api/idempotency"
+	"asset-management/services/asset-api/util"
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+)
+
+func TestRepository_ClaimCompleteReplay(t *testing.T) {
+	db, cleanup := util.SetupTestContainer(t)
+	defer cleanup()
+
+	repo := idempotency.NewRepository(db)
+
+	claim, claimed, err := repo.Claim("key-1", "POST /deposit", "fp-1")
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+	assert.Equal(t, "fp-1", claim.Fingerprint)
+
+	// A duplicate sees the in-flight record
+	record, claimed, err := repo.Claim("key-1", "POST /deposit", "fp-1")
+	assert.NoError(t, err)
+	assert.False(t, claimed)
+	assert.Zero(t, record.StatusCode)
+
+	assert.NoError(t, repo.Complete(claim, 200, "application/json", []byte(`{"ok":true}`)))
+
+	record, claimed, err = repo.Claim("key-1", "POST /deposit", "fp-1")
+	assert.NoError(t, err)
+	assert.False(t, claimed)
+	assert.Equal(t, 200, record.StatusCode)
+	assert.Equal(t, "application/json", record.ContentType)
+	assert.Equal(t, `{"ok":true}`, string(record.ResponseBody))
+
+	// The same key is independent across endpoints
+	_, claimed, err = repo.Claim("key-1", "POST /withdraw", "fp-2")
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+}
+
+func TestRepository_ConcurrentClaims(t *testing.T) {
+	db, cleanup := util.SetupTestContainer(t)
+	defer cleanup()
+
+	repo := idempotency.NewRepository(db)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	winners := 0
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, claimed, err := repo.Claim("key-1", "POST /deposit", "fp-1")
+			assert.NoError(t, err)
+			if claimed {
+				mu.Lock()
+				winners++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1, winners)
+}
+
+func TestRepository_Release(t *testing.T) {
+	db, cleanup := util.SetupTestContainer(t)
+	defer cleanup()
+
+	repo := idempotency.NewRepository(db)
+
+	claim, _, err := repo.Claim("key-1", "POST /deposit", "fp-1")
+	assert.NoError(t, err)
+	assert.NoError(t, repo.Release(claim))
+
+	_, claimed, err := repo.Claim("key-1", "POST /deposit", "fp-1")
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+}
+
+func TestRepository_AbandonedClaimIsKept(t *testing.T) {
+	db, cleanup := util.SetupTestContainer(t)
+	defer cleanup()
+
+	repo := idempotency.NewRepository(db)
+
+	_, _, err := repo.Claim("key-1", "POST /deposit", "fp-1")
+	assert.NoError(t, err)
+
+	// The request that claimed the key may have moved funds before it crashed, so however old the
+	// claim, a retry is not run again
+	_, err = db.Exec(`UPDATE idempotency_keys SET claimed_at = claimed_at - INTERVAL '1 hour'`)
+	assert.NoError(t, err)
+	record, claimed, err := repo.Claim("key-1", "POST /deposit", "fp-1")
+	assert.NoError(t, err)
+	assert.False(t, claimed)
+	assert.Zero(t, record.StatusCode)
+}
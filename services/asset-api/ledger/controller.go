@@ -0,0 +1,67 @@
+package ledger
+
+import (
+	ledger2 "asset-management/internal/ledger"
+	"asset-management/services/asset-api/dto"
+	"github.com/gofiber/fiber/v2"
+	"strconv"
+)
+
+type Controller interface {
+	GetLedger(ctx *fiber.Ctx) error
+}
+
+type Response struct {
+	Entries    []ledger2.Entry `json:"entries"`
+	NextCursor string          `json:"next_cursor,omitempty" example:"41"`
+}
+
+type controller struct {
+	service Service
+}
+
+func NewController(service Service) Controller {
+	return &controller{service: service}
+}
+
+// GetLedger godoc
+// @Summary      Get wallet ledger
+// @Description  Lists the ledger entries of a wallet, newest first, using cursor pagination
+// @Tags         ledger
+// @Produce      json
+// @Param        network path string true "Wallet network"
+// @Param        address path string true "Wallet address"
+// @Param        asset query string false "Asset symbol filter"
+// @Param        cursor query string false "Cursor returned as next_cursor by the previous page"
+// @Param        limit query int false "Page size (default 50, max 200)"
+// @Success      200  {object}  Response
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /wallet/{network}/{address}/ledger [get]
+func (c *controller) GetLedger(ctx *fiber.Ctx) error {
+	var cursor int64
+	if raw := ctx.Query("cursor"); raw != "" {
+		parsed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || parsed <= 0 {
+			return ctx.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Message: "Invalid cursor"})
+		}
+		cursor = parsed
+	}
+
+	limit := ctx.QueryInt("limit", 0)
+	if limit < 0 {
+		return ctx.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Message: "Invalid limit"})
+	}
+
+	page, err := c.service.GetEntries(ctx.Params("address"), ctx.Params("network"), ctx.Query("asset"), cursor, limit)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Message: err.Error()})
+	}
+
+	response := Response{Entries: page.Entries}
+	if page.NextCursor != 0 {
+		response.NextCursor = strconv.FormatInt(page.NextCursor, 10)
+	}
+
+	return ctx.JSON(response)
+}
@@ -0,0 +1,78 @@
+package ledger_test
+
+import (
+	ledger2 "asset-management/internal/ledger"
+	"asset-management/services/asset-api/ledger"
+	"encoding/json"
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type mockService struct{ mock.Mock }
+
+func (m *mockService) GetEntries(walletAddress, network, asset string, cursor int64, limit int) (*ledger.Page, error) {
+	args := m.Called(walletAddress, network, asset, cursor, limit)
+	if page, ok := args.Get(0).(*ledger.Page); ok {
+		return page, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func setupApp(service ledger.Service) *fiber.App {
+	app := fiber.New()
+	app.Get("/wallet/:network/:address/ledger", ledger.NewController(service).GetLedger)
+	return app
+}
+
+func TestLedgerController_Success(t *testing.T) {
+	service := new(mockService)
+	page := &ledger.Page{
+		Entries: []ledger2.Entry{
+			{ID: 7, WalletAddress: "0x123", Network: "Ethereum", Asset: "ETH", EntryType: ledger2.EntryTypeCredit,
+				Operation: ledger2.OperationDeposit, Counterparty: ledger2.ExternalAccount,
+				Amount: decimal.RequireFromString("10"), BalanceAfter: ledger2.Balance(decimal.RequireFromString("25"))},
+		},
+		NextCursor: 7,
+	}
+	service.On("GetEntries", "0x123", "Ethereum", "ETH", int64(12), 1).Return(page, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/wallet/Ethereum/0x123/ledger?asset=ETH&cursor=12&limit=1", nil)
+	resp, err := setupApp(service).Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var response map[string]interface{}
+	_ = json.NewDecoder(resp.Body).Decode(&response)
+	assert.Equal(t, "7", response["next_cursor"])
+	entries := response["entries"].([]interface{})
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "25", entries[0].(map[string]interface{})["balance_after"])
+
+	service.AssertExpectations(t)
+}
+
+func TestLedgerController_InvalidCursor(t *testing.T) {
+	service := new(mockService)
+
+	req := httptest.NewRequest(http.MethodGet, "/wallet/Ethereum/0x123/ledger?cursor=abc", nil)
+	resp, err := setupApp(service).Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	service.AssertNotCalled(t, "GetEntries")
+}
+
+func TestLedgerController_ServiceError(t *testing.T) {
+	service := new(mockService)
+	service.On("GetEntries", "0x123", "Ethereum", "", int64(0), 0).Return(nil, errors.New("db down"))
+
+	req := httptest.NewRequest(http.MethodGet, "/wallet/Ethereum/0x123/ledger", nil)
+	resp, err := setupApp(service).Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+}
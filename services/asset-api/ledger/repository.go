@@ -0,0 +1,59 @@
+package ledger
+
+import (
+	ledger2 "asset-management/internal/ledger"
+	"database/sql"
+	"fmt"
+)
+
+type Repository interface {
+	GetEntries(walletAddress, network, asset string, cursor int64, limit int) ([]ledger2.Entry, error)
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+// GetEntries returns ledger entries of a wallet, including those recorded under its aliases, newest
+// first. Only entries older than the cursor are returned when the cursor is set; an empty asset
+// matches every asset.
+func (r *repository) GetEntries(walletAddress, network, asset string, cursor int64, limit int) ([]ledger2.Entry, error) {
+	rows, err := r.db.Query(`
+        SELECT entry_id, wallet_address, network, asset, entry_type, operation, counterparty, amount, balance_after, reference, created_at
+        FROM ledger_entries
+        WHERE (wallet_address, network) IN (
+                SELECT $1::VARCHAR, $2::VARCHAR
+                UNION ALL SELECT wallet_address, network FROM wallet_aliases WHERE canonical_address = $1 AND canonical_network = $2)
+          AND ($3 = '' OR asset = $3)
+          AND ($4::BIGINT = 0 OR entry_id < $4::BIGINT)
+        ORDER BY entry_id DESC
+        LIMIT $5`, walletAddress, network, asset, cursor, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query ledger entries: %w", err)
+	}
+
+	entries := []ledger2.Entry{}
+	for rows.Next() {
+		var e ledger2.Entry
+		if err := rows.Scan(&e.ID, &e.WalletAddress, &e.Network, &e.Asset, &e.EntryType, &e.Operation,
+			&e.Counterparty, &e.Amount, &e.BalanceAfter, &e.Reference, &e.CreatedAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan ledger entry: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return nil, fmt.Errorf("failed to read ledger entries: %w", err)
+	}
+
+	if err := rows.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close rows: %w", err)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,46 @@
+package ledger_test
+
+import (
+	ledger2 "asset-management/internal/ledger"
+	"asset-management/services/asset-api/deposit"
+	"asset-management/services/asset-api/ledger"
+	"asset-management/services/asset-api/util"
+	"asset-management/services/asset-api/withdraw"
+	_ "github.com/lib/pq"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRepository_GetEntries(t *testing.T) {
+	db, cleanup := util.SetupTestContainer(t)
+	defer cleanup()
+
+	_, err := deposit.NewRepository(db).Deposit("0x123", "Ethereum", "ETH", decimal.RequireFromString("100"))
+	assert.NoError(t, err)
+	_, err = withdraw.NewRepository(db).Withdraw("0x123", "Ethereum", "ETH", decimal.RequireFromString("40"), "")
+	assert.NoError(t, err)
+
+	repo := ledger.NewRepository(db)
+
+	entries, err := repo.GetEntries("0x123", "Ethereum", "", 0, 10)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	// Newest first: the withdrawal debit, then the deposit credit
+	assert.Equal(t, ledger2.EntryTypeDebit, entries[0].EntryType)
+	assert.Equal(t, ledger2.OperationWithdraw, entries[0].Operation)
+	assert.Equal(t, ledger2.ExternalAccount, entries[0].Counterparty)
+	assert.Equal(t, "60", entries[0].BalanceAfter.Decimal.String())
+	assert.Equal(t, ledger2.EntryTypeCredit, entries[1].EntryType)
+	assert.Equal(t, "100", entries[1].BalanceAfter.Decimal.String())
+
+	older, err := repo.GetEntries("0x123", "Ethereum", "", entries[0].ID, 10)
+	assert.NoError(t, err)
+	assert.Len(t, older, 1)
+	assert.Equal(t, entries[1].ID, older[0].ID)
+
+	// Ledger entries cannot be modified
+	_, err = db.Exec(`UPDATE ledger_entries SET amount = 1`)
+	assert.Error(t, err)
+}
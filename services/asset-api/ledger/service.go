@@ -0,0 +1,58 @@
+package ledger
+
+import (
+	ledger2 "asset-management/internal/ledger"
+	"asset-management/services/asset-api/wallet"
+	"errors"
+	"fmt"
+)
+
+const (
+	DefaultLimit = 50
+	MaxLimit     = 200
+)
+
+type Page struct {
+	Entries    []ledger2.Entry
+	NextCursor int64
+}
+
+type Service interface {
+	GetEntries(walletAddress, network, asset string, cursor int64, limit int) (*Page, error)
+}
+
+type service struct {
+	repository Repository
+}
+
+func NewService(repository Repository) Service {
+	return &service{repository: repository}
+}
+
+func (s *service) GetEntries(walletAddress, network, asset string, cursor int64, limit int) (*Page, error) {
+	if walletAddress == "" || network == "" || cursor < 0 || limit < 0 {
+		return nil, errors.New("invalid input parameters")
+	}
+	network, walletAddress = wallet.Canonical(network, walletAddress)
+
+	if limit == 0 {
+		limit = DefaultLimit
+	}
+	if limit > MaxLimit {
+		limit = MaxLimit
+	}
+
+	// Fetch one extra entry to find out whether another page exists
+	entries, err := s.repository.GetEntries(walletAddress, network, asset, cursor, limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve ledger: %w", err)
+	}
+
+	page := &Page{Entries: entries}
+	if len(entries) > limit {
+		page.Entries = entries[:limit]
+		page.NextCursor = page.Entries[limit-1].ID
+	}
+
+	return page, nil
+}
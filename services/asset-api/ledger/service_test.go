@@ -0,0 +1,65 @@
+package ledger_test
+
+import (
+	ledger2 "asset-management/internal/ledger"
+	"asset-management/services/asset-api/ledger"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+)
+
+type mockRepository struct{ mock.Mock }
+
+func (m *mockRepository) GetEntries(walletAddress, network, asset string, cursor int64, limit int) ([]ledger2.Entry, error) {
+	args := m.Called(walletAddress, network, asset, cursor, limit)
+	if entries, ok := args.Get(0).([]ledger2.Entry); ok {
+		return entries, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func TestLedgerService_NextPage(t *testing.T) {
+	repo := new(mockRepository)
+	repo.On("GetEntries", "0x123", "Ethereum", "", int64(0), 3).
+		Return([]ledger2.Entry{{ID: 9}, {ID: 8}, {ID: 7}}, nil)
+
+	page, err := ledger.NewService(repo).GetEntries("0x123", "Ethereum", "", 0, 2)
+
+	assert.NoError(t, err)
+	assert.Len(t, page.Entries, 2)
+	assert.Equal(t, int64(8), page.NextCursor)
+	repo.AssertExpectations(t)
+}
+
+func TestLedgerService_LastPage(t *testing.T) {
+	repo := new(mockRepository)
+	repo.On("GetEntries", "0x123", "Ethereum", "ETH", int64(8), ledger.DefaultLimit+1).
+		Return([]ledger2.Entry{{ID: 7}}, nil)
+
+	page, err := ledger.NewService(repo).GetEntries("0x123", "Ethereum", "ETH", 8, 0)
+
+	assert.NoError(t, err)
+	assert.Len(t, page.Entries, 1)
+	assert.Zero(t, page.NextCursor)
+}
+
+func TestLedgerService_InvalidInput(t *testing.T) {
+	repo := new(mockRepository)
+
+	page, err := ledger.NewService(repo).GetEntries("", "Ethereum", "", 0, 0)
+
+	assert.Error(t, err)
+	assert.Nil(t, page)
+	repo.AssertNotCalled(t, "GetEntries")
+}
+
+func TestLedgerService_RepositoryError(t *testing.T) {
+	repo := new(mockRepository)
+	repo.On("GetEntries", "0x123", "Ethereum", "", int64(0), ledger.MaxLimit+1).Return(nil, errors.New("db down"))
+
+	page, err := ledger.NewService(repo).GetEntries("0x123", "Ethereum", "", 0, 1000)
+
+	assert.Error(t, err)
+	assert.Nil(t, page)
+}
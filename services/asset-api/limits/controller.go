@@ -0,0 +1,180 @@
+package limits
+
+import (
+	limits2 "asset-management/internal/limits"
+	"asset-management/services/asset-api/dto"
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/shopspring/decimal"
+	"strconv"
+)
+
+type Controller interface {
+	List(ctx *fiber.Ctx) error
+	Get(ctx *fiber.Ctx) error
+	Create(ctx *fiber.Ctx) error
+	Update(ctx *fiber.Ctx) error
+	Delete(ctx *fiber.Ctx) error
+}
+
+type CreateRequest struct {
+	Scope         string          `json:"scope" example:"WALLET"`                            // GLOBAL, NETWORK or WALLET
+	Network       string          `json:"network,omitempty" example:"Ethereum"`              // Required by NETWORK and WALLET rules
+	WalletAddress string          `json:"wallet_address,omitempty" example:"0x123abc456def"` // Required by WALLET rules
+	Asset         string          `json:"asset,omitempty" example:"ETH"`                     // Required by every kind but COUNT_PER_HOUR
+	Kind          string          `json:"kind" example:"VOLUME_24H"`                         // MAX_AMOUNT, VOLUME_24H, VOLUME_30D, COUNT_PER_HOUR, MIN_RESERVE or APPROVAL_THRESHOLD
+	Value         decimal.Decimal `json:"value" swaggertype:"string" example:"10"`           // Amount, or number of outflows for COUNT_PER_HOUR
+}
+
+type UpdateRequest struct {
+	Value decimal.Decimal `json:"value" swaggertype:"string" example:"25"`
+}
+
+type controller struct {
+	service Service
+}
+
+func NewController(service Service) Controller {
+	return &controller{service: service}
+}
+
+// List godoc
+// @Summary      List withdrawal limits
+// @Description  Lists every withdrawal limit rule
+// @Tags         limits
+// @Produce      json
+// @Success      200  {array}   limits2.Rule
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/limits [get]
+func (c *controller) List(ctx *fiber.Ctx) error {
+	rules, err := c.service.List()
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{Message: err.Error()})
+	}
+
+	return ctx.JSON(rules)
+}
+
+// Get godoc
+// @Summary      Get a withdrawal limit
+// @Tags         limits
+// @Produce      json
+// @Param        id path int true "Rule ID"
+// @Success      200  {object}  limits2.Rule
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/limits/{id} [get]
+func (c *controller) Get(ctx *fiber.Ctx) error {
+	id, err := strconv.Atoi(ctx.Params("id"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Message: "Invalid rule ID"})
+	}
+
+	rule, err := c.service.Get(id)
+	if err != nil {
+		return ctx.Status(errorStatus(err)).JSON(dto.ErrorResponse{Message: err.Error()})
+	}
+
+	return ctx.JSON(rule)
+}
+
+// Create godoc
+// @Summary      Create a withdrawal limit
+// @Description  Adds a rule limiting withdrawals and scheduled transfers out of every wallet, the wallets of a network, or one wallet. Of the rules of one kind and asset that apply to a wallet, only the most specific is enforced.
+// @Tags         limits
+// @Accept       json
+// @Produce      json
+// @Param        rule body CreateRequest true "Rule to create"
+// @Success      201  {object}  limits2.Rule
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      409  {object}  dto.ErrorResponse "A rule of this kind already exists for the scope and asset"
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/limits [post]
+func (c *controller) Create(ctx *fiber.Ctx) error {
+	var req CreateRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Message: "Invalid request payload"})
+	}
+
+	rule, err := c.service.Create(limits2.Rule{
+		Scope:         req.Scope,
+		Network:       req.Network,
+		WalletAddress: req.WalletAddress,
+		Asset:         req.Asset,
+		Kind:          req.Kind,
+		Value:         req.Value,
+	})
+	if err != nil {
+		return ctx.Status(errorStatus(err)).JSON(dto.ErrorResponse{Message: err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(rule)
+}
+
+// Update godoc
+// @Summary      Change a withdrawal limit
+// @Description  Changes the value of a rule. Its scope, asset and kind cannot change.
+// @Tags         limits
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Rule ID"
+// @Param        rule body UpdateRequest true "New value"
+// @Success      200  {object}  limits2.Rule
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/limits/{id} [put]
+func (c *controller) Update(ctx *fiber.Ctx) error {
+	id, err := strconv.Atoi(ctx.Params("id"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Message: "Invalid rule ID"})
+	}
+
+	var req UpdateRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Message: "Invalid request payload"})
+	}
+
+	rule, err := c.service.Update(id, req.Value)
+	if err != nil {
+		return ctx.Status(errorStatus(err)).JSON(dto.ErrorResponse{Message: err.Error()})
+	}
+
+	return ctx.JSON(rule)
+}
+
+// Delete godoc
+// @Summary      Delete a withdrawal limit
+// @Tags         limits
+// @Param        id path int true "Rule ID"
+// @Success      204  "Rule deleted"
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      500  {object}  dto.ErrorResponse
+// @Router       /admin/limits/{id} [delete]
+func (c *controller) Delete(ctx *fiber.Ctx) error {
+	id, err := strconv.Atoi(ctx.Params("id"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Message: "Invalid rule ID"})
+	}
+
+	if err := c.service.Delete(id); err != nil {
+		return ctx.Status(errorStatus(err)).JSON(dto.ErrorResponse{Message: err.Error()})
+	}
+
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrInvalidRule):
+		return fiber.StatusBadRequest
+	case errors.Is(err, ErrNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, ErrRuleExists):
+		return fiber.StatusConflict
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
@@ -0,0 +1,194 @@
+package limits_test
+
+import (
+	limits2 "asset-management/internal/limits"
+	"asset-management/services/asset-api/dto"
+	"asset-management/services/asset-api/limits"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type mockService struct{ mock.Mock }
+
+func (m *mockService) List() ([]limits2.Rule, error) {
+	args := m.Called()
+	if rules, ok := args.Get(0).([]limits2.Rule); ok {
+		return rules, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockService) Get(id int) (*limits2.Rule, error) {
+	args := m.Called(id)
+	if rule, ok := args.Get(0).(*limits2.Rule); ok {
+		return rule, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockService) Create(rule limits2.Rule) (*limits2.Rule, error) {
+	args := m.Called(rule)
+	if created, ok := args.Get(0).(*limits2.Rule); ok {
+		return created, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockService) Update(id int, value decimal.Decimal) (*limits2.Rule, error) {
+	args := m.Called(id, value)
+	if rule, ok := args.Get(0).(*limits2.Rule); ok {
+		return rule, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockService) Delete(id int) error {
+	return m.Called(id).Error(0)
+}
+
+func setupApp(service limits.Service) *fiber.App {
+	app := fiber.New()
+	controller := limits.NewController(service)
+	app.Get("/admin/limits", controller.List)
+	app.Post("/admin/limits", controller.Create)
+	app.Get("/admin/limits/:id", controller.Get)
+	app.Put("/admin/limits/:id", controller.Update)
+	app.Delete("/admin/limits/:id", controller.Delete)
+	return app
+}
+
+func request(method, target string, body interface{}) *http.Request {
+	var payload bytes.Buffer
+	if body != nil {
+		_ = json.NewEncoder(&payload).Encode(body)
+	}
+	req := httptest.NewRequest(method, target, &payload)
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func TestLimitsController_List(t *testing.T) {
+	service := new(mockService)
+	service.On("List").Return([]limits2.Rule{
+		{ID: 1, Scope: limits2.ScopeGlobal, Asset: "ETH", Kind: limits2.KindMaxAmount, Value: decimal.RequireFromString("10")},
+	}, nil)
+
+	resp, err := setupApp(service).Test(request(http.MethodGet, "/admin/limits", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var rules []map[string]interface{}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&rules))
+	assert.Len(t, rules, 1)
+	assert.Equal(t, "MAX_AMOUNT", rules[0]["kind"])
+	assert.Equal(t, "10", rules[0]["value"])
+	assert.NotContains(t, rules[0], "network")
+}
+
+func TestLimitsController_Create(t *testing.T) {
+	service := new(mockService)
+	rule := limits2.Rule{Scope: limits2.ScopeNetwork, Network: "Ethereum", Asset: "ETH", Kind: limits2.KindVolume30d,
+		Value: decimal.RequireFromString("500")}
+	created := rule
+	created.ID = 2
+	service.On("Create", rule).Return(&created, nil)
+
+	resp, err := setupApp(service).Test(request(http.MethodPost, "/admin/limits", limits.CreateRequest{
+		Scope: rule.Scope, Network: rule.Network, Asset: rule.Asset, Kind: rule.Kind, Value: rule.Value,
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var response limits2.Rule
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	assert.Equal(t, 2, response.ID)
+	service.AssertExpectations(t)
+}
+
+func TestLimitsController_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		req    *http.Request
+		setup  func(s *mockService)
+		status int
+	}{
+		{
+			name: "invalid rule",
+			req:  request(http.MethodPost, "/admin/limits", limits.CreateRequest{Scope: "REGION"}),
+			setup: func(s *mockService) {
+				s.On("Create", mock.Anything).Return(nil, fmt.Errorf("%w: bad scope", limits.ErrInvalidRule))
+			},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "duplicate rule",
+			req:    request(http.MethodPost, "/admin/limits", limits.CreateRequest{Scope: limits2.ScopeGlobal}),
+			setup:  func(s *mockService) { s.On("Create", mock.Anything).Return(nil, limits.ErrRuleExists) },
+			status: http.StatusConflict,
+		},
+		{
+			name:   "invalid ID",
+			req:    request(http.MethodGet, "/admin/limits/abc", nil),
+			setup:  func(s *mockService) {},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "unknown rule",
+			req:    request(http.MethodGet, "/admin/limits/9", nil),
+			setup:  func(s *mockService) { s.On("Get", 9).Return(nil, limits.ErrNotFound) },
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "update unknown rule",
+			req:    request(http.MethodPut, "/admin/limits/9", limits.UpdateRequest{Value: decimal.RequireFromString("1")}),
+			setup:  func(s *mockService) { s.On("Update", 9, mock.Anything).Return(nil, limits.ErrNotFound) },
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "delete fails",
+			req:    request(http.MethodDelete, "/admin/limits/9", nil),
+			setup:  func(s *mockService) { s.On("Delete", 9).Return(errors.New("db down")) },
+			status: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := new(mockService)
+			tt.setup(service)
+
+			resp, err := setupApp(service).Test(tt.req)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, resp.StatusCode)
+
+			var response dto.ErrorResponse
+			assert.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+			assert.NotEmpty(t, response.Message)
+		})
+	}
+}
+
+func TestLimitsController_UpdateAndDelete(t *testing.T) {
+	service := new(mockService)
+	service.On("Update", 3, decimal.RequireFromString("25")).Return(&limits2.Rule{ID: 3, Value: decimal.RequireFromString("25")}, nil)
+	service.On("Delete", 3).Return(nil)
+	app := setupApp(service)
+
+	resp, err := app.Test(request(http.MethodPut, "/admin/limits/3", limits.UpdateRequest{Value: decimal.RequireFromString("25")}))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, err = app.Test(request(http.MethodDelete, "/admin/limits/3", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	service.AssertExpectations(t)
+}
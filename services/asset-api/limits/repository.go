@@ -0,0 +1,100 @@
+package limits
+
+import (
+	limits2 "asset-management/internal/limits"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrNotFound   = errors.New("withdrawal limit not found")
+	ErrRuleExists = errors.New("a withdrawal limit of this kind already exists for this scope and asset")
+)
+
+const uniqueViolation = "23505"
+
+type Repository interface {
+	List() ([]limits2.Rule, error)
+	Get(id int) (*limits2.Rule, error)
+	Create(rule limits2.Rule) (*limits2.Rule, error)
+	Update(id int, value decimal.Decimal) (*limits2.Rule, error)
+	Delete(id int) error
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+// List returns every rule, ordered by ID.
+func (r *repository) List() ([]limits2.Rule, error) {
+	rows, err := r.db.Query(`SELECT ` + limits2.Columns + ` FROM withdrawal_limits ORDER BY limit_id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query withdrawal limits: %w", err)
+	}
+	defer rows.Close()
+
+	rules := []limits2.Rule{}
+	for rows.Next() {
+		rule, err := limits2.Scan(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan withdrawal limit: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+func (r *repository) Get(id int) (*limits2.Rule, error) {
+	return r.one(r.db.QueryRow(`SELECT `+limits2.Columns+` FROM withdrawal_limits WHERE limit_id = $1`, id))
+}
+
+// Create stores a rule. It fails with ErrRuleExists if a rule of the same kind, scope and asset exists.
+func (r *repository) Create(rule limits2.Rule) (*limits2.Rule, error) {
+	return r.one(r.db.QueryRow(`
+        INSERT INTO withdrawal_limits (scope, network, wallet_address, asset, kind, value)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        RETURNING `+limits2.Columns, rule.Scope, rule.Network, rule.WalletAddress, rule.Asset, rule.Kind, rule.Value))
+}
+
+// Update changes the value of a rule.
+func (r *repository) Update(id int, value decimal.Decimal) (*limits2.Rule, error) {
+	return r.one(r.db.QueryRow(`
+        UPDATE withdrawal_limits SET value = $2, updated_at = CURRENT_TIMESTAMP
+        WHERE limit_id = $1
+        RETURNING `+limits2.Columns, id, value))
+}
+
+func (r *repository) Delete(id int) error {
+	result, err := r.db.Exec(`DELETE FROM withdrawal_limits WHERE limit_id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete withdrawal limit: %w", err)
+	}
+	if deleted, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to delete withdrawal limit: %w", err)
+	} else if deleted == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *repository) one(row *sql.Row) (*limits2.Rule, error) {
+	rule, err := limits2.Scan(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+		return nil, ErrRuleExists
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query withdrawal limit: %w", err)
+	}
+	return &rule, nil
+}
@@ -0,0 +1,47 @@
+package limits_test
+
+import (
+	limits2 "asset-management/internal/limits"
+	"asset-management/services/asset-api/limits"
+	"asset-management/services/asset-api/util"
+	_ "github.com/lib/pq"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRepository_Rules(t *testing.T) {
+	db, cleanup := util.SetupTestContainer(t)
+	defer cleanup()
+
+	repo := limits.NewRepository(db)
+	rule := limits2.Rule{Scope: limits2.ScopeWallet, Network: "Ethereum", WalletAddress: "0x123", Asset: "ETH",
+		Kind: limits2.KindVolume24h, Value: decimal.RequireFromString("10")}
+
+	created, err := repo.Create(rule)
+	assert.NoError(t, err)
+	assert.NotZero(t, created.ID)
+	assert.Equal(t, "10", created.Value.String())
+
+	// One rule per kind, scope and asset
+	_, err = repo.Create(rule)
+	assert.ErrorIs(t, err, limits.ErrRuleExists)
+
+	updated, err := repo.Update(created.ID, decimal.RequireFromString("25"))
+	assert.NoError(t, err)
+	assert.Equal(t, "25", updated.Value.String())
+	assert.False(t, updated.UpdatedAt.Before(created.UpdatedAt))
+
+	rules, err := repo.List()
+	assert.NoError(t, err)
+	assert.Len(t, rules, 1)
+	assert.Equal(t, "0x123", rules[0].WalletAddress)
+
+	assert.NoError(t, repo.Delete(created.ID))
+	assert.ErrorIs(t, repo.Delete(created.ID), limits.ErrNotFound)
+
+	_, err = repo.Get(created.ID)
+	assert.ErrorIs(t, err, limits.ErrNotFound)
+	_, err = repo.Update(created.ID, decimal.RequireFromString("1"))
+	assert.ErrorIs(t, err, limits.ErrNotFound)
+}
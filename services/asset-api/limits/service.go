@@ -0,0 +1,105 @@
+package limits
+
+import (
+	limits2 "asset-management/internal/limits"
+	"asset-management/services/asset-api/wallet"
+	"errors"
+	"fmt"
+	"github.com/shopspring/decimal"
+	"slices"
+)
+
+// ErrInvalidRule wraps every validation error of a rule.
+var ErrInvalidRule = errors.New("invalid withdrawal limit")
+
+type Service interface {
+	List() ([]limits2.Rule, error)
+	Get(id int) (*limits2.Rule, error)
+	Create(rule limits2.Rule) (*limits2.Rule, error)
+	Update(id int, value decimal.Decimal) (*limits2.Rule, error)
+	Delete(id int) error
+}
+
+type service struct {
+	repository Repository
+}
+
+func NewService(repository Repository) Service {
+	return &service{repository: repository}
+}
+
+func (s *service) List() ([]limits2.Rule, error) {
+	return s.repository.List()
+}
+
+func (s *service) Get(id int) (*limits2.Rule, error) {
+	return s.repository.Get(id)
+}
+
+func (s *service) Create(rule limits2.Rule) (*limits2.Rule, error) {
+	if err := validateScope(rule); err != nil {
+		return nil, err
+	}
+	// Outflows are checked under the canonical network and address, which the rule must match
+	if rule.Scope == limits2.ScopeWallet {
+		rule.Network, rule.WalletAddress = wallet.Canonical(rule.Network, rule.WalletAddress)
+	} else {
+		rule.Network = wallet.CanonicalNetwork(rule.Network)
+	}
+	if !slices.Contains(limits2.Kinds, rule.Kind) {
+		return nil, fmt.Errorf("%w: kind must be one of %v", ErrInvalidRule, limits2.Kinds)
+	}
+	if rule.IsAmount() && rule.Asset == "" {
+		return nil, fmt.Errorf("%w: asset is required for %s", ErrInvalidRule, rule.Kind)
+	}
+	if err := validateValue(rule, rule.Value); err != nil {
+		return nil, err
+	}
+	return s.repository.Create(rule)
+}
+
+// Update changes the value of a rule. Its scope, asset and kind cannot change; replace the rule instead.
+func (s *service) Update(id int, value decimal.Decimal) (*limits2.Rule, error) {
+	rule, err := s.repository.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateValue(*rule, value); err != nil {
+		return nil, err
+	}
+	return s.repository.Update(id, value)
+}
+
+func (s *service) Delete(id int) error {
+	return s.repository.Delete(id)
+}
+
+func validateScope(rule limits2.Rule) error {
+	switch rule.Scope {
+	case limits2.ScopeGlobal:
+		if rule.Network != "" || rule.WalletAddress != "" {
+			return fmt.Errorf("%w: GLOBAL rules take no network or wallet_address", ErrInvalidRule)
+		}
+	case limits2.ScopeNetwork:
+		if rule.Network == "" || rule.WalletAddress != "" {
+			return fmt.Errorf("%w: NETWORK rules take a network and no wallet_address", ErrInvalidRule)
+		}
+	case limits2.ScopeWallet:
+		if rule.Network == "" || rule.WalletAddress == "" {
+			return fmt.Errorf("%w: WALLET rules take a network and a wallet_address", ErrInvalidRule)
+		}
+	default:
+		return fmt.Errorf("%w: scope must be GLOBAL, NETWORK or WALLET", ErrInvalidRule)
+	}
+	return nil
+}
+
+func validateValue(rule limits2.Rule, value decimal.Decimal) error {
+	if value.IsNegative() {
+		return fmt.Errorf("%w: value must not be negative", ErrInvalidRule)
+	}
+	if !rule.IsAmount() && !value.IsInteger() {
+		return fmt.Errorf("%w: value of %s must be a whole number", ErrInvalidRule, rule.Kind)
+	}
+	return nil
+}
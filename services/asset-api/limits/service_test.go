@@ -0,0 +1,168 @@
+package limits_test
+
+import (
+	limits2 "asset-management/internal/limits"
+	"asset-management/services/asset-api/limits"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+)
+
+type mockRepository struct{ mock.Mock }
+
+func (m *mockRepository) List() ([]limits2.Rule, error) {
+	args := m.Called()
+	if rules, ok := args.Get(0).([]limits2.Rule); ok {
+		return rules, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockRepository) Get(id int) (*limits2.Rule, error) {
+	args := m.Called(id)
+	if rule, ok := args.Get(0).(*limits2.Rule); ok {
+		return rule, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockRepository) Create(rule limits2.Rule) (*limits2.Rule, error) {
+	args := m.Called(rule)
+	if created, ok := args.Get(0).(*limits2.Rule); ok {
+		return created, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockRepository) Update(id int, value decimal.Decimal) (*limits2.Rule, error) {
+	args := m.Called(id, value)
+	if rule, ok := args.Get(0).(*limits2.Rule); ok {
+		return rule, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockRepository) Delete(id int) error {
+	return m.Called(id).Error(0)
+}
+
+func TestLimitsService_Create(t *testing.T) {
+	rule := limits2.Rule{Scope: limits2.ScopeWallet, Network: "Ethereum", WalletAddress: "0x123", Asset: "ETH",
+		Kind: limits2.KindVolume24h, Value: decimal.RequireFromString("10")}
+	repo := new(mockRepository)
+	repo.On("Create", rule).Return(&limits2.Rule{ID: 1}, nil)
+
+	created, err := limits.NewService(repo).Create(rule)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, created.ID)
+	repo.AssertExpectations(t)
+}
+
+func TestLimitsService_Create_CanonicalWallet(t *testing.T) {
+	repo := new(mockRepository)
+	repo.On("Create", limits2.Rule{Scope: limits2.ScopeWallet, Network: "Ethereum", WalletAddress: "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed",
+		Asset: "ETH", Kind: limits2.KindMaxAmount, Value: decimal.RequireFromString("5")}).Return(&limits2.Rule{ID: 1}, nil)
+	repo.On("Create", limits2.Rule{Scope: limits2.ScopeNetwork, Network: "Ethereum",
+		Asset: "ETH", Kind: limits2.KindMaxAmount, Value: decimal.RequireFromString("5")}).Return(&limits2.Rule{ID: 2}, nil)
+	service := limits.NewService(repo)
+
+	// Rules apply to the network and address outflows are checked under, however they are spelled
+	_, err := service.Create(limits2.Rule{Scope: limits2.ScopeWallet, Network: "ETH", WalletAddress: "0x5AAEB6053F3E94C9B9A09F33669435E7EF1BEAED",
+		Asset: "ETH", Kind: limits2.KindMaxAmount, Value: decimal.RequireFromString("5")})
+	assert.NoError(t, err)
+	_, err = service.Create(limits2.Rule{Scope: limits2.ScopeNetwork, Network: "eth",
+		Asset: "ETH", Kind: limits2.KindMaxAmount, Value: decimal.RequireFromString("5")})
+	assert.NoError(t, err)
+
+	repo.AssertExpectations(t)
+}
+
+func TestLimitsService_Create_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		rule limits2.Rule
+		err  string
+	}{
+		{
+			name: "unknown scope",
+			rule: limits2.Rule{Scope: "REGION", Asset: "ETH", Kind: limits2.KindMaxAmount},
+			err:  "invalid withdrawal limit: scope must be GLOBAL, NETWORK or WALLET",
+		},
+		{
+			name: "global rule with network",
+			rule: limits2.Rule{Scope: limits2.ScopeGlobal, Network: "Ethereum", Asset: "ETH", Kind: limits2.KindMaxAmount},
+			err:  "invalid withdrawal limit: GLOBAL rules take no network or wallet_address",
+		},
+		{
+			name: "network rule without network",
+			rule: limits2.Rule{Scope: limits2.ScopeNetwork, Asset: "ETH", Kind: limits2.KindMaxAmount},
+			err:  "invalid withdrawal limit: NETWORK rules take a network and no wallet_address",
+		},
+		{
+			name: "wallet rule without wallet",
+			rule: limits2.Rule{Scope: limits2.ScopeWallet, Network: "Ethereum", Asset: "ETH", Kind: limits2.KindMaxAmount},
+			err:  "invalid withdrawal limit: WALLET rules take a network and a wallet_address",
+		},
+		{
+			name: "unknown kind",
+			rule: limits2.Rule{Scope: limits2.ScopeGlobal, Asset: "ETH", Kind: "VOLUME_1Y"},
+			err:  "invalid withdrawal limit: kind must be one of [MAX_AMOUNT VOLUME_24H VOLUME_30D COUNT_PER_HOUR MIN_RESERVE APPROVAL_THRESHOLD]",
+		},
+		{
+			name: "amount without asset",
+			rule: limits2.Rule{Scope: limits2.ScopeGlobal, Kind: limits2.KindMinReserve},
+			err:  "invalid withdrawal limit: asset is required for MIN_RESERVE",
+		},
+		{
+			name: "negative value",
+			rule: limits2.Rule{Scope: limits2.ScopeGlobal, Asset: "ETH", Kind: limits2.KindMaxAmount, Value: decimal.RequireFromString("-1")},
+			err:  "invalid withdrawal limit: value must not be negative",
+		},
+		{
+			name: "fractional count",
+			rule: limits2.Rule{Scope: limits2.ScopeGlobal, Kind: limits2.KindCountPerHour, Value: decimal.RequireFromString("1.5")},
+			err:  "invalid withdrawal limit: value of COUNT_PER_HOUR must be a whole number",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(mockRepository)
+
+			created, err := limits.NewService(repo).Create(tt.rule)
+
+			assert.EqualError(t, err, tt.err)
+			assert.ErrorIs(t, err, limits.ErrInvalidRule)
+			assert.Nil(t, created)
+			repo.AssertNotCalled(t, "Create", mock.Anything)
+		})
+	}
+}
+
+func TestLimitsService_Update(t *testing.T) {
+	repo := new(mockRepository)
+	repo.On("Get", 4).Return(&limits2.Rule{ID: 4, Kind: limits2.KindCountPerHour}, nil)
+	repo.On("Update", 4, decimal.RequireFromString("5")).Return(&limits2.Rule{ID: 4}, nil)
+
+	service := limits.NewService(repo)
+
+	_, err := service.Update(4, decimal.RequireFromString("2.5"))
+	assert.ErrorIs(t, err, limits.ErrInvalidRule)
+
+	updated, err := service.Update(4, decimal.RequireFromString("5"))
+	assert.NoError(t, err)
+	assert.Equal(t, 4, updated.ID)
+	repo.AssertExpectations(t)
+}
+
+func TestLimitsService_Update_NotFound(t *testing.T) {
+	repo := new(mockRepository)
+	repo.On("Get", 4).Return(nil, limits.ErrNotFound)
+
+	_, err := limits.NewService(repo).Update(4, decimal.RequireFromString("5"))
+
+	assert.ErrorIs(t, err, limits.ErrNotFound)
+	repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
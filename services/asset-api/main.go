@@ -1,15 +1,23 @@
 package main
 
 import (
+	approval2 "asset-management/internal/approval"
 	"asset-management/internal/schedule/scheduled_next"
 	"asset-management/internal/schedule/scheduled_process"
 	sql2 "asset-management/internal/sql"
 	"asset-management/pkg/app"
 	"asset-management/pkg/database"
 	"asset-management/pkg/logger"
+	"asset-management/services/asset-api/approval"
+	"asset-management/services/asset-api/balance"
 	deposit2 "asset-management/services/asset-api/deposit"
 	_ "asset-management/services/asset-api/docs"
+	"asset-management/services/asset-api/holdings"
+	"asset-management/services/asset-api/idempotency"
+	"asset-management/services/asset-api/ledger"
+	"asset-management/services/asset-api/limits"
 	"asset-management/services/asset-api/scheduled"
+	"asset-management/services/asset-api/transfer"
 	"asset-management/services/asset-api/wallet"
 	"asset-management/services/asset-api/withdraw"
 	"database/sql"
@@ -19,6 +27,7 @@ import (
 	"github.com/rs/zerolog/log"
 	fiberSwagger "github.com/swaggo/fiber-swagger"
 	"os"
+	"strconv"
 )
 
 // @title Asset Service API
@@ -56,6 +65,8 @@ func main() {
 	appInstance.AddRoute("/swagger/*", fiberSwagger.WrapHandler)
 
 	walletValidator := wallet.NewValidationAdapter(os.Getenv("WALLET_API"))
+	idempotent := idempotency.NewMiddleware(idempotency.NewRepository(db.Conn))
+
 	depositR := deposit2.NewRepository(db.Conn)
 	depositS := deposit2.NewService(walletValidator, depositR)
 	depositC := deposit2.NewController(depositS)
@@ -64,23 +75,84 @@ func main() {
 	withdrawS := withdraw.NewService(withdrawR, walletValidator)
 	withdrawC := withdraw.NewController(withdrawS)
 
+	transferR := transfer.NewRepository(db.Conn)
+	transferS := transfer.NewService(transferR, walletValidator)
+	transferC := transfer.NewController(transferS)
+
 	createScheduledR := scheduled.NewCreateRepository(db.Conn)
 	createScheduledS := scheduled.NewCreateService(createScheduledR, walletValidator)
 	createScheduledC := scheduled.NewCreateController(createScheduledS)
 
+	updateScheduledR := scheduled.NewUpdateRepository(db.Conn)
+	updateScheduledS := scheduled.NewUpdateService(updateScheduledR)
+	updateScheduledC := scheduled.NewUpdateController(updateScheduledS)
+
+	queryScheduledR := scheduled.NewQueryRepository(db.Conn)
+	queryScheduledS := scheduled.NewQueryService(queryScheduledR)
+	queryScheduledC := scheduled.NewQueryController(queryScheduledS)
+
+	occurrencesScheduledR := scheduled.NewOccurrencesRepository(db.Conn)
+	occurrencesScheduledS := scheduled.NewOccurrencesService(occurrencesScheduledR)
+	occurrencesScheduledC := scheduled.NewOccurrencesController(occurrencesScheduledS)
+
 	nextScheduledR := scheduled_next.NewNextRepository(db.Conn)
 	nextScheduledS := scheduled_next.NewNextService(nextScheduledR)
 	nextScheduledC := scheduled.NewNextController(nextScheduledS)
 
 	processScheduledR := scheduled_process.NewProcessRepository(db.Conn)
-	processScheduledS := scheduled_process.NewProcessService(processScheduledR)
+	processScheduledS := scheduled_process.NewProcessService(processScheduledR, walletValidator)
 	processScheduledC := scheduled.NewProcessController(processScheduledS)
 
-	appInstance.Fiber.Post("/deposit", depositC.Deposit)
-	appInstance.Fiber.Post("/withdraw", withdrawC.Withdraw)
-	appInstance.Fiber.Post("/scheduled-transaction", createScheduledC.Create)
+	holdingsR := holdings.NewRepository(db.Conn)
+	holdingsS := holdings.NewService(holdingsR, walletValidator)
+	holdingsC := holdings.NewController(holdingsS)
+
+	ledgerR := ledger.NewRepository(db.Conn)
+	ledgerS := ledger.NewService(ledgerR)
+	ledgerC := ledger.NewController(ledgerS)
+
+	balanceR := balance.NewRepository(db.Conn)
+	balanceS := balance.NewService(balanceR)
+	balanceC := balance.NewController(balanceS)
+
+	limitsR := limits.NewRepository(db.Conn)
+	limitsS := limits.NewService(limitsR)
+	limitsC := limits.NewController(limitsS)
+
+	requiredApprovals := approval2.DefaultRequiredApprovals
+	if value, err := strconv.Atoi(os.Getenv("REQUIRED_APPROVALS")); err == nil && value > 0 {
+		requiredApprovals = value
+	}
+	approvalR := approval.NewRepository(db.Conn)
+	approvalS := approval.NewService(approvalR, walletValidator, requiredApprovals)
+	approvalC := approval.NewController(approvalS)
+
+	appInstance.Fiber.Post("/deposit", idempotent, depositC.Deposit)
+	appInstance.Fiber.Post("/withdraw", idempotent, withdrawC.Withdraw)
+	appInstance.Fiber.Post("/transfer", idempotent, transferC.Transfer)
+	appInstance.Fiber.Post("/scheduled-transaction", idempotent, createScheduledC.Create)
+	appInstance.Fiber.Get("/scheduled-transaction", queryScheduledC.List)
+	// Registered before /scheduled-transaction/:id, which would match "next" as an ID
 	appInstance.Fiber.Get("/scheduled-transaction/next", nextScheduledC.GetNextMinuteTransactions)
+	appInstance.Fiber.Get("/scheduled-transaction/:id", queryScheduledC.Get)
+	appInstance.Fiber.Delete("/scheduled-transaction/:id", updateScheduledC.Cancel)
+	appInstance.Fiber.Patch("/scheduled-transaction/:id", updateScheduledC.Reschedule)
 	appInstance.Fiber.Post("/scheduled-transaction/:id/process", processScheduledC.Process)
+	appInstance.Fiber.Get("/scheduled-transaction/:id/occurrences", occurrencesScheduledC.GetOccurrences)
+	appInstance.Fiber.Get("/wallet/:network/:address/ledger", ledgerC.GetLedger)
+	appInstance.Fiber.Get("/wallet/:network/:address/holdings", holdingsC.GetHoldings)
+	appInstance.Fiber.Post("/wallet/:network/:address/sweep", idempotent, holdingsC.Sweep)
+	appInstance.Fiber.Get("/balance/:network/:address", balanceC.GetBalance)
+	appInstance.Fiber.Post("/balance/query", balanceC.Query)
+	appInstance.Fiber.Get("/admin/limits", limitsC.List)
+	appInstance.Fiber.Post("/admin/limits", limitsC.Create)
+	appInstance.Fiber.Get("/admin/limits/:id", limitsC.Get)
+	appInstance.Fiber.Put("/admin/limits/:id", limitsC.Update)
+	appInstance.Fiber.Delete("/admin/limits/:id", limitsC.Delete)
+	appInstance.Fiber.Get("/approvals", approvalC.List)
+	appInstance.Fiber.Get("/approvals/:id", approvalC.Get)
+	appInstance.Fiber.Post("/approvals/:id/approve", approvalC.Approve)
+	appInstance.Fiber.Post("/approvals/:id/reject", approvalC.Reject)
 
 	log.Info().Msg("Asset Service is running on port 8081")
 	appInstance.Start(":8001")
@@ -100,5 +172,37 @@ func CreateTables(db *sql.DB) error {
 		return fmt.Errorf("failed to create scheduled transactions table: %w", schErr)
 	}
 
+	if _, ledgerErr := db.Exec(sql2.CreateLedgerEntriesTable); ledgerErr != nil {
+		return fmt.Errorf("failed to create ledger entries table: %w", ledgerErr)
+	}
+
+	if _, idemErr := db.Exec(sql2.CreateIdempotencyKeysTable); idemErr != nil {
+		return fmt.Errorf("failed to create idempotency keys table: %w", idemErr)
+	}
+
+	if _, outboxErr := db.Exec(sql2.CreateEventOutboxTable); outboxErr != nil {
+		return fmt.Errorf("failed to create event outbox table: %w", outboxErr)
+	}
+
+	if _, limitsErr := db.Exec(sql2.CreateWithdrawalLimitsTable); limitsErr != nil {
+		return fmt.Errorf("failed to create withdrawal limits table: %w", limitsErr)
+	}
+
+	if _, approvalErr := db.Exec(sql2.CreateApprovalRequestsTable); approvalErr != nil {
+		return fmt.Errorf("failed to create approval requests table: %w", approvalErr)
+	}
+
+	if _, aliasErr := db.Exec(sql2.CreateWalletAliasesTable); aliasErr != nil {
+		return fmt.Errorf("failed to create wallet aliases table: %w", aliasErr)
+	}
+
+	if _, migrationsErr := db.Exec(sql2.CreateSchemaMigrationsTable); migrationsErr != nil {
+		return fmt.Errorf("failed to create schema migrations table: %w", migrationsErr)
+	}
+
+	if keysErr := wallet.CanonicalizeKeys(db); keysErr != nil {
+		return fmt.Errorf("failed to canonicalize wallet keys: %w", keysErr)
+	}
+
 	return nil
 }
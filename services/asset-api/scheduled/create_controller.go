@@ -1,7 +1,14 @@
 package scheduled
 
 import (
+	"asset-management/internal/approval"
+	"asset-management/internal/funds"
+	"asset-management/internal/limits"
+	"asset-management/internal/schedule"
+	"asset-management/services/asset-api/wallet"
+	"errors"
 	"github.com/gofiber/fiber/v2"
+	"github.com/shopspring/decimal"
 	"time"
 )
 
@@ -14,22 +21,33 @@ func NewCreateController(service CreateService) *CreateController {
 }
 
 type Request struct {
-	From          string  `json:"from" example:"wallet123"`
-	To            string  `json:"to" example:"wallet456"`
-	Network       string  `json:"network" example:"mainnet"`
-	Amount        float64 `json:"amount" example:"100.50"`
-	ScheduledTime string  `json:"scheduled_time" example:"2023-12-31T12:00:00Z"`
+	From          string          `json:"from" example:"wallet123"`
+	To            string          `json:"to" example:"wallet456"`
+	Network       string          `json:"network" example:"mainnet"`
+	Asset         string          `json:"asset" example:"ETH"`
+	Amount        decimal.Decimal `json:"amount" swaggertype:"string" example:"100.50"`
+	ScheduledTime string          `json:"scheduled_time" example:"2023-12-31T12:00:00Z"`
+	// Optional cron expression or RRULE that repeats the transfer, starting at ScheduledTime
+	Recurrence     string `json:"recurrence,omitempty" example:"0 9 * * MON"`
+	RecurrenceEnd  string `json:"recurrence_end,omitempty" example:"2024-12-31T00:00:00Z"`
+	MaxOccurrences *int   `json:"max_occurrences,omitempty" example:"12"`
+	// Who requests the transfer. Required above an approval threshold, as the requester may not approve it
+	RequestedBy string `json:"requested_by,omitempty" example:"alice@example.com"`
 }
 
 // Create godoc
 // @Summary      Create a new scheduled transaction
-// @Description  Schedules a new transaction to be executed at a specified future time
+// @Description  Schedules a new transaction to be executed at a specified future time, optionally repeating it on a cron or RRULE recurrence
 // @Tags         ScheduledTransaction
 // @Accept       json
 // @Produce      json
 // @Param        transaction body Request true "Schedule Transfer request payload"
-// @Success      201  {object}  map[string]int "Created transaction ID"  example: {"transaction_id": 123}
-// @Failure      400  {object}  map[string]string "Invalid request payload or scheduled time format" example: {"error": "Invalid scheduled time format"}
+// @Param        Idempotency-Key header string false "Key that makes retries of this request safe"
+// @Success      201  {object}  map[string]interface{} "Created transaction ID and status, AWAITING_APPROVAL above an approval threshold"  example: {"transaction_id": 123, "status": "PENDING"}
+// @Failure      400  {object}  map[string]string "Invalid request payload, scheduled time format, or available balance of the source wallet too low" example: {"error": "Invalid scheduled time format"}
+// @Failure      409  {object}  map[string]string "A wallet is being deleted, or Idempotency-Key reused with a different payload or still in progress"
+// @Failure      403  {object}  map[string]string "Source wallet is frozen" example: {"error": "source wallet validation failed: wallet is frozen"}
+// @Failure      422  {object}  map[string]string "Withdrawal limit exceeded" example: {"error": "withdrawal limit exceeded: global rule 1 allows at most 10 ETH per transaction"}
 // @Failure      500  {object}  map[string]string "Failed to create scheduled transaction" example: {"error": "Failed to create scheduled transaction"}
 // @Router       /scheduled-transaction [post]
 func (c *CreateController) Create(ctx *fiber.Ctx) error {
@@ -43,10 +61,31 @@ func (c *CreateController) Create(ctx *fiber.Ctx) error {
 		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid scheduled time format"})
 	}
 
-	id, err := c.service.Create(req.From, req.To, req.Network, req.Amount, scheduledTime)
+	rec := schedule.Recurrence{Rule: req.Recurrence, MaxOccurrences: req.MaxOccurrences}
+	if req.RecurrenceEnd != "" {
+		until, err := time.Parse(time.RFC3339, req.RecurrenceEnd)
+		if err != nil {
+			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid recurrence end format"})
+		}
+		rec.Until = &until
+	}
+
+	txn, err := c.service.Create(req.From, req.To, req.Network, req.Asset, req.Amount, scheduledTime, rec, req.RequestedBy)
 	if err != nil {
+		if errors.Is(err, wallet.ErrWalletFrozen) {
+			return ctx.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+		}
+		if errors.Is(err, wallet.ErrWalletDeleting) {
+			return ctx.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+		}
+		if errors.Is(err, funds.ErrInsufficientBalance) || errors.Is(err, funds.ErrFundsHeld) || errors.Is(err, approval.ErrRequesterRequired) {
+			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		if errors.Is(err, limits.ErrLimitExceeded) {
+			return ctx.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{"error": err.Error()})
+		}
 		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{"transaction_id": id})
+	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{"transaction_id": txn.ID, "status": txn.Status})
 }
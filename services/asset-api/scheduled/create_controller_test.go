@@ -1,10 +1,13 @@
 package scheduled_test
 
 import (
+	"asset-management/internal/limits"
+	"asset-management/internal/schedule"
 	"asset-management/services/asset-api/scheduled"
 	"bytes"
 	"encoding/json"
 	"github.com/gofiber/fiber/v2"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"net/http"
@@ -17,9 +20,13 @@ type MockCreateService struct {
 	mock.Mock
 }
 
-func (m *MockCreateService) Create(fromWallet, toWallet, network string, amount float64, scheduledTime time.Time) (int, error) {
-	args := m.Called(fromWallet, toWallet, network, amount, scheduledTime)
-	return args.Int(0), args.Error(1)
+func (m *MockCreateService) Create(fromWallet, toWallet, network, asset string, amount decimal.Decimal, scheduledTime time.Time, rec schedule.Recurrence,
+	requestedBy string) (*schedule.ScheduledTransaction, error) {
+	args := m.Called(fromWallet, toWallet, network, asset, amount, scheduledTime, rec, requestedBy)
+	if txn, ok := args.Get(0).(*schedule.ScheduledTransaction); ok {
+		return txn, args.Error(1)
+	}
+	return nil, args.Error(1)
 }
 
 func TestCreateController_Success(t *testing.T) {
@@ -32,12 +39,14 @@ func TestCreateController_Success(t *testing.T) {
 		From:          "wallet123",
 		To:            "wallet456",
 		Network:       "mainnet",
-		Amount:        100.50,
+		Asset:         "ETH",
+		Amount:        decimal.RequireFromString("100.5"),
 		ScheduledTime: "2023-12-31T12:00:00Z",
 	}
 	reqBody, _ := json.Marshal(reqPayload)
 
-	mockService.On("Create", "wallet123", "wallet456", "mainnet", 100.50, time.Date(2023, 12, 31, 12, 0, 0, 0, time.UTC)).Return(123, nil)
+	mockService.On("Create", "wallet123", "wallet456", "mainnet", "ETH", decimal.RequireFromString("100.5"), time.Date(2023, 12, 31, 12, 0, 0, 0, time.UTC), schedule.Recurrence{}, "").
+		Return(&schedule.ScheduledTransaction{ID: 123, Status: schedule.StatusPending}, nil)
 
 	req := httptest.NewRequest(http.MethodPost, "/scheduled-transaction", bytes.NewBuffer(reqBody))
 	req.Header.Set("Content-Type", "application/json")
@@ -45,9 +54,10 @@ func TestCreateController_Success(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, http.StatusCreated, resp.StatusCode)
 
-	var response map[string]int
+	var response map[string]interface{}
 	json.NewDecoder(resp.Body).Decode(&response)
-	assert.Equal(t, 123, response["transaction_id"])
+	assert.Equal(t, float64(123), response["transaction_id"])
+	assert.Equal(t, "PENDING", response["status"])
 
 	mockService.AssertExpectations(t)
 }
@@ -62,7 +72,8 @@ func TestCreateController_InvalidScheduledTime(t *testing.T) {
 		From:          "wallet123",
 		To:            "wallet456",
 		Network:       "mainnet",
-		Amount:        100.50,
+		Asset:         "ETH",
+		Amount:        decimal.RequireFromString("100.5"),
 		ScheduledTime: "invalid-time-format",
 	}
 	reqBody, _ := json.Marshal(reqPayload)
@@ -77,3 +88,67 @@ func TestCreateController_InvalidScheduledTime(t *testing.T) {
 	json.NewDecoder(resp.Body).Decode(&response)
 	assert.Equal(t, "Invalid scheduled time format", response["error"])
 }
+
+func TestCreateController_Recurring(t *testing.T) {
+	mockService := new(MockCreateService)
+	controller := scheduled.NewCreateController(mockService)
+	app := fiber.New()
+	app.Post("/scheduled-transaction", controller.Create)
+
+	maxOccurrences := 12
+	reqPayload := scheduled.Request{
+		From:           "wallet123",
+		To:             "wallet456",
+		Network:        "mainnet",
+		Asset:          "ETH",
+		Amount:         decimal.RequireFromString("100.5"),
+		ScheduledTime:  "2023-12-31T12:00:00Z",
+		Recurrence:     "0 12 * * SUN",
+		RecurrenceEnd:  "2024-12-31T00:00:00Z",
+		MaxOccurrences: &maxOccurrences,
+	}
+	reqBody, _ := json.Marshal(reqPayload)
+
+	until := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+	mockService.On("Create", "wallet123", "wallet456", "mainnet", "ETH", decimal.RequireFromString("100.5"), time.Date(2023, 12, 31, 12, 0, 0, 0, time.UTC),
+		schedule.Recurrence{Rule: "0 12 * * SUN", Until: &until, MaxOccurrences: &maxOccurrences}, "").Return(&schedule.ScheduledTransaction{ID: 123}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/scheduled-transaction", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	mockService.AssertExpectations(t)
+}
+
+func TestCreateController_LimitExceeded(t *testing.T) {
+	mockService := new(MockCreateService)
+	controller := scheduled.NewCreateController(mockService)
+	app := fiber.New()
+	app.Post("/scheduled-transaction", controller.Create)
+
+	reqBody, _ := json.Marshal(scheduled.Request{
+		From:          "wallet123",
+		To:            "wallet456",
+		Network:       "mainnet",
+		Asset:         "ETH",
+		Amount:        decimal.RequireFromString("100.5"),
+		ScheduledTime: "2023-12-31T12:00:00Z",
+	})
+
+	violation := &limits.Violation{
+		Rule:   limits.Rule{ID: 4, Scope: limits.ScopeNetwork, Network: "mainnet", Kind: limits.KindCountPerHour},
+		Detail: "at most 3 outflows per hour, 3 already made",
+	}
+	mockService.On("Create", "wallet123", "wallet456", "mainnet", "ETH", mock.Anything, mock.Anything, mock.Anything, "").Return(nil, violation)
+
+	req := httptest.NewRequest(http.MethodPost, "/scheduled-transaction", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+
+	var response map[string]string
+	json.NewDecoder(resp.Body).Decode(&response)
+	assert.Equal(t, "withdrawal limit exceeded: rule 4 for network mainnet allows at most 3 outflows per hour, 3 already made", response["error"])
+}
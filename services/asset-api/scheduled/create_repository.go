@@ -1,13 +1,17 @@
 package scheduled
 
 import (
+	"asset-management/internal/approval"
+	"asset-management/internal/funds"
+	"asset-management/internal/limits"
 	"asset-management/internal/schedule"
+	"context"
 	"database/sql"
 	"fmt"
 )
 
 type CreateRepository interface {
-	Create(tx *schedule.ScheduledTransaction) (int, error)
+	Create(tx *schedule.ScheduledTransaction, requestedBy string) (int, error)
 }
 
 type postgresCreateRepository struct {
@@ -18,16 +22,80 @@ func NewCreateRepository(db *sql.DB) CreateRepository {
 	return &postgresCreateRepository{db: db}
 }
 
-// Create inserts a new scheduled transaction into the database.
-func (r *postgresCreateRepository) Create(tx *schedule.ScheduledTransaction) (int, error) {
+// Create inserts a new scheduled transaction into the database. The transaction holds its amount in
+// the sender's wallet, so it fails with funds.ErrInsufficientBalance or funds.ErrFundsHeld unless
+// that much is available, or with a limits.Violation if it breaks a withdrawal limit. A transaction
+// above an approval threshold is stored as AWAITING_APPROVAL, with an approval request, and tx.Status
+// is set accordingly.
+func (r *postgresCreateRepository) Create(tx *schedule.ScheduledTransaction, requestedBy string) (int, error) {
+	ctx := context.Background()
+
+	dbTx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer dbTx.Rollback()
+
+	sender, err := funds.Lock(ctx, dbTx, tx.FromWallet, tx.Network, tx.Asset)
+	if err != nil {
+		return 0, err
+	}
+	if err := sender.Cover(tx.Amount, tx.Asset); err != nil {
+		return 0, err
+	}
+
+	outflow := limits.Outflow{
+		WalletAddress: tx.FromWallet,
+		Network:       tx.Network,
+		Asset:         tx.Asset,
+		Amount:        tx.Amount,
+		Funds:         *sender,
+	}
+	if err := limits.Check(ctx, dbTx, outflow); err != nil {
+		return 0, err
+	}
+
+	threshold, err := limits.ApprovalThreshold(ctx, dbTx, outflow)
+	if err != nil {
+		return 0, err
+	}
+	status := tx.Status
+	if threshold != nil {
+		status = schedule.StatusAwaitingApproval
+	}
+
 	query := `
-		INSERT INTO scheduled_transactions (from_wallet_address, to_wallet_address, network, amount, scheduled_time, status)
-		VALUES ($1, $2, $3, $4, $5, $6) RETURNING scheduled_transaction_id
+		INSERT INTO scheduled_transactions (from_wallet_address, to_wallet_address, network, asset, amount, scheduled_time, status,
+		                                    recurrence, recurrence_end, max_occurrences, recurrence_start)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), $9, $10, CASE WHEN $8 <> '' THEN $6::TIMESTAMP END)
+		RETURNING scheduled_transaction_id
 	`
 	var id int
-	err := r.db.QueryRow(query, tx.FromWallet, tx.ToWallet, tx.Network, tx.Amount, tx.ScheduledTime, tx.Status).Scan(&id)
+	err = dbTx.QueryRowContext(ctx, query, tx.FromWallet, tx.ToWallet, tx.Network, tx.Asset, tx.Amount, tx.ScheduledTime, status,
+		tx.Rule, tx.Until, tx.MaxOccurrences).Scan(&id)
 	if err != nil {
 		return 0, fmt.Errorf("failed to insert scheduled transaction: %v", err)
 	}
+
+	if threshold != nil {
+		_, err = approval.Open(ctx, dbTx, approval.Request{
+			Operation:              approval.OperationScheduledTransfer,
+			WalletAddress:          tx.FromWallet,
+			Network:                tx.Network,
+			Asset:                  tx.Asset,
+			Amount:                 tx.Amount,
+			ScheduledTransactionID: &id,
+			RuleID:                 threshold.ID,
+			RequestedBy:            requestedBy,
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit scheduled transaction: %w", err)
+	}
+	tx.Status = status
 	return id, nil
 }
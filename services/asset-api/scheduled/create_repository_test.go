@@ -1,10 +1,14 @@
 package scheduled_test
 
 import (
+	"asset-management/internal/approval"
+	"asset-management/internal/funds"
+	"asset-management/internal/limits"
 	"asset-management/internal/schedule"
 	"asset-management/services/asset-api/scheduled"
 	"asset-management/services/asset-api/util"
 	_ "github.com/lib/pq"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"testing"
 	"time"
@@ -15,17 +19,19 @@ func TestPostgresCreateRepository_Create_Success(t *testing.T) {
 	defer cleanup()
 
 	repo := scheduled.NewCreateRepository(db)
+	assert.NoError(t, util.InsertBalance(db, "wallet123", "mainnet", "ETH", decimal.RequireFromString("100.50")))
 
 	tx := &schedule.ScheduledTransaction{
 		FromWallet:    "wallet123",
 		ToWallet:      "wallet456",
 		Network:       "mainnet",
-		Amount:        100.50,
+		Asset:         "ETH",
+		Amount:        decimal.RequireFromString("100.50"),
 		ScheduledTime: time.Now().Add(24 * time.Hour),
 		Status:        schedule.StatusPending,
 	}
 
-	id, err := repo.Create(tx)
+	id, err := repo.Create(tx, "")
 	assert.NoError(t, err)
 	assert.NotEqual(t, 0, id)
 }
@@ -40,12 +46,136 @@ func TestPostgresCreateRepository_Create_Failure(t *testing.T) {
 		FromWallet:    "",
 		ToWallet:      "wallet456",
 		Network:       "mainnet",
-		Amount:        0,
+		Asset:         "ETH",
+		Amount:        decimal.Zero,
 		ScheduledTime: time.Now().Add(24 * time.Hour),
 		Status:        schedule.StatusPending,
 	}
 
-	id, err := repo.Create(tx)
+	id, err := repo.Create(tx, "")
 	assert.Error(t, err)
 	assert.Equal(t, 0, id)
 }
+
+func TestPostgresCreateRepository_Create_HoldsFunds(t *testing.T) {
+	db, cleanup := util.SetupTestContainer(t)
+	defer cleanup()
+
+	repo := scheduled.NewCreateRepository(db)
+	assert.NoError(t, util.InsertBalance(db, "wallet123", "mainnet", "ETH", decimal.RequireFromString("100")))
+
+	create := func(amount string) error {
+		_, err := repo.Create(&schedule.ScheduledTransaction{
+			FromWallet:    "wallet123",
+			ToWallet:      "wallet456",
+			Network:       "mainnet",
+			Asset:         "ETH",
+			Amount:        decimal.RequireFromString(amount),
+			ScheduledTime: time.Now().Add(24 * time.Hour),
+			Status:        schedule.StatusPending,
+		}, "")
+		return err
+	}
+
+	assert.NoError(t, create("60"))
+	// 60 of the 100 ETH are held by the first transaction
+	assert.ErrorIs(t, create("50"), funds.ErrFundsHeld)
+	assert.ErrorIs(t, create("150"), funds.ErrInsufficientBalance)
+	assert.NoError(t, create("40"))
+
+	// Cancelling a transaction releases its hold
+	_, err := db.Exec(`UPDATE scheduled_transactions SET status = 'CANCELLED' WHERE amount = 60`)
+	assert.NoError(t, err)
+	assert.NoError(t, create("60"))
+}
+
+func TestPostgresCreateRepository_Create_LimitExceeded(t *testing.T) {
+	db, cleanup := util.SetupTestContainer(t)
+	defer cleanup()
+
+	repo := scheduled.NewCreateRepository(db)
+	assert.NoError(t, util.InsertBalance(db, "wallet123", "mainnet", "ETH", decimal.RequireFromString("100")))
+	_, err := db.Exec(`
+		INSERT INTO withdrawal_limits (scope, network, wallet_address, asset, kind, value) VALUES
+		('GLOBAL', '', '', 'ETH', 'MAX_AMOUNT', 40),
+		('NETWORK', 'mainnet', '', '', 'COUNT_PER_HOUR', 2)`)
+	assert.NoError(t, err)
+
+	create := func(amount string) error {
+		_, err := repo.Create(&schedule.ScheduledTransaction{
+			FromWallet:    "wallet123",
+			ToWallet:      "wallet456",
+			Network:       "mainnet",
+			Asset:         "ETH",
+			Amount:        decimal.RequireFromString(amount),
+			ScheduledTime: time.Now().Add(24 * time.Hour),
+			Status:        schedule.StatusPending,
+		}, "")
+		return err
+	}
+
+	err = create("41")
+	assert.ErrorIs(t, err, limits.ErrLimitExceeded)
+	assert.EqualError(t, err, "withdrawal limit exceeded: global rule 1 allows at most 40 ETH per transaction")
+
+	assert.NoError(t, create("10"))
+	assert.NoError(t, create("10"))
+	err = create("10")
+	assert.ErrorIs(t, err, limits.ErrLimitExceeded)
+	assert.Contains(t, err.Error(), "at most 2 outflows per hour, 2 already made")
+
+	// Cancelled transfers no longer count
+	_, err = db.Exec(`UPDATE scheduled_transactions SET status = 'CANCELLED'`)
+	assert.NoError(t, err)
+	assert.NoError(t, create("10"))
+}
+
+func TestPostgresCreateRepository_Create_AwaitingApproval(t *testing.T) {
+	db, cleanup := util.SetupTestContainer(t)
+	defer cleanup()
+
+	repo := scheduled.NewCreateRepository(db)
+	assert.NoError(t, util.InsertBalance(db, "wallet123", "mainnet", "ETH", decimal.RequireFromString("100")))
+	_, err := db.Exec(`
+		INSERT INTO withdrawal_limits (scope, network, wallet_address, asset, kind, value)
+		VALUES ('GLOBAL', '', '', 'ETH', 'APPROVAL_THRESHOLD', 50)`)
+	assert.NoError(t, err)
+
+	newTransfer := func(amount string) *schedule.ScheduledTransaction {
+		return &schedule.ScheduledTransaction{
+			FromWallet:    "wallet123",
+			ToWallet:      "wallet456",
+			Network:       "mainnet",
+			Asset:         "ETH",
+			Amount:        decimal.RequireFromString(amount),
+			ScheduledTime: time.Now().Add(24 * time.Hour),
+			Status:        schedule.StatusPending,
+		}
+	}
+
+	// Only the requester's colleagues may approve, so the requester must be known
+	_, err = repo.Create(newTransfer("60"), "")
+	assert.ErrorIs(t, err, approval.ErrRequesterRequired)
+
+	transfer := newTransfer("60")
+	id, err := repo.Create(transfer, "alice")
+	assert.NoError(t, err)
+	assert.Equal(t, schedule.StatusAwaitingApproval, transfer.Status)
+
+	var requestedBy, status string
+	err = db.QueryRow(`SELECT requested_by, status FROM approval_requests WHERE scheduled_transaction_id = $1`, id).
+		Scan(&requestedBy, &status)
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", requestedBy)
+	assert.Equal(t, approval.StatusAwaitingApproval, status)
+
+	// The amount is held while awaiting approval
+	_, err = repo.Create(newTransfer("50"), "")
+	assert.ErrorIs(t, err, funds.ErrFundsHeld)
+
+	// Amounts up to the threshold are scheduled right away
+	transfer = newTransfer("40")
+	_, err = repo.Create(transfer, "")
+	assert.NoError(t, err)
+	assert.Equal(t, schedule.StatusPending, transfer.Status)
+}
@@ -2,13 +2,18 @@ package scheduled
 
 import (
 	"asset-management/internal/schedule"
+	"asset-management/internal/schedule/recurrence"
 	"asset-management/services/asset-api/wallet"
 	"errors"
+	"fmt"
+	"github.com/shopspring/decimal"
 	"time"
 )
 
 type CreateService interface {
-	Create(fromWallet, toWallet, network string, amount float64, scheduledTime time.Time) (int, error)
+	// Create returns the new transaction, which is AWAITING_APPROVAL if its amount exceeds an approval threshold.
+	Create(fromWallet, toWallet, network, asset string, amount decimal.Decimal, scheduledTime time.Time, rec schedule.Recurrence,
+		requestedBy string) (*schedule.ScheduledTransaction, error)
 }
 
 type createService struct {
@@ -20,22 +25,65 @@ func NewCreateService(repo CreateRepository, wv wallet.ValidationAdapter) Create
 	return &createService{repo: repo, walletValidator: wv}
 }
 
-func (s *createService) Create(fromWallet, toWallet, network string, amount float64, scheduledTime time.Time) (int, error) {
-	if amount <= 0 {
-		return 0, errors.New("amount must be greater than zero")
+func (s *createService) Create(fromWallet, toWallet, network, asset string, amount decimal.Decimal, scheduledTime time.Time, rec schedule.Recurrence,
+	requestedBy string) (*schedule.ScheduledTransaction, error) {
+	if !amount.IsPositive() {
+		return nil, errors.New("amount must be greater than zero")
 	}
 
+	if asset == "" {
+		return nil, errors.New("asset is required")
+	}
+
+	if err := validateRecurrence(rec, scheduledTime); err != nil {
+		return nil, err
+	}
+
+	_, toWallet = wallet.Canonical(network, toWallet)
+	network, fromWallet = wallet.Canonical(network, fromWallet)
+
 	if err := s.walletValidator.Both(fromWallet, toWallet, network); err != nil {
-		return 0, err
+		return nil, err
 	}
 
 	tx := &schedule.ScheduledTransaction{
 		FromWallet:    fromWallet,
 		ToWallet:      toWallet,
 		Network:       network,
+		Asset:         asset,
 		Amount:        amount,
 		ScheduledTime: scheduledTime,
 		Status:        schedule.StatusPending,
+		Recurrence:    rec,
 	}
-	return s.repo.Create(tx)
+
+	id, err := s.repo.Create(tx, requestedBy)
+	if err != nil {
+		return nil, err
+	}
+	tx.ID = id
+	return tx, nil
+}
+
+func validateRecurrence(rec schedule.Recurrence, scheduledTime time.Time) error {
+	if rec.Rule == "" {
+		if rec.Until != nil || rec.MaxOccurrences != nil {
+			return errors.New("recurrence is required when recurrence_end or max_occurrences is set")
+		}
+		return nil
+	}
+
+	if _, err := recurrence.Parse(rec.Rule, scheduledTime); err != nil {
+		return fmt.Errorf("invalid recurrence: %w", err)
+	}
+
+	if rec.Until != nil && rec.Until.Before(scheduledTime) {
+		return errors.New("recurrence_end must not be before scheduled_time")
+	}
+
+	if rec.MaxOccurrences != nil && *rec.MaxOccurrences < 1 {
+		return errors.New("max_occurrences must be greater than zero")
+	}
+
+	return nil
 }
@@ -3,6 +3,7 @@ package scheduled
 import (
 	"asset-management/internal/schedule"
 	"errors"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"testing"
@@ -13,8 +14,8 @@ type MockCreateRepository struct {
 	mock.Mock
 }
 
-func (m *MockCreateRepository) Create(tx *schedule.ScheduledTransaction) (int, error) {
-	args := m.Called(tx)
+func (m *MockCreateRepository) Create(tx *schedule.ScheduledTransaction, requestedBy string) (int, error) {
+	args := m.Called(tx, requestedBy)
 	return args.Int(0), args.Error(1)
 }
 
@@ -32,17 +33,28 @@ func (m *MockValidationAdapter) One(wallet, network string) error {
 	return args.Error(0)
 }
 
+func (m *MockValidationAdapter) CanDebit(wallet, network string) error {
+	args := m.Called(wallet, network)
+	return args.Error(0)
+}
+
+func (m *MockValidationAdapter) CanCredit(wallet, network string) error {
+	args := m.Called(wallet, network)
+	return args.Error(0)
+}
+
 func TestCreateService_Success(t *testing.T) {
 	mockRepo := new(MockCreateRepository)
 	mockValidator := new(MockValidationAdapter)
 	service := NewCreateService(mockRepo, mockValidator)
 
 	mockValidator.On("Both", "wallet123", "wallet456", "mainnet").Return(nil)
-	mockRepo.On("Create", mock.Anything).Return(123, nil)
+	mockRepo.On("Create", mock.Anything, "alice").Return(123, nil)
 
-	id, err := service.Create("wallet123", "wallet456", "mainnet", 100.50, time.Now())
+	txn, err := service.Create("wallet123", "wallet456", "mainnet", "ETH", decimal.RequireFromString("100.50"), time.Now(), schedule.Recurrence{}, "alice")
 	assert.NoError(t, err)
-	assert.Equal(t, 123, id)
+	assert.Equal(t, 123, txn.ID)
+	assert.Equal(t, schedule.StatusPending, txn.Status)
 
 	mockValidator.AssertExpectations(t)
 	mockRepo.AssertExpectations(t)
@@ -55,10 +67,10 @@ func TestCreateService_ValidationError(t *testing.T) {
 
 	mockValidator.On("Both", "wallet123", "wallet456", "mainnet").Return(errors.New("validation failed"))
 
-	id, err := service.Create("wallet123", "wallet456", "mainnet", 100.50, time.Now())
+	txn, err := service.Create("wallet123", "wallet456", "mainnet", "ETH", decimal.RequireFromString("100.50"), time.Now(), schedule.Recurrence{}, "")
 	assert.Error(t, err)
 	assert.Equal(t, "validation failed", err.Error())
-	assert.Equal(t, 0, id)
+	assert.Nil(t, txn)
 
 	mockValidator.AssertExpectations(t)
 }
@@ -68,8 +80,58 @@ func TestCreateService_InvalidAmount(t *testing.T) {
 	mockValidator := new(MockValidationAdapter)
 	service := NewCreateService(mockRepo, mockValidator)
 
-	id, err := service.Create("wallet123", "wallet456", "mainnet", 0, time.Now())
+	txn, err := service.Create("wallet123", "wallet456", "mainnet", "ETH", decimal.Zero, time.Now(), schedule.Recurrence{}, "")
 	assert.Error(t, err)
 	assert.Equal(t, "amount must be greater than zero", err.Error())
-	assert.Equal(t, 0, id)
+	assert.Nil(t, txn)
+}
+
+func TestCreateService_MissingAsset(t *testing.T) {
+	mockRepo := new(MockCreateRepository)
+	mockValidator := new(MockValidationAdapter)
+	service := NewCreateService(mockRepo, mockValidator)
+
+	txn, err := service.Create("wallet123", "wallet456", "mainnet", "", decimal.RequireFromString("100.50"), time.Now(), schedule.Recurrence{}, "")
+	assert.Error(t, err)
+	assert.Equal(t, "asset is required", err.Error())
+	assert.Nil(t, txn)
+}
+
+func TestCreateService_Recurring(t *testing.T) {
+	mockRepo := new(MockCreateRepository)
+	mockValidator := new(MockValidationAdapter)
+	service := NewCreateService(mockRepo, mockValidator)
+
+	rec := schedule.Recurrence{Rule: "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=1"}
+	mockValidator.On("Both", "wallet123", "wallet456", "mainnet").Return(nil)
+	mockRepo.On("Create", mock.MatchedBy(func(tx *schedule.ScheduledTransaction) bool {
+		return tx.Rule == rec.Rule
+	}), "").Return(123, nil)
+
+	txn, err := service.Create("wallet123", "wallet456", "mainnet", "ETH", decimal.RequireFromString("100.50"), time.Now(), rec, "")
+	assert.NoError(t, err)
+	assert.Equal(t, 123, txn.ID)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateService_InvalidRecurrence(t *testing.T) {
+	mockRepo := new(MockCreateRepository)
+	mockValidator := new(MockValidationAdapter)
+	service := NewCreateService(mockRepo, mockValidator)
+
+	now := time.Now()
+	before := now.Add(-time.Hour)
+	zero := 0
+
+	for _, rec := range []schedule.Recurrence{
+		{Rule: "every monday"},
+		{Rule: "@daily", Until: &before},
+		{Rule: "@daily", MaxOccurrences: &zero},
+		{MaxOccurrences: &zero},
+	} {
+		txn, err := service.Create("wallet123", "wallet456", "mainnet", "ETH", decimal.RequireFromString("100.50"), now, rec, "")
+		assert.Error(t, err)
+		assert.Nil(t, txn)
+	}
+	mockValidator.AssertNotCalled(t, "Both", mock.Anything, mock.Anything, mock.Anything)
 }
@@ -3,6 +3,7 @@ package scheduled
 import (
 	"asset-management/internal/schedule/scheduled_next"
 	"github.com/gofiber/fiber/v2"
+	"github.com/shopspring/decimal"
 	"time"
 )
 
@@ -15,19 +16,29 @@ func NewNextController(service scheduled_next.NextService) *NextController {
 }
 
 type ScheduledTransaction struct {
-	ID            int       `json:"id" example:"1"`                                // Transaction ID
-	FromWallet    string    `json:"from_wallet" example:"wallet_123"`              // Sender's wallet address
-	ToWallet      string    `json:"to_wallet" example:"wallet_456"`                // Recipient's wallet address
-	Network       string    `json:"network" example:"Ethereum"`                    // Blockchain network (e.g., Ethereum)
-	Amount        float64   `json:"amount" example:"250.75"`                       // Amount to be transferred
-	ScheduledTime time.Time `json:"scheduled_time" example:"2024-10-30T15:04:05Z"` // Scheduled time for transaction
-	Status        string    `json:"status" example:"PENDING"`                      // Transaction status (e.g., pending, completed)
-	CreatedAt     time.Time `json:"created_at" example:"2024-10-29T10:15:00Z"`     // Time when the transaction was created
+	ID            int             `json:"id" example:"1"`                                // Transaction ID
+	FromWallet    string          `json:"from_wallet" example:"wallet_123"`              // Sender's wallet address
+	ToWallet      string          `json:"to_wallet" example:"wallet_456"`                // Recipient's wallet address
+	Network       string          `json:"network" example:"Ethereum"`                    // Blockchain network (e.g., Ethereum)
+	Asset         string          `json:"asset" example:"ETH"`                           // Asset symbol (e.g., ETH, USDT)
+	Amount        decimal.Decimal `json:"amount" swaggertype:"string" example:"250.75"`  // Amount to be transferred
+	ScheduledTime time.Time       `json:"scheduled_time" example:"2024-10-30T15:04:05Z"` // Scheduled time for transaction
+	Status        string          `json:"status" example:"PENDING"`                      // Transaction status (e.g., pending, completed)
+	CreatedAt     time.Time       `json:"created_at" example:"2024-10-29T10:15:00Z"`     // Time when the transaction was created
+
+	PublishedAt      *time.Time `json:"published_at,omitempty" example:"2024-10-30T15:04:06Z"`   // Time the event was confirmed by Kafka
+	PublishAttempts  int        `json:"publish_attempts" example:"1"`                            // Number of times publishing was attempted
+	FailureReason    string     `json:"failure_reason,omitempty" example:"insufficient balance"` // Why processing failed, set with status FAILED
+	Recurrence       string     `json:"recurrence,omitempty" example:"0 9 * * MON"`              // Cron expression or RRULE
+	RecurrenceEnd    *time.Time `json:"recurrence_end,omitempty" example:"2025-12-31T00:00:00Z"` // No occurrence is scheduled after this time
+	MaxOccurrences   *int       `json:"max_occurrences,omitempty" example:"12"`                  // Maximum number of occurrences in the series
+	SeriesID         *int       `json:"series_id,omitempty" example:"1"`                         // ID of the first occurrence of a recurring series
+	OccurrenceNumber int        `json:"occurrence_number" example:"1"`                           // 1-based position within the series
 }
 
 // GetNextMinuteTransactions godoc
 // @Summary Get transactions scheduled for the next minute
-// @Description Retrieve all transactions scheduled for the upcoming minute
+// @Description Retrieve all pending transactions that are overdue or scheduled for the upcoming minute, i.e. what the publisher will pick up next
 // @Tags ScheduledTransaction
 // @Accept  json
 // @Produce  json
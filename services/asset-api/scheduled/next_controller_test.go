@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"github.com/gofiber/fiber/v2"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"net/http"
@@ -25,6 +26,11 @@ func (m *mockNextService) GetNextMinuteTransactions() ([]schedule.ScheduledTrans
 	return nil, args.Error(1)
 }
 
+func (m *mockNextService) PublishDue(batchSize int, publish func([]schedule.ScheduledTransaction) error) (int, error) {
+	args := m.Called(batchSize, publish)
+	return args.Int(0), args.Error(1)
+}
+
 func TestNextController_GetNextMinuteTransactions_Success(t *testing.T) {
 	mockService := new(mockNextService)
 	controller := scheduled.NewNextController(mockService)
@@ -34,7 +40,7 @@ func TestNextController_GetNextMinuteTransactions_Success(t *testing.T) {
 
 	// Mock successful response
 	transactions := []schedule.ScheduledTransaction{
-		{ID: 1, FromWallet: "wallet123", ToWallet: "wallet456", Network: "mainnet", Amount: 100.50},
+		{ID: 1, FromWallet: "wallet123", ToWallet: "wallet456", Network: "mainnet", Amount: decimal.RequireFromString("100.5")},
 	}
 	mockService.On("GetNextMinuteTransactions").Return(transactions, nil)
 
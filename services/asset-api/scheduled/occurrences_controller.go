@@ -0,0 +1,42 @@
+package scheduled
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"strconv"
+)
+
+type OccurrencesController struct {
+	service OccurrencesService
+}
+
+func NewOccurrencesController(service OccurrencesService) *OccurrencesController {
+	return &OccurrencesController{service: service}
+}
+
+// GetOccurrences godoc
+// @Summary List the occurrences of a scheduled transaction
+// @Description Lists every occurrence of the recurring series the scheduled transaction belongs to, oldest first. A one-off transaction is returned as its only occurrence.
+// @Tags ScheduledTransaction
+// @Produce json
+// @Param id path int true "Transaction ID"
+// @Success 200 {array} ScheduledTransaction
+// @Failure 400 {object} map[string]string "error": "Invalid transaction ID"
+// @Failure 404 {object} map[string]string "error": "scheduled transaction not found"
+// @Failure 500 {object} map[string]string "error": "Failed to retrieve occurrences"
+// @Router /scheduled-transaction/{id}/occurrences [get]
+func (c *OccurrencesController) GetOccurrences(ctx *fiber.Ctx) error {
+	id, err := strconv.Atoi(ctx.Params("id"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid transaction ID"})
+	}
+
+	occurrences, err := c.service.GetOccurrences(id)
+	if errors.Is(err, ErrNotFound) {
+		return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	} else if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve occurrences"})
+	}
+
+	return ctx.JSON(occurrences)
+}
@@ -0,0 +1,77 @@
+package scheduled_test
+
+import (
+	"asset-management/internal/schedule"
+	"asset-management/services/asset-api/scheduled"
+	"encoding/json"
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type MockOccurrencesService struct {
+	mock.Mock
+}
+
+func (m *MockOccurrencesService) GetOccurrences(id int) ([]schedule.ScheduledTransaction, error) {
+	args := m.Called(id)
+	if occurrences, ok := args.Get(0).([]schedule.ScheduledTransaction); ok {
+		return occurrences, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func newOccurrencesApp(service scheduled.OccurrencesService) *fiber.App {
+	app := fiber.New()
+	app.Get("/scheduled-transaction/:id/occurrences", scheduled.NewOccurrencesController(service).GetOccurrences)
+	return app
+}
+
+func TestOccurrencesController_GetOccurrences_Success(t *testing.T) {
+	mockService := new(MockOccurrencesService)
+	app := newOccurrencesApp(mockService)
+
+	seriesID := 1
+	occurrences := []schedule.ScheduledTransaction{
+		{ID: 1, Status: schedule.StatusCompleted, OccurrenceNumber: 1, Recurrence: schedule.Recurrence{Rule: "@daily"}},
+		{ID: 7, Status: schedule.StatusPending, OccurrenceNumber: 2, SeriesID: &seriesID, Recurrence: schedule.Recurrence{Rule: "@daily"}},
+	}
+	mockService.On("GetOccurrences", 7).Return(occurrences, nil)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/scheduled-transaction/7/occurrences", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result []schedule.ScheduledTransaction
+	_ = json.NewDecoder(resp.Body).Decode(&result)
+	assert.Len(t, result, 2)
+	assert.Equal(t, 2, result[1].OccurrenceNumber)
+	assert.Equal(t, "@daily", result[1].Rule)
+	mockService.AssertExpectations(t)
+}
+
+func TestOccurrencesController_GetOccurrences_NotFound(t *testing.T) {
+	mockService := new(MockOccurrencesService)
+	app := newOccurrencesApp(mockService)
+
+	mockService.On("GetOccurrences", 7).Return(nil, scheduled.ErrNotFound)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/scheduled-transaction/7/occurrences", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestOccurrencesController_GetOccurrences_Error(t *testing.T) {
+	mockService := new(MockOccurrencesService)
+	app := newOccurrencesApp(mockService)
+
+	mockService.On("GetOccurrences", 7).Return(nil, errors.New("db down"))
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/scheduled-transaction/7/occurrences", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+}
@@ -0,0 +1,54 @@
+package scheduled
+
+import (
+	"asset-management/internal/schedule"
+	"database/sql"
+	"fmt"
+)
+
+type OccurrencesRepository interface {
+	GetOccurrences(id int) ([]schedule.ScheduledTransaction, error)
+}
+
+type postgresOccurrencesRepository struct {
+	db *sql.DB
+}
+
+func NewOccurrencesRepository(db *sql.DB) OccurrencesRepository {
+	return &postgresOccurrencesRepository{db: db}
+}
+
+// GetOccurrences returns every occurrence of the series the given scheduled transaction belongs to, oldest first.
+func (r *postgresOccurrencesRepository) GetOccurrences(id int) ([]schedule.ScheduledTransaction, error) {
+	rows, err := r.db.Query(`
+		SELECT `+schedule.Columns+`
+		FROM scheduled_transactions
+		WHERE COALESCE(series_id, scheduled_transaction_id) = (
+			SELECT COALESCE(series_id, scheduled_transaction_id)
+			FROM scheduled_transactions
+			WHERE scheduled_transaction_id = $1)
+		ORDER BY occurrence_number`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query occurrences: %w", err)
+	}
+	defer rows.Close()
+
+	var occurrences []schedule.ScheduledTransaction
+	for rows.Next() {
+		txn, err := schedule.Scan(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan occurrence: %w", err)
+		}
+		occurrences = append(occurrences, txn)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read occurrences: %w", err)
+	}
+
+	if len(occurrences) == 0 {
+		return nil, ErrNotFound
+	}
+
+	return occurrences, nil
+}
@@ -0,0 +1,39 @@
+package scheduled_test
+
+import (
+	"asset-management/internal/schedule"
+	"asset-management/services/asset-api/scheduled"
+	"asset-management/services/asset-api/util"
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestPostgresOccurrencesRepository_GetOccurrences(t *testing.T) {
+	db, cleanup := util.SetupTestContainer(t)
+	defer cleanup()
+
+	repo := scheduled.NewOccurrencesRepository(db)
+	id := insertScheduled(t, db, schedule.StatusPending)
+
+	first, err := repo.GetOccurrences(id)
+	assert.NoError(t, err)
+	assert.Len(t, first, 1)
+
+	_, err = db.Exec(`
+		INSERT INTO scheduled_transactions (from_wallet_address, to_wallet_address, network, asset, amount, scheduled_time, series_id, occurrence_number)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		"wallet123", "wallet456", "mainnet", "ETH", "100.50", first[0].ScheduledTime.Add(24*time.Hour), id, 2)
+	assert.NoError(t, err)
+
+	// Asking for the second occurrence returns the whole series
+	occurrences, err := repo.GetOccurrences(id + 1)
+	assert.NoError(t, err)
+	assert.Len(t, occurrences, 2)
+	assert.Equal(t, id, occurrences[0].ID)
+	assert.Equal(t, 2, occurrences[1].OccurrenceNumber)
+
+	_, err = repo.GetOccurrences(999)
+	assert.ErrorIs(t, err, scheduled.ErrNotFound)
+}
@@ -0,0 +1,19 @@
+package scheduled
+
+import "asset-management/internal/schedule"
+
+type OccurrencesService interface {
+	GetOccurrences(id int) ([]schedule.ScheduledTransaction, error)
+}
+
+type occurrencesService struct {
+	repo OccurrencesRepository
+}
+
+func NewOccurrencesService(repo OccurrencesRepository) OccurrencesService {
+	return &occurrencesService{repo: repo}
+}
+
+func (s *occurrencesService) GetOccurrences(id int) ([]schedule.ScheduledTransaction, error) {
+	return s.repo.GetOccurrences(id)
+}
@@ -2,6 +2,7 @@ package scheduled
 
 import (
 	"asset-management/internal/schedule/scheduled_process"
+	"errors"
 	"fmt"
 	"github.com/gofiber/fiber/v2"
 	"strconv"
@@ -17,11 +18,12 @@ func NewProcessController(service scheduled_process.ProcessService) *ProcessCont
 
 // Process godoc
 // @Summary Process a scheduled transaction
-// @Description Processes a scheduled transaction by its ID
+// @Description Processes a scheduled transaction by its ID. Only PENDING, PUBLISHED and FAILED transactions can be processed.
 // @Tags ScheduledTransaction
 // @Param id path int true "Transaction ID"
 // @Success 200 {object} map[string]string "message": "Transaction processed successfully"
 // @Failure 400 {object} map[string]string "error": "Invalid transaction ID"
+// @Failure 409 {object} map[string]string "error": "scheduled transaction cannot be processed in its current status"
 // @Failure 500 {object} map[string]string "error": "Failed to process transaction"
 // @Router /scheduled-transaction/{id}/process [post]
 func (c *ProcessController) Process(ctx *fiber.Ctx) error {
@@ -34,6 +36,9 @@ func (c *ProcessController) Process(ctx *fiber.Ctx) error {
 	}
 
 	if err := c.service.Process(transactionID); err != nil {
+		if errors.Is(err, scheduled_process.ErrNotProcessable) {
+			return ctx.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+		}
 		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": fmt.Errorf("failed to process transaction: %w", err).Error(),
 		})
@@ -1,9 +1,11 @@
 package scheduled_test
 
 import (
+	"asset-management/internal/schedule/scheduled_process"
 	"asset-management/services/asset-api/scheduled"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -21,6 +23,11 @@ func (m *MockProcessService) Process(scheduledTransactionID int) error {
 	return args.Error(0)
 }
 
+func (m *MockProcessService) MarkFailed(scheduledTransactionID int, reason string) error {
+	args := m.Called(scheduledTransactionID, reason)
+	return args.Error(0)
+}
+
 func TestProcessController_Process_Success(t *testing.T) {
 	mockService := new(MockProcessService)
 	controller := scheduled.NewProcessController(mockService)
@@ -90,3 +97,20 @@ func TestProcessController_Process_Failure(t *testing.T) {
 
 	mockService.AssertExpectations(t)
 }
+
+func TestProcessController_Process_NotProcessable(t *testing.T) {
+	mockService := new(MockProcessService)
+	controller := scheduled.NewProcessController(mockService)
+
+	app := fiber.New()
+	app.Post("/scheduled-transaction/:id/process", controller.Process)
+
+	mockService.On("Process", 123).Return(fmt.Errorf("failed to process transaction: %w: AWAITING_APPROVAL", scheduled_process.ErrNotProcessable))
+
+	req := httptest.NewRequest(http.MethodPost, "/scheduled-transaction/123/process", nil)
+	resp, err := app.Test(req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusConflict, resp.StatusCode)
+	mockService.AssertExpectations(t)
+}
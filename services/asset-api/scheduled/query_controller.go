@@ -0,0 +1,132 @@
+package scheduled
+
+import (
+	"asset-management/internal/schedule"
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"strconv"
+	"time"
+)
+
+type QueryController struct {
+	service QueryService
+}
+
+func NewQueryController(service QueryService) *QueryController {
+	return &QueryController{service: service}
+}
+
+type ListResponse struct {
+	Transactions []schedule.ScheduledTransaction `json:"transactions"`
+	NextCursor   string                          `json:"next_cursor,omitempty" example:"41"`
+}
+
+// Get godoc
+// @Summary Get a scheduled transaction
+// @Description Retrieve a scheduled transaction by ID, with its status and, if it failed, why
+// @Tags ScheduledTransaction
+// @Produce json
+// @Param id path int true "Transaction ID"
+// @Success 200 {object} ScheduledTransaction
+// @Failure 400 {object} map[string]string "error": "Invalid transaction ID"
+// @Failure 404 {object} map[string]string "error": "scheduled transaction not found"
+// @Failure 500 {object} map[string]string "error": "Failed to retrieve transaction"
+// @Router /scheduled-transaction/{id} [get]
+func (c *QueryController) Get(ctx *fiber.Ctx) error {
+	id, err := strconv.Atoi(ctx.Params("id"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid transaction ID"})
+	}
+
+	txn, err := c.service.Get(id)
+	if errors.Is(err, ErrNotFound) {
+		return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	} else if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve transaction"})
+	}
+
+	return ctx.JSON(txn)
+}
+
+// List godoc
+// @Summary List scheduled transactions
+// @Description Lists scheduled transactions, newest first, using cursor pagination. Time ranges include their start and exclude their end, in RFC 3339.
+// @Tags ScheduledTransaction
+// @Produce json
+// @Param from_wallet query string false "Sender's wallet address"
+// @Param to_wallet query string false "Recipient's wallet address"
+// @Param network query string false "Network"
+// @Param status query string false "Status" Enums(AWAITING_APPROVAL, PENDING, PUBLISHED, COMPLETED, FAILED, CANCELLED)
+// @Param scheduled_from query string false "Scheduled at or after this time"
+// @Param scheduled_to query string false "Scheduled before this time"
+// @Param created_from query string false "Created at or after this time"
+// @Param created_to query string false "Created before this time"
+// @Param cursor query string false "Cursor returned as next_cursor by the previous page"
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Success 200 {object} ListResponse
+// @Failure 400 {object} map[string]string "error": "invalid filter: unknown status \"DONE\""
+// @Failure 500 {object} map[string]string "error": "Failed to list transactions"
+// @Router /scheduled-transaction [get]
+func (c *QueryController) List(ctx *fiber.Ctx) error {
+	filter := ListFilter{
+		FromWallet: ctx.Query("from_wallet"),
+		ToWallet:   ctx.Query("to_wallet"),
+		Network:    ctx.Query("network"),
+		Status:     ctx.Query("status"),
+	}
+
+	var err error
+	for _, param := range []struct {
+		name string
+		time **time.Time
+	}{
+		{"scheduled_from", &filter.ScheduledFrom},
+		{"scheduled_to", &filter.ScheduledTo},
+		{"created_from", &filter.CreatedFrom},
+		{"created_to", &filter.CreatedTo},
+	} {
+		if *param.time, err = queryTime(ctx, param.name); err != nil {
+			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+	}
+
+	if raw := ctx.Query("cursor"); raw != "" {
+		if filter.Cursor, err = strconv.Atoi(raw); err != nil || filter.Cursor <= 0 {
+			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid cursor"})
+		}
+	}
+	if raw := ctx.Query("limit"); raw != "" {
+		if filter.Limit, err = strconv.Atoi(raw); err != nil || filter.Limit <= 0 {
+			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid limit"})
+		}
+	}
+
+	page, err := c.service.List(filter)
+	if errors.Is(err, ErrInvalidFilter) {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	} else if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to list transactions"})
+	}
+
+	response := ListResponse{Transactions: page.Transactions}
+	if page.NextCursor != 0 {
+		response.NextCursor = strconv.Itoa(page.NextCursor)
+	}
+
+	return ctx.JSON(response)
+}
+
+// queryTime parses an optional RFC 3339 query parameter.
+func queryTime(ctx *fiber.Ctx, param string) (*time.Time, error) {
+	raw := ctx.Query(param)
+	if raw == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: expected RFC 3339, e.g. 2024-10-30T15:04:05Z", param)
+	}
+	return &t, nil
+}
@@ -0,0 +1,110 @@
+package scheduled_test
+
+import (
+	"asset-management/internal/schedule"
+	"asset-management/services/asset-api/scheduled"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type MockQueryService struct {
+	mock.Mock
+}
+
+func (m *MockQueryService) Get(id int) (*schedule.ScheduledTransaction, error) {
+	args := m.Called(id)
+	if txn, ok := args.Get(0).(*schedule.ScheduledTransaction); ok {
+		return txn, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockQueryService) List(filter scheduled.ListFilter) (*scheduled.Page, error) {
+	args := m.Called(filter)
+	if page, ok := args.Get(0).(*scheduled.Page); ok {
+		return page, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func newQueryApp(service scheduled.QueryService) *fiber.App {
+	app := fiber.New()
+	controller := scheduled.NewQueryController(service)
+	app.Get("/scheduled-transaction", controller.List)
+	app.Get("/scheduled-transaction/:id", controller.Get)
+	return app
+}
+
+func TestQueryController_Get(t *testing.T) {
+	mockService := new(MockQueryService)
+	app := newQueryApp(mockService)
+
+	mockService.On("Get", 7).Return(&schedule.ScheduledTransaction{ID: 7, Status: schedule.StatusFailed, FailureReason: "insufficient balance"}, nil)
+	mockService.On("Get", 8).Return(nil, scheduled.ErrNotFound)
+	mockService.On("Get", 9).Return(nil, errors.New("db down"))
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/scheduled-transaction/7", nil))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	var txn schedule.ScheduledTransaction
+	_ = json.NewDecoder(resp.Body).Decode(&txn)
+	assert.Equal(t, "insufficient balance", txn.FailureReason)
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, "/scheduled-transaction/8", nil))
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, "/scheduled-transaction/9", nil))
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, "/scheduled-transaction/abc", nil))
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	mockService.AssertExpectations(t)
+}
+
+func TestQueryController_List(t *testing.T) {
+	mockService := new(MockQueryService)
+	app := newQueryApp(mockService)
+
+	scheduledFrom := time.Date(2024, 10, 30, 15, 0, 0, 0, time.UTC)
+	filter := scheduled.ListFilter{FromWallet: "wallet123", Network: "Ethereum", Status: schedule.StatusCompleted, ScheduledFrom: &scheduledFrom, Cursor: 42, Limit: 2}
+	page := &scheduled.Page{Transactions: []schedule.ScheduledTransaction{{ID: 41}, {ID: 40}}, NextCursor: 40}
+	mockService.On("List", filter).Return(page, nil)
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodGet,
+		"/scheduled-transaction?from_wallet=wallet123&network=Ethereum&status=COMPLETED&scheduled_from=2024-10-30T15:00:00Z&cursor=42&limit=2", nil))
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	var result scheduled.ListResponse
+	_ = json.NewDecoder(resp.Body).Decode(&result)
+	assert.Len(t, result.Transactions, 2)
+	assert.Equal(t, "40", result.NextCursor)
+	mockService.AssertExpectations(t)
+}
+
+func TestQueryController_List_BadRequest(t *testing.T) {
+	mockService := new(MockQueryService)
+	app := newQueryApp(mockService)
+	mockService.On("List", scheduled.ListFilter{Status: "DONE"}).Return(nil, fmt.Errorf("%w: unknown status \"DONE\"", scheduled.ErrInvalidFilter))
+
+	for _, query := range []string{"status=DONE", "created_to=yesterday", "cursor=0", "limit=abc"} {
+		resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/scheduled-transaction?"+query, nil))
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, query)
+	}
+}
+
+func TestQueryController_List_Error(t *testing.T) {
+	mockService := new(MockQueryService)
+	app := newQueryApp(mockService)
+	mockService.On("List", scheduled.ListFilter{}).Return(nil, errors.New("db down"))
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/scheduled-transaction", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+}
@@ -0,0 +1,115 @@
+package scheduled
+
+import (
+	"asset-management/internal/schedule"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ListFilter selects scheduled transactions. Empty fields match every transaction; time ranges
+// include their start and exclude their end.
+type ListFilter struct {
+	FromWallet    string
+	ToWallet      string
+	Network       string
+	Status        string
+	ScheduledFrom *time.Time
+	ScheduledTo   *time.Time
+	CreatedFrom   *time.Time
+	CreatedTo     *time.Time
+	Cursor        int // Only transactions with a lower ID, if set
+	Limit         int
+}
+
+type QueryRepository interface {
+	Get(id int) (*schedule.ScheduledTransaction, error)
+	List(filter ListFilter) ([]schedule.ScheduledTransaction, error)
+}
+
+type postgresQueryRepository struct {
+	db *sql.DB
+}
+
+func NewQueryRepository(db *sql.DB) QueryRepository {
+	return &postgresQueryRepository{db: db}
+}
+
+func (r *postgresQueryRepository) Get(id int) (*schedule.ScheduledTransaction, error) {
+	txn, err := schedule.Scan(r.db.QueryRow(`SELECT `+schedule.Columns+` FROM scheduled_transactions WHERE scheduled_transaction_id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query scheduled transaction: %w", err)
+	}
+	return &txn, nil
+}
+
+// List returns up to filter.Limit transactions matching the filter, newest first.
+func (r *postgresQueryRepository) List(filter ListFilter) ([]schedule.ScheduledTransaction, error) {
+	var conditions []string
+	var args []any
+	where := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.FromWallet != "" {
+		where("from_wallet_address = $%d", filter.FromWallet)
+	}
+	if filter.ToWallet != "" {
+		where("to_wallet_address = $%d", filter.ToWallet)
+	}
+	if filter.Network != "" {
+		where("network = $%d", filter.Network)
+	}
+	if filter.Status != "" {
+		where("status = $%d", filter.Status)
+	}
+	if filter.ScheduledFrom != nil {
+		where("scheduled_time >= $%d", *filter.ScheduledFrom)
+	}
+	if filter.ScheduledTo != nil {
+		where("scheduled_time < $%d", *filter.ScheduledTo)
+	}
+	if filter.CreatedFrom != nil {
+		where("created_at >= $%d", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		where("created_at < $%d", *filter.CreatedTo)
+	}
+	if filter.Cursor != 0 {
+		where("scheduled_transaction_id < $%d", filter.Cursor)
+	}
+
+	query := `SELECT ` + schedule.Columns + ` FROM scheduled_transactions`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, ` AND `)
+	}
+	args = append(args, filter.Limit)
+	query += fmt.Sprintf(` ORDER BY scheduled_transaction_id DESC LIMIT $%d`, len(args))
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query scheduled transactions: %w", err)
+	}
+	defer rows.Close()
+
+	transactions := []schedule.ScheduledTransaction{}
+	for rows.Next() {
+		txn, err := schedule.Scan(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan scheduled transaction: %w", err)
+		}
+		transactions = append(transactions, txn)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read scheduled transactions: %w", err)
+	}
+
+	return transactions, nil
+}
@@ -0,0 +1,65 @@
+package scheduled_test
+
+import (
+	"asset-management/internal/schedule"
+	"asset-management/services/asset-api/scheduled"
+	"asset-management/services/asset-api/util"
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestPostgresQueryRepository_Get(t *testing.T) {
+	db, cleanup := util.SetupTestContainer(t)
+	defer cleanup()
+
+	repo := scheduled.NewQueryRepository(db)
+	id := insertScheduled(t, db, schedule.StatusPending)
+
+	txn, err := repo.Get(id)
+	assert.NoError(t, err)
+	assert.Equal(t, "wallet123", txn.FromWallet)
+	assert.Equal(t, schedule.StatusPending, txn.Status)
+
+	_, err = repo.Get(999)
+	assert.ErrorIs(t, err, scheduled.ErrNotFound)
+}
+
+func TestPostgresQueryRepository_List(t *testing.T) {
+	db, cleanup := util.SetupTestContainer(t)
+	defer cleanup()
+
+	repo := scheduled.NewQueryRepository(db)
+	pending := insertScheduled(t, db, schedule.StatusPending)
+	completed := insertScheduled(t, db, schedule.StatusCompleted)
+	failed := insertScheduled(t, db, schedule.StatusFailed)
+
+	all, err := repo.List(scheduled.ListFilter{FromWallet: "wallet123", Network: "mainnet", Limit: 10})
+	assert.NoError(t, err)
+	if assert.Len(t, all, 3) {
+		assert.Equal(t, []int{failed, completed, pending}, []int{all[0].ID, all[1].ID, all[2].ID})
+	}
+
+	byStatus, err := repo.List(scheduled.ListFilter{Status: schedule.StatusCompleted, Limit: 10})
+	assert.NoError(t, err)
+	if assert.Len(t, byStatus, 1) {
+		assert.Equal(t, completed, byStatus[0].ID)
+	}
+
+	afterCursor, err := repo.List(scheduled.ListFilter{Cursor: failed, Limit: 1})
+	assert.NoError(t, err)
+	if assert.Len(t, afterCursor, 1) {
+		assert.Equal(t, completed, afterCursor[0].ID)
+	}
+
+	// Every transaction is scheduled a day from now
+	tomorrow := time.Now().UTC().Add(12 * time.Hour)
+	none, err := repo.List(scheduled.ListFilter{ScheduledTo: &tomorrow, Limit: 10})
+	assert.NoError(t, err)
+	assert.Empty(t, none)
+
+	none, err = repo.List(scheduled.ListFilter{ToWallet: "wallet123", Limit: 10})
+	assert.NoError(t, err)
+	assert.Empty(t, none)
+}
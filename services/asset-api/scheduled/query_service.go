@@ -0,0 +1,105 @@
+package scheduled
+
+import (
+	"asset-management/internal/schedule"
+	"asset-management/services/asset-api/wallet"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	DefaultListLimit = 50
+	MaxListLimit     = 200
+)
+
+var ErrInvalidFilter = errors.New("invalid filter")
+
+type Page struct {
+	Transactions []schedule.ScheduledTransaction
+	NextCursor   int
+}
+
+type QueryService interface {
+	Get(id int) (*schedule.ScheduledTransaction, error)
+	List(filter ListFilter) (*Page, error)
+}
+
+type queryService struct {
+	repo QueryRepository
+}
+
+func NewQueryService(repo QueryRepository) QueryService {
+	return &queryService{repo: repo}
+}
+
+func (s *queryService) Get(id int) (*schedule.ScheduledTransaction, error) {
+	return s.repo.Get(id)
+}
+
+// List returns a page of transactions, newest first. A zero Limit defaults to DefaultListLimit, and
+// larger limits are capped at MaxListLimit.
+func (s *queryService) List(filter ListFilter) (*Page, error) {
+	if err := validateFilter(filter); err != nil {
+		return nil, err
+	}
+
+	if filter.Limit == 0 {
+		filter.Limit = DefaultListLimit
+	}
+	if filter.Limit > MaxListLimit {
+		filter.Limit = MaxListLimit
+	}
+
+	// Transactions are stored under canonical networks and addresses
+	if filter.FromWallet != "" {
+		_, filter.FromWallet = wallet.Canonical(filter.Network, filter.FromWallet)
+	}
+	if filter.ToWallet != "" {
+		_, filter.ToWallet = wallet.Canonical(filter.Network, filter.ToWallet)
+	}
+	filter.Network = wallet.CanonicalNetwork(filter.Network)
+
+	// Times are stored without a time zone, in UTC
+	for _, t := range []**time.Time{&filter.ScheduledFrom, &filter.ScheduledTo, &filter.CreatedFrom, &filter.CreatedTo} {
+		if *t != nil {
+			utc := (*t).UTC()
+			*t = &utc
+		}
+	}
+
+	// Fetch one extra transaction to find out whether another page exists
+	limit := filter.Limit
+	filter.Limit++
+	transactions, err := s.repo.List(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scheduled transactions: %w", err)
+	}
+
+	page := &Page{Transactions: transactions}
+	if len(transactions) > limit {
+		page.Transactions = transactions[:limit]
+		page.NextCursor = page.Transactions[limit-1].ID
+	}
+
+	return page, nil
+}
+
+func validateFilter(filter ListFilter) error {
+	switch filter.Status {
+	case "", schedule.StatusAwaitingApproval, schedule.StatusPending, schedule.StatusPublished, schedule.StatusCompleted, schedule.StatusFailed, schedule.StatusCancelled:
+	default:
+		return fmt.Errorf("%w: unknown status %q", ErrInvalidFilter, filter.Status)
+	}
+
+	if filter.Limit < 0 || filter.Cursor < 0 {
+		return fmt.Errorf("%w: limit and cursor must not be negative", ErrInvalidFilter)
+	}
+	if filter.ScheduledFrom != nil && filter.ScheduledTo != nil && !filter.ScheduledFrom.Before(*filter.ScheduledTo) {
+		return fmt.Errorf("%w: scheduled_from must be before scheduled_to", ErrInvalidFilter)
+	}
+	if filter.CreatedFrom != nil && filter.CreatedTo != nil && !filter.CreatedFrom.Before(*filter.CreatedTo) {
+		return fmt.Errorf("%w: created_from must be before created_to", ErrInvalidFilter)
+	}
+	return nil
+}
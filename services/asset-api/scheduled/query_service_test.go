@@ -0,0 +1,89 @@
+package scheduled
+
+import (
+	"asset-management/internal/schedule"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+type MockQueryRepository struct {
+	mock.Mock
+}
+
+func (m *MockQueryRepository) Get(id int) (*schedule.ScheduledTransaction, error) {
+	args := m.Called(id)
+	if txn, ok := args.Get(0).(*schedule.ScheduledTransaction); ok {
+		return txn, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockQueryRepository) List(filter ListFilter) ([]schedule.ScheduledTransaction, error) {
+	args := m.Called(filter)
+	if transactions, ok := args.Get(0).([]schedule.ScheduledTransaction); ok {
+		return transactions, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func TestQueryService_List_NextPage(t *testing.T) {
+	mockRepo := new(MockQueryRepository)
+	service := NewQueryService(mockRepo)
+
+	// One more transaction than requested is fetched to detect the next page
+	transactions := []schedule.ScheduledTransaction{{ID: 9}, {ID: 8}, {ID: 5}}
+	mockRepo.On("List", ListFilter{FromWallet: "wallet123", Status: schedule.StatusFailed, Limit: 3}).Return(transactions, nil)
+
+	page, err := service.List(ListFilter{FromWallet: "wallet123", Status: schedule.StatusFailed, Limit: 2})
+
+	assert.NoError(t, err)
+	assert.Equal(t, transactions[:2], page.Transactions)
+	assert.Equal(t, 8, page.NextCursor)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestQueryService_List_LastPage(t *testing.T) {
+	mockRepo := new(MockQueryRepository)
+	service := NewQueryService(mockRepo)
+
+	// Times are converted to UTC, in which they are stored
+	from := time.Date(2024, 10, 30, 17, 0, 0, 0, time.FixedZone("CET", 3600))
+	utc := from.UTC()
+	mockRepo.On("List", ListFilter{ScheduledFrom: &utc, Limit: DefaultListLimit + 1}).Return([]schedule.ScheduledTransaction{{ID: 1}}, nil)
+
+	page, err := service.List(ListFilter{ScheduledFrom: &from})
+
+	assert.NoError(t, err)
+	assert.Len(t, page.Transactions, 1)
+	assert.Zero(t, page.NextCursor)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestQueryService_List_InvalidFilter(t *testing.T) {
+	service := NewQueryService(new(MockQueryRepository))
+	from := time.Date(2024, 10, 30, 0, 0, 0, 0, time.UTC)
+	to := from.Add(-time.Hour)
+
+	for _, filter := range []ListFilter{
+		{Status: "DONE"},
+		{Limit: -1},
+		{ScheduledFrom: &from, ScheduledTo: &to},
+		{CreatedFrom: &from, CreatedTo: &from},
+	} {
+		_, err := service.List(filter)
+		assert.ErrorIs(t, err, ErrInvalidFilter)
+	}
+}
+
+func TestQueryService_List_RepositoryError(t *testing.T) {
+	mockRepo := new(MockQueryRepository)
+	service := NewQueryService(mockRepo)
+	mockRepo.On("List", ListFilter{Limit: MaxListLimit + 1}).Return(nil, errors.New("db down"))
+
+	_, err := service.List(ListFilter{Limit: 1000})
+
+	assert.EqualError(t, err, "failed to list scheduled transactions: db down")
+}
@@ -0,0 +1,112 @@
+package scheduled
+
+import (
+	"asset-management/internal/funds"
+	"asset-management/internal/limits"
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/shopspring/decimal"
+	"strconv"
+	"time"
+)
+
+type UpdateController struct {
+	service UpdateService
+}
+
+func NewUpdateController(service UpdateService) *UpdateController {
+	return &UpdateController{service: service}
+}
+
+type RescheduleRequest struct {
+	Amount        *decimal.Decimal `json:"amount,omitempty" swaggertype:"string" example:"150.00"`
+	ScheduledTime *string          `json:"scheduled_time,omitempty" example:"2024-01-01T12:00:00Z"`
+}
+
+// Cancel godoc
+// @Summary Cancel a scheduled transaction
+// @Description Cancels a scheduled transaction that is still pending
+// @Tags ScheduledTransaction
+// @Produce json
+// @Param id path int true "Transaction ID"
+// @Success 200 {object} ScheduledTransaction
+// @Failure 400 {object} map[string]string "error": "Invalid transaction ID"
+// @Failure 404 {object} map[string]string "error": "scheduled transaction not found"
+// @Failure 409 {object} map[string]string "error": "scheduled transaction is no longer pending"
+// @Failure 500 {object} map[string]string "error": "Failed to cancel transaction"
+// @Router /scheduled-transaction/{id} [delete]
+func (c *UpdateController) Cancel(ctx *fiber.Ctx) error {
+	id, err := strconv.Atoi(ctx.Params("id"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid transaction ID"})
+	}
+
+	txn, err := c.service.Cancel(id)
+	if err != nil {
+		return ctx.Status(updateErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(txn)
+}
+
+// Reschedule godoc
+// @Summary Reschedule a scheduled transaction
+// @Description Changes the scheduled time and/or amount of a scheduled transaction that is still pending
+// @Tags ScheduledTransaction
+// @Accept json
+// @Produce json
+// @Param id path int true "Transaction ID"
+// @Param transaction body RescheduleRequest true "Fields to change"
+// @Success 200 {object} ScheduledTransaction
+// @Failure 400 {object} map[string]string "error": "Invalid request payload"
+// @Failure 400 {object} map[string]string "error": "scheduled_time must not be in the past"
+// @Failure 400 {object} map[string]string "error": "insufficient available balance"
+// @Failure 404 {object} map[string]string "error": "scheduled transaction not found"
+// @Failure 409 {object} map[string]string "error": "scheduled transaction is no longer pending"
+// @Failure 422 {object} map[string]string "error": "new amount exceeds an approval threshold, schedule a new transaction instead"
+// @Failure 422 {object} map[string]string "error": "withdrawal limit exceeded"
+// @Failure 500 {object} map[string]string "error": "Failed to reschedule transaction"
+// @Router /scheduled-transaction/{id} [patch]
+func (c *UpdateController) Reschedule(ctx *fiber.Ctx) error {
+	id, err := strconv.Atoi(ctx.Params("id"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid transaction ID"})
+	}
+
+	var req RescheduleRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request payload"})
+	}
+
+	var scheduledTime *time.Time
+	if req.ScheduledTime != nil {
+		parsed, err := time.Parse(time.RFC3339, *req.ScheduledTime)
+		if err != nil {
+			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid scheduled time format"})
+		}
+		scheduledTime = &parsed
+	}
+
+	txn, err := c.service.Reschedule(id, scheduledTime, req.Amount)
+	if err != nil {
+		return ctx.Status(updateErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(txn)
+}
+
+func updateErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, ErrNotPending):
+		return fiber.StatusConflict
+	case errors.Is(err, errNothingToUpdate), errors.Is(err, errInvalidAmount), errors.Is(err, errTimeInPast),
+		errors.Is(err, funds.ErrInsufficientBalance), errors.Is(err, funds.ErrFundsHeld):
+		return fiber.StatusBadRequest
+	case errors.Is(err, ErrNeedsApproval), errors.Is(err, limits.ErrLimitExceeded):
+		return fiber.StatusUnprocessableEntity
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
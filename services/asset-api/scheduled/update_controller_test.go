@@ -0,0 +1,158 @@
+package scheduled_test
+
+import (
+	"asset-management/internal/funds"
+	"asset-management/internal/limits"
+	"asset-management/internal/schedule"
+	"asset-management/services/asset-api/scheduled"
+	"bytes"
+	"encoding/json"
+	"github.com/gofiber/fiber/v2"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type MockUpdateService struct {
+	mock.Mock
+}
+
+func (m *MockUpdateService) Cancel(id int) (*schedule.ScheduledTransaction, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*schedule.ScheduledTransaction), args.Error(1)
+}
+
+func (m *MockUpdateService) Reschedule(id int, scheduledTime *time.Time, amount *decimal.Decimal) (*schedule.ScheduledTransaction, error) {
+	args := m.Called(id, scheduledTime, amount)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*schedule.ScheduledTransaction), args.Error(1)
+}
+
+func newUpdateApp(service scheduled.UpdateService) *fiber.App {
+	controller := scheduled.NewUpdateController(service)
+	app := fiber.New()
+	app.Delete("/scheduled-transaction/:id", controller.Cancel)
+	app.Patch("/scheduled-transaction/:id", controller.Reschedule)
+	return app
+}
+
+func TestUpdateController_Cancel_Success(t *testing.T) {
+	mockService := new(MockUpdateService)
+	app := newUpdateApp(mockService)
+
+	mockService.On("Cancel", 123).Return(&schedule.ScheduledTransaction{ID: 123, Status: schedule.StatusCancelled}, nil)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodDelete, "/scheduled-transaction/123", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var txn schedule.ScheduledTransaction
+	_ = json.NewDecoder(resp.Body).Decode(&txn)
+	assert.Equal(t, schedule.StatusCancelled, txn.Status)
+	mockService.AssertExpectations(t)
+}
+
+func TestUpdateController_Cancel_NotFound(t *testing.T) {
+	mockService := new(MockUpdateService)
+	app := newUpdateApp(mockService)
+
+	mockService.On("Cancel", 123).Return(nil, scheduled.ErrNotFound)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodDelete, "/scheduled-transaction/123", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestUpdateController_Cancel_NotPending(t *testing.T) {
+	mockService := new(MockUpdateService)
+	app := newUpdateApp(mockService)
+
+	mockService.On("Cancel", 123).Return(nil, scheduled.ErrNotPending)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodDelete, "/scheduled-transaction/123", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+	var response map[string]string
+	_ = json.NewDecoder(resp.Body).Decode(&response)
+	assert.Equal(t, scheduled.ErrNotPending.Error(), response["error"])
+}
+
+func TestUpdateController_Reschedule_Success(t *testing.T) {
+	mockService := new(MockUpdateService)
+	app := newUpdateApp(mockService)
+
+	newTime := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+	mockService.On("Reschedule", 123, &newTime, (*decimal.Decimal)(nil)).
+		Return(&schedule.ScheduledTransaction{ID: 123, ScheduledTime: newTime, Status: schedule.StatusPending}, nil)
+
+	req := httptest.NewRequest(http.MethodPatch, "/scheduled-transaction/123", bytes.NewBufferString(`{"scheduled_time":"2030-01-01T12:00:00Z"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	mockService.AssertExpectations(t)
+}
+
+func TestUpdateController_Reschedule_InvalidScheduledTime(t *testing.T) {
+	mockService := new(MockUpdateService)
+	app := newUpdateApp(mockService)
+
+	req := httptest.NewRequest(http.MethodPatch, "/scheduled-transaction/123", bytes.NewBufferString(`{"scheduled_time":"tomorrow"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	mockService.AssertNotCalled(t, "Reschedule", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUpdateController_Reschedule_NeedsApproval(t *testing.T) {
+	mockService := new(MockUpdateService)
+	app := newUpdateApp(mockService)
+
+	mockService.On("Reschedule", 123, (*time.Time)(nil), mock.Anything).Return(nil, scheduled.ErrNeedsApproval)
+
+	req := httptest.NewRequest(http.MethodPatch, "/scheduled-transaction/123", bytes.NewBufferString(`{"amount":"5000"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+	mockService.AssertExpectations(t)
+}
+
+func TestUpdateController_Reschedule_FundsHeld(t *testing.T) {
+	mockService := new(MockUpdateService)
+	app := newUpdateApp(mockService)
+
+	mockService.On("Reschedule", 123, (*time.Time)(nil), mock.Anything).Return(nil, funds.ErrFundsHeld)
+
+	req := httptest.NewRequest(http.MethodPatch, "/scheduled-transaction/123", bytes.NewBufferString(`{"amount":"5000"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	mockService.AssertExpectations(t)
+}
+
+func TestUpdateController_Reschedule_LimitExceeded(t *testing.T) {
+	mockService := new(MockUpdateService)
+	app := newUpdateApp(mockService)
+
+	mockService.On("Reschedule", 123, (*time.Time)(nil), mock.Anything).Return(nil, &limits.Violation{Detail: "at most 50 ETH per transaction"})
+
+	req := httptest.NewRequest(http.MethodPatch, "/scheduled-transaction/123", bytes.NewBufferString(`{"amount":"5000"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,152 @@
+package scheduled
+
+import (
+	"asset-management/internal/funds"
+	"asset-management/internal/limits"
+	"asset-management/internal/schedule"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+var (
+	ErrNotFound   = errors.New("scheduled transaction not found")
+	ErrNotPending = errors.New("scheduled transaction is no longer pending")
+	// ErrNeedsApproval is returned when a new amount exceeds an approval threshold. Such transfers
+	// are scheduled anew, so the amount is approved before it is held.
+	ErrNeedsApproval = errors.New("new amount exceeds an approval threshold, schedule a new transaction instead")
+)
+
+type UpdateRepository interface {
+	Cancel(id int) (*schedule.ScheduledTransaction, error)
+	Reschedule(id int, scheduledTime *time.Time, amount decimal.NullDecimal) (*schedule.ScheduledTransaction, error)
+}
+
+type postgresUpdateRepository struct {
+	db *sql.DB
+}
+
+func NewUpdateRepository(db *sql.DB) UpdateRepository {
+	return &postgresUpdateRepository{db: db}
+}
+
+// Cancel moves a pending scheduled transaction to CANCELLED.
+func (r *postgresUpdateRepository) Cancel(id int) (*schedule.ScheduledTransaction, error) {
+	return r.updatePending(id, nil, `
+		UPDATE scheduled_transactions SET status = $2
+		WHERE scheduled_transaction_id = $1`, schedule.StatusCancelled)
+}
+
+// Reschedule changes the time and/or amount of a pending scheduled transaction; nil values are left unchanged.
+// A new amount above an approval threshold fails with ErrNeedsApproval. A larger one must be covered
+// by the sender's available funds, since the transaction holds it, and is checked against withdrawal
+// limits as if the transaction had been scheduled with it.
+func (r *postgresUpdateRepository) Reschedule(id int, scheduledTime *time.Time, amount decimal.NullDecimal) (*schedule.ScheduledTransaction, error) {
+	var check func(ctx context.Context, tx *sql.Tx, previous, txn schedule.ScheduledTransaction) error
+	if amount.Valid {
+		check = checkAmount
+	}
+	return r.updatePending(id, check, `
+		UPDATE scheduled_transactions
+		SET scheduled_time = COALESCE($2::TIMESTAMP, scheduled_time), amount = COALESCE($3::NUMERIC, amount)
+		WHERE scheduled_transaction_id = $1`, scheduledTime, amount)
+}
+
+func checkAmount(ctx context.Context, tx *sql.Tx, previous, txn schedule.ScheduledTransaction) error {
+	if err := checkThreshold(ctx, tx, txn); err != nil {
+		return err
+	}
+	if !txn.Amount.GreaterThan(previous.Amount) {
+		return nil
+	}
+
+	// The hold already includes the new amount, so the rest of the funds must cover it
+	sender, err := funds.Lock(ctx, tx, txn.FromWallet, txn.Network, txn.Asset)
+	if err != nil {
+		return err
+	}
+	sender.Held = sender.Held.Sub(txn.Amount)
+	if err := sender.Cover(txn.Amount, txn.Asset); err != nil {
+		return err
+	}
+	return limits.Check(ctx, tx, limits.Outflow{
+		WalletAddress: txn.FromWallet,
+		Network:       txn.Network,
+		Asset:         txn.Asset,
+		Amount:        txn.Amount,
+		Funds:         *sender,
+		Replaces:      txn.ID,
+	})
+}
+
+func checkThreshold(ctx context.Context, tx *sql.Tx, txn schedule.ScheduledTransaction) error {
+	threshold, err := limits.ApprovalThreshold(ctx, tx, limits.Outflow{
+		WalletAddress: txn.FromWallet,
+		Network:       txn.Network,
+		Asset:         txn.Asset,
+		Amount:        txn.Amount,
+	})
+	if err != nil {
+		return err
+	}
+	if threshold != nil {
+		return fmt.Errorf("%w: %s allows at most %s %s", ErrNeedsApproval, threshold, threshold.Value, threshold.Asset)
+	}
+	return nil
+}
+
+// updatePending locks the row the same way the consumer's Process does, so an update either happens
+// before processing starts or fails with ErrNotPending once the transaction has been processed. If
+// check is set, it vets the updated transaction against the previous one before the update is committed.
+// The row is locked before the sender's balance, in the same order Process locks them.
+func (r *postgresUpdateRepository) updatePending(id int, check func(ctx context.Context, tx *sql.Tx, previous, txn schedule.ScheduledTransaction) error,
+	query string, args ...interface{}) (*schedule.ScheduledTransaction, error) {
+	ctx := context.Background()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	previous, err := schedule.Scan(tx.QueryRowContext(ctx, `
+		SELECT `+schedule.Columns+`
+		FROM scheduled_transactions
+		WHERE scheduled_transaction_id = $1 FOR UPDATE`, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to lock scheduled transaction: %v", err)
+	}
+
+	if previous.Status != schedule.StatusPending {
+		return nil, ErrNotPending
+	}
+
+	if _, err := tx.ExecContext(ctx, query, append([]interface{}{id}, args...)...); err != nil {
+		return nil, fmt.Errorf("failed to update scheduled transaction: %v", err)
+	}
+
+	txn, err := schedule.Scan(tx.QueryRowContext(ctx, `
+		SELECT `+schedule.Columns+`
+		FROM scheduled_transactions
+		WHERE scheduled_transaction_id = $1`, id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scheduled transaction: %v", err)
+	}
+
+	if check != nil {
+		if err := check(ctx, tx, previous, txn); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return &txn, nil
+}
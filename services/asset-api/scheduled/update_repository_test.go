@@ -0,0 +1,156 @@
+package scheduled_test
+
+import (
+	"asset-management/internal/funds"
+	"asset-management/internal/limits"
+	"asset-management/internal/schedule"
+	"asset-management/services/asset-api/scheduled"
+	"asset-management/services/asset-api/util"
+	"database/sql"
+	_ "github.com/lib/pq"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func insertScheduled(t *testing.T, db *sql.DB, status string) int {
+	// Scheduling holds the amount, so the sender is funded first
+	_, err := db.Exec(`
+		INSERT INTO balance (wallet_address, network, asset, balance) VALUES ('wallet123', 'mainnet', 'ETH', 100.50)
+		ON CONFLICT (wallet_address, network, asset) DO UPDATE SET balance = balance.balance + EXCLUDED.balance`)
+	assert.NoError(t, err)
+
+	id, err := scheduled.NewCreateRepository(db).Create(&schedule.ScheduledTransaction{
+		FromWallet:    "wallet123",
+		ToWallet:      "wallet456",
+		Network:       "mainnet",
+		Asset:         "ETH",
+		Amount:        decimal.RequireFromString("100.50"),
+		ScheduledTime: time.Now().Add(24 * time.Hour),
+		Status:        status,
+	}, "")
+	assert.NoError(t, err)
+	return id
+}
+
+func TestPostgresUpdateRepository_Cancel_Success(t *testing.T) {
+	db, cleanup := util.SetupTestContainer(t)
+	defer cleanup()
+
+	repo := scheduled.NewUpdateRepository(db)
+	id := insertScheduled(t, db, schedule.StatusPending)
+
+	txn, err := repo.Cancel(id)
+	assert.NoError(t, err)
+	assert.Equal(t, schedule.StatusCancelled, txn.Status)
+}
+
+func TestPostgresUpdateRepository_Cancel_NotPending(t *testing.T) {
+	db, cleanup := util.SetupTestContainer(t)
+	defer cleanup()
+
+	repo := scheduled.NewUpdateRepository(db)
+	id := insertScheduled(t, db, schedule.StatusCompleted)
+
+	_, err := repo.Cancel(id)
+	assert.ErrorIs(t, err, scheduled.ErrNotPending)
+}
+
+func TestPostgresUpdateRepository_Cancel_NotFound(t *testing.T) {
+	db, cleanup := util.SetupTestContainer(t)
+	defer cleanup()
+
+	repo := scheduled.NewUpdateRepository(db)
+
+	_, err := repo.Cancel(999)
+	assert.ErrorIs(t, err, scheduled.ErrNotFound)
+}
+
+func TestPostgresUpdateRepository_Reschedule_Success(t *testing.T) {
+	db, cleanup := util.SetupTestContainer(t)
+	defer cleanup()
+
+	repo := scheduled.NewUpdateRepository(db)
+	id := insertScheduled(t, db, schedule.StatusPending)
+
+	// Only the amount changes, the scheduled time is kept
+	txn, err := repo.Reschedule(id, nil, decimal.NewNullDecimal(decimal.RequireFromString("42")))
+	assert.NoError(t, err)
+	assert.Equal(t, "42", txn.Amount.String())
+	assert.Equal(t, schedule.StatusPending, txn.Status)
+
+	newTime := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+	txn, err = repo.Reschedule(id, &newTime, decimal.NullDecimal{})
+	assert.NoError(t, err)
+	assert.True(t, newTime.Equal(txn.ScheduledTime))
+	assert.Equal(t, "42", txn.Amount.String())
+}
+
+func TestPostgresUpdateRepository_Reschedule_NeedsApproval(t *testing.T) {
+	db, cleanup := util.SetupTestContainer(t)
+	defer cleanup()
+
+	repo := scheduled.NewUpdateRepository(db)
+	id := insertScheduled(t, db, schedule.StatusPending)
+	_, err := db.Exec(`
+		INSERT INTO withdrawal_limits (scope, network, wallet_address, asset, kind, value)
+		VALUES ('NETWORK', 'mainnet', '', 'ETH', 'APPROVAL_THRESHOLD', 500)`)
+	assert.NoError(t, err)
+
+	_, err = repo.Reschedule(id, nil, decimal.NewNullDecimal(decimal.RequireFromString("501")))
+	assert.ErrorIs(t, err, scheduled.ErrNeedsApproval)
+
+	// The amount is left unchanged, and other changes are still allowed
+	newTime := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+	txn, err := repo.Reschedule(id, &newTime, decimal.NullDecimal{})
+	assert.NoError(t, err)
+	assert.Equal(t, "100.5", txn.Amount.String())
+}
+
+func TestPostgresUpdateRepository_Reschedule_CoversIncrease(t *testing.T) {
+	db, cleanup := util.SetupTestContainer(t)
+	defer cleanup()
+
+	repo := scheduled.NewUpdateRepository(db)
+	id := insertScheduled(t, db, schedule.StatusPending)
+	// The transaction holds 100.5 of 150.5, so its amount can rise by 50 at most
+	_, err := db.Exec(`UPDATE balance SET balance = 150.50 WHERE wallet_address = 'wallet123'`)
+	assert.NoError(t, err)
+
+	_, err = repo.Reschedule(id, nil, decimal.NewNullDecimal(decimal.RequireFromString("150.51")))
+	assert.ErrorIs(t, err, funds.ErrInsufficientBalance)
+	_, err = db.Exec(`
+		INSERT INTO scheduled_transactions (from_wallet_address, to_wallet_address, network, asset, amount, scheduled_time, status)
+		VALUES ('wallet123', 'wallet789', 'mainnet', 'ETH', 10, NOW() + INTERVAL '1 day', 'PENDING')`)
+	assert.NoError(t, err)
+	_, err = repo.Reschedule(id, nil, decimal.NewNullDecimal(decimal.RequireFromString("141")))
+	assert.ErrorIs(t, err, funds.ErrFundsHeld)
+
+	txn, err := repo.Reschedule(id, nil, decimal.NewNullDecimal(decimal.RequireFromString("140.5")))
+	assert.NoError(t, err)
+	assert.Equal(t, "140.5", txn.Amount.String())
+}
+
+func TestPostgresUpdateRepository_Reschedule_LimitExceeded(t *testing.T) {
+	db, cleanup := util.SetupTestContainer(t)
+	defer cleanup()
+
+	repo := scheduled.NewUpdateRepository(db)
+	id := insertScheduled(t, db, schedule.StatusPending)
+	_, err := db.Exec(`UPDATE balance SET balance = 500 WHERE wallet_address = 'wallet123'`)
+	assert.NoError(t, err)
+	_, err = db.Exec(`
+		INSERT INTO withdrawal_limits (scope, network, wallet_address, asset, kind, value)
+		VALUES ('GLOBAL', '', '', 'ETH', 'VOLUME_24H', 150)`)
+	assert.NoError(t, err)
+
+	// The previous amount of the transaction is not counted twice
+	txn, err := repo.Reschedule(id, nil, decimal.NewNullDecimal(decimal.RequireFromString("150")))
+	assert.NoError(t, err)
+	assert.Equal(t, "150", txn.Amount.String())
+
+	_, err = repo.Reschedule(id, nil, decimal.NewNullDecimal(decimal.RequireFromString("151")))
+	assert.ErrorIs(t, err, limits.ErrLimitExceeded)
+	assert.Contains(t, err.Error(), "0 ETH already used")
+}
@@ -0,0 +1,53 @@
+package scheduled
+
+import (
+	"asset-management/internal/schedule"
+	"errors"
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+var (
+	errNothingToUpdate = errors.New("scheduled_time or amount is required")
+	errInvalidAmount   = errors.New("amount must be greater than zero")
+	errTimeInPast      = errors.New("scheduled_time must not be in the past")
+)
+
+type UpdateService interface {
+	Cancel(id int) (*schedule.ScheduledTransaction, error)
+	Reschedule(id int, scheduledTime *time.Time, amount *decimal.Decimal) (*schedule.ScheduledTransaction, error)
+}
+
+type updateService struct {
+	repo UpdateRepository
+}
+
+func NewUpdateService(repo UpdateRepository) UpdateService {
+	return &updateService{repo: repo}
+}
+
+func (s *updateService) Cancel(id int) (*schedule.ScheduledTransaction, error) {
+	return s.repo.Cancel(id)
+}
+
+// Reschedule rejects a new time in the past, which would make the transaction due at once, and a new
+// amount that is not positive.
+func (s *updateService) Reschedule(id int, scheduledTime *time.Time, amount *decimal.Decimal) (*schedule.ScheduledTransaction, error) {
+	if scheduledTime == nil && amount == nil {
+		return nil, errNothingToUpdate
+	}
+
+	if scheduledTime != nil && scheduledTime.Before(time.Now()) {
+		return nil, errTimeInPast
+	}
+
+	var newAmount decimal.NullDecimal
+	if amount != nil {
+		if !amount.IsPositive() {
+			return nil, errInvalidAmount
+		}
+		newAmount = decimal.NewNullDecimal(*amount)
+	}
+
+	return s.repo.Reschedule(id, scheduledTime, newAmount)
+}
@@ -0,0 +1,83 @@
+package scheduled
+
+import (
+	"asset-management/internal/schedule"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+type MockUpdateRepository struct {
+	mock.Mock
+}
+
+func (m *MockUpdateRepository) Cancel(id int) (*schedule.ScheduledTransaction, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*schedule.ScheduledTransaction), args.Error(1)
+}
+
+func (m *MockUpdateRepository) Reschedule(id int, scheduledTime *time.Time, amount decimal.NullDecimal) (*schedule.ScheduledTransaction, error) {
+	args := m.Called(id, scheduledTime, amount)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*schedule.ScheduledTransaction), args.Error(1)
+}
+
+func TestUpdateService_Cancel_NotPending(t *testing.T) {
+	mockRepo := new(MockUpdateRepository)
+	service := NewUpdateService(mockRepo)
+
+	mockRepo.On("Cancel", 1).Return(nil, ErrNotPending)
+
+	_, err := service.Cancel(1)
+	assert.ErrorIs(t, err, ErrNotPending)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateService_Reschedule_Success(t *testing.T) {
+	mockRepo := new(MockUpdateRepository)
+	service := NewUpdateService(mockRepo)
+
+	amount := decimal.RequireFromString("10")
+	expected := &schedule.ScheduledTransaction{ID: 1, Amount: amount, Status: schedule.StatusPending}
+	mockRepo.On("Reschedule", 1, (*time.Time)(nil), decimal.NewNullDecimal(amount)).Return(expected, nil)
+
+	txn, err := service.Reschedule(1, nil, &amount)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, txn)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateService_Reschedule_NothingToUpdate(t *testing.T) {
+	mockRepo := new(MockUpdateRepository)
+	service := NewUpdateService(mockRepo)
+
+	_, err := service.Reschedule(1, nil, nil)
+	assert.ErrorIs(t, err, errNothingToUpdate)
+	mockRepo.AssertNotCalled(t, "Reschedule", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUpdateService_Reschedule_InvalidAmount(t *testing.T) {
+	mockRepo := new(MockUpdateRepository)
+	service := NewUpdateService(mockRepo)
+
+	amount := decimal.RequireFromString("-1")
+	_, err := service.Reschedule(1, nil, &amount)
+	assert.ErrorIs(t, err, errInvalidAmount)
+}
+
+func TestUpdateService_Reschedule_TimeInPast(t *testing.T) {
+	mockRepo := new(MockUpdateRepository)
+	service := NewUpdateService(mockRepo)
+
+	past := time.Now().Add(-time.Hour)
+	_, err := service.Reschedule(1, &past, nil)
+	assert.ErrorIs(t, err, errTimeInPast)
+	mockRepo.AssertNotCalled(t, "Reschedule", mock.Anything, mock.Anything, mock.Anything)
+}
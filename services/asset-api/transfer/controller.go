@@ -0,0 +1,73 @@
+package transfer
+
+import (
+	"asset-management/internal/limits"
+	"asset-management/services/asset-api/dto"
+	"asset-management/services/asset-api/wallet"
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/shopspring/decimal"
+)
+
+type Request struct {
+	FromWallet string          `json:"from_wallet" example:"0x123abc456def"`
+	ToWallet   string          `json:"to_wallet" example:"0x789ghi012jkl"`
+	Network    string          `json:"network" example:"Ethereum"`
+	Asset      string          `json:"asset" example:"ETH"`
+	Amount     decimal.Decimal `json:"amount" swaggertype:"string" example:"100.50"`
+}
+
+type Response struct {
+	Reference   string          `json:"reference" example:"transfer:4f1c2a8e-5d7b-4c1e-9a3f-0b6d8e2f1a7c"`
+	FromBalance decimal.Decimal `json:"from_balance" swaggertype:"string" example:"899.50"`
+	ToBalance   decimal.Decimal `json:"to_balance" swaggertype:"string" example:"100.50"`
+}
+
+type Controller interface {
+	Transfer(ctx *fiber.Ctx) error
+}
+
+type controller struct {
+	service Service
+}
+
+func NewController(service Service) Controller {
+	return &controller{service: service}
+}
+
+// Transfer godoc
+// @Summary      Transfer assets
+// @Description  Immediately moves an amount of an asset from one wallet to another on the same network
+// @Tags         transfer
+// @Accept       json
+// @Produce      json
+// @Param        transferRequest body Request true "Transfer request payload"
+// @Param        Idempotency-Key header string false "Key that makes retries of this request safe"
+// @Success      200  {object}  Response
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      403  {object}  dto.ErrorResponse "Source wallet is frozen"
+// @Failure      409  {object}  dto.ErrorResponse "A wallet is being deleted, or Idempotency-Key reused with a different payload or still in progress"
+// @Failure      422  {object}  dto.ErrorResponse "Withdrawal limit or approval threshold exceeded"
+// @Router       /transfer [post]
+func (c *controller) Transfer(ctx *fiber.Ctx) error {
+	var req Request
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Message: "Invalid request payload"})
+	}
+
+	response, err := c.service.Transfer(req.FromWallet, req.ToWallet, req.Network, req.Asset, req.Amount)
+	if err != nil {
+		if errors.Is(err, wallet.ErrWalletFrozen) {
+			return ctx.Status(fiber.StatusForbidden).JSON(dto.ErrorResponse{Message: err.Error()})
+		}
+		if errors.Is(err, wallet.ErrWalletDeleting) {
+			return ctx.Status(fiber.StatusConflict).JSON(dto.ErrorResponse{Message: err.Error()})
+		}
+		if errors.Is(err, limits.ErrLimitExceeded) || errors.Is(err, ErrNeedsApproval) {
+			return ctx.Status(fiber.StatusUnprocessableEntity).JSON(dto.ErrorResponse{Message: err.Error()})
+		}
+		return ctx.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Message: err.Error()})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response)
+}
@@ -0,0 +1,173 @@
+package transfer_test
+
+import (
+	"asset-management/internal/limits"
+	"asset-management/services/asset-api/dto"
+	"asset-management/services/asset-api/transfer"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type MockService struct {
+	mock.Mock
+}
+
+func (m *MockService) Transfer(from, to, network, asset string, amount decimal.Decimal) (*transfer.Response, error) {
+	args := m.Called(from, to, network, asset, amount)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*transfer.Response), args.Error(1)
+}
+
+func TestController_Transfer_Success(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockService)
+	controller := transfer.NewController(mockService)
+
+	app.Post("/transfer", controller.Transfer)
+
+	// Arrange
+	req := transfer.Request{
+		FromWallet: "0x123abc456def",
+		ToWallet:   "0x789ghi012jkl",
+		Network:    "Ethereum",
+		Asset:      "ETH",
+		Amount:     decimal.RequireFromString("100.5"),
+	}
+	mockService.On("Transfer", req.FromWallet, req.ToWallet, req.Network, req.Asset, req.Amount).
+		Return(&transfer.Response{
+			Reference:   "transfer:abc",
+			FromBalance: decimal.RequireFromString("899.5"),
+			ToBalance:   decimal.RequireFromString("100.5"),
+		}, nil)
+
+	body, _ := json.Marshal(req)
+	request := httptest.NewRequest(http.MethodPost, "/transfer", bytes.NewBuffer(body))
+	request.Header.Set("Content-Type", "application/json")
+	response, _ := app.Test(request)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	var res transfer.Response
+	_ = json.NewDecoder(response.Body).Decode(&res)
+	assert.Equal(t, "transfer:abc", res.Reference)
+	assert.Equal(t, "899.5", res.FromBalance.String())
+	assert.Equal(t, "100.5", res.ToBalance.String())
+	mockService.AssertExpectations(t)
+}
+
+func TestController_Transfer_InvalidPayload(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockService)
+	controller := transfer.NewController(mockService)
+
+	app.Post("/transfer", controller.Transfer)
+
+	// Act
+	request := httptest.NewRequest(http.MethodPost, "/transfer", bytes.NewBuffer([]byte(`invalid json`)))
+	request.Header.Set("Content-Type", "application/json")
+	response, _ := app.Test(request)
+
+	// Assert
+	assert.Equal(t, fiber.StatusBadRequest, response.StatusCode)
+
+	var errorResponse dto.ErrorResponse
+	_ = json.NewDecoder(response.Body).Decode(&errorResponse)
+	assert.Equal(t, "Invalid request payload", errorResponse.Message)
+}
+
+func TestController_Transfer_ServiceError(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockService)
+	controller := transfer.NewController(mockService)
+
+	app.Post("/transfer", controller.Transfer)
+
+	// Arrange
+	req := transfer.Request{
+		FromWallet: "0x123abc456def",
+		ToWallet:   "0x789ghi012jkl",
+		Network:    "Ethereum",
+		Asset:      "ETH",
+		Amount:     decimal.RequireFromString("100.5"),
+	}
+	mockService.On("Transfer", req.FromWallet, req.ToWallet, req.Network, req.Asset, req.Amount).
+		Return(nil, errors.New("insufficient balance in sender's wallet"))
+
+	body, _ := json.Marshal(req)
+	request := httptest.NewRequest(http.MethodPost, "/transfer", bytes.NewBuffer(body))
+	request.Header.Set("Content-Type", "application/json")
+	response, _ := app.Test(request)
+
+	// Assert
+	assert.Equal(t, fiber.StatusBadRequest, response.StatusCode)
+
+	var errorResponse dto.ErrorResponse
+	_ = json.NewDecoder(response.Body).Decode(&errorResponse)
+	assert.Equal(t, "insufficient balance in sender's wallet", errorResponse.Message)
+}
+
+func TestController_Transfer_LimitExceeded(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockService)
+	controller := transfer.NewController(mockService)
+
+	app.Post("/transfer", controller.Transfer)
+
+	// Arrange
+	req := transfer.Request{
+		FromWallet: "0x123abc456def",
+		ToWallet:   "0x789ghi012jkl",
+		Network:    "Ethereum",
+		Asset:      "ETH",
+		Amount:     decimal.RequireFromString("100.5"),
+	}
+	mockService.On("Transfer", req.FromWallet, req.ToWallet, req.Network, req.Asset, req.Amount).
+		Return(nil, fmt.Errorf("transfer failed: %w", &limits.Violation{Detail: "50 ETH already used"}))
+
+	body, _ := json.Marshal(req)
+	request := httptest.NewRequest(http.MethodPost, "/transfer", bytes.NewBuffer(body))
+	request.Header.Set("Content-Type", "application/json")
+	response, _ := app.Test(request)
+
+	// Assert
+	assert.Equal(t, fiber.StatusUnprocessableEntity, response.StatusCode)
+}
+
+func TestController_Transfer_NeedsApproval(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockService)
+	controller := transfer.NewController(mockService)
+
+	app.Post("/transfer", controller.Transfer)
+
+	// Arrange
+	req := transfer.Request{
+		FromWallet: "0x123abc456def",
+		ToWallet:   "0x789ghi012jkl",
+		Network:    "Ethereum",
+		Asset:      "ETH",
+		Amount:     decimal.RequireFromString("100.5"),
+	}
+	mockService.On("Transfer", req.FromWallet, req.ToWallet, req.Network, req.Asset, req.Amount).
+		Return(nil, fmt.Errorf("transfer failed: %w", transfer.ErrNeedsApproval))
+
+	body, _ := json.Marshal(req)
+	request := httptest.NewRequest(http.MethodPost, "/transfer", bytes.NewBuffer(body))
+	request.Header.Set("Content-Type", "application/json")
+	response, _ := app.Test(request)
+
+	// Assert
+	assert.Equal(t, fiber.StatusUnprocessableEntity, response.StatusCode)
+}
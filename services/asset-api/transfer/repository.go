@@ -0,0 +1,106 @@
+package transfer
+
+import (
+	"asset-management/internal/funds"
+	"asset-management/internal/ledger"
+	"asset-management/internal/limits"
+	"asset-management/internal/outbox"
+	"asset-management/pkg/event"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// ErrNeedsApproval is returned when the amount exceeds an approval threshold. Transfers are immediate
+// and cannot wait for approval, so such amounts are scheduled instead.
+var ErrNeedsApproval = errors.New("amount exceeds an approval threshold, schedule the transfer instead")
+
+type Repository interface {
+	Transfer(from, to, network, asset string, amount decimal.Decimal) (*Response, error)
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+// Transfer moves the amount at once. It fails with a limits.Violation if the outflow breaks a withdrawal
+// limit of the sender, and with ErrNeedsApproval if it exceeds an approval threshold.
+func (r *repository) Transfer(from, to, network, asset string, amount decimal.Decimal) (*Response, error) {
+	ctx := context.Background()
+
+	// Start a transaction
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Debit and credit both wallets with the same locking as scheduled transfers
+	reference := "transfer:" + uuid.NewString()
+	result, err := funds.Move(ctx, tx, funds.Transfer{
+		From:      from,
+		To:        to,
+		Network:   network,
+		Asset:     asset,
+		Amount:    amount,
+		Operation: ledger.OperationTransfer,
+		Reference: reference,
+
+		RespectHolds: true,
+		Check: func(sender funds.Funds) error {
+			outflow := limits.Outflow{
+				WalletAddress: from,
+				Network:       network,
+				Asset:         asset,
+				Amount:        amount,
+				Funds:         sender,
+			}
+			if err := limits.Check(ctx, tx, outflow); err != nil {
+				return err
+			}
+			threshold, err := limits.ApprovalThreshold(ctx, tx, outflow)
+			if err != nil {
+				return err
+			}
+			if threshold != nil {
+				return fmt.Errorf("%w: %s allows at most %s %s", ErrNeedsApproval, threshold, threshold.Value, threshold.Asset)
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = outbox.Write(ctx, tx, event.TypeTransferCompleted, event.TransferCompleted{
+		Reference:   reference,
+		FromWallet:  from,
+		ToWallet:    to,
+		Network:     network,
+		Asset:       asset,
+		Amount:      amount,
+		FromBalance: result.FromBalance,
+		ToBalance:   result.ToBalance,
+	}, from)
+	if err != nil {
+		return nil, err
+	}
+
+	// Commit the transaction
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &Response{
+		Reference:   reference,
+		FromBalance: result.FromBalance,
+		ToBalance:   result.ToBalance,
+	}, nil
+}
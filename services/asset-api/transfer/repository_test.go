@@ -0,0 +1,130 @@
+package transfer
+
+import (
+	"asset-management/internal/funds"
+	"asset-management/internal/limits"
+	"asset-management/services/asset-api/util"
+	_ "github.com/lib/pq"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRepository_Transfer_Success(t *testing.T) {
+	db, cleanup := util.SetupTestContainer(t)
+	defer cleanup()
+
+	repo := NewRepository(db)
+
+	// Set up initial balance
+	err := util.InsertBalance(db, "0xabc", "Ethereum", "ETH", decimal.RequireFromString("200.00"))
+	assert.NoError(t, err)
+
+	// Act
+	response, err := repo.Transfer("0xabc", "0xdef", "Ethereum", "ETH", decimal.RequireFromString("50.25"))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "149.75", response.FromBalance.String())
+	assert.Equal(t, "50.25", response.ToBalance.String())
+
+	var entries int
+	err = db.QueryRow(`SELECT COUNT(*) FROM ledger_entries WHERE reference = $1`, response.Reference).Scan(&entries)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, entries)
+
+	var eventType, key string
+	err = db.QueryRow(`SELECT event_type, message_key FROM event_outbox WHERE envelope->'payload'->>'reference' = $1`, response.Reference).Scan(&eventType, &key)
+	assert.NoError(t, err)
+	assert.Equal(t, "transfer.completed", eventType)
+	assert.Equal(t, "0xabc", key)
+}
+
+func TestRepository_Transfer_InsufficientBalance(t *testing.T) {
+	db, cleanup := util.SetupTestContainer(t)
+	defer cleanup()
+
+	repo := NewRepository(db)
+
+	// Set up initial balance
+	err := util.InsertBalance(db, "0xabc", "Ethereum", "ETH", decimal.RequireFromString("10"))
+	assert.NoError(t, err)
+
+	// Act
+	_, err = repo.Transfer("0xabc", "0xdef", "Ethereum", "ETH", decimal.RequireFromString("50"))
+
+	// Assert
+	assert.ErrorIs(t, err, funds.ErrInsufficientBalance)
+
+	var receivers int
+	err = db.QueryRow(`SELECT COUNT(*) FROM balance WHERE wallet_address = '0xdef'`).Scan(&receivers)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, receivers)
+}
+
+func TestRepository_Transfer_FundsHeld(t *testing.T) {
+	db, cleanup := util.SetupTestContainer(t)
+	defer cleanup()
+
+	repo := NewRepository(db)
+	assert.NoError(t, util.InsertBalance(db, "0xabc", "Ethereum", "ETH", decimal.RequireFromString("10")))
+	_, err := db.Exec(`
+		INSERT INTO scheduled_transactions (from_wallet_address, to_wallet_address, network, asset, amount, scheduled_time, status)
+		VALUES ('0xabc', '0x456', 'Ethereum', 'ETH', 8, NOW() + INTERVAL '1 day', 'PUBLISHED')`)
+	assert.NoError(t, err)
+
+	_, err = repo.Transfer("0xabc", "0xdef", "Ethereum", "ETH", decimal.RequireFromString("5"))
+	assert.ErrorIs(t, err, funds.ErrFundsHeld)
+
+	response, err := repo.Transfer("0xabc", "0xdef", "Ethereum", "ETH", decimal.RequireFromString("2"))
+	assert.NoError(t, err)
+	if assert.NotNil(t, response) {
+		assert.Equal(t, "8", response.FromBalance.String())
+	}
+}
+
+func TestRepository_Transfer_LimitExceeded(t *testing.T) {
+	db, cleanup := util.SetupTestContainer(t)
+	defer cleanup()
+
+	repo := NewRepository(db)
+	assert.NoError(t, util.InsertBalance(db, "0xabc", "Ethereum", "ETH", decimal.RequireFromString("100")))
+	_, err := db.Exec(`
+		INSERT INTO withdrawal_limits (scope, network, wallet_address, asset, kind, value)
+		VALUES ('GLOBAL', '', '', 'ETH', 'VOLUME_24H', 50)`)
+	assert.NoError(t, err)
+
+	_, err = repo.Transfer("0xabc", "0xdef", "Ethereum", "ETH", decimal.RequireFromString("40"))
+	assert.NoError(t, err)
+
+	// The first transfer counts towards the limit
+	_, err = repo.Transfer("0xabc", "0xdef", "Ethereum", "ETH", decimal.RequireFromString("15"))
+	assert.ErrorIs(t, err, limits.ErrLimitExceeded)
+	assert.Contains(t, err.Error(), "40 ETH already used")
+
+	var balance decimal.Decimal
+	err = db.QueryRow(`SELECT balance FROM balance WHERE wallet_address = '0xabc' AND asset = 'ETH'`).Scan(&balance)
+	assert.NoError(t, err)
+	assert.Equal(t, "60", balance.String())
+}
+
+func TestRepository_Transfer_NeedsApproval(t *testing.T) {
+	db, cleanup := util.SetupTestContainer(t)
+	defer cleanup()
+
+	repo := NewRepository(db)
+	assert.NoError(t, util.InsertBalance(db, "0xabc", "Ethereum", "ETH", decimal.RequireFromString("100")))
+	_, err := db.Exec(`
+		INSERT INTO withdrawal_limits (scope, network, wallet_address, asset, kind, value)
+		VALUES ('GLOBAL', '', '', 'ETH', 'APPROVAL_THRESHOLD', 50)`)
+	assert.NoError(t, err)
+
+	_, err = repo.Transfer("0xabc", "0xdef", "Ethereum", "ETH", decimal.RequireFromString("60"))
+	assert.ErrorIs(t, err, ErrNeedsApproval)
+
+	response, err := repo.Transfer("0xabc", "0xdef", "Ethereum", "ETH", decimal.RequireFromString("50"))
+	assert.NoError(t, err)
+	if assert.NotNil(t, response) {
+		assert.Equal(t, "50", response.FromBalance.String())
+	}
+}
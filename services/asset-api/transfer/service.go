@@ -0,0 +1,44 @@
+package transfer
+
+import (
+	"asset-management/services/asset-api/wallet"
+	"errors"
+	"fmt"
+	"github.com/shopspring/decimal"
+)
+
+type Service interface {
+	Transfer(from, to, network, asset string, amount decimal.Decimal) (*Response, error)
+}
+
+type service struct {
+	transferRepository Repository
+	walletValidator    wallet.ValidationAdapter
+}
+
+func NewService(tr Repository, va wallet.ValidationAdapter) Service {
+	return &service{transferRepository: tr, walletValidator: va}
+}
+
+func (s *service) Transfer(from, to, network, asset string, amount decimal.Decimal) (*Response, error) {
+	if from == "" || to == "" || network == "" || asset == "" || !amount.IsPositive() {
+		return nil, errors.New("invalid input parameters")
+	}
+	_, to = wallet.Canonical(network, to)
+	network, from = wallet.Canonical(network, from)
+
+	if from == to {
+		return nil, errors.New("source and destination wallets must be different")
+	}
+
+	if err := s.walletValidator.Both(from, to, network); err != nil {
+		return nil, fmt.Errorf("wallet validation failed: %w", err)
+	}
+
+	response, err := s.transferRepository.Transfer(from, to, network, asset, amount)
+	if err != nil {
+		return nil, fmt.Errorf("transfer failed: %w", err)
+	}
+
+	return response, nil
+}
@@ -0,0 +1,132 @@
+package transfer_test
+
+import (
+	"asset-management/internal/funds"
+	"asset-management/services/asset-api/transfer"
+	"errors"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+)
+
+// Mock for ValidationAdapter
+type MockValidationAdapter struct {
+	mock.Mock
+}
+
+func (m *MockValidationAdapter) One(walletAddress, network string) error {
+	args := m.Called(walletAddress, network)
+	return args.Error(0)
+}
+
+func (m *MockValidationAdapter) CanDebit(walletAddress, network string) error {
+	args := m.Called(walletAddress, network)
+	return args.Error(0)
+}
+
+func (m *MockValidationAdapter) CanCredit(walletAddress, network string) error {
+	args := m.Called(walletAddress, network)
+	return args.Error(0)
+}
+
+func (m *MockValidationAdapter) Both(from, to, network string) error {
+	args := m.Called(from, to, network)
+	return args.Error(0)
+}
+
+// Mock for Repository
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) Transfer(from, to, network, asset string, amount decimal.Decimal) (*transfer.Response, error) {
+	args := m.Called(from, to, network, asset, amount)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*transfer.Response), args.Error(1)
+}
+
+func TestTransferService_Success(t *testing.T) {
+	mockValidator := new(MockValidationAdapter)
+	mockRepo := new(MockRepository)
+	service := transfer.NewService(mockRepo, mockValidator)
+
+	// Arrange
+	amount := decimal.RequireFromString("100.50")
+	expected := &transfer.Response{FromBalance: decimal.RequireFromString("899.50"), ToBalance: amount}
+	mockValidator.On("Both", "0xabc", "0xdef", "Ethereum").Return(nil)
+	mockRepo.On("Transfer", "0xabc", "0xdef", "Ethereum", "ETH", amount).Return(expected, nil)
+
+	// Act
+	response, err := service.Transfer("0xabc", "0xdef", "Ethereum", "ETH", amount)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, expected, response)
+	mockValidator.AssertExpectations(t)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTransferService_InvalidInput(t *testing.T) {
+	mockValidator := new(MockValidationAdapter)
+	mockRepo := new(MockRepository)
+	service := transfer.NewService(mockRepo, mockValidator)
+
+	// Act
+	_, err := service.Transfer("0xabc", "0xdef", "Ethereum", "ETH", decimal.Zero)
+
+	// Assert
+	assert.EqualError(t, err, "invalid input parameters")
+	mockValidator.AssertNotCalled(t, "Both", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTransferService_SameWallet(t *testing.T) {
+	mockValidator := new(MockValidationAdapter)
+	mockRepo := new(MockRepository)
+	service := transfer.NewService(mockRepo, mockValidator)
+
+	// Act
+	_, err := service.Transfer("0xabc", "0xabc", "Ethereum", "ETH", decimal.RequireFromString("1"))
+
+	// Assert
+	assert.EqualError(t, err, "source and destination wallets must be different")
+
+	// Two spellings of one wallet are the same wallet
+	_, err = service.Transfer("0x5AAEB6053F3E94C9B9A09F33669435E7EF1BEAED", "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", "ETH", "ETH", decimal.RequireFromString("1"))
+	assert.EqualError(t, err, "source and destination wallets must be different")
+}
+
+func TestTransferService_ValidationFailed(t *testing.T) {
+	mockValidator := new(MockValidationAdapter)
+	mockRepo := new(MockRepository)
+	service := transfer.NewService(mockRepo, mockValidator)
+
+	// Arrange
+	mockValidator.On("Both", "0xabc", "0xdef", "Ethereum").Return(errors.New("destination wallet validation failed"))
+
+	// Act
+	_, err := service.Transfer("0xabc", "0xdef", "Ethereum", "ETH", decimal.RequireFromString("1"))
+
+	// Assert
+	assert.ErrorContains(t, err, "wallet validation failed")
+	mockRepo.AssertNotCalled(t, "Transfer", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTransferService_InsufficientBalance(t *testing.T) {
+	mockValidator := new(MockValidationAdapter)
+	mockRepo := new(MockRepository)
+	service := transfer.NewService(mockRepo, mockValidator)
+
+	// Arrange
+	amount := decimal.RequireFromString("1")
+	mockValidator.On("Both", "0xabc", "0xdef", "Ethereum").Return(nil)
+	mockRepo.On("Transfer", "0xabc", "0xdef", "Ethereum", "ETH", amount).Return(nil, funds.ErrInsufficientBalance)
+
+	// Act
+	_, err := service.Transfer("0xabc", "0xdef", "Ethereum", "ETH", amount)
+
+	// Assert
+	assert.ErrorIs(t, err, funds.ErrInsufficientBalance)
+}
@@ -0,0 +1,107 @@
+package util_test
+
+import (
+	sql2 "asset-management/internal/sql"
+	"asset-management/services/asset-api/deposit"
+	"asset-management/services/asset-api/util"
+	"database/sql"
+	_ "github.com/lib/pq"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// legacySchema is the schema before balances were kept per asset.
+const legacySchema = `
+DROP TABLE balance, scheduled_transactions CASCADE;
+
+CREATE TABLE balance (
+	wallet_address VARCHAR(255) NOT NULL,
+	network VARCHAR(100) NOT NULL,
+	balance NUMERIC(30, 10) NOT NULL DEFAULT 0 CHECK (balance >= 0),
+	UNIQUE (wallet_address, network)
+);
+
+CREATE TABLE scheduled_transactions (
+    scheduled_transaction_id SERIAL PRIMARY KEY,
+    from_wallet_address VARCHAR(255) NOT NULL,
+    to_wallet_address VARCHAR(255) NOT NULL,
+    network VARCHAR(100) NOT NULL,
+    amount NUMERIC(30, 10) NOT NULL CHECK (amount > 0),
+    scheduled_time TIMESTAMP NOT NULL,
+    status VARCHAR(50) DEFAULT 'PENDING' CHECK (status IN ('PENDING', 'COMPLETED', 'FAILED')),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO balance (wallet_address, network, balance) VALUES ('0x123', 'Ethereum', 10), ('0x123', 'Polygon', 4);
+INSERT INTO scheduled_transactions (from_wallet_address, to_wallet_address, network, amount, scheduled_time)
+VALUES ('0x123', '0x456', 'eth', 5, NOW() + INTERVAL '1 hour');
+`
+
+func migrate(t *testing.T, db *sql.DB) {
+	t.Helper()
+	for _, statement := range []string{sql2.CreateBalanceTableSQL, sql2.CreateScheduledTransactionsTable} {
+		_, err := db.Exec(statement)
+		assert.NoError(t, err)
+	}
+}
+
+func TestRepository_MigrateLegacySchema(t *testing.T) {
+	db, cleanup := util.SetupTestContainer(t)
+	defer cleanup()
+
+	_, err := db.Exec(legacySchema)
+	assert.NoError(t, err)
+
+	migrate(t, db)
+	// Running again changes nothing
+	migrate(t, db)
+
+	// Existing balances hold the native asset of their network
+	var asset string
+	var balance decimal.Decimal
+	err = db.QueryRow(`SELECT asset, balance FROM balance WHERE wallet_address = '0x123' AND network = 'Ethereum'`).Scan(&asset, &balance)
+	assert.NoError(t, err)
+	assert.Equal(t, "ETH", asset)
+	assert.Equal(t, "10", balance.String())
+
+	err = db.QueryRow(`SELECT asset FROM balance WHERE network = 'Polygon'`).Scan(&asset)
+	assert.NoError(t, err)
+	assert.Equal(t, "POLYGON", asset)
+
+	err = db.QueryRow(`SELECT asset FROM scheduled_transactions`).Scan(&asset)
+	assert.NoError(t, err)
+	assert.Equal(t, "ETH", asset)
+
+	// Statuses added since are allowed
+	_, err = db.Exec(`UPDATE scheduled_transactions SET status = 'CANCELLED'`)
+	assert.NoError(t, err)
+	_, err = db.Exec(`UPDATE scheduled_transactions SET status = 'UNKNOWN'`)
+	assert.Error(t, err)
+
+	// Existing transactions are single occurrences
+	var occurrence int
+	var seriesID sql.NullInt64
+	err = db.QueryRow(`SELECT occurrence_number, series_id FROM scheduled_transactions`).Scan(&occurrence, &seriesID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, occurrence)
+	assert.False(t, seriesID.Valid)
+
+	// They have not been published
+	var attempts int
+	var publishedAt sql.NullTime
+	err = db.QueryRow(`SELECT publish_attempts, published_at FROM scheduled_transactions`).Scan(&attempts, &publishedAt)
+	assert.NoError(t, err)
+	assert.Zero(t, attempts)
+	assert.False(t, publishedAt.Valid)
+
+	_, err = db.Exec(`UPDATE scheduled_transactions SET status = 'FAILED', failure_reason = 'insufficient balance'`)
+	assert.NoError(t, err)
+
+	// A wallet holds several assets
+	_, err = deposit.NewRepository(db).Deposit("0x123", "Ethereum", "USDC", decimal.RequireFromString("3"))
+	assert.NoError(t, err)
+	balance, err = deposit.NewRepository(db).Deposit("0x123", "Ethereum", "ETH", decimal.RequireFromString("1"))
+	assert.NoError(t, err)
+	assert.Equal(t, "11", balance.String())
+}
@@ -4,6 +4,7 @@ import (
 	sql2 "asset-management/internal/sql"
 	"context"
 	"database/sql"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/wait"
@@ -57,6 +58,27 @@ func SetupTestContainer(t *testing.T) (*sql.DB, func()) {
 	_, err = db.Exec(sql2.CreateScheduledTransactionsTable)
 	assert.NoError(t, err)
 
+	_, err = db.Exec(sql2.CreateLedgerEntriesTable)
+	assert.NoError(t, err)
+
+	_, err = db.Exec(sql2.CreateIdempotencyKeysTable)
+	assert.NoError(t, err)
+
+	_, err = db.Exec(sql2.CreateEventOutboxTable)
+	assert.NoError(t, err)
+
+	_, err = db.Exec(sql2.CreateWithdrawalLimitsTable)
+	assert.NoError(t, err)
+
+	_, err = db.Exec(sql2.CreateApprovalRequestsTable)
+	assert.NoError(t, err)
+
+	_, err = db.Exec(sql2.CreateWalletAliasesTable)
+	assert.NoError(t, err)
+
+	_, err = db.Exec(sql2.CreateSchemaMigrationsTable)
+	assert.NoError(t, err)
+
 	// Cleanup function to terminate the container
 	cleanup := func() {
 		db.Close()
@@ -66,9 +88,9 @@ func SetupTestContainer(t *testing.T) (*sql.DB, func()) {
 	return db, cleanup
 }
 
-func InsertBalance(db *sql.DB, walletAddress, network string, balance float64) error {
+func InsertBalance(db *sql.DB, walletAddress, network, asset string, balance decimal.Decimal) error {
 	_, err := db.Exec(`
-		INSERT INTO balance (wallet_address, network, balance) 
-		VALUES ($1, $2, $3)`, walletAddress, network, balance)
+		INSERT INTO balance (wallet_address, network, asset, balance) 
+		VALUES ($1, $2, $3, $4)`, walletAddress, network, asset, balance)
 	return err
 }
@@ -0,0 +1,270 @@
+package wallet
+
+import (
+	limits2 "asset-management/internal/limits"
+	"asset-management/internal/network"
+	"database/sql"
+	"fmt"
+	"github.com/rs/zerolog/log"
+)
+
+// networks resolves the network and address spellings wallet-api accepts to the ones it stores.
+var networks = network.Default()
+
+// Canonical returns the network and address wallet-api stores a wallet under. Balances, ledger
+// entries, scheduled transactions, approvals and limits are keyed by them, so that every spelling of
+// a wallet reaches the same rows. Pairs that do not validate are returned unchanged.
+func Canonical(networkName, address string) (string, string) {
+	return networks.Canonical(networkName, address)
+}
+
+// CanonicalNetwork resolves network aliases such as ETH, leaving empty and unknown networks unchanged.
+func CanonicalNetwork(networkName string) string {
+	return networks.CanonicalName(networkName)
+}
+
+// canonicalKeysVersion records in schema_migrations that CanonicalizeKeys was applied.
+const canonicalKeysVersion = "canonical_wallet_keys"
+
+// CanonicalizeKeys moves rows keyed by another spelling of a wallet to its canonical form. Balances
+// are added to the canonical wallet's; scheduled transactions, approval requests and withdrawal limits
+// are rewritten. Ledger entries cannot change, so the spellings they were recorded under are kept in
+// wallet_aliases, through which the canonical wallet's ledger finds them.
+//
+// Every row written since keys are canonical is keyed that way, so the migration runs once and is
+// recorded in schema_migrations. Instances starting at the same time wait for the one applying it.
+func CanonicalizeKeys(db *sql.DB) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`INSERT INTO schema_migrations (version) VALUES ($1) ON CONFLICT (version) DO NOTHING`, canonicalKeysVersion)
+	if err != nil {
+		return fmt.Errorf("failed to record migration: %w", err)
+	}
+	if claimed, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to record migration: %w", err)
+	} else if claimed == 0 {
+		return nil
+	}
+
+	// Keeps funds from moving under a spelling while it is rewritten, in the order rows are locked elsewhere
+	if _, err := tx.Exec(`LOCK TABLE approval_requests, scheduled_transactions, balance, withdrawal_limits IN EXCLUSIVE MODE`); err != nil {
+		return fmt.Errorf("failed to lock tables: %w", err)
+	}
+
+	rows, err := tx.Query(`
+        SELECT network, wallet_address FROM balance
+        UNION SELECT network, wallet_address FROM ledger_entries entry
+            WHERE NOT EXISTS (SELECT 1 FROM wallet_aliases alias WHERE alias.network = entry.network AND alias.wallet_address = entry.wallet_address)
+        UNION SELECT network, wallet_address FROM approval_requests
+        UNION SELECT network, wallet_address FROM withdrawal_limits WHERE scope = $1`, limits2.ScopeWallet)
+	if err != nil {
+		return fmt.Errorf("failed to query wallet keys: %w", err)
+	}
+	var keys [][2]string
+	for rows.Next() {
+		var key [2]string
+		if err := rows.Scan(&key[0], &key[1]); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan wallet key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Close(); err != nil {
+		return fmt.Errorf("failed to close rows: %w", err)
+	}
+
+	for _, key := range keys {
+		walletNetwork, walletAddress := Canonical(key[0], key[1])
+		if walletNetwork == key[0] && walletAddress == key[1] {
+			continue
+		}
+		if err := moveWallet(tx, key[0], key[1], walletNetwork, walletAddress); err != nil {
+			return err
+		}
+		log.Info().Str("network", key[0]).Str("address", key[1]).
+			Str("canonical_network", walletNetwork).Str("canonical_address", walletAddress).
+			Msg("Moved wallet to its canonical network and address")
+	}
+
+	if err := canonicalizeScheduled(tx); err != nil {
+		return err
+	}
+	if err := canonicalizeNetworkRules(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// moveWallet rekeys the balances, approval requests and wallet limits of one spelling of a wallet.
+func moveWallet(tx *sql.Tx, fromNetwork, fromAddress, toNetwork, toAddress string) error {
+	_, err := tx.Exec(`
+        INSERT INTO balance (wallet_address, network, asset, balance)
+        SELECT $3, $4, asset, balance FROM balance WHERE wallet_address = $1 AND network = $2
+        ON CONFLICT (wallet_address, network, asset) DO UPDATE SET balance = balance.balance + EXCLUDED.balance`,
+		fromAddress, fromNetwork, toAddress, toNetwork)
+	if err != nil {
+		return fmt.Errorf("failed to merge balances: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM balance WHERE wallet_address = $1 AND network = $2`, fromAddress, fromNetwork); err != nil {
+		return fmt.Errorf("failed to delete merged balances: %w", err)
+	}
+
+	_, err = tx.Exec(`UPDATE approval_requests SET wallet_address = $3, network = $4 WHERE wallet_address = $1 AND network = $2`,
+		fromAddress, fromNetwork, toAddress, toNetwork)
+	if err != nil {
+		return fmt.Errorf("failed to update approval requests: %w", err)
+	}
+
+	_, err = tx.Exec(`
+        INSERT INTO wallet_aliases (network, wallet_address, canonical_network, canonical_address)
+        VALUES ($1, $2, $3, $4)
+        ON CONFLICT (network, wallet_address) DO NOTHING`, fromNetwork, fromAddress, toNetwork, toAddress)
+	if err != nil {
+		return fmt.Errorf("failed to record wallet alias: %w", err)
+	}
+
+	ids, err := ruleIDs(tx, `SELECT limit_id FROM withdrawal_limits WHERE scope = $1 AND network = $2 AND wallet_address = $3`,
+		limits2.ScopeWallet, fromNetwork, fromAddress)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if err := moveRule(tx, id, toNetwork, toAddress); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// canonicalizeScheduled rewrites scheduled transactions whose wallets both validate on their network.
+// Transactions with only one valid wallet are left as they are, since one network must hold both.
+func canonicalizeScheduled(tx *sql.Tx) error {
+	rows, err := tx.Query(`SELECT DISTINCT network, from_wallet_address, to_wallet_address FROM scheduled_transactions`)
+	if err != nil {
+		return fmt.Errorf("failed to query scheduled transaction wallets: %w", err)
+	}
+	var keys [][3]string
+	for rows.Next() {
+		var key [3]string
+		if err := rows.Scan(&key[0], &key[1], &key[2]); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan scheduled transaction wallets: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Close(); err != nil {
+		return fmt.Errorf("failed to close rows: %w", err)
+	}
+
+	for _, key := range keys {
+		fromNetwork, from := Canonical(key[0], key[1])
+		toNetwork, to := Canonical(key[0], key[2])
+		if fromNetwork == key[0] && from == key[1] && toNetwork == key[0] && to == key[2] {
+			continue
+		}
+		if fromNetwork != toNetwork {
+			log.Warn().Str("network", key[0]).Str("from_wallet_address", key[1]).Str("to_wallet_address", key[2]).
+				Msg("Left scheduled transactions with an invalid wallet under their original network and addresses")
+			continue
+		}
+
+		_, err := tx.Exec(`
+            UPDATE scheduled_transactions SET network = $4, from_wallet_address = $5, to_wallet_address = $6
+            WHERE network = $1 AND from_wallet_address = $2 AND to_wallet_address = $3`,
+			key[0], key[1], key[2], fromNetwork, from, to)
+		if err != nil {
+			return fmt.Errorf("failed to update scheduled transactions: %w", err)
+		}
+	}
+	return nil
+}
+
+// canonicalizeNetworkRules rewrites network limits set on a network alias.
+func canonicalizeNetworkRules(tx *sql.Tx) error {
+	rows, err := tx.Query(`SELECT limit_id, network FROM withdrawal_limits WHERE scope = $1`, limits2.ScopeNetwork)
+	if err != nil {
+		return fmt.Errorf("failed to query network limits: %w", err)
+	}
+	renamed := map[int]string{}
+	for rows.Next() {
+		var id int
+		var networkName string
+		if err := rows.Scan(&id, &networkName); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan network limit: %w", err)
+		}
+		if canonical := CanonicalNetwork(networkName); canonical != networkName {
+			renamed[id] = canonical
+		}
+	}
+	if err := rows.Close(); err != nil {
+		return fmt.Errorf("failed to close rows: %w", err)
+	}
+
+	for id, networkName := range renamed {
+		if err := moveRule(tx, id, networkName, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// moveRule rekeys a limit rule. If the canonical key has a rule of the same kind already, the stricter
+// of the two values is kept on it and the moved rule is deleted.
+func moveRule(tx *sql.Tx, id int, networkName, address string) error {
+	result, err := tx.Exec(`
+        UPDATE withdrawal_limits kept
+        SET value = CASE WHEN kept.kind = $4 THEN GREATEST(kept.value, moved.value) ELSE LEAST(kept.value, moved.value) END,
+            updated_at = CURRENT_TIMESTAMP
+        FROM withdrawal_limits moved
+        WHERE moved.limit_id = $1 AND kept.limit_id <> moved.limit_id
+          AND kept.scope = moved.scope AND kept.network = $2 AND kept.wallet_address = $3
+          AND kept.asset = moved.asset AND kept.kind = moved.kind`,
+		id, networkName, address, limits2.KindMinReserve)
+	if err != nil {
+		return fmt.Errorf("failed to merge limit %d: %w", id, err)
+	}
+	if merged, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to merge limit %d: %w", id, err)
+	} else if merged > 0 {
+		if _, err := tx.Exec(`DELETE FROM withdrawal_limits WHERE limit_id = $1`, id); err != nil {
+			return fmt.Errorf("failed to delete merged limit %d: %w", id, err)
+		}
+		return nil
+	}
+
+	_, err = tx.Exec(`UPDATE withdrawal_limits SET network = $2, wallet_address = $3, updated_at = CURRENT_TIMESTAMP WHERE limit_id = $1`,
+		id, networkName, address)
+	if err != nil {
+		return fmt.Errorf("failed to update limit %d: %w", id, err)
+	}
+	return nil
+}
+
+func ruleIDs(tx *sql.Tx, query string, args ...interface{}) ([]int, error) {
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query limits: %w", err)
+	}
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan limit: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close rows: %w", err)
+	}
+	return ids, nil
+}
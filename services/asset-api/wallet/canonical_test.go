@@ -0,0 +1,97 @@
+package wallet_test
+
+import (
+	"asset-management/services/asset-api/deposit"
+	"asset-management/services/asset-api/ledger"
+	"asset-management/services/asset-api/util"
+	"asset-management/services/asset-api/wallet"
+	_ "github.com/lib/pq"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+const (
+	legacyAddress    = "0x5AAEB6053F3E94C9B9A09F33669435E7EF1BEAED"
+	canonicalAddress = "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"
+)
+
+func TestCanonical(t *testing.T) {
+	network, address := wallet.Canonical("eth", legacyAddress)
+	assert.Equal(t, "Ethereum", network)
+	assert.Equal(t, canonicalAddress, address)
+
+	network, address = wallet.Canonical("ETH", "0x123")
+	assert.Equal(t, "ETH", network)
+	assert.Equal(t, "0x123", address)
+}
+
+func TestRepository_CanonicalizeKeys(t *testing.T) {
+	db, cleanup := util.SetupTestContainer(t)
+	defer cleanup()
+
+	// Rows keyed by the spelling callers used before keys were canonical
+	deposits := deposit.NewRepository(db)
+	_, err := deposits.Deposit(legacyAddress, "ETH", "ETH", decimal.RequireFromString("30"))
+	assert.NoError(t, err)
+	_, err = deposits.Deposit(canonicalAddress, "Ethereum", "ETH", decimal.RequireFromString("20"))
+	assert.NoError(t, err)
+	_, err = db.Exec(`
+		INSERT INTO scheduled_transactions (from_wallet_address, to_wallet_address, network, asset, amount, scheduled_time)
+		VALUES ($1, '0xFB6916095CA1DF60BB79CE92CE3EA74C37C5D359', 'ETH', 'ETH', 5, NOW() + INTERVAL '1 hour')`, legacyAddress)
+	assert.NoError(t, err)
+	_, err = db.Exec(`
+		INSERT INTO withdrawal_limits (scope, network, wallet_address, asset, kind, value) VALUES
+			('WALLET', 'ETH', $1, 'ETH', 'MAX_AMOUNT', 10),
+			('WALLET', 'Ethereum', $2, 'ETH', 'MAX_AMOUNT', 7),
+			('NETWORK', 'eth', '', 'ETH', 'VOLUME_24H', 100)`, legacyAddress, canonicalAddress)
+	assert.NoError(t, err)
+
+	assert.NoError(t, wallet.CanonicalizeKeys(db))
+
+	var balance decimal.Decimal
+	err = db.QueryRow(`SELECT balance FROM balance WHERE wallet_address = $1 AND network = 'Ethereum' AND asset = 'ETH'`, canonicalAddress).Scan(&balance)
+	assert.NoError(t, err)
+	assert.Equal(t, "50", balance.String())
+
+	var legacyRows int
+	err = db.QueryRow(`
+		SELECT (SELECT COUNT(*) FROM balance WHERE network <> 'Ethereum')
+		     + (SELECT COUNT(*) FROM scheduled_transactions WHERE network <> 'Ethereum')
+		     + (SELECT COUNT(*) FROM withdrawal_limits WHERE network <> 'Ethereum')`).Scan(&legacyRows)
+	assert.NoError(t, err)
+	assert.Zero(t, legacyRows)
+
+	var from, to string
+	err = db.QueryRow(`SELECT from_wallet_address, to_wallet_address FROM scheduled_transactions`).Scan(&from, &to)
+	assert.NoError(t, err)
+	assert.Equal(t, canonicalAddress, from)
+	assert.Equal(t, "0xfb6916095ca1df60bb79ce92ce3ea74c37c5d359", to)
+
+	// The stricter of two rules on the same wallet is kept
+	var limit decimal.Decimal
+	err = db.QueryRow(`SELECT value FROM withdrawal_limits WHERE scope = 'WALLET' AND wallet_address = $1`, canonicalAddress).Scan(&limit)
+	assert.NoError(t, err)
+	assert.Equal(t, "7", limit.String())
+
+	// The migration is applied once
+	var applied int
+	err = db.QueryRow(`SELECT COUNT(*) FROM schema_migrations WHERE version = 'canonical_wallet_keys'`).Scan(&applied)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, applied)
+
+	_, err = db.Exec(`INSERT INTO balance (wallet_address, network, asset, balance) VALUES ($1, 'ETH', 'BTC', 1)`, legacyAddress)
+	assert.NoError(t, err)
+	assert.NoError(t, wallet.CanonicalizeKeys(db))
+	// so a row written under another spelling afterwards is left alone
+	err = db.QueryRow(`SELECT COUNT(*) FROM balance WHERE network = 'ETH'`).Scan(&legacyRows)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, legacyRows)
+
+	// Ledger entries stay as recorded and are found through the alias
+	entries, err := ledger.NewRepository(db).GetEntries(canonicalAddress, "Ethereum", "", 0, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, entries, 2) {
+		assert.Equal(t, legacyAddress, entries[1].WalletAddress)
+	}
+}
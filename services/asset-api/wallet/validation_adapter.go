@@ -1,36 +1,85 @@
 package wallet
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"golang.org/x/sync/errgroup"
+	"io"
 	"net/http"
+	"time"
+)
+
+// statusFrozen mirrors the wallet-api status that blocks outgoing funds.
+const statusFrozen = "frozen"
+
+var (
+	ErrWalletFrozen   = errors.New("wallet is frozen")
+	ErrWalletDeleting = errors.New("wallet is being deleted")
+	ErrWalletNotFound = errors.New("wallet not found")
 )
 
 type ValidationAdapter interface {
+	// One reports whether the wallet exists.
 	One(walletAddress, network string) error
+	// Both checks that funds may move from one wallet to the other.
 	Both(from, to, network string) error
+	// CanDebit reports whether funds may leave the wallet.
+	CanDebit(walletAddress, network string) error
+	// CanCredit reports whether funds may arrive in the wallet.
+	CanCredit(walletAddress, network string) error
 }
 
 type walletValidationAdapter struct {
 	baseURL string
 }
 
+type walletStatus struct {
+	Status       string `json:"status"`
+	StatusReason string `json:"status_reason"`
+	// DeletingSince is set while the wallet's holdings are settled before it is deleted, which blocks
+	// funds in both directions.
+	DeletingSince *time.Time `json:"deleting_since"`
+}
+
 func NewValidationAdapter(baseURL string) ValidationAdapter {
 	return &walletValidationAdapter{baseURL: baseURL}
 }
 
 func (a *walletValidationAdapter) One(walletAddress, network string) error {
-	url := fmt.Sprintf("%s/wallet/%s/%s", a.baseURL, network, walletAddress)
+	_, err := a.fetch(walletAddress, network)
+	return err
+}
 
-	resp, err := http.Get(url)
+func (a *walletValidationAdapter) CanDebit(walletAddress, network string) error {
+	wallet, err := a.fetch(walletAddress, network)
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return errors.New("failed to validate wallet")
+	if wallet.DeletingSince != nil {
+		return ErrWalletDeleting
+	}
+	if wallet.Status == statusFrozen {
+		if wallet.StatusReason != "" {
+			return fmt.Errorf("%w: %s", ErrWalletFrozen, wallet.StatusReason)
+		}
+		return ErrWalletFrozen
+	}
+
+	return nil
+}
+
+// CanCredit requires the wallet to exist and not to be being deleted: a frozen
+// wallet may still receive funds, it just cannot send them.
+func (a *walletValidationAdapter) CanCredit(walletAddress, network string) error {
+	wallet, err := a.fetch(walletAddress, network)
+	if err != nil {
+		return err
+	}
+
+	if wallet.DeletingSince != nil {
+		return ErrWalletDeleting
 	}
 
 	return nil
@@ -40,18 +89,43 @@ func (a *walletValidationAdapter) Both(from, to, network string) error {
 	var g errgroup.Group
 
 	g.Go(func() error {
-		if err := a.One(from, network); err != nil {
-			return errors.New("source wallet validation failed: " + err.Error())
+		if err := a.CanDebit(from, network); err != nil {
+			return fmt.Errorf("source wallet validation failed: %w", err)
 		}
 		return nil
 	})
 
 	g.Go(func() error {
-		if err := a.One(to, network); err != nil {
-			return errors.New("destination wallet validation failed: " + err.Error())
+		if err := a.CanCredit(to, network); err != nil {
+			return fmt.Errorf("destination wallet validation failed: %w", err)
 		}
 		return nil
 	})
 
 	return g.Wait()
 }
+
+func (a *walletValidationAdapter) fetch(walletAddress, network string) (*walletStatus, error) {
+	url := fmt.Sprintf("%s/wallet/%s/%s", a.baseURL, network, walletAddress)
+
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrWalletNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New("failed to validate wallet")
+	}
+
+	// An empty body carries no status, which is treated as active
+	var wallet walletStatus
+	if err := json.NewDecoder(resp.Body).Decode(&wallet); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to decode wallet: %w", err)
+	}
+
+	return &wallet, nil
+}
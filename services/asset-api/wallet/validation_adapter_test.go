@@ -1,6 +1,7 @@
 package wallet
 
 import (
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -38,6 +39,20 @@ func TestOne_Failure(t *testing.T) {
 	}
 }
 
+func TestOne_NotFound(t *testing.T) {
+	mockServer := setupMockServer(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	defer mockServer.Close()
+
+	adapter := NewValidationAdapter(mockServer.URL)
+	err := adapter.One("testWallet", "testNetwork")
+
+	if !errors.Is(err, ErrWalletNotFound) {
+		t.Errorf("expected wallet not found error, got %v", err)
+	}
+}
+
 func TestBoth_Success(t *testing.T) {
 	mockServer := setupMockServer(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -104,3 +119,64 @@ func TestBoth_ParallelFailures(t *testing.T) {
 		t.Errorf("unexpected error: %v", err)
 	}
 }
+
+func TestCanDebit_Frozen(t *testing.T) {
+	mockServer := setupMockServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"frozen","status_reason":"Suspected key compromise"}`))
+	})
+	defer mockServer.Close()
+
+	adapter := NewValidationAdapter(mockServer.URL)
+	err := adapter.CanDebit("testWallet", "testNetwork")
+
+	if !errors.Is(err, ErrWalletFrozen) || err.Error() != "wallet is frozen: Suspected key compromise" {
+		t.Errorf("expected frozen wallet error, got %v", err)
+	}
+}
+
+func TestCanCredit_Frozen(t *testing.T) {
+	mockServer := setupMockServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"frozen"}`))
+	})
+	defer mockServer.Close()
+
+	adapter := NewValidationAdapter(mockServer.URL)
+
+	if err := adapter.CanCredit("testWallet", "testNetwork"); err != nil {
+		t.Errorf("expected frozen wallet to accept credits, got %v", err)
+	}
+}
+
+func TestDeleting(t *testing.T) {
+	mockServer := setupMockServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"frozen","deleting_since":"2026-10-18T09:00:00Z"}`))
+	})
+	defer mockServer.Close()
+
+	adapter := NewValidationAdapter(mockServer.URL)
+
+	if err := adapter.CanDebit("testWallet", "testNetwork"); !errors.Is(err, ErrWalletDeleting) {
+		t.Errorf("expected deleting wallet to refuse debits, got %v", err)
+	}
+	if err := adapter.CanCredit("testWallet", "testNetwork"); !errors.Is(err, ErrWalletDeleting) {
+		t.Errorf("expected deleting wallet to refuse credits, got %v", err)
+	}
+}
+
+func TestBoth_SourceFrozen(t *testing.T) {
+	mockServer := setupMockServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/wallet/testNetwork/fromWallet" {
+			w.Write([]byte(`{"status":"frozen"}`))
+		} else {
+			w.Write([]byte(`{"status":"active"}`))
+		}
+	})
+	defer mockServer.Close()
+
+	adapter := NewValidationAdapter(mockServer.URL)
+	err := adapter.Both("fromWallet", "toWallet", "testNetwork")
+
+	if !errors.Is(err, ErrWalletFrozen) {
+		t.Errorf("expected frozen wallet error, got %v", err)
+	}
+}
@@ -1,18 +1,32 @@
 package withdraw
 
 import (
+	"asset-management/internal/approval"
+	"asset-management/internal/limits"
 	"asset-management/services/asset-api/dto"
+	"asset-management/services/asset-api/wallet"
+	"errors"
 	"github.com/gofiber/fiber/v2"
+	"github.com/shopspring/decimal"
 )
 
 type Request struct {
-	WalletAddress string  `json:"wallet_address" example:"0x123abc456def"`
-	Network       string  `json:"network" example:"Ethereum"`
-	Amount        float64 `json:"amount" example:"100.50"`
+	WalletAddress string          `json:"wallet_address" example:"0x123abc456def"`
+	Network       string          `json:"network" example:"Ethereum"`
+	Asset         string          `json:"asset" example:"ETH"`
+	Amount        decimal.Decimal `json:"amount" swaggertype:"string" example:"100.50"`
+	// Who requests the withdrawal. Required above an approval threshold, as the requester may not approve it
+	RequestedBy string `json:"requested_by,omitempty" example:"alice@example.com"`
+}
+
+// PendingResponse is returned for withdrawals that wait for approval.
+type PendingResponse struct {
+	ApprovalID int    `json:"approval_id" example:"12"`
+	Status     string `json:"status" example:"AWAITING_APPROVAL"`
 }
 
 type Response struct {
-	NewBalance float64 `json:"new_balance" example:"1500.75"`
+	NewBalance decimal.Decimal `json:"new_balance" swaggertype:"string" example:"1500.75"`
 }
 type Controller interface {
 	Withdraw(ctx *fiber.Ctx) error
@@ -33,8 +47,13 @@ func NewController(service Service) Controller {
 // @Accept       json
 // @Produce      json
 // @Param        depositRequest body Request true "Withdraw request payload"
+// @Param        Idempotency-Key header string false "Key that makes retries of this request safe"
 // @Success      200  "Withdraw operation successful"
+// @Success      202  {object}  PendingResponse "Amount above an approval threshold: held until the withdrawal is approved"
 // @Failure      400  {object}  dto.ErrorResponse
+// @Failure      403  {object}  dto.ErrorResponse "Source wallet is frozen"
+// @Failure      409  {object}  dto.ErrorResponse "A wallet is being deleted, or Idempotency-Key reused with a different payload or still in progress"
+// @Failure      422  {object}  dto.ErrorResponse "Withdrawal limit exceeded"
 // @Router       /withdraw [post]
 func (c *controller) Withdraw(ctx *fiber.Ctx) error {
 	var req Request
@@ -42,10 +61,23 @@ func (c *controller) Withdraw(ctx *fiber.Ctx) error {
 		return ctx.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Message: "Invalid request payload"})
 	}
 
-	err := c.service.Withdraw(req.WalletAddress, req.Network, req.Amount)
+	approvalID, err := c.service.Withdraw(req.WalletAddress, req.Network, req.Asset, req.Amount, req.RequestedBy)
 	if err != nil {
+		if errors.Is(err, wallet.ErrWalletFrozen) {
+			return ctx.Status(fiber.StatusForbidden).JSON(dto.ErrorResponse{Message: err.Error()})
+		}
+		if errors.Is(err, wallet.ErrWalletDeleting) {
+			return ctx.Status(fiber.StatusConflict).JSON(dto.ErrorResponse{Message: err.Error()})
+		}
+		if errors.Is(err, limits.ErrLimitExceeded) {
+			return ctx.Status(fiber.StatusUnprocessableEntity).JSON(dto.ErrorResponse{Message: err.Error()})
+		}
 		return ctx.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{Message: err.Error()})
 	}
 
+	if approvalID != 0 {
+		return ctx.Status(fiber.StatusAccepted).JSON(PendingResponse{ApprovalID: approvalID, Status: approval.StatusAwaitingApproval})
+	}
+
 	return ctx.SendStatus(fiber.StatusOK)
 }
@@ -1,12 +1,16 @@
 package withdraw_test
 
 import (
+	"asset-management/internal/limits"
 	"asset-management/services/asset-api/dto"
+	"asset-management/services/asset-api/wallet"
 	"asset-management/services/asset-api/withdraw"
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"github.com/gofiber/fiber/v2"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"net/http"
@@ -18,9 +22,9 @@ type MockService struct {
 	mock.Mock
 }
 
-func (m *MockService) Withdraw(walletAddress, network string, amount float64) error {
-	args := m.Called(walletAddress, network, amount)
-	return args.Error(0)
+func (m *MockService) Withdraw(walletAddress, network, asset string, amount decimal.Decimal, requestedBy string) (int, error) {
+	args := m.Called(walletAddress, network, asset, amount, requestedBy)
+	return args.Int(0), args.Error(1)
 }
 
 func TestController_Withdraw_Success(t *testing.T) {
@@ -34,9 +38,10 @@ func TestController_Withdraw_Success(t *testing.T) {
 	req := withdraw.Request{
 		WalletAddress: "0x123abc456def",
 		Network:       "Ethereum",
-		Amount:        100.50,
+		Asset:         "ETH",
+		Amount:        decimal.RequireFromString("100.5"),
 	}
-	mockService.On("Withdraw", req.WalletAddress, req.Network, req.Amount).Return(nil)
+	mockService.On("Withdraw", req.WalletAddress, req.Network, req.Asset, req.Amount, "").Return(0, nil)
 
 	body, _ := json.Marshal(req)
 	request := httptest.NewRequest(http.MethodPost, "/withdraw", bytes.NewBuffer(body))
@@ -79,9 +84,10 @@ func TestController_Withdraw_ServiceError(t *testing.T) {
 	req := withdraw.Request{
 		WalletAddress: "0x123abc456def",
 		Network:       "Ethereum",
-		Amount:        100.50,
+		Asset:         "ETH",
+		Amount:        decimal.RequireFromString("100.5"),
 	}
-	mockService.On("Withdraw", req.WalletAddress, req.Network, req.Amount).Return(errors.New("insufficient balance"))
+	mockService.On("Withdraw", req.WalletAddress, req.Network, req.Asset, req.Amount, "").Return(0, errors.New("insufficient balance"))
 
 	body, _ := json.Marshal(req)
 	request := httptest.NewRequest(http.MethodPost, "/withdraw", bytes.NewBuffer(body))
@@ -95,3 +101,97 @@ func TestController_Withdraw_ServiceError(t *testing.T) {
 	_ = json.NewDecoder(response.Body).Decode(&errorResponse)
 	assert.Equal(t, "insufficient balance", errorResponse.Message)
 }
+
+func TestController_Withdraw_FrozenWallet(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockService)
+	controller := withdraw.NewController(mockService)
+
+	app.Post("/withdraw", controller.Withdraw)
+
+	// Arrange
+	req := withdraw.Request{
+		WalletAddress: "0x123abc456def",
+		Network:       "Ethereum",
+		Asset:         "ETH",
+		Amount:        decimal.RequireFromString("100.5"),
+	}
+	frozen := fmt.Errorf("wallet validation failed: %w", wallet.ErrWalletFrozen)
+	mockService.On("Withdraw", req.WalletAddress, req.Network, req.Asset, req.Amount, "").Return(0, frozen)
+
+	body, _ := json.Marshal(req)
+	request := httptest.NewRequest(http.MethodPost, "/withdraw", bytes.NewBuffer(body))
+	request.Header.Set("Content-Type", "application/json")
+	response, _ := app.Test(request)
+
+	// Assert
+	assert.Equal(t, http.StatusForbidden, response.StatusCode)
+
+	var errorResponse dto.ErrorResponse
+	_ = json.NewDecoder(response.Body).Decode(&errorResponse)
+	assert.Equal(t, "wallet validation failed: wallet is frozen", errorResponse.Message)
+}
+
+func TestController_Withdraw_LimitExceeded(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockService)
+	controller := withdraw.NewController(mockService)
+
+	app.Post("/withdraw", controller.Withdraw)
+
+	// Arrange
+	req := withdraw.Request{
+		WalletAddress: "0x123abc456def",
+		Network:       "Ethereum",
+		Asset:         "ETH",
+		Amount:        decimal.RequireFromString("100.5"),
+	}
+	violation := &limits.Violation{
+		Rule:   limits.Rule{ID: 1, Scope: limits.ScopeGlobal, Asset: "ETH", Kind: limits.KindMaxAmount},
+		Detail: "at most 50 ETH per transaction",
+	}
+	mockService.On("Withdraw", req.WalletAddress, req.Network, req.Asset, req.Amount, "").
+		Return(0, fmt.Errorf("withdraw transaction failed: %w", violation))
+
+	body, _ := json.Marshal(req)
+	request := httptest.NewRequest(http.MethodPost, "/withdraw", bytes.NewBuffer(body))
+	request.Header.Set("Content-Type", "application/json")
+	response, _ := app.Test(request)
+
+	// Assert
+	assert.Equal(t, http.StatusUnprocessableEntity, response.StatusCode)
+
+	var errorResponse dto.ErrorResponse
+	_ = json.NewDecoder(response.Body).Decode(&errorResponse)
+	assert.Equal(t, "withdraw transaction failed: withdrawal limit exceeded: global rule 1 allows at most 50 ETH per transaction", errorResponse.Message)
+}
+
+func TestController_Withdraw_AwaitingApproval(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockService)
+	controller := withdraw.NewController(mockService)
+
+	app.Post("/withdraw", controller.Withdraw)
+
+	// Arrange
+	req := withdraw.Request{
+		WalletAddress: "0x123abc456def",
+		Network:       "Ethereum",
+		Asset:         "ETH",
+		Amount:        decimal.RequireFromString("15000"),
+		RequestedBy:   "alice@example.com",
+	}
+	mockService.On("Withdraw", req.WalletAddress, req.Network, req.Asset, req.Amount, req.RequestedBy).Return(12, nil)
+
+	body, _ := json.Marshal(req)
+	request := httptest.NewRequest(http.MethodPost, "/withdraw", bytes.NewBuffer(body))
+	request.Header.Set("Content-Type", "application/json")
+	response, _ := app.Test(request)
+
+	// Assert
+	assert.Equal(t, http.StatusAccepted, response.StatusCode)
+
+	var pending withdraw.PendingResponse
+	_ = json.NewDecoder(response.Body).Decode(&pending)
+	assert.Equal(t, withdraw.PendingResponse{ApprovalID: 12, Status: "AWAITING_APPROVAL"}, pending)
+}
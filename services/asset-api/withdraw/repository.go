@@ -1,12 +1,21 @@
 package withdraw
 
 import (
+	"asset-management/internal/approval"
+	"asset-management/internal/funds"
+	"asset-management/internal/ledger"
+	"asset-management/internal/limits"
+	"asset-management/internal/outbox"
+	"asset-management/pkg/event"
+	"context"
 	"database/sql"
 	"errors"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
 type Repository interface {
-	Withdraw(walletAddress, network string, amount float64) error
+	Withdraw(walletAddress, network, asset string, amount decimal.Decimal, requestedBy string) (int, error)
 }
 
 type repository struct {
@@ -17,44 +26,122 @@ func NewRepository(db *sql.DB) Repository {
 	return &repository{db: db}
 }
 
-func (r *repository) Withdraw(walletAddress, network string, amount float64) error {
-	var currentBalance float64
+// Withdraw debits the wallet, unless the amount exceeds an approval threshold. Then the amount is held
+// and the ID of the approval request is returned; the withdrawal is made once it is approved.
+func (r *repository) Withdraw(walletAddress, network, asset string, amount decimal.Decimal, requestedBy string) (int, error) {
+	var currentBalance decimal.Decimal
+	ctx := context.Background()
 
 	// Start a transaction
-	tx, err := r.db.Begin()
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	defer tx.Rollback()
 
 	// Check the current balance
-	err = tx.QueryRow(`
+	err = tx.QueryRowContext(ctx, `
         SELECT balance 
         FROM balance 
-        WHERE wallet_address = $1 AND network = $2 
-        FOR UPDATE`, walletAddress, network).Scan(&currentBalance)
+        WHERE wallet_address = $1 AND network = $2 AND asset = $3
+        FOR UPDATE`, walletAddress, network, asset).Scan(&currentBalance)
 
 	if err == sql.ErrNoRows {
-		return errors.New("wallet not found")
+		return 0, errors.New("wallet not found")
 	} else if err != nil {
-		return err
+		return 0, err
 	}
 
 	// Check if balance is sufficient
-	if currentBalance < amount {
-		return errors.New("insufficient balance")
+	if currentBalance.LessThan(amount) {
+		return 0, errors.New("insufficient balance")
+	}
+
+	// Funds held by scheduled transfers can only leave through them
+	held, err := funds.Held(ctx, tx, walletAddress, network, asset)
+	if err != nil {
+		return 0, err
+	}
+	outflow := limits.Outflow{
+		WalletAddress: walletAddress,
+		Network:       network,
+		Asset:         asset,
+		Amount:        amount,
+		Funds:         funds.Funds{Balance: currentBalance, Held: held},
+	}
+	if err := outflow.Funds.Cover(amount, asset); err != nil {
+		return 0, err
+	}
+
+	if err := limits.Check(ctx, tx, outflow); err != nil {
+		return 0, err
 	}
 
+	threshold, err := limits.ApprovalThreshold(ctx, tx, outflow)
+	if err != nil {
+		return 0, err
+	}
+	if threshold != nil {
+		id, err := approval.Open(ctx, tx, approval.Request{
+			Operation:     approval.OperationWithdraw,
+			WalletAddress: walletAddress,
+			Network:       network,
+			Asset:         asset,
+			Amount:        amount,
+			RuleID:        threshold.ID,
+			RequestedBy:   requestedBy,
+		})
+		if err != nil {
+			return 0, err
+		}
+		return id, tx.Commit()
+	}
+
+	if err := Debit(ctx, tx, walletAddress, network, asset, amount, "withdraw:"+uuid.NewString()); err != nil {
+		return 0, err
+	}
+
+	// Commit the transaction
+	return 0, tx.Commit()
+}
+
+// Debit withdraws amount from the wallet inside tx, recording it in the ledger and the outbox under
+// reference. The caller has locked the balance and made sure the available funds cover the amount.
+func Debit(ctx context.Context, tx *sql.Tx, walletAddress, network, asset string, amount decimal.Decimal, reference string) error {
 	// Perform the withdrawal by updating the balance
-	_, err = tx.Exec(`
+	var newBalance decimal.Decimal
+	err := tx.QueryRowContext(ctx, `
         UPDATE balance 
         SET balance = balance - $1 
-        WHERE wallet_address = $2 AND network = $3`, amount, walletAddress, network)
+        WHERE wallet_address = $2 AND network = $3 AND asset = $4
+        RETURNING balance`, amount, walletAddress, network, asset).Scan(&newBalance)
 
 	if err != nil {
 		return err
 	}
 
-	// Commit the transaction
-	return tx.Commit()
+	// Record the movement in the ledger within the same transaction
+	err = ledger.Record(ctx, tx, ledger.Movement{
+		Operation:   ledger.OperationWithdraw,
+		Reference:   reference,
+		Network:     network,
+		Asset:       asset,
+		Amount:      amount,
+		From:        walletAddress,
+		FromBalance: ledger.Balance(newBalance),
+		To:          ledger.ExternalAccount,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = outbox.Write(ctx, tx, event.TypeWithdrawalCompleted, event.WithdrawalCompleted{
+		Reference:     reference,
+		WalletAddress: walletAddress,
+		Network:       network,
+		Asset:         asset,
+		Amount:        amount,
+		Balance:       newBalance,
+	}, walletAddress)
+	return err
 }
@@ -1,8 +1,12 @@
 package withdraw
 
 import (
+	"asset-management/internal/approval"
+	"asset-management/internal/funds"
+	"asset-management/internal/limits"
 	"asset-management/services/asset-api/util"
 	_ "github.com/lib/pq"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
@@ -14,22 +18,29 @@ func TestRepository_Withdraw_Success(t *testing.T) {
 	repo := NewRepository(db)
 
 	// Set up initial balance
-	err := util.InsertBalance(db, "0x123abc456def", "Ethereum", 200.00)
+	err := util.InsertBalance(db, "0x123abc456def", "Ethereum", "ETH", decimal.RequireFromString("200.00"))
 	assert.NoError(t, err)
 
 	// Act
-	err = repo.Withdraw("0x123abc456def", "Ethereum", 100.50)
+	_, err = repo.Withdraw("0x123abc456def", "Ethereum", "ETH", decimal.RequireFromString("100.50"), "")
 
 	// Assert
 	assert.NoError(t, err)
 
 	// Verify balance update
-	var newBalance float64
+	var newBalance decimal.Decimal
 	err = db.QueryRow(`
 		SELECT balance FROM balance 
-		WHERE wallet_address = $1 AND network = $2`, "0x123abc456def", "Ethereum").Scan(&newBalance)
+		WHERE wallet_address = $1 AND network = $2 AND asset = $3`, "0x123abc456def", "Ethereum", "ETH").Scan(&newBalance)
 	assert.NoError(t, err)
-	assert.Equal(t, 99.50, newBalance)
+	assert.Equal(t, "99.5", newBalance.String())
+
+	// Verify the event was written to the outbox
+	var eventType, balance string
+	err = db.QueryRow(`SELECT event_type, envelope->'payload'->>'balance' FROM event_outbox WHERE message_key = $1`, "0x123abc456def").Scan(&eventType, &balance)
+	assert.NoError(t, err)
+	assert.Equal(t, "withdrawal.completed", eventType)
+	assert.Equal(t, "99.5", balance)
 }
 
 func TestRepository_Withdraw_InsufficientBalance(t *testing.T) {
@@ -39,11 +50,11 @@ func TestRepository_Withdraw_InsufficientBalance(t *testing.T) {
 	repo := NewRepository(db)
 
 	// Set up initial balance
-	err := util.InsertBalance(db, "0x123abc456def", "Ethereum", 50.00)
+	err := util.InsertBalance(db, "0x123abc456def", "Ethereum", "ETH", decimal.RequireFromString("50.00"))
 	assert.NoError(t, err)
 
 	// Act
-	err = repo.Withdraw("0x123abc456def", "Ethereum", 100.50)
+	_, err = repo.Withdraw("0x123abc456def", "Ethereum", "ETH", decimal.RequireFromString("100.50"), "")
 
 	// Assert
 	assert.Error(t, err)
@@ -57,9 +68,95 @@ func TestRepository_Withdraw_WalletNotFound(t *testing.T) {
 	repo := NewRepository(db)
 
 	// Act
-	err := repo.Withdraw("0x123abc456def", "Ethereum", 100.50)
+	_, err := repo.Withdraw("0x123abc456def", "Ethereum", "ETH", decimal.RequireFromString("100.50"), "")
 
 	// Assert
 	assert.Error(t, err)
 	assert.Equal(t, "wallet not found", err.Error())
 }
+
+func TestRepository_Withdraw_FundsHeld(t *testing.T) {
+	db, cleanup := util.SetupTestContainer(t)
+	defer cleanup()
+
+	repo := NewRepository(db)
+	assert.NoError(t, util.InsertBalance(db, "0x123abc456def", "Ethereum", "ETH", decimal.RequireFromString("100")))
+	_, err := db.Exec(`
+		INSERT INTO scheduled_transactions (from_wallet_address, to_wallet_address, network, asset, amount, scheduled_time, status)
+		VALUES ('0x123abc456def', '0x456', 'Ethereum', 'ETH', 70, NOW() + INTERVAL '1 day', 'PENDING')`)
+	assert.NoError(t, err)
+
+	// 70 ETH are held by the scheduled transaction, so only 30 can be withdrawn
+	_, err = repo.Withdraw("0x123abc456def", "Ethereum", "ETH", decimal.RequireFromString("40"), "")
+	assert.ErrorIs(t, err, funds.ErrFundsHeld)
+
+	_, err = repo.Withdraw("0x123abc456def", "Ethereum", "ETH", decimal.RequireFromString("30"), "")
+	assert.NoError(t, err)
+}
+
+func TestRepository_Withdraw_LimitExceeded(t *testing.T) {
+	db, cleanup := util.SetupTestContainer(t)
+	defer cleanup()
+
+	repo := NewRepository(db)
+	assert.NoError(t, util.InsertBalance(db, "0x123abc456def", "Ethereum", "ETH", decimal.RequireFromString("100")))
+	_, err := db.Exec(`
+		INSERT INTO withdrawal_limits (scope, network, wallet_address, asset, kind, value) VALUES
+		('GLOBAL', '', '', 'ETH', 'VOLUME_24H', 5),
+		('WALLET', 'Ethereum', '0x123abc456def', 'ETH', 'VOLUME_24H', 50),
+		('NETWORK', 'Ethereum', '', 'ETH', 'MIN_RESERVE', 20)`)
+	assert.NoError(t, err)
+
+	// The wallet rule overrides the global one, and counts the transfer scheduled earlier
+	_, err = db.Exec(`
+		INSERT INTO scheduled_transactions (from_wallet_address, to_wallet_address, network, asset, amount, scheduled_time, status)
+		VALUES ('0x123abc456def', '0x456', 'Ethereum', 'ETH', 10, NOW() + INTERVAL '1 day', 'PENDING')`)
+	assert.NoError(t, err)
+	_, err = repo.Withdraw("0x123abc456def", "Ethereum", "ETH", decimal.RequireFromString("30"), "")
+	assert.NoError(t, err)
+
+	_, err = repo.Withdraw("0x123abc456def", "Ethereum", "ETH", decimal.RequireFromString("15"), "")
+	assert.ErrorIs(t, err, limits.ErrLimitExceeded)
+	assert.Contains(t, err.Error(), "at most 50 ETH per 24 hours, 40 ETH already used")
+
+	_, err = repo.Withdraw("0x123abc456def", "Ethereum", "ETH", decimal.RequireFromString("10"), "")
+	assert.NoError(t, err)
+	_, err = db.Exec(`DELETE FROM withdrawal_limits WHERE kind = 'VOLUME_24H'`)
+	assert.NoError(t, err)
+
+	// 60 ETH are left, 10 of them held, and 20 must stay available
+	_, err = repo.Withdraw("0x123abc456def", "Ethereum", "ETH", decimal.RequireFromString("31"), "")
+	assert.ErrorIs(t, err, limits.ErrLimitExceeded)
+	assert.Contains(t, err.Error(), "no less than 20 ETH to remain available, 19 ETH would remain")
+}
+
+func TestRepository_Withdraw_AwaitingApproval(t *testing.T) {
+	db, cleanup := util.SetupTestContainer(t)
+	defer cleanup()
+
+	repo := NewRepository(db)
+	assert.NoError(t, util.InsertBalance(db, "0x123abc456def", "Ethereum", "ETH", decimal.RequireFromString("20000")))
+	_, err := db.Exec(`INSERT INTO withdrawal_limits (scope, asset, kind, value) VALUES ('GLOBAL', 'ETH', 'APPROVAL_THRESHOLD', 10000)`)
+	assert.NoError(t, err)
+
+	_, err = repo.Withdraw("0x123abc456def", "Ethereum", "ETH", decimal.RequireFromString("15000"), "")
+	assert.ErrorIs(t, err, approval.ErrRequesterRequired)
+
+	approvalID, err := repo.Withdraw("0x123abc456def", "Ethereum", "ETH", decimal.RequireFromString("15000"), "alice")
+	assert.NoError(t, err)
+	assert.NotZero(t, approvalID)
+
+	// Nothing is debited yet, but the amount is held until the request is decided
+	var balance decimal.Decimal
+	err = db.QueryRow(`SELECT balance FROM balance WHERE wallet_address = '0x123abc456def'`).Scan(&balance)
+	assert.NoError(t, err)
+	assert.Equal(t, "20000", balance.String())
+
+	_, err = repo.Withdraw("0x123abc456def", "Ethereum", "ETH", decimal.RequireFromString("6000"), "")
+	assert.ErrorIs(t, err, funds.ErrFundsHeld)
+
+	// Amounts up to the threshold are withdrawn at once
+	approvalID, err = repo.Withdraw("0x123abc456def", "Ethereum", "ETH", decimal.RequireFromString("5000"), "")
+	assert.NoError(t, err)
+	assert.Zero(t, approvalID)
+}
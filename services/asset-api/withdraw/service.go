@@ -4,6 +4,7 @@ import (
 	"asset-management/services/asset-api/wallet"
 	"errors"
 	"fmt"
+	"github.com/shopspring/decimal"
 )
 
 type service struct {
@@ -12,28 +13,30 @@ type service struct {
 }
 
 type Service interface {
-	Withdraw(walletAddress, network string, amount float64) error
+	// Withdraw returns the ID of the approval request if the withdrawal waits for approval, and 0 once it is made.
+	Withdraw(walletAddress, network, asset string, amount decimal.Decimal, requestedBy string) (int, error)
 }
 
 func NewService(wr Repository, va wallet.ValidationAdapter) Service {
 	return &service{withdrawRepository: wr, walletValidator: va}
 }
 
-func (s *service) Withdraw(walletAddress, network string, amount float64) error {
-	if walletAddress == "" || network == "" || amount <= 0 {
-		return errors.New("invalid input parameters")
+func (s *service) Withdraw(walletAddress, network, asset string, amount decimal.Decimal, requestedBy string) (int, error) {
+	if walletAddress == "" || network == "" || asset == "" || !amount.IsPositive() {
+		return 0, errors.New("invalid input parameters")
 	}
+	network, walletAddress = wallet.Canonical(network, walletAddress)
 
-	err := s.walletValidator.One(walletAddress, network)
+	err := s.walletValidator.CanDebit(walletAddress, network)
 
 	if err != nil {
-		return fmt.Errorf("wallet validation failed: %w", err)
+		return 0, fmt.Errorf("wallet validation failed: %w", err)
 	}
 
-	repoErr := s.withdrawRepository.Withdraw(walletAddress, network, amount)
+	approvalID, repoErr := s.withdrawRepository.Withdraw(walletAddress, network, asset, amount, requestedBy)
 	if repoErr != nil {
-		return fmt.Errorf("withdraw transaction failed: %w", repoErr)
+		return 0, fmt.Errorf("withdraw transaction failed: %w", repoErr)
 	}
 
-	return nil
+	return approvalID, nil
 }
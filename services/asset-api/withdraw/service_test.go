@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"asset-management/services/asset-api/withdraw"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -19,6 +20,16 @@ func (m *MockValidationAdapter) One(walletAddress, network string) error {
 	return args.Error(0)
 }
 
+func (m *MockValidationAdapter) CanDebit(walletAddress, network string) error {
+	args := m.Called(walletAddress, network)
+	return args.Error(0)
+}
+
+func (m *MockValidationAdapter) CanCredit(walletAddress, network string) error {
+	args := m.Called(walletAddress, network)
+	return args.Error(0)
+}
+
 func (m *MockValidationAdapter) Both(from, to, network string) error {
 	args := m.Called(from, to, network)
 	return args.Error(0)
@@ -29,9 +40,9 @@ type MockRepository struct {
 	mock.Mock
 }
 
-func (m *MockRepository) Withdraw(walletAddress, network string, amount float64) error {
-	args := m.Called(walletAddress, network, amount)
-	return args.Error(0)
+func (m *MockRepository) Withdraw(walletAddress, network, asset string, amount decimal.Decimal, requestedBy string) (int, error) {
+	args := m.Called(walletAddress, network, asset, amount, requestedBy)
+	return args.Int(0), args.Error(1)
 }
 
 func TestWithdrawService_Success(t *testing.T) {
@@ -40,11 +51,11 @@ func TestWithdrawService_Success(t *testing.T) {
 
 	// Arrange
 	service := withdraw.NewService(mockRepo, mockValidator)
-	mockValidator.On("One", "0x123abc456def", "Ethereum").Return(nil)
-	mockRepo.On("Withdraw", "0x123abc456def", "Ethereum", 100.50).Return(nil)
+	mockValidator.On("CanDebit", "0x123abc456def", "Ethereum").Return(nil)
+	mockRepo.On("Withdraw", "0x123abc456def", "Ethereum", "ETH", decimal.RequireFromString("100.50"), "").Return(0, nil)
 
 	// Act
-	err := service.Withdraw("0x123abc456def", "Ethereum", 100.50)
+	_, err := service.Withdraw("0x123abc456def", "Ethereum", "ETH", decimal.RequireFromString("100.50"), "")
 
 	// Assert
 	assert.NoError(t, err)
@@ -60,7 +71,7 @@ func TestWithdrawService_InvalidInput(t *testing.T) {
 	service := withdraw.NewService(mockRepo, mockValidator)
 
 	// Act
-	err := service.Withdraw("", "Ethereum", 100.50)
+	_, err := service.Withdraw("", "Ethereum", "ETH", decimal.RequireFromString("100.50"), "")
 
 	// Assert
 	assert.Error(t, err)
@@ -73,10 +84,10 @@ func TestWithdrawService_ValidationFailed(t *testing.T) {
 
 	// Arrange
 	service := withdraw.NewService(mockRepo, mockValidator)
-	mockValidator.On("One", "0x123abc456def", "Ethereum").Return(errors.New("wallet validation failed"))
+	mockValidator.On("CanDebit", "0x123abc456def", "Ethereum").Return(errors.New("wallet validation failed"))
 
 	// Act
-	err := service.Withdraw("0x123abc456def", "Ethereum", 100.50)
+	_, err := service.Withdraw("0x123abc456def", "Ethereum", "ETH", decimal.RequireFromString("100.50"), "")
 
 	// Assert
 	assert.Error(t, err)
@@ -89,13 +100,31 @@ func TestWithdrawService_RepositoryError(t *testing.T) {
 
 	// Arrange
 	service := withdraw.NewService(mockRepo, mockValidator)
-	mockValidator.On("One", "0x123abc456def", "Ethereum").Return(nil)
-	mockRepo.On("Withdraw", "0x123abc456def", "Ethereum", 100.50).Return(errors.New("insufficient balance"))
+	mockValidator.On("CanDebit", "0x123abc456def", "Ethereum").Return(nil)
+	mockRepo.On("Withdraw", "0x123abc456def", "Ethereum", "ETH", decimal.RequireFromString("100.50"), "").Return(0, errors.New("insufficient balance"))
 
 	// Act
-	err := service.Withdraw("0x123abc456def", "Ethereum", 100.50)
+	_, err := service.Withdraw("0x123abc456def", "Ethereum", "ETH", decimal.RequireFromString("100.50"), "")
 
 	// Assert
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "withdraw transaction failed")
 }
+
+func TestWithdrawService_AwaitingApproval(t *testing.T) {
+	mockValidator := new(MockValidationAdapter)
+	mockRepo := new(MockRepository)
+
+	// Arrange
+	service := withdraw.NewService(mockRepo, mockValidator)
+	mockValidator.On("CanDebit", "0x123abc456def", "Ethereum").Return(nil)
+	mockRepo.On("Withdraw", "0x123abc456def", "Ethereum", "ETH", decimal.RequireFromString("15000"), "alice").Return(12, nil)
+
+	// Act
+	approvalID, err := service.Withdraw("0x123abc456def", "Ethereum", "ETH", decimal.RequireFromString("15000"), "alice")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 12, approvalID)
+	mockRepo.AssertExpectations(t)
+}
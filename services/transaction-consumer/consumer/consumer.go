@@ -0,0 +1,235 @@
+package consumer
+
+import (
+	"context"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"github.com/segmentio/kafka-go"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+// Reader is the part of kafka.Reader the consumer needs; offsets are committed explicitly.
+type Reader interface {
+	FetchMessage(ctx context.Context) (kafka.Message, error)
+	CommitMessages(ctx context.Context, msgs ...kafka.Message) error
+}
+
+// statsReader is implemented by kafka.Reader and used to report lag.
+type statsReader interface {
+	Stats() kafka.ReaderStats
+}
+
+// Handler handles one message. An error means the message was not handled and must not be committed.
+type Handler interface {
+	Handle(ctx context.Context, msg kafka.Message) error
+}
+
+type Config struct {
+	Workers      int           // Messages handled in parallel; messages with the same key are never handled concurrently
+	QueueSize    int           // Messages buffered per worker before fetching blocks
+	DrainTimeout time.Duration // Time in-flight messages get to finish after shutdown starts
+}
+
+var DefaultConfig = Config{
+	Workers:      8,
+	QueueSize:    100,
+	DrainTimeout: 25 * time.Second,
+}
+
+const retryInterval = time.Second
+
+// Consumer fetches messages from one topic and hands them to a pool of workers. Messages are routed to
+// workers by key, so messages with the same key are handled one at a time and in order. An offset is
+// committed only once it and every earlier offset of its partition have been handled, so a crash never
+// loses a message.
+type Consumer struct {
+	topic   string
+	reader  Reader
+	handler Handler
+	config  Config
+	offsets *offsetTracker
+
+	commitMu sync.Mutex
+	mu       sync.Mutex
+	status   Status
+}
+
+// Status is a snapshot of a consumer, reported by the health and metrics endpoints.
+type Status struct {
+	Topic         string     `json:"topic"`
+	Running       bool       `json:"running"`
+	Workers       int        `json:"workers"`
+	Processed     int64      `json:"processed"`
+	InFlight      int        `json:"in_flight"`
+	Lag           int64      `json:"lag"`
+	LastMessageAt *time.Time `json:"last_message_at,omitempty"`
+	LastError     string     `json:"last_error,omitempty"`
+}
+
+func New(topic string, reader Reader, handler Handler, config Config) *Consumer {
+	if config.Workers < 1 {
+		config.Workers = 1
+	}
+	if config.QueueSize < 0 {
+		config.QueueSize = 0
+	}
+	return &Consumer{
+		topic:   topic,
+		reader:  reader,
+		handler: handler,
+		config:  config,
+		offsets: newOffsetTracker(),
+		status:  Status{Topic: topic, Workers: config.Workers},
+	}
+}
+
+// Run consumes until ctx is cancelled or the reader fails. Once ctx is cancelled no new message is
+// fetched, but messages already fetched are still handled and committed, for at most the drain timeout.
+// It returns nil after a graceful stop and the reader's error otherwise.
+func (c *Consumer) Run(ctx context.Context) error {
+	c.update(func(s *Status) { s.Running = true })
+	defer c.update(func(s *Status) { s.Running = false })
+
+	// In-flight work outlives ctx by up to the drain timeout
+	workCtx, cancelWork := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelWork()
+	go func() {
+		select {
+		case <-ctx.Done():
+			timer := time.NewTimer(c.config.DrainTimeout)
+			defer timer.Stop()
+			select {
+			case <-timer.C:
+				cancelWork()
+			case <-workCtx.Done():
+			}
+		case <-workCtx.Done():
+		}
+	}()
+
+	queues := make([]chan kafka.Message, c.config.Workers)
+	var wg sync.WaitGroup
+	for i := range queues {
+		queues[i] = make(chan kafka.Message, c.config.QueueSize)
+		wg.Add(1)
+		go func(queue <-chan kafka.Message) {
+			defer wg.Done()
+			c.work(workCtx, queue)
+		}(queues[i])
+	}
+
+	err := c.dispatch(ctx, queues)
+
+	for _, queue := range queues {
+		close(queue)
+	}
+	wg.Wait()
+
+	return err
+}
+
+// dispatch fetches messages and routes them to the queue of their key's worker, blocking while it is full.
+func (c *Consumer) dispatch(ctx context.Context, queues []chan kafka.Message) error {
+	for {
+		msg, err := c.reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				log.Info().Str("topic", c.topic).Msg("Consumer stopped fetching, draining in-flight messages")
+				return nil
+			}
+			c.update(func(s *Status) { s.LastError = err.Error() })
+			return fmt.Errorf("failed to fetch message from %s: %w", c.topic, err)
+		}
+
+		c.offsets.add(msg)
+		c.update(func(s *Status) { s.InFlight++ })
+
+		select {
+		case queues[c.worker(msg)] <- msg:
+		case <-ctx.Done():
+			// Never handled, so never committed; it is redelivered after restart
+			return nil
+		}
+	}
+}
+
+func (c *Consumer) worker(msg kafka.Message) int {
+	h := fnv.New32a()
+	if len(msg.Key) > 0 {
+		_, _ = h.Write(msg.Key)
+	} else {
+		// Without a key, keep the partition's order
+		_, _ = fmt.Fprintf(h, "partition-%d", msg.Partition)
+	}
+	return int(h.Sum32() % uint32(c.config.Workers))
+}
+
+func (c *Consumer) work(ctx context.Context, queue <-chan kafka.Message) {
+	for msg := range queue {
+		if err := c.handle(ctx, msg); err != nil {
+			// Only reachable when the drain timeout expired; the message is redelivered after restart
+			log.Warn().Err(err).Str("topic", c.topic).Int64("offset", msg.Offset).Msg("Stopped before message was handled")
+			continue
+		}
+		c.complete(ctx, msg)
+	}
+}
+
+// handle retries the handler until the message is handled, so a message is never skipped.
+func (c *Consumer) handle(ctx context.Context, msg kafka.Message) error {
+	for {
+		err := c.handler.Handle(ctx, msg)
+		if err == nil {
+			return nil
+		}
+
+		c.update(func(s *Status) { s.LastError = err.Error() })
+		log.Error().Err(err).Int64("offset", msg.Offset).Str("topic", msg.Topic).Msg("Error handling message, retrying")
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(retryInterval):
+		}
+	}
+}
+
+// complete marks msg as handled and commits the highest offset of its partition that has no unhandled
+// offset before it.
+func (c *Consumer) complete(ctx context.Context, msg kafka.Message) {
+	c.commitMu.Lock()
+	defer c.commitMu.Unlock()
+
+	if commit, ok := c.offsets.done(msg); ok {
+		if err := c.reader.CommitMessages(ctx, commit); err != nil {
+			c.update(func(s *Status) { s.LastError = err.Error() })
+			log.Error().Err(err).Int64("offset", commit.Offset).Str("topic", commit.Topic).Msg("Error committing message")
+		}
+	}
+
+	now := time.Now()
+	c.update(func(s *Status) {
+		s.Processed++
+		s.InFlight--
+		s.LastMessageAt = &now
+	})
+}
+
+func (c *Consumer) Status() Status {
+	c.mu.Lock()
+	status := c.status
+	c.mu.Unlock()
+
+	if stats, ok := c.reader.(statsReader); ok {
+		status.Lag = stats.Stats().Lag
+	}
+	return status
+}
+
+func (c *Consumer) update(change func(*Status)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	change(&c.status)
+}
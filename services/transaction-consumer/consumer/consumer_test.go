@@ -0,0 +1,232 @@
+package consumer_test
+
+import (
+	"asset-management/services/transaction-consumer/consumer"
+	"context"
+	"errors"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeReader serves queued messages, then blocks until the context is cancelled or fails with err.
+type fakeReader struct {
+	mu        sync.Mutex
+	messages  []kafka.Message
+	committed []int64
+	err       error
+}
+
+func (r *fakeReader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	r.mu.Lock()
+	if len(r.messages) > 0 {
+		msg := r.messages[0]
+		r.messages = r.messages[1:]
+		r.mu.Unlock()
+		return msg, nil
+	}
+	err := r.err
+	r.mu.Unlock()
+
+	if err != nil {
+		return kafka.Message{}, err
+	}
+	<-ctx.Done()
+	return kafka.Message{}, ctx.Err()
+}
+
+func (r *fakeReader) CommitMessages(_ context.Context, msgs ...kafka.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, msg := range msgs {
+		r.committed = append(r.committed, msg.Offset)
+	}
+	return nil
+}
+
+func (r *fakeReader) Committed() []int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]int64(nil), r.committed...)
+}
+
+func (r *fakeReader) Remaining() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.messages)
+}
+
+type handlerFunc func(ctx context.Context, msg kafka.Message) error
+
+func (f handlerFunc) Handle(ctx context.Context, msg kafka.Message) error {
+	return f(ctx, msg)
+}
+
+func TestConsumer_CommitsHandledMessages(t *testing.T) {
+	reader := &fakeReader{messages: []kafka.Message{{Offset: 1}, {Offset: 2}}, err: errors.New("connection lost")}
+	var handled []int64
+	c := consumer.New("test-topic", reader, handlerFunc(func(_ context.Context, msg kafka.Message) error {
+		handled = append(handled, msg.Offset)
+		return nil
+	}), consumer.Config{Workers: 1, DrainTimeout: time.Second})
+
+	err := c.Run(context.Background())
+
+	assert.ErrorContains(t, err, "connection lost")
+	assert.Equal(t, []int64{1, 2}, handled)
+	assert.Equal(t, []int64{1, 2}, reader.Committed())
+	assert.Equal(t, int64(2), c.Status().Processed)
+	assert.False(t, c.Status().Running)
+}
+
+func TestConsumer_RetriesUnhandledMessageBeforeCommitting(t *testing.T) {
+	reader := &fakeReader{messages: []kafka.Message{{Offset: 1}}, err: errors.New("done")}
+	attempts := 0
+	c := consumer.New("test-topic", reader, handlerFunc(func(context.Context, kafka.Message) error {
+		attempts++
+		if attempts == 1 {
+			return errors.New("retry topic unavailable")
+		}
+		return nil
+	}), consumer.Config{Workers: 1, DrainTimeout: time.Second})
+
+	_ = c.Run(context.Background())
+
+	assert.Equal(t, 2, attempts)
+	assert.Equal(t, []int64{1}, reader.Committed())
+}
+
+func TestConsumer_DrainsInFlightMessageOnShutdown(t *testing.T) {
+	reader := &fakeReader{messages: []kafka.Message{{Offset: 7}}}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	c := consumer.New("test-topic", reader, handlerFunc(func(handleCtx context.Context, msg kafka.Message) error {
+		close(started)
+		<-release
+		// Shutdown must not cancel the work in flight
+		return handleCtx.Err()
+	}), consumer.Config{Workers: 1, DrainTimeout: time.Second})
+
+	done := make(chan error)
+	go func() { done <- c.Run(ctx) }()
+
+	<-started
+	cancel()
+	close(release)
+
+	assert.NoError(t, <-done)
+	assert.Equal(t, []int64{7}, reader.Committed())
+}
+
+func TestConsumer_DrainTimeoutLeavesMessageUncommitted(t *testing.T) {
+	reader := &fakeReader{messages: []kafka.Message{{Offset: 7}}}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	c := consumer.New("test-topic", reader, handlerFunc(func(handleCtx context.Context, msg kafka.Message) error {
+		<-handleCtx.Done()
+		return handleCtx.Err()
+	}), consumer.Config{Workers: 1, DrainTimeout: 10 * time.Millisecond})
+
+	err := c.Run(ctx)
+
+	assert.NoError(t, err)
+	assert.Empty(t, reader.Committed())
+}
+
+func TestConsumer_PreservesOrderPerKey(t *testing.T) {
+	keys := []string{"wallet-a", "wallet-b", "wallet-c", "wallet-d"}
+	var messages []kafka.Message
+	for offset := 0; offset < 20; offset++ {
+		messages = append(messages, kafka.Message{Offset: int64(offset), Key: []byte(keys[offset%len(keys)])})
+	}
+	reader := &fakeReader{messages: messages, err: errors.New("done")}
+
+	var mu sync.Mutex
+	handled := map[string][]int64{}
+	c := consumer.New("test-topic", reader, handlerFunc(func(_ context.Context, msg kafka.Message) error {
+		// Later messages of other keys overtake earlier ones
+		time.Sleep(time.Duration(20-msg.Offset) * time.Millisecond / 10)
+		mu.Lock()
+		defer mu.Unlock()
+		handled[string(msg.Key)] = append(handled[string(msg.Key)], msg.Offset)
+		return nil
+	}), consumer.Config{Workers: 4, QueueSize: 10, DrainTimeout: time.Second})
+
+	_ = c.Run(context.Background())
+
+	for i, key := range keys {
+		assert.Equal(t, []int64{int64(i), int64(i + 4), int64(i + 8), int64(i + 12), int64(i + 16)}, handled[key], key)
+	}
+
+	committed := reader.Committed()
+	assert.Equal(t, int64(19), committed[len(committed)-1])
+	assert.IsIncreasing(t, committed)
+	assert.Equal(t, int64(20), c.Status().Processed)
+	assert.Zero(t, c.Status().InFlight)
+}
+
+func TestConsumer_CommitsOnlyContiguousOffsets(t *testing.T) {
+	reader := &fakeReader{messages: []kafka.Message{
+		{Offset: 1, Key: []byte("wallet-a")},
+		{Offset: 2, Key: []byte("wallet-b")},
+		{Offset: 3, Key: []byte("wallet-c")},
+	}}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	release := make(chan struct{})
+	c := consumer.New("test-topic", reader, handlerFunc(func(_ context.Context, msg kafka.Message) error {
+		if msg.Offset == 1 {
+			<-release
+		}
+		return nil
+	}), consumer.Config{Workers: 4, DrainTimeout: time.Second})
+
+	done := make(chan error)
+	go func() { done <- c.Run(ctx) }()
+
+	// Offsets 2 and 3 are handled, but committing them would skip offset 1
+	assert.Eventually(t, func() bool { return c.Status().Processed == 2 }, time.Second, time.Millisecond)
+	assert.Empty(t, reader.Committed())
+	assert.Equal(t, 1, c.Status().InFlight)
+
+	close(release)
+	assert.Eventually(t, func() bool { return len(reader.Committed()) > 0 }, time.Second, time.Millisecond)
+	assert.Equal(t, []int64{3}, reader.Committed())
+
+	cancel()
+	assert.NoError(t, <-done)
+}
+
+func TestConsumer_StopsFetchingWhenQueueIsFull(t *testing.T) {
+	var messages []kafka.Message
+	for offset := 0; offset < 10; offset++ {
+		messages = append(messages, kafka.Message{Offset: int64(offset), Key: []byte("wallet-a")})
+	}
+	reader := &fakeReader{messages: messages}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	release := make(chan struct{})
+	c := consumer.New("test-topic", reader, handlerFunc(func(context.Context, kafka.Message) error {
+		<-release
+		return nil
+	}), consumer.Config{Workers: 1, QueueSize: 2, DrainTimeout: time.Second})
+
+	done := make(chan error)
+	go func() { done <- c.Run(ctx) }()
+
+	// One message in the handler, two queued and one waiting to be queued
+	assert.Eventually(t, func() bool { return reader.Remaining() == 6 }, time.Second, time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, 6, reader.Remaining())
+
+	close(release)
+	assert.Eventually(t, func() bool { return c.Status().Processed == 10 }, time.Second, time.Millisecond)
+
+	cancel()
+	assert.NoError(t, <-done)
+}
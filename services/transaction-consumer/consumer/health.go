@@ -0,0 +1,65 @@
+package consumer
+
+import (
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"strings"
+)
+
+type HealthResponse struct {
+	Status    string   `json:"status"`
+	Consumers []Status `json:"consumers"`
+}
+
+type HealthController struct {
+	consumers []*Consumer
+}
+
+func NewHealthController(consumers ...*Consumer) *HealthController {
+	return &HealthController{consumers: consumers}
+}
+
+// Health reports UP with 200 while every consumer is running and DOWN with 503 otherwise.
+func (c *HealthController) Health(ctx *fiber.Ctx) error {
+	response := HealthResponse{Status: "UP"}
+	for _, consumer := range c.consumers {
+		status := consumer.Status()
+		if !status.Running {
+			response.Status = "DOWN"
+		}
+		response.Consumers = append(response.Consumers, status)
+	}
+
+	if response.Status != "UP" {
+		return ctx.Status(fiber.StatusServiceUnavailable).JSON(response)
+	}
+	return ctx.JSON(response)
+}
+
+// Metrics reports every consumer's lag, in-flight and processed messages in the Prometheus text format.
+func (c *HealthController) Metrics(ctx *fiber.Ctx) error {
+	var b strings.Builder
+	gauges := []struct {
+		name, help string
+		value      func(Status) int64
+	}{
+		{"transaction_consumer_lag", "Messages in the topic not yet fetched by the consumer.", func(s Status) int64 { return s.Lag }},
+		{"transaction_consumer_in_flight", "Messages fetched but not yet handled.", func(s Status) int64 { return int64(s.InFlight) }},
+		{"transaction_consumer_workers", "Messages the consumer handles in parallel.", func(s Status) int64 { return int64(s.Workers) }},
+		{"transaction_consumer_processed_total", "Messages handled since the consumer started.", func(s Status) int64 { return s.Processed }},
+	}
+	for _, gauge := range gauges {
+		metricType := "gauge"
+		if strings.HasSuffix(gauge.name, "_total") {
+			metricType = "counter"
+		}
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", gauge.name, gauge.help, gauge.name, metricType)
+		for _, consumer := range c.consumers {
+			status := consumer.Status()
+			fmt.Fprintf(&b, "%s{topic=%q} %d\n", gauge.name, status.Topic, gauge.value(status))
+		}
+	}
+
+	ctx.Set(fiber.HeaderContentType, "text/plain; version=0.0.4")
+	return ctx.SendString(b.String())
+}
@@ -0,0 +1,71 @@
+package consumer_test
+
+import (
+	"asset-management/services/transaction-consumer/consumer"
+	"context"
+	"encoding/json"
+	"github.com/gofiber/fiber/v2"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHealthController_Health(t *testing.T) {
+	reader := &fakeReader{}
+	c := consumer.New("test-topic", reader, handlerFunc(func(context.Context, kafka.Message) error { return nil }), consumer.Config{Workers: 1, DrainTimeout: time.Second})
+
+	app := fiber.New()
+	app.Get("/health", consumer.NewHealthController(c).Health)
+
+	// Not running yet
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- c.Run(ctx) }()
+	assert.Eventually(t, func() bool { return c.Status().Running }, time.Second, time.Millisecond)
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var health consumer.HealthResponse
+	_ = json.NewDecoder(resp.Body).Decode(&health)
+	assert.Equal(t, "UP", health.Status)
+	assert.Equal(t, "test-topic", health.Consumers[0].Topic)
+
+	cancel()
+	assert.NoError(t, <-done)
+}
+
+func TestHealthController_Metrics(t *testing.T) {
+	reader := &fakeReader{messages: []kafka.Message{{Offset: 1}, {Offset: 2}}}
+	c := consumer.New("test-topic", reader, handlerFunc(func(context.Context, kafka.Message) error { return nil }), consumer.Config{Workers: 3, DrainTimeout: time.Second})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- c.Run(ctx) }()
+	assert.Eventually(t, func() bool { return c.Status().Processed == 2 }, time.Second, time.Millisecond)
+
+	app := fiber.New()
+	app.Get("/metrics", consumer.NewHealthController(c).Metrics)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	body, _ := io.ReadAll(resp.Body)
+	assert.Contains(t, string(body), `transaction_consumer_processed_total{topic="test-topic"} 2`)
+	assert.Contains(t, string(body), `transaction_consumer_in_flight{topic="test-topic"} 0`)
+	assert.Contains(t, string(body), `transaction_consumer_workers{topic="test-topic"} 3`)
+	assert.Contains(t, string(body), "# TYPE transaction_consumer_lag gauge")
+
+	cancel()
+	assert.NoError(t, <-done)
+}
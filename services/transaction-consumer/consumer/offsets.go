@@ -0,0 +1,65 @@
+package consumer
+
+import (
+	"github.com/segmentio/kafka-go"
+	"sync"
+)
+
+type partitionKey struct {
+	topic     string
+	partition int
+}
+
+type partitionOffsets struct {
+	pending []int64                 // Fetched offsets not yet committable, in fetch order
+	handled map[int64]kafka.Message // Handled messages waiting for an earlier offset
+}
+
+// offsetTracker works out which offset can be committed when messages of a partition are handled out of order.
+type offsetTracker struct {
+	mu         sync.Mutex
+	partitions map[partitionKey]*partitionOffsets
+}
+
+func newOffsetTracker() *offsetTracker {
+	return &offsetTracker{partitions: map[partitionKey]*partitionOffsets{}}
+}
+
+func (t *offsetTracker) add(msg kafka.Message) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := partitionKey{msg.Topic, msg.Partition}
+	p, ok := t.partitions[key]
+	if !ok {
+		p = &partitionOffsets{handled: map[int64]kafka.Message{}}
+		t.partitions[key] = p
+	}
+	p.pending = append(p.pending, msg.Offset)
+}
+
+// done marks msg as handled and returns the last message of the contiguous handled run at the start
+// of its partition, if that run is not empty.
+func (t *offsetTracker) done(msg kafka.Message) (kafka.Message, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	p, ok := t.partitions[partitionKey{msg.Topic, msg.Partition}]
+	if !ok {
+		return kafka.Message{}, false
+	}
+	p.handled[msg.Offset] = msg
+
+	var commit kafka.Message
+	found := false
+	for len(p.pending) > 0 {
+		handled, ok := p.handled[p.pending[0]]
+		if !ok {
+			break
+		}
+		delete(p.handled, p.pending[0])
+		p.pending = p.pending[1:]
+		commit, found = handled, true
+	}
+	return commit, found
+}
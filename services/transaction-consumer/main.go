@@ -1,16 +1,24 @@
 package main
 
 import (
-	"asset-management/internal/schedule"
 	"asset-management/internal/schedule/scheduled_process"
+	"asset-management/pkg/app"
 	"asset-management/pkg/database"
+	kafka2 "asset-management/pkg/kafka"
 	"asset-management/pkg/logger"
+	"asset-management/services/asset-api/wallet"
+	"asset-management/services/transaction-consumer/consumer"
+	"asset-management/services/transaction-consumer/retry"
 	"context"
-	"encoding/json"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/segmentio/kafka-go"
 	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
 )
 
 func main() {
@@ -24,27 +32,25 @@ func main() {
 		return
 	}
 
-	reader := kafka.NewReader(kafka.ReaderConfig{
-		Brokers: []string{kafkaBroker}, // Kafka broker address
-		Topic:   kafkaTopic,            // Topic to consume from
-		GroupID: kafkaGroupId,          // Consumer group ID
-	})
-
-	defer func() {
-		if err := reader.Close(); err != nil {
-			log.Error().Err(err).Msg("Failed to close Kafka reader")
-		}
-	}()
+	retryTopic := os.Getenv("KAFKA_RETRY_TOPIC")
+	if retryTopic == "" {
+		retryTopic = kafkaTopic + ".retry"
+	}
+	deadLetterTopic := os.Getenv("KAFKA_DLQ_TOPIC")
+	if deadLetterTopic == "" {
+		deadLetterTopic = kafkaTopic + ".dlq"
+	}
 
-	if _, err := reader.FetchMessage(context.Background()); err != nil {
-		log.Error().Err(err).Msg("Failed to connect to Kafka")
-		return
+	consumerConfig := consumer.DefaultConfig
+	if value, err := time.ParseDuration(os.Getenv("CONSUMER_DRAIN_TIMEOUT")); err == nil && value > 0 {
+		consumerConfig.DrainTimeout = value
+	}
+	if value, err := strconv.Atoi(os.Getenv("CONSUMER_WORKERS")); err == nil && value > 0 {
+		consumerConfig.Workers = value
+	}
+	if value, err := strconv.Atoi(os.Getenv("CONSUMER_QUEUE_SIZE")); err == nil && value >= 0 {
+		consumerConfig.QueueSize = value
 	}
-	log.Info().
-		Str("broker", kafkaBroker).
-		Str("topic", kafkaTopic).
-		Str("group_id", kafkaGroupId).
-		Msg("Successfully connected to Kafka")
 
 	db, err := database.NewDatabaseRaw(
 		os.Getenv("DB_HOST"),
@@ -58,37 +64,82 @@ func main() {
 		return
 	}
 	processRepo := scheduled_process.NewProcessRepository(db.Conn)
-	processServ := scheduled_process.NewProcessService(processRepo)
-
-	// Start consuming messages
-	for {
-		// Read messages from Kafka
-		msg, err := reader.ReadMessage(context.Background())
-		if err != nil {
-			log.Error().Err(err).Msg("Error reading message")
-			break
-		}
+	// Debits from frozen wallets and credits to wallets being deleted are refused, so the wallet service is asked before moving funds
+	walletValidator := wallet.NewValidationAdapter(os.Getenv("WALLET_API"))
+	processServ := scheduled_process.NewProcessService(processRepo, walletValidator)
+
+	retryProducer := kafka2.NewProducer(kafkaBroker, retryTopic)
+	deadLetterProducer := kafka2.NewProducer(kafkaBroker, deadLetterTopic)
+
+	policy := retry.PolicyFromEnv()
+	handler := retry.NewHandler(policy, processServ, retryProducer.Writer, deadLetterProducer.Writer)
 
-		// Parse message to ScheduledTransaction
-		var transaction schedule.ScheduledTransaction
-		err = json.Unmarshal(msg.Value, &transaction)
-		if err != nil {
-			log.Error().Err(err).Msg("Error parsing message")
-			continue
+	// Consume the main and the retry topic side by side
+	var consumers []*consumer.Consumer
+	var readers []*kafka.Reader
+	for _, topic := range []string{kafkaTopic, retryTopic} {
+		reader := kafka.NewReader(kafka.ReaderConfig{
+			Brokers: []string{kafkaBroker}, // Kafka broker address
+			Topic:   topic,                 // Topic to consume from
+			GroupID: kafkaGroupId,          // Consumer group ID
+		})
+		readers = append(readers, reader)
+		consumers = append(consumers, consumer.New(topic, reader, handler, consumerConfig))
+	}
+
+	appInstance := app.NewApp()
+	healthController := consumer.NewHealthController(consumers...)
+	appInstance.Fiber.Get("/health", healthController.Health)
+	appInstance.Fiber.Get("/metrics", healthController.Metrics)
+	go func() {
+		if err := appInstance.Fiber.Listen(":8003"); err != nil {
+			log.Error().Err(err).Msg("Error starting health server")
 		}
+	}()
+
+	log.Info().
+		Str("broker", kafkaBroker).
+		Str("topic", kafkaTopic).
+		Str("retry_topic", retryTopic).
+		Str("dlq_topic", deadLetterTopic).
+		Str("group_id", kafkaGroupId).
+		Int("max_attempts", policy.MaxAttempts).
+		Int("workers", consumerConfig.Workers).
+		Msg("Starting transaction consumer")
 
-		// Process the transaction
-		if err := processServ.Process(transaction.ID); err != nil {
-			log.Error().
-				Err(err).
-				Interface("transaction", transaction).
-				Msg("Error processing transaction")
-			continue
+	// SIGINT/SIGTERM stop fetching and drain the messages in flight; if one consumer fails, stop all
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	var wg sync.WaitGroup
+	for _, c := range consumers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := c.Run(ctx); err != nil {
+				log.Error().Err(err).Msg("Consumer stopped")
+				stop()
+			}
+		}()
+	}
+	wg.Wait()
+	log.Info().Msg("Shutting down transaction consumer...")
+
+	for _, reader := range readers {
+		if err := reader.Close(); err != nil {
+			log.Error().Err(err).Msg("Failed to close Kafka reader")
 		}
+	}
+	_ = retryProducer.Close()
+	_ = deadLetterProducer.Close()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := appInstance.Shutdown(shutdownCtx); err != nil {
+		log.Error().Err(err).Msg("Failed to shut down health server")
+	}
 
-		// Log the successful transaction details
-		log.Info().
-			Interface("transaction", transaction).
-			Msg("Consumed transaction")
+	if dbCloseErr := db.Close(); dbCloseErr != nil {
+		log.Error().Err(dbCloseErr).Msg("Failed to close database connection")
 	}
 }
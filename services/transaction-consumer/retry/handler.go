@@ -0,0 +1,179 @@
+package retry
+
+import (
+	"asset-management/internal/funds"
+	"asset-management/internal/schedule/scheduled_process"
+	"asset-management/pkg/event"
+	"asset-management/services/asset-api/wallet"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"github.com/segmentio/kafka-go"
+	"strconv"
+	"time"
+)
+
+// Headers set on messages written to the retry and dead-letter topics.
+const (
+	HeaderAttempt       = "x-attempt"        // Attempt the message is on, starting at 1
+	HeaderNotBefore     = "x-not-before"     // RFC 3339 time before which a retry must not be processed
+	HeaderFailureReason = "x-failure-reason" // Error of the last failed attempt
+	HeaderOriginalTopic = "x-original-topic" // Topic the message was first consumed from
+	HeaderFailedAt      = "x-failed-at"      // RFC 3339 time of the last failed attempt
+	HeaderTransactionID = "x-transaction-id" // Scheduled transaction ID, when the message could be parsed
+)
+
+// Writer is the part of kafka.Writer used to hand messages over to another topic.
+type Writer interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+}
+
+type Handler struct {
+	policy     Policy
+	service    scheduled_process.ProcessService
+	retry      Writer
+	deadLetter Writer
+	now        func() time.Time
+}
+
+func NewHandler(policy Policy, service scheduled_process.ProcessService, retry, deadLetter Writer) *Handler {
+	return &Handler{policy: policy, service: service, retry: retry, deadLetter: deadLetter, now: time.Now}
+}
+
+// Handle processes a scheduled transaction message. When processing fails the message is written to the
+// retry topic with a backoff or, once the policy's attempts are used up or the error is permanent, the
+// transaction is marked FAILED and the message is written to the dead-letter topic. Handle returns an error
+// only when the message could not be handed over, in which case its offset must not be committed.
+func (h *Handler) Handle(ctx context.Context, msg kafka.Message) error {
+	if err := h.waitUntilDue(ctx, msg); err != nil {
+		return err
+	}
+
+	// Accepts every supported schema version, so older messages still in the topics are processed
+	transaction, err := event.DecodeScheduledTransactionDue(msg)
+	if err != nil {
+		// Retrying cannot fix a message that does not parse
+		log.Error().Err(err).Msg("Error parsing message")
+		return h.deadLetterMessage(ctx, msg, fmt.Sprintf("invalid message: %v", err))
+	}
+
+	processErr := h.service.Process(transaction.TransactionID)
+	if processErr == nil {
+		log.Info().
+			Str("event_id", event.Header(msg, event.HeaderEventID)).
+			Str("traceparent", event.Header(msg, event.HeaderTraceParent)).
+			Interface("transaction", transaction).
+			Msg("Consumed transaction")
+		return nil
+	}
+
+	attempt := Attempt(msg)
+	log.Error().
+		Err(processErr).
+		Int("attempt", attempt).
+		Str("event_id", event.Header(msg, event.HeaderEventID)).
+		Str("traceparent", event.Header(msg, event.HeaderTraceParent)).
+		Interface("transaction", transaction).
+		Msg("Error processing transaction")
+
+	if attempt < h.policy.MaxAttempts && !permanent(processErr) {
+		return h.retryMessage(ctx, msg, attempt, processErr.Error())
+	}
+
+	if err := h.service.MarkFailed(transaction.TransactionID, processErr.Error()); err != nil {
+		return err
+	}
+	return h.deadLetterMessage(ctx, msg, processErr.Error())
+}
+
+func (h *Handler) retryMessage(ctx context.Context, msg kafka.Message, attempt int, reason string) error {
+	now := h.now()
+	headers := failureHeaders(msg, now, reason)
+	headers = setHeader(headers, HeaderAttempt, strconv.Itoa(attempt+1))
+	headers = setHeader(headers, HeaderNotBefore, now.Add(h.policy.Backoff(attempt)).Format(time.RFC3339Nano))
+
+	if err := h.retry.WriteMessages(ctx, kafka.Message{Key: msg.Key, Value: msg.Value, Headers: headers}); err != nil {
+		return fmt.Errorf("failed to write message to retry topic: %w", err)
+	}
+	return nil
+}
+
+func (h *Handler) deadLetterMessage(ctx context.Context, msg kafka.Message, reason string) error {
+	headers := failureHeaders(msg, h.now(), reason)
+	headers = setHeader(headers, HeaderAttempt, strconv.Itoa(Attempt(msg)))
+
+	out := kafka.Message{Key: msg.Key, Value: msg.Value, Headers: headers}
+	if err := h.deadLetter.WriteMessages(ctx, out); err != nil {
+		return fmt.Errorf("failed to write message to dead-letter topic: %w", err)
+	}
+
+	log.Warn().
+		Str("reason", reason).
+		Str("transaction_id", event.Header(out, HeaderTransactionID)).
+		Msg("Message moved to dead-letter topic")
+	return nil
+}
+
+// waitUntilDue blocks until the message's not-before time, if it has one.
+func (h *Handler) waitUntilDue(ctx context.Context, msg kafka.Message) error {
+	notBefore, err := time.Parse(time.RFC3339Nano, event.Header(msg, HeaderNotBefore))
+	if err != nil {
+		return nil
+	}
+
+	delay := notBefore.Sub(h.now())
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// permanent reports whether err cannot go away by retrying, so the transaction fails at once.
+// A frozen wallet may be unfrozen, so its transfers are retried.
+func permanent(err error) bool {
+	return errors.Is(err, funds.ErrInsufficientBalance) ||
+		errors.Is(err, wallet.ErrWalletNotFound) ||
+		errors.Is(err, scheduled_process.ErrNotProcessable)
+}
+
+// Attempt returns the attempt a message is on; messages from the main topic are on their first.
+func Attempt(msg kafka.Message) int {
+	attempt, err := strconv.Atoi(event.Header(msg, HeaderAttempt))
+	if err != nil || attempt < 1 {
+		return 1
+	}
+	return attempt
+}
+
+func failureHeaders(msg kafka.Message, now time.Time, reason string) []kafka.Header {
+	headers := append([]kafka.Header(nil), msg.Headers...)
+	if event.Header(msg, HeaderOriginalTopic) == "" {
+		headers = setHeader(headers, HeaderOriginalTopic, msg.Topic)
+	}
+
+	if transaction, err := event.DecodeScheduledTransactionDue(msg); err == nil {
+		headers = setHeader(headers, HeaderTransactionID, strconv.Itoa(transaction.TransactionID))
+	}
+
+	headers = setHeader(headers, HeaderFailureReason, reason)
+	return setHeader(headers, HeaderFailedAt, now.Format(time.RFC3339Nano))
+}
+
+func setHeader(headers []kafka.Header, key, value string) []kafka.Header {
+	for i, h := range headers {
+		if h.Key == key {
+			headers[i].Value = []byte(value)
+			return headers
+		}
+	}
+	return append(headers, kafka.Header{Key: key, Value: []byte(value)})
+}
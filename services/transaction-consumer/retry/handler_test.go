@@ -0,0 +1,220 @@
+package retry_test
+
+import (
+	"asset-management/internal/funds"
+	"asset-management/pkg/event"
+	"asset-management/services/transaction-consumer/retry"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+type MockProcessService struct {
+	mock.Mock
+}
+
+func (m *MockProcessService) Process(scheduledTransactionID int) error {
+	args := m.Called(scheduledTransactionID)
+	return args.Error(0)
+}
+
+func (m *MockProcessService) MarkFailed(scheduledTransactionID int, reason string) error {
+	args := m.Called(scheduledTransactionID, reason)
+	return args.Error(0)
+}
+
+type fakeWriter struct {
+	messages []kafka.Message
+	err      error
+}
+
+func (w *fakeWriter) WriteMessages(_ context.Context, msgs ...kafka.Message) error {
+	if w.err != nil {
+		return w.err
+	}
+	w.messages = append(w.messages, msgs...)
+	return nil
+}
+
+func headerValue(msg kafka.Message, key string) string {
+	for _, h := range msg.Headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+var policy = retry.Policy{MaxAttempts: 3, InitialBackoff: time.Minute, MaxBackoff: time.Hour}
+
+func message(attempt string) kafka.Message {
+	msg := kafka.Message{Topic: "test-topic", Key: []byte("wallet123"), Value: []byte(`{"id":42}`)}
+	if attempt != "" {
+		msg.Headers = []kafka.Header{{Key: retry.HeaderAttempt, Value: []byte(attempt)}}
+	}
+	return msg
+}
+
+func TestHandler_Success(t *testing.T) {
+	service := new(MockProcessService)
+	retryWriter, deadLetterWriter := &fakeWriter{}, &fakeWriter{}
+	handler := retry.NewHandler(policy, service, retryWriter, deadLetterWriter)
+
+	service.On("Process", 42).Return(nil)
+
+	err := handler.Handle(context.Background(), message(""))
+
+	assert.NoError(t, err)
+	assert.Empty(t, retryWriter.messages)
+	assert.Empty(t, deadLetterWriter.messages)
+	service.AssertExpectations(t)
+}
+
+func TestHandler_CurrentSchemaVersion(t *testing.T) {
+	service := new(MockProcessService)
+	retryWriter, deadLetterWriter := &fakeWriter{}, &fakeWriter{}
+	handler := retry.NewHandler(policy, service, retryWriter, deadLetterWriter)
+
+	envelope, err := event.NewScheduledTransactionDue(event.ScheduledTransactionDue{TransactionID: 42, FromWallet: "wallet123"}, time.Now())
+	assert.NoError(t, err)
+	msg, err := envelope.Message("wallet123")
+	assert.NoError(t, err)
+	msg.Topic = "test-topic"
+
+	service.On("Process", 42).Return(errors.New("insufficient balance in sender's wallet"))
+
+	err = handler.Handle(context.Background(), msg)
+
+	// The retried message keeps the event headers, so it is decoded as the same version again
+	assert.NoError(t, err)
+	assert.Len(t, retryWriter.messages, 1)
+	assert.Equal(t, "42", headerValue(retryWriter.messages[0], retry.HeaderTransactionID))
+	assert.Equal(t, envelope.ID, headerValue(retryWriter.messages[0], event.HeaderEventID))
+	assert.Equal(t, "2", headerValue(retryWriter.messages[0], event.HeaderSchemaVersion))
+	service.AssertExpectations(t)
+}
+
+func TestHandler_UnsupportedSchemaVersionIsDeadLettered(t *testing.T) {
+	service := new(MockProcessService)
+	retryWriter, deadLetterWriter := &fakeWriter{}, &fakeWriter{}
+	handler := retry.NewHandler(policy, service, retryWriter, deadLetterWriter)
+
+	msg := message("")
+	msg.Headers = append(msg.Headers, kafka.Header{Key: event.HeaderSchemaVersion, Value: []byte("99")})
+
+	err := handler.Handle(context.Background(), msg)
+
+	assert.NoError(t, err)
+	assert.Empty(t, retryWriter.messages)
+	assert.Len(t, deadLetterWriter.messages, 1)
+	assert.Contains(t, headerValue(deadLetterWriter.messages[0], retry.HeaderFailureReason), "unsupported event schema version")
+	service.AssertNotCalled(t, "Process", mock.Anything)
+}
+
+func TestHandler_FailureIsRetried(t *testing.T) {
+	service := new(MockProcessService)
+	retryWriter, deadLetterWriter := &fakeWriter{}, &fakeWriter{}
+	handler := retry.NewHandler(policy, service, retryWriter, deadLetterWriter)
+
+	service.On("Process", 42).Return(errors.New("insufficient balance in sender's wallet"))
+
+	before := time.Now()
+	err := handler.Handle(context.Background(), message(""))
+
+	assert.NoError(t, err)
+	assert.Empty(t, deadLetterWriter.messages)
+	assert.Len(t, retryWriter.messages, 1)
+
+	retried := retryWriter.messages[0]
+	assert.Equal(t, "2", headerValue(retried, retry.HeaderAttempt))
+	assert.Equal(t, "test-topic", headerValue(retried, retry.HeaderOriginalTopic))
+	assert.Equal(t, "42", headerValue(retried, retry.HeaderTransactionID))
+	assert.Equal(t, "insufficient balance in sender's wallet", headerValue(retried, retry.HeaderFailureReason))
+
+	notBefore, err := time.Parse(time.RFC3339Nano, headerValue(retried, retry.HeaderNotBefore))
+	assert.NoError(t, err)
+	assert.False(t, notBefore.Before(before.Add(time.Minute)))
+	service.AssertNotCalled(t, "MarkFailed", mock.Anything, mock.Anything)
+}
+
+func TestHandler_ExhaustedIsDeadLettered(t *testing.T) {
+	service := new(MockProcessService)
+	retryWriter, deadLetterWriter := &fakeWriter{}, &fakeWriter{}
+	handler := retry.NewHandler(policy, service, retryWriter, deadLetterWriter)
+
+	service.On("Process", 42).Return(errors.New("insufficient balance in sender's wallet"))
+	service.On("MarkFailed", 42, "insufficient balance in sender's wallet").Return(nil)
+
+	err := handler.Handle(context.Background(), message("3"))
+
+	assert.NoError(t, err)
+	assert.Empty(t, retryWriter.messages)
+	assert.Len(t, deadLetterWriter.messages, 1)
+	assert.Equal(t, "3", headerValue(deadLetterWriter.messages[0], retry.HeaderAttempt))
+	assert.Equal(t, "insufficient balance in sender's wallet", headerValue(deadLetterWriter.messages[0], retry.HeaderFailureReason))
+	service.AssertExpectations(t)
+}
+
+func TestHandler_PermanentFailureIsNotRetried(t *testing.T) {
+	service := new(MockProcessService)
+	retryWriter, deadLetterWriter := &fakeWriter{}, &fakeWriter{}
+	handler := retry.NewHandler(policy, service, retryWriter, deadLetterWriter)
+
+	processErr := fmt.Errorf("failed to process transaction: %w", funds.ErrInsufficientBalance)
+	service.On("Process", 42).Return(processErr)
+	service.On("MarkFailed", 42, processErr.Error()).Return(nil)
+
+	err := handler.Handle(context.Background(), message(""))
+
+	assert.NoError(t, err)
+	assert.Empty(t, retryWriter.messages)
+	assert.Len(t, deadLetterWriter.messages, 1)
+	assert.Equal(t, "1", headerValue(deadLetterWriter.messages[0], retry.HeaderAttempt))
+	service.AssertExpectations(t)
+}
+
+func TestHandler_InvalidMessageIsDeadLettered(t *testing.T) {
+	service := new(MockProcessService)
+	retryWriter, deadLetterWriter := &fakeWriter{}, &fakeWriter{}
+	handler := retry.NewHandler(policy, service, retryWriter, deadLetterWriter)
+
+	err := handler.Handle(context.Background(), kafka.Message{Topic: "test-topic", Value: []byte("not json")})
+
+	assert.NoError(t, err)
+	assert.Len(t, deadLetterWriter.messages, 1)
+	assert.Contains(t, headerValue(deadLetterWriter.messages[0], retry.HeaderFailureReason), "invalid message")
+	service.AssertNotCalled(t, "Process", mock.Anything)
+}
+
+func TestHandler_WriteFailureIsReturned(t *testing.T) {
+	service := new(MockProcessService)
+	retryWriter, deadLetterWriter := &fakeWriter{err: errors.New("broker down")}, &fakeWriter{}
+	handler := retry.NewHandler(policy, service, retryWriter, deadLetterWriter)
+
+	service.On("Process", 42).Return(errors.New("database unavailable"))
+
+	err := handler.Handle(context.Background(), message(""))
+
+	assert.ErrorContains(t, err, "broker down")
+}
+
+func TestHandler_WaitsForNotBefore(t *testing.T) {
+	service := new(MockProcessService)
+	handler := retry.NewHandler(policy, service, &fakeWriter{}, &fakeWriter{})
+
+	msg := message("2")
+	msg.Headers = append(msg.Headers, kafka.Header{Key: retry.HeaderNotBefore, Value: []byte(time.Now().Add(time.Hour).Format(time.RFC3339Nano))})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := handler.Handle(ctx, msg)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	service.AssertNotCalled(t, "Process", mock.Anything)
+}
@@ -0,0 +1,50 @@
+package retry
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+// Policy bounds how often and how fast a failed transaction is retried.
+type Policy struct {
+	MaxAttempts    int           // Processing attempts, including the first one, before a message is dead-lettered
+	InitialBackoff time.Duration // Delay before the first retry, doubled for every following one
+	MaxBackoff     time.Duration // Upper bound of the delay between two attempts
+}
+
+var DefaultPolicy = Policy{
+	MaxAttempts:    5,
+	InitialBackoff: 5 * time.Second,
+	MaxBackoff:     5 * time.Minute,
+}
+
+// PolicyFromEnv reads CONSUMER_MAX_ATTEMPTS, CONSUMER_RETRY_BACKOFF and CONSUMER_MAX_RETRY_BACKOFF,
+// falling back to DefaultPolicy for unset or invalid values.
+func PolicyFromEnv() Policy {
+	policy := DefaultPolicy
+
+	if value, err := strconv.Atoi(os.Getenv("CONSUMER_MAX_ATTEMPTS")); err == nil && value > 0 {
+		policy.MaxAttempts = value
+	}
+	if value, err := time.ParseDuration(os.Getenv("CONSUMER_RETRY_BACKOFF")); err == nil && value > 0 {
+		policy.InitialBackoff = value
+	}
+	if value, err := time.ParseDuration(os.Getenv("CONSUMER_MAX_RETRY_BACKOFF")); err == nil && value > 0 {
+		policy.MaxBackoff = value
+	}
+
+	return policy
+}
+
+// Backoff returns the delay before the attempt following the given failed attempt (1-based).
+func (p Policy) Backoff(attempt int) time.Duration {
+	backoff := p.InitialBackoff
+	for i := 1; i < attempt && backoff < p.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > p.MaxBackoff {
+		return p.MaxBackoff
+	}
+	return backoff
+}
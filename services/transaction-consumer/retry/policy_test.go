@@ -0,0 +1,30 @@
+package retry_test
+
+import (
+	"asset-management/services/transaction-consumer/retry"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestPolicy_Backoff(t *testing.T) {
+	policy := retry.Policy{MaxAttempts: 10, InitialBackoff: time.Second, MaxBackoff: 10 * time.Second}
+
+	assert.Equal(t, time.Second, policy.Backoff(1))
+	assert.Equal(t, 2*time.Second, policy.Backoff(2))
+	assert.Equal(t, 8*time.Second, policy.Backoff(4))
+	assert.Equal(t, 10*time.Second, policy.Backoff(5))
+	assert.Equal(t, 10*time.Second, policy.Backoff(50))
+}
+
+func TestPolicyFromEnv(t *testing.T) {
+	t.Setenv("CONSUMER_MAX_ATTEMPTS", "3")
+	t.Setenv("CONSUMER_RETRY_BACKOFF", "250ms")
+	t.Setenv("CONSUMER_MAX_RETRY_BACKOFF", "invalid")
+
+	policy := retry.PolicyFromEnv()
+
+	assert.Equal(t, 3, policy.MaxAttempts)
+	assert.Equal(t, 250*time.Millisecond, policy.InitialBackoff)
+	assert.Equal(t, retry.DefaultPolicy.MaxBackoff, policy.MaxBackoff)
+}
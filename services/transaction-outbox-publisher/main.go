@@ -1,6 +1,7 @@
 package main
 
 import (
+	"asset-management/internal/outbox"
 	scheduled_next2 "asset-management/internal/schedule/scheduled_next"
 	"asset-management/pkg/app"
 	"asset-management/pkg/database"
@@ -45,12 +46,38 @@ func main() {
 	nextService := scheduled_next2.NewNextService(nextRepo)
 	kafkaProducer := kafka.NewProducer(os.Getenv("KAFKA_BROKER"), os.Getenv("KAFKA_TOPIC"))
 
+	// Lifecycle events are relayed from the outbox of every service database
+	outboxes := []publisher.Outbox{{Name: "asset", Repository: outbox.NewRepository(db.Conn)}}
+	if os.Getenv("WALLET_DB_HOST") != "" {
+		walletDB, err := database.NewDatabaseRaw(
+			os.Getenv("WALLET_DB_HOST"),
+			os.Getenv("WALLET_DB_PORT"),
+			os.Getenv("WALLET_DB_USERNAME"),
+			os.Getenv("WALLET_DB_PASSWORD"),
+			os.Getenv("WALLET_DB_NAME"))
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to initialize wallet database")
+			return
+		}
+		defer walletDB.Close()
+		outboxes = append(outboxes, publisher.Outbox{Name: "wallet", Repository: outbox.NewRepository(walletDB.Conn)})
+	}
+
+	eventsTopic := os.Getenv("KAFKA_EVENTS_TOPIC")
+	if eventsTopic == "" {
+		eventsTopic = "asset-events"
+	}
+	eventsProducer := kafka.NewProducer(os.Getenv("KAFKA_BROKER"), eventsTopic)
+	defer eventsProducer.Close()
+
 	s := publisher.NewService(nextService, kafkaProducer)
-	c := publisher.NewController(s)
+	r := publisher.NewRelayService(eventsProducer, outboxes...)
+	c := publisher.NewController(s, r)
 
 	appInstance.Fiber.Post("/trigger-publisher", c.TriggerPublisher)
+	appInstance.Fiber.Post("/trigger-event-relay", c.TriggerEventRelay)
 
-	cronJob := publisher.NewJob(s)
+	cronJob := publisher.NewJob(s, r)
 	if cronErr := cronJob.Start(); cronErr != nil {
 		log.Error().Err(cronErr).Msg("Failed to start cron job")
 		return
@@ -7,14 +7,16 @@ import (
 
 type Controller interface {
 	TriggerPublisher(ctx *fiber.Ctx) error
+	TriggerEventRelay(ctx *fiber.Ctx) error
 }
 
 type controller struct {
 	service Service
+	relay   RelayService
 }
 
-func NewController(s Service) Controller {
-	return &controller{service: s}
+func NewController(s Service, r RelayService) Controller {
+	return &controller{service: s, relay: r}
 }
 
 // TriggerPublisher godoc
@@ -35,3 +37,22 @@ func (c *controller) TriggerPublisher(ctx *fiber.Ctx) error {
 
 	return ctx.JSON(fiber.Map{"eventCount": eventCount})
 }
+
+// TriggerEventRelay godoc
+// @Summary Triggers the event relay
+// @Description Manually relays the unpublished lifecycle events of every outbox to the events topic
+// @Tags Publisher
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]int "eventCount"
+// @Failure 500 {object} map[string]string "error"
+// @Router /trigger-event-relay [post]
+func (c *controller) TriggerEventRelay(ctx *fiber.Ctx) error {
+	eventCount, err := c.relay.RelayEvents()
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).
+			JSON(fiber.Map{"error": fmt.Errorf("failed to trigger event relay: %w", err).Error()})
+	}
+
+	return ctx.JSON(fiber.Map{"eventCount": eventCount})
+}
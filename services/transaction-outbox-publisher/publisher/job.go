@@ -10,12 +10,14 @@ import (
 type Job struct {
 	scheduler *cron.Cron
 	service   Service
+	relay     RelayService
 }
 
-func NewJob(service Service) *Job {
+func NewJob(service Service, relay RelayService) *Job {
 	return &Job{
 		scheduler: cron.New(cron.WithSeconds()),
 		service:   service,
+		relay:     relay,
 	}
 }
 
@@ -33,6 +35,13 @@ func (j *Job) Start() error {
 		} else {
 			log.Info().Int("event_count", eventCount).Msg("Cron job: Successfully triggered publisher")
 		}
+
+		relayed, err := j.relay.RelayEvents()
+		if err != nil {
+			log.Error().Err(err).Int("event_count", relayed).Msg("Cron job: Failed to relay events")
+		} else if relayed > 0 {
+			log.Info().Int("event_count", relayed).Msg("Cron job: Successfully relayed events")
+		}
 	})
 	if err != nil {
 		return err
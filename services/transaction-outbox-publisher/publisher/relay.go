@@ -0,0 +1,82 @@
+package publisher
+
+import (
+	"asset-management/internal/outbox"
+	"asset-management/pkg/kafka"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	kafka2 "github.com/segmentio/kafka-go"
+)
+
+// Outbox is an event_outbox table of one service's database.
+type Outbox struct {
+	Name       string
+	Repository outbox.Repository
+}
+
+type RelayService interface {
+	RelayEvents() (int, error)
+}
+
+type relayService struct {
+	outboxes []Outbox
+	producer *kafka.Producer
+}
+
+func NewRelayService(producer *kafka.Producer, outboxes ...Outbox) RelayService {
+	return &relayService{
+		outboxes: outboxes,
+		producer: producer,
+	}
+}
+
+// RelayEvents publishes the unpublished events of every outbox to the events topic, oldest first. A failing
+// outbox does not hold up the others; its events stay unpublished until the next run.
+func (s *relayService) RelayEvents() (int, error) {
+	size := batchSize()
+	relayed := 0
+	var errs []error
+	for _, o := range s.outboxes {
+		for {
+			count, err := o.Repository.Claim(size, s.publish)
+			relayed += count
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to relay %s events: %w", o.Name, err))
+				break
+			}
+			if count < size {
+				break
+			}
+		}
+	}
+	return relayed, errors.Join(errs...)
+}
+
+// publish writes the events to Kafka and returns once the write is acknowledged.
+func (s *relayService) publish(records []outbox.Record) error {
+	messages := make([]kafka2.Message, 0, len(records))
+	for _, record := range records {
+		message, err := record.Message()
+		if err != nil {
+			return err
+		}
+		messages = append(messages, message)
+	}
+
+	if err := s.producer.Writer.WriteMessages(context.Background(), messages...); err != nil {
+		log.Error().
+			Err(err).
+			Int("message_count", len(messages)).
+			Str("topic", s.producer.Writer.Topic).
+			Msg("Error writing events to Kafka")
+		return err
+	}
+
+	log.Info().
+		Int("message_count", len(messages)).
+		Str("topic", s.producer.Writer.Topic).
+		Msg("Successfully relayed events to Kafka")
+	return nil
+}
@@ -3,12 +3,14 @@ package publisher
 import (
 	"asset-management/internal/schedule"
 	"asset-management/internal/schedule/scheduled_next"
+	"asset-management/pkg/event"
 	"asset-management/pkg/kafka"
 	"context"
-	"encoding/json"
 	"github.com/rs/zerolog/log"
 	kafka2 "github.com/segmentio/kafka-go"
+	"github.com/shopspring/decimal"
 	"os"
+	"strconv"
 	"time"
 )
 
@@ -28,40 +30,57 @@ func NewService(nextService scheduled_next.NextService, producer *kafka.Producer
 	}
 }
 
+// defaultBatchSize is the number of transactions claimed and written to Kafka at once.
+const defaultBatchSize = 100
+
 func (s *service) TriggerPublisher() (int, error) {
-	topic := os.Getenv("KAFKA_TOPIC")
-	enableMockEvent := os.Getenv("ENABLE_MOCK_EVENT")
-
-	var transactions []schedule.ScheduledTransaction
-	var err error
-	if enableMockEvent == "true" {
-		transactions = mockTransactions()
-	} else {
-		transactions, err = s.nextService.GetNextMinuteTransactions()
-		if err != nil {
+	if os.Getenv("ENABLE_MOCK_EVENT") == "true" {
+		transactions := mockTransactions()
+		if err := s.publish(transactions); err != nil {
 			return 0, err
 		}
+		return len(transactions), nil
+	}
+
+	// Due transactions are claimed in the database and only marked as published once Kafka has confirmed the write
+	return s.nextService.PublishDue(batchSize(), s.publish)
+}
+
+// batchSize returns PUBLISH_BATCH_SIZE, or defaultBatchSize when it is not set.
+func batchSize() int {
+	if value, err := strconv.Atoi(os.Getenv("PUBLISH_BATCH_SIZE")); err == nil && value > 0 {
+		return value
 	}
+	return defaultBatchSize
+}
+
+// publish writes the transactions to Kafka and returns once the write is acknowledged.
+func (s *service) publish(transactions []schedule.ScheduledTransaction) error {
+	topic := os.Getenv("KAFKA_TOPIC")
 
 	var messages []kafka2.Message
 
-	// Serialize each transaction to JSON and prepare the Kafka messages.
+	// Wrap each transaction in a versioned event and prepare the Kafka messages.
 	for _, transaction := range transactions {
-		message, err := json.Marshal(transaction)
+		envelope, err := event.NewScheduledTransactionDue(dueEvent(transaction), time.Now())
 		if err != nil {
 			log.Error().
 				Int("transaction_id", transaction.ID).
 				Err(err).
 				Msg("Error serializing transaction")
-			continue
+			return err
 		}
 
-		// Append the serialized transaction as a Kafka message to the slice.
-		messages = append(messages, kafka2.Message{
-			Key:   []byte(transaction.FromWallet), // Using FromWallet as the message key
-			Value: message,
-			Time:  time.Now(),
-		})
+		// Using FromWallet as the message key keeps the transfers of a wallet in order
+		message, err := envelope.Message(transaction.FromWallet)
+		if err != nil {
+			log.Error().
+				Int("transaction_id", transaction.ID).
+				Err(err).
+				Msg("Error serializing transaction")
+			return err
+		}
+		messages = append(messages, message)
 	}
 
 	// Send all messages in bulk to Kafka.
@@ -72,7 +91,7 @@ func (s *service) TriggerPublisher() (int, error) {
 			Int("message_count", len(messages)).
 			Str("topic", topic).
 			Msg("Error writing messages to Kafka")
-		return 0, writeErr
+		return writeErr
 	}
 
 	log.Info().
@@ -80,7 +99,22 @@ func (s *service) TriggerPublisher() (int, error) {
 		Str("topic", topic).
 		Msg("Successfully sent transactions to Kafka")
 
-	return len(messages), nil
+	return nil
+}
+
+// dueEvent maps a scheduled transaction onto the published contract, which must not follow model changes.
+func dueEvent(transaction schedule.ScheduledTransaction) event.ScheduledTransactionDue {
+	return event.ScheduledTransactionDue{
+		TransactionID:    transaction.ID,
+		FromWallet:       transaction.FromWallet,
+		ToWallet:         transaction.ToWallet,
+		Network:          transaction.Network,
+		Asset:            transaction.Asset,
+		Amount:           transaction.Amount,
+		ScheduledTime:    transaction.ScheduledTime,
+		SeriesID:         transaction.SeriesID,
+		OccurrenceNumber: transaction.OccurrenceNumber,
+	}
 }
 
 func mockTransactions() []schedule.ScheduledTransaction {
@@ -90,7 +124,8 @@ func mockTransactions() []schedule.ScheduledTransaction {
 			FromWallet:    "wallet_ABC123",
 			ToWallet:      "wallet_XYZ789",
 			Network:       "Ethereum",
-			Amount:        250.75,
+			Asset:         "ETH",
+			Amount:        decimal.RequireFromString("250.75"),
 			ScheduledTime: time.Date(2024, 10, 31, 15, 0, 0, 0, time.UTC),
 			Status:        "PENDING",
 			CreatedAt:     time.Date(2024, 10, 29, 10, 15, 0, 0, time.UTC),
@@ -100,7 +135,8 @@ func mockTransactions() []schedule.ScheduledTransaction {
 			FromWallet:    "wallet_DEF456",
 			ToWallet:      "wallet_UVW123",
 			Network:       "Bitcoin",
-			Amount:        500.00,
+			Asset:         "BTC",
+			Amount:        decimal.RequireFromString("500.00"),
 			ScheduledTime: time.Date(2024, 11, 1, 12, 0, 0, 0, time.UTC),
 			Status:        "PENDING",
 			CreatedAt:     time.Date(2024, 10, 30, 11, 30, 0, 0, time.UTC),
@@ -0,0 +1,111 @@
+package asset
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/shopspring/decimal"
+	"net/http"
+	"strings"
+)
+
+var (
+	ErrUnavailable   = errors.New("asset service unavailable")
+	ErrSweepRejected = errors.New("sweep rejected")
+)
+
+type Balance struct {
+	Asset  string          `json:"asset"`
+	Amount decimal.Decimal `json:"amount"`
+}
+
+// Holdings is what asset-api still holds for a wallet: funds and scheduled transactions not yet processed.
+type Holdings struct {
+	Balances            []Balance `json:"balances"`
+	PendingTransactions []int     `json:"pending_transactions"`
+}
+
+// Empty reports whether the wallet can be deleted without orphaning funds.
+func (h Holdings) Empty() bool {
+	return len(h.Balances) == 0 && len(h.PendingTransactions) == 0
+}
+
+func (h Holdings) String() string {
+	var parts []string
+	for _, b := range h.Balances {
+		parts = append(parts, b.Amount.String()+" "+b.Asset)
+	}
+	if len(parts) == 0 {
+		parts = append(parts, "no funds")
+	}
+	return fmt.Sprintf("holds %s and has %d pending scheduled transactions", strings.Join(parts, ", "), len(h.PendingTransactions))
+}
+
+type HoldingsAdapter interface {
+	// Holdings returns what asset-api holds for the wallet.
+	Holdings(walletAddress, network string) (*Holdings, error)
+	// Sweep cancels the wallet's pending scheduled transactions and moves its funds to sweepTo.
+	Sweep(walletAddress, network, sweepTo string) error
+}
+
+type assetHoldingsAdapter struct {
+	baseURL string
+}
+
+func NewHoldingsAdapter(baseURL string) HoldingsAdapter {
+	return &assetHoldingsAdapter{baseURL: baseURL}
+}
+
+func (a *assetHoldingsAdapter) Holdings(walletAddress, network string) (*Holdings, error) {
+	url := fmt.Sprintf("%s/wallet/%s/%s/holdings", a.baseURL, network, walletAddress)
+
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %s", ErrUnavailable, errorMessage(resp))
+	}
+
+	var holdings Holdings
+	if err := json.NewDecoder(resp.Body).Decode(&holdings); err != nil {
+		return nil, fmt.Errorf("failed to decode holdings: %w", err)
+	}
+	return &holdings, nil
+}
+
+func (a *assetHoldingsAdapter) Sweep(walletAddress, network, sweepTo string) error {
+	url := fmt.Sprintf("%s/wallet/%s/%s/sweep", a.baseURL, network, walletAddress)
+	body, err := json.Marshal(map[string]string{"sweep_to": sweepTo})
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("%w: %s", ErrUnavailable, errorMessage(resp))
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: %s", ErrSweepRejected, errorMessage(resp))
+	}
+	return nil
+}
+
+// errorMessage returns the message of an asset-api error response, or its status if it has none.
+func errorMessage(resp *http.Response) string {
+	var body struct {
+		Message string `json:"message"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil || body.Message == "" {
+		return resp.Status
+	}
+	return body.Message
+}
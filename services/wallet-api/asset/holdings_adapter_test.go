@@ -0,0 +1,59 @@
+package asset
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHoldings(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/wallet/Ethereum/0x123/holdings" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"balances":[{"asset":"ETH","amount":"1.5"}],"pending_transactions":[12,13]}`))
+	}))
+	defer server.Close()
+
+	holdings, err := NewHoldingsAdapter(server.URL).Holdings("0x123", "Ethereum")
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if holdings.Empty() || holdings.String() != "holds 1.5 ETH and has 2 pending scheduled transactions" {
+		t.Errorf("unexpected holdings %q", holdings)
+	}
+}
+
+func TestHoldings_Unavailable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	_, err := NewHoldingsAdapter(server.URL).Holdings("0x123", "Ethereum")
+
+	if !errors.Is(err, ErrUnavailable) {
+		t.Errorf("expected ErrUnavailable, got %v", err)
+	}
+}
+
+func TestSweep(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/wallet/Ethereum/0x123/sweep" {
+			http.NotFound(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"message":"sweep_to validation failed: failed to validate wallet"}`))
+	}))
+	defer server.Close()
+
+	err := NewHoldingsAdapter(server.URL).Sweep("0x123", "Ethereum", "0xunknown")
+
+	if !errors.Is(err, ErrSweepRejected) || err.Error() != "sweep rejected: sweep_to validation failed: failed to validate wallet" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
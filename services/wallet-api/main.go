@@ -1,10 +1,13 @@
 package main
 
 import (
+	network2 "asset-management/internal/network"
 	"asset-management/pkg/app"
 	"asset-management/pkg/database"
 	"asset-management/pkg/logger"
+	"asset-management/services/wallet-api/asset"
 	_ "asset-management/services/wallet-api/docs"
+	"asset-management/services/wallet-api/network"
 	wallet2 "asset-management/services/wallet-api/wallet"
 	"github.com/gofiber/fiber/v2"
 	"github.com/rs/zerolog"
@@ -36,17 +39,31 @@ func main() {
 	})
 
 	appInstance.Fiber.Get("/swagger/*", fiberSwagger.WrapHandler)
-	if err := db.Conn.AutoMigrate(&wallet2.Wallet{}, &wallet2.WalletDeleted{}); err != nil {
+	networks := network2.Default()
+	if err := wallet2.Migrate(db.Conn, networks); err != nil {
 		log.Error().Err(err).Msg("Failed to migrate database schema")
 		return
 	}
 
 	repo := wallet2.NewWalletRepository(db.Conn)
-	service := wallet2.NewWalletService(repo)
+	// Deletions are checked against the funds and scheduled transactions held by asset-api
+	assets := asset.NewHoldingsAdapter(os.Getenv("ASSET_API"))
+	service := wallet2.NewWalletService(repo, assets, networks)
 	controller := wallet2.NewWalletController(service)
+	networkController := network.NewNetworkController(networks)
+
+	appInstance.Fiber.Get("/networks", networkController.ListNetworks)
 
 	appInstance.Fiber.Post("/wallet", controller.CreateWallet)
+	appInstance.Fiber.Get("/wallet", controller.ListWallets)
+	// Registered before /wallet/:network, which would match "deleted" as a network
+	appInstance.Fiber.Get("/wallet/deleted", controller.ListDeletedWallets)
+	appInstance.Fiber.Post("/wallet/deleted/:id/restore", controller.RestoreWallet)
+	appInstance.Fiber.Get("/wallet/:network", controller.ListNetworkWallets)
 	appInstance.Fiber.Get("/wallet/:network/:address", controller.GetWallet)
+	appInstance.Fiber.Patch("/wallet/:network/:address", controller.UpdateWallet)
+	appInstance.Fiber.Post("/wallet/:network/:address/freeze", controller.FreezeWallet)
+	appInstance.Fiber.Post("/wallet/:network/:address/unfreeze", controller.UnfreezeWallet)
 	appInstance.Fiber.Delete("/wallet/:network/:address", controller.DeleteWallet)
 
 	log.Info().Msg("Wallet Service is running on port 8000")
@@ -0,0 +1,29 @@
+package network
+
+import (
+	network2 "asset-management/internal/network"
+	"github.com/gofiber/fiber/v2"
+)
+
+type NetworkController interface {
+	ListNetworks(ctx *fiber.Ctx) error
+}
+
+type networkController struct {
+	registry *network2.Registry
+}
+
+// NewNetworkController creates a new NetworkController
+func NewNetworkController(registry *network2.Registry) NetworkController {
+	return &networkController{registry: registry}
+}
+
+// ListNetworks lists the networks wallets can be created on
+// @Summary List supported networks
+// @Description List the networks wallets can be created on, with the aliases and address format each accepts
+// @Produce json
+// @Success 200 {array} network2.Network
+// @Router /networks [get]
+func (c *networkController) ListNetworks(ctx *fiber.Ctx) error {
+	return ctx.JSON(c.registry.Networks())
+}
@@ -0,0 +1,25 @@
+package network
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	network2 "asset-management/internal/network"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListNetworks(t *testing.T) {
+	app := fiber.New()
+	app.Get("/networks", NewNetworkController(network2.Default()).ListNetworks)
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/networks", nil))
+
+	var networks []network2.Network
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&networks))
+	assert.Len(t, networks, 4)
+	assert.Equal(t, network2.Network{Name: "Ethereum", Aliases: []string{"ETH"}, AddressFormat: "0x-prefixed hex, EIP-55 checksum if mixed case"}, networks[1])
+}
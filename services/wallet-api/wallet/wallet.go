@@ -1,17 +1,217 @@
 package wallet
 
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"strconv"
+	"strings"
+	"time"
+)
+
 // Wallet struct
 // @Description Represents a wallet in the system
 type Wallet struct {
 	ID      uint   `gorm:"primaryKey;autoIncrement" json:"-"`
-	Network string `gorm:"not null" json:"network"`
-	Address string `gorm:"not null" json:"address"`
+	Network string `gorm:"not null;uniqueIndex:idx_wallets_network_address" json:"network"`
+	Address string `gorm:"not null;uniqueIndex:idx_wallets_network_address" json:"address"`
+
+	OwnerID   string         `gorm:"index" json:"owner_id,omitempty" example:"customer_42"`                               // Customer owning the wallet
+	Label     string         `json:"label,omitempty" example:"Treasury hot wallet"`                                       // Human readable name
+	Tags      pq.StringArray `gorm:"type:text[]" json:"tags,omitempty" swaggertype:"array,string" example:"treasury,hot"` // Free-form tags
+	Status    string         `gorm:"not null;default:active" json:"status" example:"active"`                              // WalletStatusActive or WalletStatusFrozen
+	CreatedAt time.Time      `gorm:"not null;default:CURRENT_TIMESTAMP;index:idx_wallets_created_at" json:"created_at"`   // Time the wallet was created
+	UpdatedAt time.Time      `gorm:"not null;default:CURRENT_TIMESTAMP" json:"updated_at"`                                // Time the wallet was last changed
+
+	StatusReason    string     `json:"status_reason,omitempty" example:"Suspected key compromise"` // Why the wallet was last frozen or unfrozen
+	StatusChangedBy string     `json:"status_changed_by,omitempty" example:"ops@example.com"`      // Who last froze or unfroze the wallet
+	StatusChangedAt *time.Time `json:"status_changed_at,omitempty" example:"2024-10-29T10:15:00Z"` // When the wallet was last frozen or unfrozen
+
+	DeletingSince *time.Time `json:"deleting_since,omitempty" example:"2024-10-29T10:15:00Z"` // Set while the wallet's holdings are settled before it is deleted; funds can neither enter nor leave it
+}
+
+const (
+	WalletStatusActive = "active"
+	WalletStatusFrozen = "frozen"
+)
+
+// WalletUpdate struct
+// @Description Mutable wallet fields; fields left out are not changed. The status is changed by freezing or unfreezing.
+type WalletUpdate struct {
+	OwnerID *string   `json:"owner_id" example:"customer_42"`      // Customer owning the wallet, empty to clear
+	Label   *string   `json:"label" example:"Treasury hot wallet"` // Human readable name, empty to clear
+	Tags    *[]string `json:"tags" example:"treasury,hot"`         // Replaces all tags, empty to clear
+}
+
+// StatusChange struct
+// @Description Who freezes or unfreezes a wallet and why
+type StatusChange struct {
+	Reason string `json:"reason" example:"Suspected key compromise"` // Why the status changes
+	Actor  string `json:"actor" example:"ops@example.com"`           // Who changes it
 }
 
 // WalletDeleted struct
 // @Description Represents a deleted wallet in the system
 type WalletDeleted struct {
-	ID      uint   `gorm:"primaryKey" json:"-"`
+	ID      uint   `gorm:"primaryKey;autoIncrement" json:"id" example:"7"`
 	Network string `gorm:"not null" json:"network"`
 	Address string `gorm:"not null" json:"address"`
+
+	WalletID  uint           `gorm:"not null;default:0;index" json:"wallet_id" example:"42"`                              // ID the wallet had, given back when it is restored
+	DeletedAt time.Time      `gorm:"not null;default:CURRENT_TIMESTAMP;index" json:"deleted_at"`                          // Time the wallet was deleted
+	Reason    string         `json:"reason,omitempty" example:"Created by mistake"`                                       // Why the wallet was deleted
+	CreatedAt time.Time      `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`                                // Time the wallet was originally created
+	OwnerID   string         `json:"owner_id,omitempty" example:"customer_42"`                                            // Customer owning the wallet
+	Label     string         `json:"label,omitempty" example:"Treasury hot wallet"`                                       // Human readable name
+	Tags      pq.StringArray `gorm:"type:text[]" json:"tags,omitempty" swaggertype:"array,string" example:"treasury,hot"` // Free-form tags
+	Status    string         `gorm:"not null;default:active" json:"status" example:"active"`                              // Status the wallet had, kept on restore
+
+	StatusReason    string     `json:"status_reason,omitempty" example:"Suspected key compromise"` // Why the wallet was last frozen or unfrozen
+	StatusChangedBy string     `json:"status_changed_by,omitempty" example:"ops@example.com"`      // Who last froze or unfroze the wallet
+	StatusChangedAt *time.Time `json:"status_changed_at,omitempty" example:"2024-10-29T10:15:00Z"` // When the wallet was last frozen or unfrozen
+}
+
+// NewWalletDeleted archives wallet, deleted for reason.
+func NewWalletDeleted(wallet Wallet, reason string) WalletDeleted {
+	return WalletDeleted{
+		Network:         wallet.Network,
+		Address:         wallet.Address,
+		WalletID:        wallet.ID,
+		Reason:          reason,
+		CreatedAt:       wallet.CreatedAt,
+		OwnerID:         wallet.OwnerID,
+		Label:           wallet.Label,
+		Tags:            wallet.Tags,
+		Status:          wallet.Status,
+		StatusReason:    wallet.StatusReason,
+		StatusChangedBy: wallet.StatusChangedBy,
+		StatusChangedAt: wallet.StatusChangedAt,
+	}
+}
+
+// Wallet returns the wallet as it was before it was deleted.
+func (d WalletDeleted) Wallet() Wallet {
+	return Wallet{
+		ID:              d.WalletID,
+		Network:         d.Network,
+		Address:         d.Address,
+		OwnerID:         d.OwnerID,
+		Label:           d.Label,
+		Tags:            d.Tags,
+		Status:          d.Status,
+		CreatedAt:       d.CreatedAt,
+		StatusReason:    d.StatusReason,
+		StatusChangedBy: d.StatusChangedBy,
+		StatusChangedAt: d.StatusChangedAt,
+	}
+}
+
+// DeleteOptions control how a wallet is deleted.
+type DeleteOptions struct {
+	Reason  string // Why the wallet is deleted, kept with the archived wallet
+	Force   bool   // Sweep funds and cancel scheduled transactions instead of refusing the deletion
+	SweepTo string // Wallet receiving the funds when forcing the deletion of a wallet that holds any
+}
+
+// DeletedWalletFilter selects a page of deleted wallets, most recently deleted first.
+type DeletedWalletFilter struct {
+	Network string  // Only wallets of this network, if set
+	Address string  // Only wallets with this address, if set
+	Limit   int     // Maximum number of wallets on the page
+	Cursor  *Cursor // Position after which the page starts, nil for the first page; its time is the deletion time
+}
+
+// DeletedWalletPage struct
+// @Description A page of deleted wallets
+type DeletedWalletPage struct {
+	Wallets    []WalletDeleted `json:"wallets"`
+	NextCursor string          `json:"next_cursor,omitempty" example:"MjAyNC0xMC0yOVQxMDoxNTowMFp8Nw"` // Pass as cursor to get the next page, empty on the last page
+}
+
+const (
+	SortAsc  = "asc"
+	SortDesc = "desc"
+
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+var (
+	ErrInvalidStatus    = fmt.Errorf("status must be %s or %s", WalletStatusActive, WalletStatusFrozen)
+	ErrNothingToUpdate  = errors.New("no fields to update")
+	ErrStatusUnchanged  = errors.New("wallet already has this status")
+	ErrReasonRequired   = errors.New("reason and actor are required")
+	ErrInvalidCursor    = errors.New("invalid cursor")
+	ErrInvalidSort      = errors.New("sort must be asc or desc")
+	ErrInvalidPageSize  = fmt.Errorf("limit must be between 1 and %d", MaxPageSize)
+	ErrWalletExists     = errors.New("a wallet with this network and address already exists")
+	ErrWalletNotFound   = errors.New("wallet not found")
+	ErrWalletNotEmpty   = errors.New("wallet cannot be deleted")
+	ErrDeleteInProgress = errors.New("wallet is already being deleted")
+
+	ErrSweepTargetRequired = errors.New("sweep_to is required to force the deletion of a wallet that holds funds")
+)
+
+// ErrorResponse struct
+// @Description Error returned by the wallet endpoints
+type ErrorResponse struct {
+	Message string `json:"message" example:"Wallet not found"`
+}
+
+// WalletFilter selects a page of wallets ordered by creation time.
+type WalletFilter struct {
+	Network       string   // Only wallets of this network, if set
+	AddressPrefix string   // Only wallets whose address starts with this, if set
+	OwnerID       string   // Only wallets of this owner, if set
+	Status        string   // Only wallets in this status, if set
+	Label         string   // Only wallets whose label contains this, ignoring case, if set
+	Tags          []string // Only wallets that have all of these tags
+	Sort          string   // SortAsc or SortDesc by creation time
+	Limit         int      // Maximum number of wallets on the page
+	Cursor        *Cursor  // Position after which the page starts, nil for the first page
+}
+
+// Cursor is the position of a wallet in the creation time order. The ID breaks ties between
+// wallets created at the same time.
+type Cursor struct {
+	CreatedAt time.Time
+	ID        uint
+}
+
+// WalletPage struct
+// @Description A page of wallets
+type WalletPage struct {
+	Wallets    []Wallet `json:"wallets"`
+	NextCursor string   `json:"next_cursor,omitempty" example:"MjAyNC0xMC0yOVQxMDoxNTowMFp8NDI"` // Pass as cursor to get the next page, empty on the last page
+}
+
+// Encode returns the cursor in the opaque form used by the API.
+func (c Cursor) Encode() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(c.CreatedAt.Format(time.RFC3339Nano) + "|" + strconv.FormatUint(uint64(c.ID), 10)))
+}
+
+// DecodeCursor parses a cursor returned by Encode.
+func DecodeCursor(value string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	createdAt, id, found := strings.Cut(string(raw), "|")
+	if !found {
+		return nil, ErrInvalidCursor
+	}
+
+	cursor := &Cursor{}
+	if cursor.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	parsedID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	cursor.ID = uint(parsedID)
+
+	return cursor, nil
 }
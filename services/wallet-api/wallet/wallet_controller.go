@@ -1,6 +1,9 @@
 package wallet
 
 import (
+	"asset-management/internal/network"
+	"asset-management/services/wallet-api/asset"
+	"errors"
 	"github.com/gofiber/fiber/v2"
 	"net/http"
 )
@@ -8,7 +11,14 @@ import (
 type WalletController interface {
 	CreateWallet(ctx *fiber.Ctx) error
 	GetWallet(ctx *fiber.Ctx) error
+	ListWallets(ctx *fiber.Ctx) error
+	ListNetworkWallets(ctx *fiber.Ctx) error
 	DeleteWallet(ctx *fiber.Ctx) error
+	UpdateWallet(ctx *fiber.Ctx) error
+	FreezeWallet(ctx *fiber.Ctx) error
+	UnfreezeWallet(ctx *fiber.Ctx) error
+	ListDeletedWallets(ctx *fiber.Ctx) error
+	RestoreWallet(ctx *fiber.Ctx) error
 }
 
 // WalletController struct
@@ -27,17 +37,25 @@ func NewWalletController(service WalletService) WalletController {
 // @Accept json
 // @Produce json
 // @Param wallet body Wallet true "Wallet data"
-// @Failure 400 {string} string "Invalid request"
-// @Failure 500 {string} string "Failed to create wallet"
+// @Success 201 {object} Wallet
+// @Failure 400 {object} ErrorResponse "Invalid request, unsupported network or invalid address"
+// @Failure 409 {object} ErrorResponse "A wallet with this network and address already exists"
+// @Failure 500 {object} ErrorResponse "Failed to create wallet"
 // @Router /wallet [post]
 func (c *walletController) CreateWallet(ctx *fiber.Ctx) error {
 	var wallet Wallet
 	if err := ctx.BodyParser(&wallet); err != nil {
-		return ctx.Status(http.StatusBadRequest).SendString("Invalid request")
+		return ctx.Status(http.StatusBadRequest).JSON(ErrorResponse{Message: "Invalid request"})
 	}
 
-	if err := c.service.CreateWallet(&wallet); err != nil {
-		return ctx.Status(http.StatusInternalServerError).SendString("Failed to create wallet")
+	err := c.service.CreateWallet(&wallet)
+	switch {
+	case errors.Is(err, network.ErrUnsupportedNetwork), errors.Is(err, network.ErrInvalidAddress), errors.Is(err, ErrInvalidStatus):
+		return ctx.Status(http.StatusBadRequest).JSON(ErrorResponse{Message: err.Error()})
+	case errors.Is(err, ErrWalletExists):
+		return ctx.Status(http.StatusConflict).JSON(ErrorResponse{Message: err.Error()})
+	case err != nil:
+		return ctx.Status(http.StatusInternalServerError).JSON(ErrorResponse{Message: "Failed to create wallet"})
 	}
 
 	return ctx.Status(http.StatusCreated).JSON(wallet)
@@ -49,35 +67,295 @@ func (c *walletController) CreateWallet(ctx *fiber.Ctx) error {
 // @Param network path string true "Wallet network"
 // @Param address path string true "Wallet address"
 // @Success 200 {object} Wallet
-// @Failure 404 {string} string "Wallet not found"
-// @Failure 500 {string} string "Failed to retrieve wallet"
+// @Failure 404 {object} ErrorResponse "Wallet not found"
+// @Failure 500 {object} ErrorResponse "Failed to retrieve wallet"
 // @Router /wallet/{network}/{address} [get]
 func (c *walletController) GetWallet(ctx *fiber.Ctx) error {
 	network := ctx.Params("network")
 	address := ctx.Params("address")
 
 	wallet, err := c.service.GetWallet(network, address)
-	if err != nil {
-		return ctx.Status(http.StatusNotFound).SendString("Wallet not found")
+	switch {
+	case errors.Is(err, ErrWalletNotFound):
+		return ctx.Status(http.StatusNotFound).JSON(ErrorResponse{Message: "Wallet not found"})
+	case err != nil:
+		return ctx.Status(http.StatusInternalServerError).JSON(ErrorResponse{Message: "Failed to retrieve wallet"})
 	}
 	return ctx.JSON(wallet)
 }
 
+// ListWallets lists wallets page by page
+// @Summary List wallets
+// @Description List wallets ordered by creation time, optionally filtered by network and address prefix. Pass the returned next_cursor as cursor to get the next page.
+// @Produce json
+// @Param network query string false "Wallet network"
+// @Param address_prefix query string false "Start of the wallet address"
+// @Param owner_id query string false "Wallet owner"
+// @Param status query string false "Wallet status" Enums(active, frozen)
+// @Param label query string false "Part of the wallet label, ignoring case"
+// @Param tag query []string false "Tag the wallet must have; repeat for several" collectionFormat(multi)
+// @Param sort query string false "Order by creation time: asc or desc" default(desc)
+// @Param limit query int false "Page size, at most 100" default(20)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} WalletPage
+// @Failure 400 {object} ErrorResponse "Invalid filter"
+// @Failure 500 {object} ErrorResponse "Failed to list wallets"
+// @Router /wallet [get]
+func (c *walletController) ListWallets(ctx *fiber.Ctx) error {
+	return c.listWallets(ctx, ctx.Query("network"))
+}
+
+// ListNetworkWallets lists the wallets of a network page by page
+// @Summary List wallets of a network
+// @Description Shortcut for GET /wallet?network={network}
+// @Produce json
+// @Param network path string true "Wallet network"
+// @Param address_prefix query string false "Start of the wallet address"
+// @Param owner_id query string false "Wallet owner"
+// @Param status query string false "Wallet status" Enums(active, frozen)
+// @Param label query string false "Part of the wallet label, ignoring case"
+// @Param tag query []string false "Tag the wallet must have; repeat for several" collectionFormat(multi)
+// @Param sort query string false "Order by creation time: asc or desc" default(desc)
+// @Param limit query int false "Page size, at most 100" default(20)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} WalletPage
+// @Failure 400 {object} ErrorResponse "Invalid filter"
+// @Failure 500 {object} ErrorResponse "Failed to list wallets"
+// @Router /wallet/{network} [get]
+func (c *walletController) ListNetworkWallets(ctx *fiber.Ctx) error {
+	return c.listWallets(ctx, ctx.Params("network"))
+}
+
+func (c *walletController) listWallets(ctx *fiber.Ctx, network string) error {
+	filter := WalletFilter{
+		Network:       network,
+		AddressPrefix: ctx.Query("address_prefix"),
+		OwnerID:       ctx.Query("owner_id"),
+		Status:        ctx.Query("status"),
+		Label:         ctx.Query("label"),
+		Sort:          ctx.Query("sort"),
+		Limit:         ctx.QueryInt("limit"),
+	}
+	for _, tag := range ctx.Context().QueryArgs().PeekMulti("tag") {
+		filter.Tags = append(filter.Tags, string(tag))
+	}
+	if ctx.Query("limit") != "" && filter.Limit == 0 {
+		return ctx.Status(http.StatusBadRequest).JSON(ErrorResponse{Message: ErrInvalidPageSize.Error()})
+	}
+	if cursor := ctx.Query("cursor"); cursor != "" {
+		decoded, err := DecodeCursor(cursor)
+		if err != nil {
+			return ctx.Status(http.StatusBadRequest).JSON(ErrorResponse{Message: err.Error()})
+		}
+		filter.Cursor = decoded
+	}
+
+	page, err := c.service.ListWallets(filter)
+	if errors.Is(err, ErrInvalidSort) || errors.Is(err, ErrInvalidPageSize) {
+		return ctx.Status(http.StatusBadRequest).JSON(ErrorResponse{Message: err.Error()})
+	}
+	if err != nil {
+		return ctx.Status(http.StatusInternalServerError).JSON(ErrorResponse{Message: "Failed to list wallets"})
+	}
+	return ctx.JSON(page)
+}
+
 // DeleteWallet deletes a wallet by address and network
 // @Summary Delete a wallet
-// @Description Delete a wallet by its address and network
+// @Description Delete a wallet by its address and network. The wallet is archived and can be restored.
+// @Description A wallet that still holds funds or has scheduled transactions not yet processed is only deleted with force=true,
+// @Description which cancels the transactions and moves the funds to sweep_to first. Meanwhile funds can neither enter nor leave the wallet.
 // @Param address path string true "Wallet address"
 // @Param network path string true "Wallet network"
+// @Param reason query string false "Why the wallet is deleted"
+// @Param force query bool false "Sweep funds and cancel scheduled transactions first"
+// @Param sweep_to query string false "Wallet receiving the funds when forcing the deletion"
 // @Success 204 {string} string "No content"
-// @Failure 404 {string} string "Wallet not found"
-// @Failure 500 {string} string "Failed to delete wallet"
+// @Failure 400 {object} ErrorResponse "Sweep target missing or rejected"
+// @Failure 404 {object} ErrorResponse "Wallet not found"
+// @Failure 409 {object} ErrorResponse "Wallet still holds funds or scheduled transactions, or is already being deleted"
+// @Failure 500 {object} ErrorResponse "Failed to delete wallet"
+// @Failure 502 {object} ErrorResponse "Asset service unavailable"
 // @Router /wallet/{network}/{address} [delete]
 func (c *walletController) DeleteWallet(ctx *fiber.Ctx) error {
 	network := ctx.Params("network")
 	address := ctx.Params("address")
 
-	if err := c.service.DeleteWallet(network, address); err != nil {
-		return ctx.Status(http.StatusNotFound).SendString("Wallet not found")
+	options := DeleteOptions{
+		Reason:  ctx.Query("reason"),
+		Force:   ctx.QueryBool("force"),
+		SweepTo: ctx.Query("sweep_to"),
+	}
+
+	err := c.service.DeleteWallet(network, address, options)
+	switch {
+	case errors.Is(err, ErrWalletNotEmpty):
+		return ctx.Status(http.StatusConflict).JSON(ErrorResponse{Message: err.Error() + "; delete with force=true and sweep_to to sweep it first"})
+	case errors.Is(err, ErrDeleteInProgress):
+		return ctx.Status(http.StatusConflict).JSON(ErrorResponse{Message: err.Error()})
+	case errors.Is(err, ErrSweepTargetRequired) || errors.Is(err, asset.ErrSweepRejected):
+		return ctx.Status(http.StatusBadRequest).JSON(ErrorResponse{Message: err.Error()})
+	case errors.Is(err, asset.ErrUnavailable):
+		return ctx.Status(http.StatusBadGateway).JSON(ErrorResponse{Message: "Failed to check wallet holdings"})
+	case errors.Is(err, ErrWalletNotFound):
+		return ctx.Status(http.StatusNotFound).JSON(ErrorResponse{Message: "Wallet not found"})
+	case err != nil:
+		return ctx.Status(http.StatusInternalServerError).JSON(ErrorResponse{Message: "Failed to delete wallet"})
 	}
 	return ctx.SendStatus(http.StatusNoContent)
 }
+
+// ListDeletedWallets lists deleted wallets page by page
+// @Summary List deleted wallets
+// @Description List archived wallets, most recently deleted first. Pass the returned next_cursor as cursor to get the next page.
+// @Produce json
+// @Param network query string false "Wallet network"
+// @Param address query string false "Wallet address"
+// @Param limit query int false "Page size, at most 100" default(20)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} DeletedWalletPage
+// @Failure 400 {object} ErrorResponse "Invalid filter"
+// @Failure 500 {object} ErrorResponse "Failed to list deleted wallets"
+// @Router /wallet/deleted [get]
+func (c *walletController) ListDeletedWallets(ctx *fiber.Ctx) error {
+	filter := DeletedWalletFilter{
+		Network: ctx.Query("network"),
+		Address: ctx.Query("address"),
+		Limit:   ctx.QueryInt("limit"),
+	}
+	if ctx.Query("limit") != "" && filter.Limit == 0 {
+		return ctx.Status(http.StatusBadRequest).JSON(ErrorResponse{Message: ErrInvalidPageSize.Error()})
+	}
+	if cursor := ctx.Query("cursor"); cursor != "" {
+		decoded, err := DecodeCursor(cursor)
+		if err != nil {
+			return ctx.Status(http.StatusBadRequest).JSON(ErrorResponse{Message: err.Error()})
+		}
+		filter.Cursor = decoded
+	}
+
+	page, err := c.service.ListDeletedWallets(filter)
+	if errors.Is(err, ErrInvalidPageSize) {
+		return ctx.Status(http.StatusBadRequest).JSON(ErrorResponse{Message: err.Error()})
+	}
+	if err != nil {
+		return ctx.Status(http.StatusInternalServerError).JSON(ErrorResponse{Message: "Failed to list deleted wallets"})
+	}
+	return ctx.JSON(page)
+}
+
+// RestoreWallet restores a deleted wallet
+// @Summary Restore a deleted wallet
+// @Description Move an archived wallet back, with its original metadata and status, unless its network and address were taken again
+// @Produce json
+// @Param id path int true "Deleted wallet ID"
+// @Success 200 {object} Wallet
+// @Failure 400 {object} ErrorResponse "Invalid ID"
+// @Failure 404 {object} ErrorResponse "Deleted wallet not found"
+// @Failure 409 {object} ErrorResponse "A wallet with this network and address already exists"
+// @Failure 500 {object} ErrorResponse "Failed to restore wallet"
+// @Router /wallet/deleted/{id}/restore [post]
+func (c *walletController) RestoreWallet(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil || id < 1 {
+		return ctx.Status(http.StatusBadRequest).JSON(ErrorResponse{Message: "Invalid ID"})
+	}
+
+	wallet, err := c.service.RestoreWallet(uint(id))
+	switch {
+	case errors.Is(err, ErrWalletNotFound):
+		return ctx.Status(http.StatusNotFound).JSON(ErrorResponse{Message: "Deleted wallet not found"})
+	case errors.Is(err, ErrWalletExists):
+		return ctx.Status(http.StatusConflict).JSON(ErrorResponse{Message: err.Error()})
+	case err != nil:
+		return ctx.Status(http.StatusInternalServerError).JSON(ErrorResponse{Message: "Failed to restore wallet"})
+	}
+	return ctx.JSON(wallet)
+}
+
+// UpdateWallet updates the mutable fields of a wallet
+// @Summary Update a wallet
+// @Description Update the owner, label or tags of a wallet. Fields left out of the body are not changed.
+// @Accept json
+// @Produce json
+// @Param network path string true "Wallet network"
+// @Param address path string true "Wallet address"
+// @Param update body WalletUpdate true "Fields to change"
+// @Success 200 {object} Wallet
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 404 {object} ErrorResponse "Wallet not found"
+// @Failure 500 {object} ErrorResponse "Failed to update wallet"
+// @Router /wallet/{network}/{address} [patch]
+func (c *walletController) UpdateWallet(ctx *fiber.Ctx) error {
+	var update WalletUpdate
+	if err := ctx.BodyParser(&update); err != nil {
+		return ctx.Status(http.StatusBadRequest).JSON(ErrorResponse{Message: "Invalid request"})
+	}
+
+	wallet, err := c.service.UpdateWallet(ctx.Params("network"), ctx.Params("address"), update)
+	switch {
+	case errors.Is(err, ErrNothingToUpdate):
+		return ctx.Status(http.StatusBadRequest).JSON(ErrorResponse{Message: err.Error()})
+	case errors.Is(err, ErrWalletNotFound):
+		return ctx.Status(http.StatusNotFound).JSON(ErrorResponse{Message: "Wallet not found"})
+	case err != nil:
+		return ctx.Status(http.StatusInternalServerError).JSON(ErrorResponse{Message: "Failed to update wallet"})
+	}
+	return ctx.JSON(wallet)
+}
+
+// FreezeWallet freezes a wallet
+// @Summary Freeze a wallet
+// @Description Stop funds from leaving a wallet: withdrawals, transfers and scheduled transactions from it are refused until it is unfrozen. It can still receive funds.
+// @Accept json
+// @Produce json
+// @Param network path string true "Wallet network"
+// @Param address path string true "Wallet address"
+// @Param change body StatusChange true "Reason and actor"
+// @Success 200 {object} Wallet
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 404 {object} ErrorResponse "Wallet not found"
+// @Failure 409 {object} ErrorResponse "Wallet already has this status"
+// @Failure 500 {object} ErrorResponse "Failed to change wallet status"
+// @Router /wallet/{network}/{address}/freeze [post]
+func (c *walletController) FreezeWallet(ctx *fiber.Ctx) error {
+	return c.setStatus(ctx, c.service.FreezeWallet)
+}
+
+// UnfreezeWallet unfreezes a wallet
+// @Summary Unfreeze a wallet
+// @Description Allow funds to leave a frozen wallet again
+// @Accept json
+// @Produce json
+// @Param network path string true "Wallet network"
+// @Param address path string true "Wallet address"
+// @Param change body StatusChange true "Reason and actor"
+// @Success 200 {object} Wallet
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 404 {object} ErrorResponse "Wallet not found"
+// @Failure 409 {object} ErrorResponse "Wallet already has this status"
+// @Failure 500 {object} ErrorResponse "Failed to change wallet status"
+// @Router /wallet/{network}/{address}/unfreeze [post]
+func (c *walletController) UnfreezeWallet(ctx *fiber.Ctx) error {
+	return c.setStatus(ctx, c.service.UnfreezeWallet)
+}
+
+func (c *walletController) setStatus(ctx *fiber.Ctx, set func(network, address string, change StatusChange) (*Wallet, error)) error {
+	var change StatusChange
+	if err := ctx.BodyParser(&change); err != nil {
+		return ctx.Status(http.StatusBadRequest).JSON(ErrorResponse{Message: "Invalid request"})
+	}
+
+	wallet, err := set(ctx.Params("network"), ctx.Params("address"), change)
+	switch {
+	case errors.Is(err, ErrReasonRequired):
+		return ctx.Status(http.StatusBadRequest).JSON(ErrorResponse{Message: err.Error()})
+	case errors.Is(err, ErrWalletNotFound):
+		return ctx.Status(http.StatusNotFound).JSON(ErrorResponse{Message: "Wallet not found"})
+	case errors.Is(err, ErrStatusUnchanged):
+		return ctx.Status(http.StatusConflict).JSON(ErrorResponse{Message: err.Error()})
+	case err != nil:
+		return ctx.Status(http.StatusInternalServerError).JSON(ErrorResponse{Message: "Failed to change wallet status"})
+	}
+	return ctx.JSON(wallet)
+}
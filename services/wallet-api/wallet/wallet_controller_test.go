@@ -4,10 +4,15 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"asset-management/internal/network"
+	"asset-management/services/wallet-api/asset"
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -32,11 +37,59 @@ func (m *MockWalletService) GetWallet(network, address string) (*Wallet, error)
 	return nil, args.Error(1) // Ensure to return nil if wallet is not found
 }
 
-func (m *MockWalletService) DeleteWallet(network, address string) error {
-	args := m.Called(network, address)
+func (m *MockWalletService) ListWallets(filter WalletFilter) (*WalletPage, error) {
+	args := m.Called(filter)
+	if page, ok := args.Get(0).(*WalletPage); ok {
+		return page, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockWalletService) UpdateWallet(network, address string, update WalletUpdate) (*Wallet, error) {
+	args := m.Called(network, address, update)
+	if wallet, ok := args.Get(0).(*Wallet); ok {
+		return wallet, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockWalletService) FreezeWallet(network, address string, change StatusChange) (*Wallet, error) {
+	args := m.Called(network, address, change)
+	if wallet, ok := args.Get(0).(*Wallet); ok {
+		return wallet, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockWalletService) UnfreezeWallet(network, address string, change StatusChange) (*Wallet, error) {
+	args := m.Called(network, address, change)
+	if wallet, ok := args.Get(0).(*Wallet); ok {
+		return wallet, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockWalletService) DeleteWallet(network, address string, options DeleteOptions) error {
+	args := m.Called(network, address, options)
 	return args.Error(0)
 }
 
+func (m *MockWalletService) ListDeletedWallets(filter DeletedWalletFilter) (*DeletedWalletPage, error) {
+	args := m.Called(filter)
+	if page, ok := args.Get(0).(*DeletedWalletPage); ok {
+		return page, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockWalletService) RestoreWallet(id uint) (*Wallet, error) {
+	args := m.Called(id)
+	if wallet, ok := args.Get(0).(*Wallet); ok {
+		return wallet, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
 func TestCreateWallet(t *testing.T) {
 	app := fiber.New()
 	mockService := new(MockWalletService)
@@ -65,6 +118,48 @@ func TestCreateWallet(t *testing.T) {
 
 		assert.Equal(t, http.StatusBadRequest, respInvalid.StatusCode)
 	})
+
+	t.Run("invalid address", func(t *testing.T) {
+		invalid := Wallet{Address: "0x123", Network: "Ethereum"}
+		invalidBody, _ := json.Marshal(invalid)
+		mockService.On("CreateWallet", &invalid).Return(fmt.Errorf("%w: ethereum address \"0x123\" must be 0x followed by 40 hex digits", network.ErrInvalidAddress))
+
+		req := httptest.NewRequest(http.MethodPost, "/wallet", bytes.NewBuffer(invalidBody))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+
+		respBody, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assert.JSONEq(t, `{"message":"invalid address: ethereum address \"0x123\" must be 0x followed by 40 hex digits"}`, string(respBody))
+	})
+
+	t.Run("duplicate", func(t *testing.T) {
+		duplicate := Wallet{Address: "taken_address", Network: "test_network"}
+		duplicateBody, _ := json.Marshal(duplicate)
+		mockService.On("CreateWallet", &duplicate).Return(ErrWalletExists)
+
+		req := httptest.NewRequest(http.MethodPost, "/wallet", bytes.NewBuffer(duplicateBody))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+
+		respBody, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+		assert.JSONEq(t, `{"message":"a wallet with this network and address already exists"}`, string(respBody))
+	})
+
+	t.Run("database failure", func(t *testing.T) {
+		failing := Wallet{Address: "other_address", Network: "test_network"}
+		failingBody, _ := json.Marshal(failing)
+		mockService.On("CreateWallet", &failing).Return(errors.New("connection refused"))
+
+		req := httptest.NewRequest(http.MethodPost, "/wallet", bytes.NewBuffer(failingBody))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+
+		respBody, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		assert.JSONEq(t, `{"message":"Failed to create wallet"}`, string(respBody))
+	})
 }
 
 func TestGetWallet(t *testing.T) {
@@ -92,13 +187,21 @@ func TestGetWallet(t *testing.T) {
 	})
 
 	t.Run("not found", func(t *testing.T) {
-		mockService.On("GetWallet", "test_network", "nonexistent_address").Return(nil, errors.New("error"))
+		mockService.On("GetWallet", "test_network", "nonexistent_address").Return(nil, ErrWalletNotFound)
 
 		reqNotFound := httptest.NewRequest(http.MethodGet, "/wallet/test_network/nonexistent_address", nil)
 		respNotFound, _ := app.Test(reqNotFound)
 
 		assert.Equal(t, http.StatusNotFound, respNotFound.StatusCode)
 	})
+
+	t.Run("database failure", func(t *testing.T) {
+		mockService.On("GetWallet", "test_network", "other_address").Return(nil, errors.New("connection refused"))
+
+		resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/wallet/test_network/other_address", nil))
+
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	})
 }
 
 func TestDeleteWallet(t *testing.T) {
@@ -109,9 +212,9 @@ func TestDeleteWallet(t *testing.T) {
 	app.Delete("/wallet/:network/:address", controller.DeleteWallet)
 
 	t.Run("successful", func(t *testing.T) {
-		mockService.On("DeleteWallet", "test_network", "test_address").Return(nil)
+		mockService.On("DeleteWallet", "test_network", "test_address", DeleteOptions{Reason: "Created by mistake"}).Return(nil)
 
-		req := httptest.NewRequest(http.MethodDelete, "/wallet/test_network/test_address", nil)
+		req := httptest.NewRequest(http.MethodDelete, "/wallet/test_network/test_address?reason=Created%20by%20mistake", nil)
 		resp, _ := app.Test(req)
 
 		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
@@ -119,11 +222,316 @@ func TestDeleteWallet(t *testing.T) {
 	})
 
 	t.Run("not found", func(t *testing.T) {
-		mockService.On("DeleteWallet", "test_network", "nonexistent_address").Return(errors.New("error"))
+		mockService.On("DeleteWallet", "test_network", "nonexistent_address", DeleteOptions{}).Return(ErrWalletNotFound)
 
 		reqNotFound := httptest.NewRequest(http.MethodDelete, "/wallet/test_network/nonexistent_address", nil)
 		respNotFound, _ := app.Test(reqNotFound)
 
 		assert.Equal(t, http.StatusNotFound, respNotFound.StatusCode)
 	})
+
+	t.Run("holds funds", func(t *testing.T) {
+		notEmpty := fmt.Errorf("%w: it holds 1.5 ETH and has 0 pending scheduled transactions", ErrWalletNotEmpty)
+		mockService.On("DeleteWallet", "test_network", "funded_address", DeleteOptions{}).Return(notEmpty)
+
+		req := httptest.NewRequest(http.MethodDelete, "/wallet/test_network/funded_address", nil)
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+		body, _ := io.ReadAll(resp.Body)
+		assert.Contains(t, string(body), "holds 1.5 ETH")
+	})
+
+	t.Run("force", func(t *testing.T) {
+		options := DeleteOptions{Force: true, SweepTo: "safe_address"}
+		mockService.On("DeleteWallet", "test_network", "funded_address", options).Return(nil)
+
+		req := httptest.NewRequest(http.MethodDelete, "/wallet/test_network/funded_address?force=true&sweep_to=safe_address", nil)
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	})
+
+	t.Run("sweep rejected", func(t *testing.T) {
+		options := DeleteOptions{Force: true, SweepTo: "unknown_address"}
+		mockService.On("DeleteWallet", "test_network", "funded_address", options).Return(asset.ErrSweepRejected)
+
+		req := httptest.NewRequest(http.MethodDelete, "/wallet/test_network/funded_address?force=true&sweep_to=unknown_address", nil)
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("already being deleted", func(t *testing.T) {
+		mockService.On("DeleteWallet", "test_network", "busy_address", DeleteOptions{}).Return(ErrDeleteInProgress)
+
+		req := httptest.NewRequest(http.MethodDelete, "/wallet/test_network/busy_address", nil)
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	})
+
+	t.Run("asset service unavailable", func(t *testing.T) {
+		mockService.On("DeleteWallet", "test_network", "other_address", DeleteOptions{}).Return(asset.ErrUnavailable)
+
+		req := httptest.NewRequest(http.MethodDelete, "/wallet/test_network/other_address", nil)
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	})
+}
+
+func TestListWallets(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockWalletService)
+	controller := NewWalletController(mockService)
+
+	app.Get("/wallet", controller.ListWallets)
+	app.Get("/wallet/:network", controller.ListNetworkWallets)
+
+	cursor := Cursor{CreatedAt: time.Date(2024, 10, 29, 10, 15, 0, 0, time.UTC), ID: 42}
+
+	t.Run("success", func(t *testing.T) {
+		page := &WalletPage{Wallets: []Wallet{{Address: "0x123", Network: "Ethereum"}}, NextCursor: "next"}
+		mockService.On("ListWallets", WalletFilter{Network: "Ethereum", AddressPrefix: "0x1", Sort: "asc", Limit: 10, Cursor: &cursor}).Return(page, nil).Once()
+
+		req := httptest.NewRequest(http.MethodGet, "/wallet?network=Ethereum&address_prefix=0x1&sort=asc&limit=10&cursor="+cursor.Encode(), nil)
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var returnedPage WalletPage
+		json.NewDecoder(resp.Body).Decode(&returnedPage)
+		assert.Equal(t, page, &returnedPage)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("metadata filters", func(t *testing.T) {
+		filter := WalletFilter{OwnerID: "customer_42", Status: "frozen", Label: "treasury", Tags: []string{"hot", "eu"}}
+		mockService.On("ListWallets", filter).Return(&WalletPage{Wallets: []Wallet{}}, nil).Once()
+
+		resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/wallet?owner_id=customer_42&status=frozen&label=treasury&tag=hot&tag=eu", nil))
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("network shortcut", func(t *testing.T) {
+		mockService.On("ListWallets", WalletFilter{Network: "Bitcoin"}).Return(&WalletPage{Wallets: []Wallet{}}, nil).Once()
+
+		resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/wallet/Bitcoin", nil))
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/wallet?cursor=not-a-cursor", nil))
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("invalid limit", func(t *testing.T) {
+		resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/wallet?limit=many", nil))
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("invalid sort", func(t *testing.T) {
+		mockService.On("ListWallets", WalletFilter{Sort: "newest"}).Return(nil, ErrInvalidSort).Once()
+
+		resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/wallet?sort=newest", nil))
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		mockService.On("ListWallets", WalletFilter{Network: "Solana"}).Return(nil, errors.New("db down")).Once()
+
+		resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/wallet?network=Solana", nil))
+
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	})
+}
+
+func TestUpdateWallet(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockWalletService)
+	controller := NewWalletController(mockService)
+
+	app.Patch("/wallet/:network/:address", controller.UpdateWallet)
+
+	patch := func(address, body string) *http.Response {
+		req := httptest.NewRequest(http.MethodPatch, "/wallet/Ethereum/"+address, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+		return resp
+	}
+
+	t.Run("success", func(t *testing.T) {
+		label, tags := "Treasury", []string{"hot"}
+		updated := &Wallet{Network: "Ethereum", Address: "0x123", Label: label, Tags: tags, Status: WalletStatusActive}
+		mockService.On("UpdateWallet", "Ethereum", "0x123", WalletUpdate{Label: &label, Tags: &tags}).Return(updated, nil).Once()
+
+		resp := patch("0x123", `{"label":"Treasury","tags":["hot"]}`)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		var returned Wallet
+		json.NewDecoder(resp.Body).Decode(&returned)
+		assert.Equal(t, updated, &returned)
+	})
+
+	t.Run("invalid request", func(t *testing.T) {
+		resp := patch("0x123", `invalid json`)
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("nothing to update", func(t *testing.T) {
+		mockService.On("UpdateWallet", "Ethereum", "0x123", WalletUpdate{}).Return(nil, ErrNothingToUpdate).Once()
+
+		resp := patch("0x123", `{"status":"frozen"}`)
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		label := "Treasury"
+		mockService.On("UpdateWallet", "Ethereum", "0x999", WalletUpdate{Label: &label}).Return(nil, ErrWalletNotFound).Once()
+
+		resp := patch("0x999", `{"label":"Treasury"}`)
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
+
+func TestFreezeWallet(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockWalletService)
+	controller := NewWalletController(mockService)
+
+	app.Post("/wallet/:network/:address/freeze", controller.FreezeWallet)
+	app.Post("/wallet/:network/:address/unfreeze", controller.UnfreezeWallet)
+
+	post := func(path, body string) *http.Response {
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+		return resp
+	}
+	change := StatusChange{Reason: "Suspected key compromise", Actor: "ops@example.com"}
+	body := `{"reason":"Suspected key compromise","actor":"ops@example.com"}`
+
+	t.Run("freeze", func(t *testing.T) {
+		frozen := &Wallet{Network: "Ethereum", Address: "0x123", Status: WalletStatusFrozen, StatusReason: change.Reason, StatusChangedBy: change.Actor}
+		mockService.On("FreezeWallet", "Ethereum", "0x123", change).Return(frozen, nil).Once()
+
+		resp := post("/wallet/Ethereum/0x123/freeze", body)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		var returned Wallet
+		json.NewDecoder(resp.Body).Decode(&returned)
+		assert.Equal(t, frozen, &returned)
+	})
+
+	t.Run("unfreeze", func(t *testing.T) {
+		mockService.On("UnfreezeWallet", "Ethereum", "0x123", change).Return(&Wallet{Status: WalletStatusActive}, nil).Once()
+
+		resp := post("/wallet/Ethereum/0x123/unfreeze", body)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("already frozen", func(t *testing.T) {
+		mockService.On("FreezeWallet", "Ethereum", "0x456", change).Return(nil, ErrStatusUnchanged).Once()
+
+		resp := post("/wallet/Ethereum/0x456/freeze", body)
+
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	})
+
+	t.Run("missing reason", func(t *testing.T) {
+		mockService.On("FreezeWallet", "Ethereum", "0x123", StatusChange{}).Return(nil, ErrReasonRequired).Once()
+
+		resp := post("/wallet/Ethereum/0x123/freeze", `{}`)
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockService.On("FreezeWallet", "Ethereum", "0x999", change).Return(nil, ErrWalletNotFound).Once()
+
+		resp := post("/wallet/Ethereum/0x999/freeze", body)
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
+
+func TestListDeletedWallets(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockWalletService)
+	controller := NewWalletController(mockService)
+
+	app.Get("/wallet/deleted", controller.ListDeletedWallets)
+	app.Get("/wallet/:network", controller.ListNetworkWallets)
+
+	t.Run("is not routed as a network", func(t *testing.T) {
+		page := &DeletedWalletPage{Wallets: []WalletDeleted{{ID: 7, WalletID: 42, Network: "Ethereum", Address: "0x123", Reason: "Created by mistake"}}}
+		mockService.On("ListDeletedWallets", DeletedWalletFilter{Network: "Ethereum", Limit: 10}).Return(page, nil).Once()
+
+		req := httptest.NewRequest(http.MethodGet, "/wallet/deleted?network=Ethereum&limit=10", nil)
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		var returned DeletedWalletPage
+		json.NewDecoder(resp.Body).Decode(&returned)
+		assert.Equal(t, page.Wallets[0].ID, returned.Wallets[0].ID)
+		assert.Equal(t, "Created by mistake", returned.Wallets[0].Reason)
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/wallet/deleted?cursor=nope", nil)
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestRestoreWallet(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockWalletService)
+	controller := NewWalletController(mockService)
+
+	app.Post("/wallet/deleted/:id/restore", controller.RestoreWallet)
+
+	restore := func(id string) *http.Response {
+		req := httptest.NewRequest(http.MethodPost, "/wallet/deleted/"+id+"/restore", nil)
+		resp, _ := app.Test(req)
+		return resp
+	}
+
+	t.Run("successful", func(t *testing.T) {
+		restored := &Wallet{ID: 42, Network: "Ethereum", Address: "0x123", Status: WalletStatusActive}
+		mockService.On("RestoreWallet", uint(7)).Return(restored, nil).Once()
+
+		resp := restore("7")
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("address taken again", func(t *testing.T) {
+		mockService.On("RestoreWallet", uint(8)).Return(nil, ErrWalletExists).Once()
+
+		assert.Equal(t, http.StatusConflict, restore("8").StatusCode)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockService.On("RestoreWallet", uint(9)).Return(nil, ErrWalletNotFound).Once()
+
+		assert.Equal(t, http.StatusNotFound, restore("9").StatusCode)
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, restore("abc").StatusCode)
+	})
 }
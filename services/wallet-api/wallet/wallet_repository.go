@@ -1,18 +1,45 @@
 package wallet
 
 import (
+	"asset-management/internal/network"
+	"asset-management/internal/outbox"
+	"asset-management/pkg/event"
+	"errors"
 	"fmt"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"strings"
+	"time"
 )
 
-// WalletRepository defines the methods for wallet data persistence
+// WalletRepository defines the methods for wallet data persistence. Wallets that do not exist are
+// reported as ErrWalletNotFound, and network and address pairs that are taken as ErrWalletExists.
 type WalletRepository interface {
 	CreateWallet(wallet *Wallet) error
-	GetWallets() ([]Wallet, error)
-	DeleteWallet(network, address string) error
+	ListWallets(filter WalletFilter) ([]Wallet, error)
+	// BeginDelete marks the wallet as being deleted, which keeps funds from entering or leaving it while
+	// its holdings are settled. A wallet marked less than DeleteTimeout ago is reported as
+	// ErrDeleteInProgress; an older mark was left by a deletion that did not finish and is taken over.
+	BeginDelete(network, address string) error
+	// CancelDelete removes the mark of a deletion that did not complete.
+	CancelDelete(network, address string) error
+	DeleteWallet(network, address, reason string) error
+	ListDeletedWallets(filter DeletedWalletFilter) ([]WalletDeleted, error)
+	RestoreWallet(id uint) (*Wallet, error)
 	GetWallet(network, address string) (*Wallet, error)
+	UpdateWallet(network, address string, update WalletUpdate) (*Wallet, error)
+	SetStatus(network, address, status string, change StatusChange) (*Wallet, error)
 }
 
+// DeleteTimeout is how long a deletion may take before another one can take it over.
+const DeleteTimeout = 10 * time.Minute
+
+// uniqueViolation is the SQLSTATE of an insert or update that breaks a unique index.
+const uniqueViolation = "23505"
+
 type walletRepository struct {
 	db *gorm.DB
 }
@@ -21,58 +48,375 @@ func NewWalletRepository(db *gorm.DB) WalletRepository {
 	return &walletRepository{db: db}
 }
 
-func (r *walletRepository) CreateWallet(wallet *Wallet) error {
-	return r.db.Transaction(func(tx *gorm.DB) error {
-		// Check if the wallet with the same address and network already exists
-		var count int64
-		if err := tx.Model(&Wallet{}).Where("address = ? AND network = ?", wallet.Address, wallet.Network).Count(&count).Error; err != nil {
+// Migrate creates or updates the wallet tables. Wallets stored before networks and addresses were
+// validated are moved to their canonical form first, and wallets that then repeat the network and
+// address of an older one are archived, so that the unique index can be built and every spelling of
+// a wallet finds it. Deleted wallets archived before they kept their own ID still carry the wallet's,
+// which is copied to wallet_id, and the ID sequence is moved past them.
+func Migrate(db *gorm.DB, networks *network.Registry) error {
+	if err := db.AutoMigrate(&WalletDeleted{}, &outbox.Record{}); err != nil {
+		return err
+	}
+	if err := canonicalizeWallets(db, networks); err != nil {
+		return fmt.Errorf("failed to canonicalize wallets: %w", err)
+	}
+	if err := db.AutoMigrate(&Wallet{}); err != nil {
+		return err
+	}
+	if err := db.Exec(`UPDATE wallet_deleteds SET wallet_id = id WHERE wallet_id = 0`).Error; err != nil {
+		return err
+	}
+	return db.Exec(`SELECT setval(pg_get_serial_sequence('wallet_deleteds', 'id'), MAX(id)) FROM wallet_deleteds HAVING MAX(id) IS NOT NULL`).Error
+}
+
+// canonicalizeWallets stores every wallet under the canonical form of its network and address. Of the
+// wallets sharing a canonical form, the oldest keeps it and the others are archived to wallet_deleteds,
+// from where they can be inspected or restored once the older one is gone; they share the older
+// wallet's funds, which the asset service keys by the canonical form as well. Deleted wallets are
+// rewritten too, so that a restored wallet comes back under its canonical form.
+func canonicalizeWallets(db *gorm.DB, networks *network.Registry) error {
+	if !db.Migrator().HasTable(&Wallet{}) {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		// Keeps wallets from being created or changed until they are all canonical
+		if err := tx.Exec(`LOCK TABLE wallets IN EXCLUSIVE MODE`).Error; err != nil {
 			return err
 		}
-		if count > 0 {
-			return fmt.Errorf("wallet with address %s and network %s already exists", wallet.Address, wallet.Network)
+
+		var wallets []Wallet
+		if err := tx.Order("id").Find(&wallets).Error; err != nil {
+			return err
+		}
+
+		kept := map[[2]string]uint{}
+		var renamed []Wallet
+		for _, wallet := range wallets {
+			walletNetwork, walletAddress := networks.Canonical(wallet.Network, wallet.Address)
+			key := [2]string{walletNetwork, walletAddress}
+			if olderID, taken := kept[key]; taken {
+				deleted := NewWalletDeleted(wallet, "Duplicate of an older wallet with the same network and address")
+				deleted.Network, deleted.Address = walletNetwork, walletAddress
+				if err := tx.Create(&deleted).Error; err != nil {
+					return err
+				}
+				if err := tx.Delete(&wallet).Error; err != nil {
+					return err
+				}
+				log.Warn().Uint("wallet_id", wallet.ID).Uint("deleted_wallet_id", deleted.ID).Uint("kept_wallet_id", olderID).
+					Str("network", wallet.Network).Str("address", wallet.Address).
+					Msg("Archived duplicate wallet")
+				continue
+			}
+			kept[key] = wallet.ID
+			if walletNetwork != wallet.Network || walletAddress != wallet.Address {
+				renamed = append(renamed, Wallet{ID: wallet.ID, Network: walletNetwork, Address: walletAddress})
+			}
+		}
+
+		// Renamed once the duplicates are gone, which could hold the canonical form
+		for _, wallet := range renamed {
+			err := tx.Model(&Wallet{}).Where("id = ?", wallet.ID).
+				UpdateColumns(map[string]interface{}{"network": wallet.Network, "address": wallet.Address}).Error
+			if err != nil {
+				return err
+			}
+			log.Info().Uint("wallet_id", wallet.ID).Str("network", wallet.Network).Str("address", wallet.Address).
+				Msg("Moved wallet to its canonical network and address")
 		}
 
-		// If unique, create the wallet
-		return tx.Create(wallet).Error
+		var deleted []WalletDeleted
+		if err := tx.Select("id", "network", "address").Find(&deleted).Error; err != nil {
+			return err
+		}
+		for _, wallet := range deleted {
+			walletNetwork, walletAddress := networks.Canonical(wallet.Network, wallet.Address)
+			if walletNetwork == wallet.Network && walletAddress == wallet.Address {
+				continue
+			}
+			err := tx.Model(&WalletDeleted{}).Where("id = ?", wallet.ID).
+				UpdateColumns(map[string]interface{}{"network": walletNetwork, "address": walletAddress}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
 	})
 }
 
-func (r *walletRepository) GetWallets() ([]Wallet, error) {
+// CreateWallet inserts the wallet. Concurrent creations of the same network and address are settled
+// by the unique index: all but one fail with ErrWalletExists.
+func (r *walletRepository) CreateWallet(wallet *Wallet) error {
+	return translate(r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(wallet).Error; err != nil {
+			return err
+		}
+
+		return writeEvent(tx, event.TypeWalletCreated, event.WalletCreated{Address: wallet.Address, Network: wallet.Network}, wallet.Address)
+	}))
+}
+
+// translate maps the database errors callers act upon to ErrWalletNotFound and ErrWalletExists.
+func translate(err error) error {
+	var pgErr *pgconn.PgError
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return ErrWalletNotFound
+	case errors.As(err, &pgErr) && pgErr.Code == uniqueViolation && pgErr.ConstraintName == "idx_wallets_network_address":
+		return ErrWalletExists
+	}
+	return err
+}
+
+// ListWallets returns up to filter.Limit wallets matching the filter, starting after filter.Cursor.
+func (r *walletRepository) ListWallets(filter WalletFilter) ([]Wallet, error) {
+	query := r.db.Model(&Wallet{})
+	if filter.Network != "" {
+		query = query.Where("network = ?", filter.Network)
+	}
+	if filter.AddressPrefix != "" {
+		query = query.Where(`address LIKE ? ESCAPE '\'`, likePrefix(filter.AddressPrefix))
+	}
+	if filter.OwnerID != "" {
+		query = query.Where("owner_id = ?", filter.OwnerID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Label != "" {
+		query = query.Where(`label ILIKE ? ESCAPE '\'`, "%"+likePrefix(filter.Label))
+	}
+	if len(filter.Tags) > 0 {
+		query = query.Where("tags @> ?", pq.StringArray(filter.Tags))
+	}
+
+	direction, comparison := "ASC", ">"
+	if filter.Sort == SortDesc {
+		direction, comparison = "DESC", "<"
+	}
+	if filter.Cursor != nil {
+		query = query.Where("(created_at, id) "+comparison+" (?, ?)", filter.Cursor.CreatedAt, filter.Cursor.ID)
+	}
+
 	var wallets []Wallet
-	if err := r.db.Find(&wallets).Error; err != nil {
+	err := query.
+		Order("created_at " + direction).
+		Order("id " + direction).
+		Limit(filter.Limit).
+		Find(&wallets).Error
+	if err != nil {
 		return nil, err
 	}
 	return wallets, nil
 }
 
-// todo lock
-func (r *walletRepository) DeleteWallet(network, address string) error {
-	return r.db.Transaction(func(tx *gorm.DB) error {
+// likePrefix escapes the LIKE wildcards in prefix and matches everything that starts with it.
+func likePrefix(prefix string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(prefix) + "%"
+}
+
+// BeginDelete sets deleting_since unless another deletion set it less than DeleteTimeout ago.
+func (r *walletRepository) BeginDelete(network, address string) error {
+	result := r.db.Model(&Wallet{}).
+		Where("network = ? AND address = ?", network, address).
+		Where("deleting_since IS NULL OR deleting_since < CURRENT_TIMESTAMP - make_interval(secs => ?)", DeleteTimeout.Seconds()).
+		UpdateColumn("deleting_since", gorm.Expr("CURRENT_TIMESTAMP"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		if _, err := r.GetWallet(network, address); err != nil {
+			return err
+		}
+		return ErrDeleteInProgress
+	}
+	return nil
+}
+
+// CancelDelete clears deleting_since, so funds can enter and leave the wallet again.
+func (r *walletRepository) CancelDelete(network, address string) error {
+	return r.db.Model(&Wallet{}).
+		Where("network = ? AND address = ?", network, address).
+		UpdateColumn("deleting_since", nil).Error
+}
+
+// DeleteWallet moves the wallet to the wallet_deleteds table, from where it can be restored.
+func (r *walletRepository) DeleteWallet(network, address, reason string) error {
+	return translate(r.db.Transaction(func(tx *gorm.DB) error {
 		// Move the wallet to wallet_deleted table
 		var wallet Wallet
-		if err := tx.Where("network = ? AND address = ?", network, address).First(&wallet).Error; err != nil {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("network = ? AND address = ?", network, address).
+			First(&wallet).Error; err != nil {
 			return err // Return error if the wallet is not found
 		}
 
 		// Insert into wallet_deleted
-		deletedWallet := WalletDeleted{
-			ID:      wallet.ID,
-			Network: wallet.Network,
-			Address: wallet.Address,
-		}
+		deletedWallet := NewWalletDeleted(wallet, reason)
 		if err := tx.Create(&deletedWallet).Error; err != nil {
 			return err // Return error if unable to insert into wallet_deleted
 		}
 
 		// Now delete the original wallet
-		return tx.Delete(&wallet).Error
+		if err := tx.Delete(&wallet).Error; err != nil {
+			return err
+		}
+
+		return writeEvent(tx, event.TypeWalletDeleted, event.WalletDeleted{Address: wallet.Address, Network: wallet.Network, Reason: reason}, wallet.Address)
+	}))
+}
+
+// ListDeletedWallets returns up to filter.Limit deleted wallets matching the filter, most recently
+// deleted first, starting after filter.Cursor.
+func (r *walletRepository) ListDeletedWallets(filter DeletedWalletFilter) ([]WalletDeleted, error) {
+	query := r.db.Model(&WalletDeleted{})
+	if filter.Network != "" {
+		query = query.Where("network = ?", filter.Network)
+	}
+	if filter.Address != "" {
+		query = query.Where("address = ?", filter.Address)
+	}
+	if filter.Cursor != nil {
+		query = query.Where("(deleted_at, id) < (?, ?)", filter.Cursor.CreatedAt, filter.Cursor.ID)
+	}
+
+	var wallets []WalletDeleted
+	err := query.
+		Order("deleted_at DESC").
+		Order("id DESC").
+		Limit(filter.Limit).
+		Find(&wallets).Error
+	if err != nil {
+		return nil, err
+	}
+	return wallets, nil
+}
+
+// RestoreWallet moves a deleted wallet back to the wallets table with its original ID, unless
+// another wallet took its network and address in the meantime.
+func (r *walletRepository) RestoreWallet(id uint) (*Wallet, error) {
+	var wallet Wallet
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var deleted WalletDeleted
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&deleted, id).Error; err != nil {
+			return err
+		}
+
+		// Fails on the unique index if the network and address were taken again
+		wallet = deleted.Wallet()
+		if err := tx.Create(&wallet).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&deleted).Error; err != nil {
+			return err
+		}
+
+		if err := writeEvent(tx, event.TypeWalletRestored, event.WalletRestored{Address: wallet.Address, Network: wallet.Network}, wallet.Address); err != nil {
+			return err
+		}
+		return tx.First(&wallet, wallet.ID).Error
 	})
+	if err != nil {
+		return nil, translate(err)
+	}
+	return &wallet, nil
+}
+
+// SetStatus moves the wallet to status, recording who changed it and why, and reports the change as an event.
+func (r *walletRepository) SetStatus(network, address, status string, change StatusChange) (*Wallet, error) {
+	var wallet Wallet
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("network = ? AND address = ?", network, address).
+			First(&wallet).Error; err != nil {
+			return err
+		}
+		if wallet.Status == status {
+			return ErrStatusUnchanged
+		}
+
+		err := tx.Model(&wallet).Updates(map[string]any{
+			"status":            status,
+			"status_reason":     change.Reason,
+			"status_changed_by": change.Actor,
+			"status_changed_at": gorm.Expr("CURRENT_TIMESTAMP"),
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		eventType := event.TypeWalletUnfrozen
+		if status == WalletStatusFrozen {
+			eventType = event.TypeWalletFrozen
+		}
+		if err := writeEvent(tx, eventType, event.WalletStatusChanged{
+			Address: wallet.Address,
+			Network: wallet.Network,
+			Status:  status,
+			Reason:  change.Reason,
+			Actor:   change.Actor,
+		}, wallet.Address); err != nil {
+			return err
+		}
+
+		return tx.First(&wallet, wallet.ID).Error
+	})
+	if err != nil {
+		return nil, translate(err)
+	}
+	return &wallet, nil
+}
+
+// writeEvent adds an event to the outbox within tx, so it is published if and only if tx commits.
+func writeEvent(tx *gorm.DB, eventType string, payload any, key string) error {
+	record, err := outbox.NewRecord(eventType, payload, key)
+	if err != nil {
+		return err
+	}
+	if err := tx.Create(&record).Error; err != nil {
+		return fmt.Errorf("failed to write %s event to outbox: %w", eventType, err)
+	}
+	return nil
 }
 
 func (r *walletRepository) GetWallet(network, address string) (*Wallet, error) {
 	var wallet Wallet
 	if err := r.db.Where("network = ? AND address = ?", network, address).First(&wallet).Error; err != nil {
-		return nil, err
+		return nil, translate(err)
+	}
+	return &wallet, nil
+}
+
+// UpdateWallet applies the fields set in update to the wallet and returns it.
+func (r *walletRepository) UpdateWallet(network, address string, update WalletUpdate) (*Wallet, error) {
+	var wallet Wallet
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("network = ? AND address = ?", network, address).
+			First(&wallet).Error; err != nil {
+			return err
+		}
+
+		changes := map[string]any{}
+		if update.OwnerID != nil {
+			changes["owner_id"] = *update.OwnerID
+		}
+		if update.Label != nil {
+			changes["label"] = *update.Label
+		}
+		if update.Tags != nil {
+			changes["tags"] = pq.StringArray(*update.Tags)
+		}
+
+		// Updates also sets updated_at
+		if err := tx.Model(&wallet).Updates(changes).Error; err != nil {
+			return err
+		}
+		return tx.First(&wallet, wallet.ID).Error
+	})
+	if err != nil {
+		return nil, translate(err)
 	}
 	return &wallet, nil
 }
@@ -1,6 +1,8 @@
 package wallet
 
 import (
+	"asset-management/internal/network"
+	"asset-management/internal/outbox"
 	"context"
 	"fmt"
 	"github.com/stretchr/testify/assert"
@@ -12,6 +14,18 @@ import (
 )
 
 func setupTestDB(t *testing.T) (*gorm.DB, func()) {
+	db, teardown := startTestDB(t)
+
+	// Run migrations (if needed)
+	if err := Migrate(db, network.Default()); err != nil {
+		teardown()
+		t.Fatalf("Failed to migrate database: %s", err)
+	}
+	return db, teardown
+}
+
+// startTestDB starts an empty database.
+func startTestDB(t *testing.T) (*gorm.DB, func()) {
 	ctx := context.Background()
 
 	// Create PostgreSQL container
@@ -50,11 +64,6 @@ func setupTestDB(t *testing.T) (*gorm.DB, func()) {
 		t.Fatalf("Failed to connect to database: %s", err)
 	}
 
-	// Run migrations (if needed)
-	if err := db.AutoMigrate(&Wallet{}, &WalletDeleted{}); err != nil {
-		t.Fatalf("Failed to migrate database: %s", err)
-	}
-
 	// Cleanup function to stop the container
 	return db, func() {
 		_ = pgContainer.Terminate(ctx)
@@ -72,8 +81,8 @@ func TestWalletRepository(t *testing.T) {
 	err := repo.CreateWallet(wallet)
 	assert.NoError(t, err)
 
-	// Test GetWallets
-	wallets, err := repo.GetWallets()
+	// Test ListWallets
+	wallets, err := repo.ListWallets(WalletFilter{Sort: SortDesc, Limit: 10})
 	assert.NoError(t, err)
 	assert.Len(t, wallets, 1)
 
@@ -84,11 +93,299 @@ func TestWalletRepository(t *testing.T) {
 	assert.Equal(t, wallet.Address, fetchedWallet.Address)
 
 	// Test DeleteWallet
-	err = repo.DeleteWallet("Ethereum", "0x123")
+	err = repo.DeleteWallet("Ethereum", "0x123", "")
 	assert.NoError(t, err)
 
 	// Check if the wallet was deleted
 	fetchedWallet, err = repo.GetWallet("Ethereum", "0x123")
-	assert.Error(t, err)
+	assert.ErrorIs(t, err, ErrWalletNotFound)
 	assert.Nil(t, fetchedWallet)
+
+	// Both changes were written to the outbox
+	var events []outbox.Record
+	err = db.Order("occurred_at").Find(&events).Error
+	assert.NoError(t, err)
+	if assert.Len(t, events, 2) {
+		assert.Equal(t, "wallet.created", events[0].EventType)
+		assert.Equal(t, "wallet.deleted", events[1].EventType)
+		assert.Equal(t, "0x123", events[1].MessageKey)
+	}
+}
+
+func TestWalletRepository_ListWallets(t *testing.T) {
+	db, teardown := setupTestDB(t)
+	defer teardown()
+
+	repo := NewWalletRepository(db)
+
+	for _, w := range []Wallet{
+		{Network: "Ethereum", Address: "0xa1"},
+		{Network: "Ethereum", Address: "0xa2"},
+		{Network: "Ethereum", Address: "0xb1"},
+		{Network: "Bitcoin", Address: "0xa3"},
+		{Network: "Ethereum", Address: "0x_1"},
+	} {
+		wallet := w
+		assert.NoError(t, repo.CreateWallet(&wallet))
+	}
+
+	addresses := func(wallets []Wallet) []string {
+		var result []string
+		for _, w := range wallets {
+			result = append(result, w.Address)
+		}
+		return result
+	}
+
+	t.Run("filters by network and address prefix", func(t *testing.T) {
+		wallets, err := repo.ListWallets(WalletFilter{Network: "Ethereum", AddressPrefix: "0xa", Sort: SortAsc, Limit: 10})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"0xa1", "0xa2"}, addresses(wallets))
+	})
+
+	t.Run("treats wildcards in the prefix literally", func(t *testing.T) {
+		wallets, err := repo.ListWallets(WalletFilter{AddressPrefix: "0x_", Sort: SortAsc, Limit: 10})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"0x_1"}, addresses(wallets))
+	})
+
+	t.Run("pages in creation order", func(t *testing.T) {
+		first, err := repo.ListWallets(WalletFilter{Sort: SortDesc, Limit: 2})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"0x_1", "0xa3"}, addresses(first))
+
+		last := first[len(first)-1]
+		second, err := repo.ListWallets(WalletFilter{Sort: SortDesc, Limit: 2, Cursor: &Cursor{CreatedAt: last.CreatedAt, ID: last.ID}})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"0xb1", "0xa2"}, addresses(second))
+	})
+}
+
+func TestWalletRepository_Metadata(t *testing.T) {
+	db, teardown := setupTestDB(t)
+	defer teardown()
+
+	repo := NewWalletRepository(db)
+
+	wallet := &Wallet{Network: "Ethereum", Address: "0x123", OwnerID: "customer_42", Label: "Treasury hot wallet", Tags: []string{"treasury", "hot"}, Status: WalletStatusActive}
+	assert.NoError(t, repo.CreateWallet(wallet))
+	assert.NoError(t, repo.CreateWallet(&Wallet{Network: "Ethereum", Address: "0x456", OwnerID: "customer_7", Status: WalletStatusActive}))
+
+	wallets, err := repo.ListWallets(WalletFilter{Label: "HOT", Tags: []string{"hot"}, OwnerID: "customer_42", Sort: SortAsc, Limit: 10})
+	assert.NoError(t, err)
+	if assert.Len(t, wallets, 1) {
+		assert.Equal(t, "0x123", wallets[0].Address)
+		assert.Equal(t, []string{"treasury", "hot"}, []string(wallets[0].Tags))
+	}
+
+	label := ""
+	updated, err := repo.UpdateWallet("Ethereum", "0x123", WalletUpdate{Label: &label})
+	assert.NoError(t, err)
+	assert.Empty(t, updated.Label)
+	assert.Equal(t, "customer_42", updated.OwnerID)
+	assert.False(t, updated.UpdatedAt.Before(updated.CreatedAt))
+
+	_, err = repo.UpdateWallet("Ethereum", "0x999", WalletUpdate{Label: &label})
+	assert.ErrorIs(t, err, ErrWalletNotFound)
+}
+
+func TestWalletRepository_SetStatus(t *testing.T) {
+	db, teardown := setupTestDB(t)
+	defer teardown()
+
+	repo := NewWalletRepository(db)
+	assert.NoError(t, repo.CreateWallet(&Wallet{Network: "Ethereum", Address: "0x123"}))
+
+	change := StatusChange{Reason: "Suspected key compromise", Actor: "ops@example.com"}
+	frozen, err := repo.SetStatus("Ethereum", "0x123", WalletStatusFrozen, change)
+	assert.NoError(t, err)
+	assert.Equal(t, WalletStatusFrozen, frozen.Status)
+	assert.Equal(t, change.Reason, frozen.StatusReason)
+	assert.Equal(t, change.Actor, frozen.StatusChangedBy)
+	assert.NotNil(t, frozen.StatusChangedAt)
+
+	_, err = repo.SetStatus("Ethereum", "0x123", WalletStatusFrozen, change)
+	assert.ErrorIs(t, err, ErrStatusUnchanged)
+
+	wallets, err := repo.ListWallets(WalletFilter{Status: WalletStatusFrozen, Sort: SortAsc, Limit: 10})
+	assert.NoError(t, err)
+	assert.Len(t, wallets, 1)
+
+	// The freeze is reported with who did it and why
+	var record outbox.Record
+	err = db.Where("event_type = ?", "wallet.frozen").First(&record).Error
+	assert.NoError(t, err)
+	assert.Contains(t, record.Envelope, "ops@example.com")
+}
+
+func TestWalletRepository_BeginDelete(t *testing.T) {
+	db, teardown := setupTestDB(t)
+	defer teardown()
+
+	repo := NewWalletRepository(db)
+	assert.NoError(t, repo.CreateWallet(&Wallet{Network: "Ethereum", Address: "0x123"}))
+
+	assert.NoError(t, repo.BeginDelete("Ethereum", "0x123"))
+	assert.ErrorIs(t, repo.BeginDelete("Ethereum", "0x123"), ErrDeleteInProgress)
+	assert.ErrorIs(t, repo.BeginDelete("Ethereum", "0x456"), ErrWalletNotFound)
+
+	wallet, err := repo.GetWallet("Ethereum", "0x123")
+	assert.NoError(t, err)
+	assert.NotNil(t, wallet.DeletingSince)
+	// The status is kept for the archive
+	assert.Equal(t, WalletStatusActive, wallet.Status)
+
+	// A deletion that did not finish in time is taken over
+	assert.NoError(t, db.Exec(`UPDATE wallets SET deleting_since = CURRENT_TIMESTAMP - INTERVAL '1 hour'`).Error)
+	assert.NoError(t, repo.BeginDelete("Ethereum", "0x123"))
+
+	assert.NoError(t, repo.CancelDelete("Ethereum", "0x123"))
+	wallet, err = repo.GetWallet("Ethereum", "0x123")
+	assert.NoError(t, err)
+	assert.Nil(t, wallet.DeletingSince)
+}
+
+func TestWalletRepository_RestoreWallet(t *testing.T) {
+	db, teardown := setupTestDB(t)
+	defer teardown()
+
+	repo := NewWalletRepository(db)
+
+	wallet := &Wallet{Network: "Ethereum", Address: "0x123", Label: "Treasury hot wallet", Tags: []string{"treasury"}, Status: WalletStatusActive}
+	assert.NoError(t, repo.CreateWallet(wallet))
+	_, err := repo.SetStatus("Ethereum", "0x123", WalletStatusFrozen, StatusChange{Reason: "Suspected key compromise", Actor: "ops@example.com"})
+	assert.NoError(t, err)
+	assert.NoError(t, repo.DeleteWallet("Ethereum", "0x123", "Created by mistake"))
+
+	deleted, err := repo.ListDeletedWallets(DeletedWalletFilter{Limit: 10})
+	assert.NoError(t, err)
+	if !assert.Len(t, deleted, 1) {
+		return
+	}
+	assert.Equal(t, wallet.ID, deleted[0].WalletID)
+	assert.Equal(t, "Created by mistake", deleted[0].Reason)
+	assert.False(t, deleted[0].DeletedAt.IsZero())
+
+	// The wallet comes back as it was, still frozen
+	restored, err := repo.RestoreWallet(deleted[0].ID)
+	assert.NoError(t, err)
+	assert.Equal(t, wallet.ID, restored.ID)
+	assert.Equal(t, "Treasury hot wallet", restored.Label)
+	assert.Equal(t, WalletStatusFrozen, restored.Status)
+	assert.Equal(t, "ops@example.com", restored.StatusChangedBy)
+
+	deleted, err = repo.ListDeletedWallets(DeletedWalletFilter{Limit: 10})
+	assert.NoError(t, err)
+	assert.Empty(t, deleted)
+
+	// A wallet deleted again is archived again, and cannot be restored once its address is taken
+	assert.NoError(t, repo.DeleteWallet("Ethereum", "0x123", ""))
+	assert.NoError(t, repo.CreateWallet(&Wallet{Network: "Ethereum", Address: "0x123"}))
+	deleted, err = repo.ListDeletedWallets(DeletedWalletFilter{Network: "Ethereum", Address: "0x123", Limit: 10})
+	assert.NoError(t, err)
+	if assert.Len(t, deleted, 1) {
+		_, err = repo.RestoreWallet(deleted[0].ID)
+		assert.ErrorIs(t, err, ErrWalletExists)
+	}
+
+	_, err = repo.RestoreWallet(999)
+	assert.ErrorIs(t, err, ErrWalletNotFound)
+}
+
+func TestWalletRepository_ConcurrentCreate(t *testing.T) {
+	db, teardown := setupTestDB(t)
+	defer teardown()
+
+	repo := NewWalletRepository(db)
+
+	// Every creation races for the same network and address; the unique index lets one through
+	results := make(chan error, 10)
+	for i := 0; i < cap(results); i++ {
+		go func() {
+			results <- repo.CreateWallet(&Wallet{Network: "Ethereum", Address: "0x123"})
+		}()
+	}
+
+	created := 0
+	for i := 0; i < cap(results); i++ {
+		err := <-results
+		if err == nil {
+			created++
+			continue
+		}
+		assert.ErrorIs(t, err, ErrWalletExists)
+	}
+	assert.Equal(t, 1, created)
+
+	var count int64
+	assert.NoError(t, db.Model(&Wallet{}).Where("network = ? AND address = ?", "Ethereum", "0x123").Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestWalletRepository_MigrateArchivesDuplicates(t *testing.T) {
+	db, teardown := startTestDB(t)
+	defer teardown()
+
+	// A table from before the unique index, with duplicates left by concurrent creations
+	assert.NoError(t, db.Exec(`
+		CREATE TABLE wallets (id BIGSERIAL PRIMARY KEY, network TEXT NOT NULL, address TEXT NOT NULL, created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP);
+		INSERT INTO wallets (network, address) VALUES ('Ethereum', '0x123'), ('Ethereum', '0x123'), ('Bitcoin', '1abc'), ('Ethereum', '0x123')`).Error)
+
+	assert.NoError(t, Migrate(db, network.Default()))
+
+	var wallets []Wallet
+	assert.NoError(t, db.Order("id").Find(&wallets).Error)
+	if assert.Len(t, wallets, 2) {
+		// The oldest of the duplicates is kept
+		assert.Equal(t, uint(1), wallets[0].ID)
+		assert.Equal(t, "1abc", wallets[1].Address)
+	}
+
+	var archived []WalletDeleted
+	assert.NoError(t, db.Order("wallet_id").Find(&archived).Error)
+	if assert.Len(t, archived, 2) {
+		assert.Equal(t, uint(2), archived[0].WalletID)
+		assert.Equal(t, uint(4), archived[1].WalletID)
+		assert.Contains(t, archived[0].Reason, "Duplicate")
+	}
+
+	// The index is in place
+	err := NewWalletRepository(db).CreateWallet(&Wallet{Network: "Ethereum", Address: "0x123"})
+	assert.ErrorIs(t, err, ErrWalletExists)
+}
+
+func TestWalletRepository_MigrateCanonicalizesWallets(t *testing.T) {
+	db, teardown := setupTestDB(t)
+	defer teardown()
+
+	// Wallets and a deleted wallet stored before networks and addresses were validated
+	assert.NoError(t, db.Exec(`
+		INSERT INTO wallets (network, address, label) VALUES
+			('ETH', '0x5AAEB6053F3E94C9B9A09F33669435E7EF1BEAED', 'legacy'),
+			('Ethereum', '0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed', 'canonical'),
+			('sol', 'not-an-address', 'invalid');
+		INSERT INTO wallet_deleteds (network, address, wallet_id) VALUES ('eth', '0xFB6916095CA1DF60BB79CE92CE3EA74C37C5D359', 9)`).Error)
+
+	assert.NoError(t, Migrate(db, network.Default()))
+
+	var wallets []Wallet
+	assert.NoError(t, db.Order("id").Find(&wallets).Error)
+	if assert.Len(t, wallets, 2) {
+		// The oldest spelling keeps the wallet, now in canonical form
+		assert.Equal(t, "legacy", wallets[0].Label)
+		assert.Equal(t, "Ethereum", wallets[0].Network)
+		assert.Equal(t, "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", wallets[0].Address)
+		// Pairs that do not validate are left as they are
+		assert.Equal(t, "sol", wallets[1].Network)
+		assert.Equal(t, "not-an-address", wallets[1].Address)
+	}
+
+	var archived []WalletDeleted
+	assert.NoError(t, db.Order("id").Find(&archived).Error)
+	if assert.Len(t, archived, 2) {
+		assert.Equal(t, "Ethereum", archived[0].Network)
+		assert.Equal(t, "0xfb6916095ca1df60bb79ce92ce3ea74c37c5d359", archived[0].Address)
+		assert.Equal(t, "canonical", archived[1].Label)
+		assert.Contains(t, archived[1].Reason, "Duplicate")
+	}
 }
@@ -1,26 +1,235 @@
 package wallet
 
+import (
+	"asset-management/internal/network"
+	"asset-management/services/wallet-api/asset"
+	"fmt"
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+	"strings"
+	"time"
+)
+
 type walletService struct {
-	repo WalletRepository
+	repo     WalletRepository
+	assets   asset.HoldingsAdapter
+	networks *network.Registry
 }
 type WalletService interface {
 	CreateWallet(wallet *Wallet) error
 	GetWallet(network, address string) (*Wallet, error)
-	DeleteWallet(network, address string) error
+	ListWallets(filter WalletFilter) (*WalletPage, error)
+	DeleteWallet(network, address string, options DeleteOptions) error
+	ListDeletedWallets(filter DeletedWalletFilter) (*DeletedWalletPage, error)
+	RestoreWallet(id uint) (*Wallet, error)
+	UpdateWallet(network, address string, update WalletUpdate) (*Wallet, error)
+	FreezeWallet(network, address string, change StatusChange) (*Wallet, error)
+	UnfreezeWallet(network, address string, change StatusChange) (*Wallet, error)
 }
 
-func NewWalletService(repo WalletRepository) WalletService {
-	return &walletService{repo: repo}
+func NewWalletService(repo WalletRepository, assets asset.HoldingsAdapter, networks *network.Registry) WalletService {
+	return &walletService{repo: repo, assets: assets, networks: networks}
 }
 
+// CreateWallet stores a wallet under the canonical form of its network and address, which must be
+// valid for one of the registered networks.
 func (s *walletService) CreateWallet(wallet *Wallet) error {
+	networkName, address, err := s.networks.Normalize(wallet.Network, wallet.Address)
+	if err != nil {
+		return err
+	}
+	wallet.Network, wallet.Address = networkName, address
+
+	// New wallets start active; timestamps are set by the database
+	if wallet.Status == "" {
+		wallet.Status = WalletStatusActive
+	}
+	if !validStatus(wallet.Status) {
+		return ErrInvalidStatus
+	}
+	wallet.Tags = normalizeTags(wallet.Tags)
+	wallet.CreatedAt, wallet.UpdatedAt = time.Time{}, time.Time{}
+
 	return s.repo.CreateWallet(wallet)
 }
 
-func (s *walletService) DeleteWallet(network, address string) error {
-	return s.repo.DeleteWallet(network, address)
+// UpdateWallet changes the mutable fields of a wallet. Tags are trimmed and deduplicated.
+func (s *walletService) UpdateWallet(network, address string, update WalletUpdate) (*Wallet, error) {
+	if update.OwnerID == nil && update.Label == nil && update.Tags == nil {
+		return nil, ErrNothingToUpdate
+	}
+	if update.Tags != nil {
+		tags := []string(normalizeTags(*update.Tags))
+		update.Tags = &tags
+	}
+
+	network, address = s.networks.Canonical(network, address)
+	return s.repo.UpdateWallet(network, address, update)
+}
+
+// FreezeWallet stops funds from leaving the wallet until it is unfrozen. Funds can still be received.
+func (s *walletService) FreezeWallet(network, address string, change StatusChange) (*Wallet, error) {
+	return s.setStatus(network, address, WalletStatusFrozen, change)
+}
+
+func (s *walletService) UnfreezeWallet(network, address string, change StatusChange) (*Wallet, error) {
+	return s.setStatus(network, address, WalletStatusActive, change)
+}
+
+func (s *walletService) setStatus(network, address, status string, change StatusChange) (*Wallet, error) {
+	change.Reason, change.Actor = strings.TrimSpace(change.Reason), strings.TrimSpace(change.Actor)
+	if change.Reason == "" || change.Actor == "" {
+		return nil, ErrReasonRequired
+	}
+	network, address = s.networks.Canonical(network, address)
+	return s.repo.SetStatus(network, address, status, change)
+}
+
+func validStatus(status string) bool {
+	return status == WalletStatusActive || status == WalletStatusFrozen
+}
+
+// normalizeTags trims the tags and drops empty and repeated ones, keeping their order.
+func normalizeTags(tags []string) pq.StringArray {
+	normalized := pq.StringArray{}
+	seen := map[string]bool{}
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+// DeleteWallet deletes a wallet only once asset-api holds nothing for it anymore. The wallet is marked
+// as being deleted first, so that no funds or scheduled transactions reach it between the check of its
+// holdings and its deletion. With options.Force, its pending scheduled transactions are cancelled and
+// its funds swept to options.SweepTo, after which its holdings are checked again. If asset-api cannot
+// be asked, or the wallet is not deleted for another reason, the mark is removed.
+func (s *walletService) DeleteWallet(network, address string, options DeleteOptions) error {
+	network, address = s.networks.Canonical(network, address)
+	if err := s.repo.BeginDelete(network, address); err != nil {
+		return err
+	}
+
+	if err := s.settleAndDelete(network, address, options); err != nil {
+		if cancelErr := s.repo.CancelDelete(network, address); cancelErr != nil {
+			log.Error().Err(cancelErr).Str("network", network).Str("address", address).
+				Msg("Failed to unmark a wallet whose deletion did not complete")
+		}
+		return err
+	}
+	return nil
+}
+
+// settleAndDelete deletes a wallet marked as being deleted once it holds nothing, sweeping it first if
+// options.Force is set.
+func (s *walletService) settleAndDelete(network, address string, options DeleteOptions) error {
+	holdings, err := s.assets.Holdings(address, network)
+	if err != nil {
+		return err
+	}
+	if !holdings.Empty() {
+		if !options.Force {
+			return fmt.Errorf("%w: it %s", ErrWalletNotEmpty, holdings)
+		}
+		if len(holdings.Balances) > 0 && options.SweepTo == "" {
+			return ErrSweepTargetRequired
+		}
+		if err := s.assets.Sweep(address, network, options.SweepTo); err != nil {
+			return err
+		}
+
+		// Catches funds that were on their way when the wallet was marked
+		if holdings, err = s.assets.Holdings(address, network); err != nil {
+			return err
+		}
+		if !holdings.Empty() {
+			return fmt.Errorf("%w: after the sweep it %s", ErrWalletNotEmpty, holdings)
+		}
+	}
+
+	return s.repo.DeleteWallet(network, address, strings.TrimSpace(options.Reason))
+}
+
+// ListDeletedWallets returns a page of deleted wallets. A zero Limit defaults to DefaultPageSize.
+func (s *walletService) ListDeletedWallets(filter DeletedWalletFilter) (*DeletedWalletPage, error) {
+	if filter.Limit == 0 {
+		filter.Limit = DefaultPageSize
+	}
+	if filter.Limit < 0 || filter.Limit > MaxPageSize {
+		return nil, ErrInvalidPageSize
+	}
+	if filter.Address != "" {
+		filter.Network, filter.Address = s.networks.Canonical(filter.Network, filter.Address)
+	} else {
+		filter.Network = s.networks.CanonicalName(filter.Network)
+	}
+
+	// Fetch one more wallet than requested to learn whether there is a next page
+	pageSize := filter.Limit
+	filter.Limit++
+	wallets, err := s.repo.ListDeletedWallets(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &DeletedWalletPage{Wallets: wallets}
+	if len(wallets) > pageSize {
+		page.Wallets = wallets[:pageSize]
+		last := page.Wallets[pageSize-1]
+		page.NextCursor = Cursor{CreatedAt: last.DeletedAt, ID: last.ID}.Encode()
+	}
+	if page.Wallets == nil {
+		page.Wallets = []WalletDeleted{}
+	}
+	return page, nil
+}
+
+func (s *walletService) RestoreWallet(id uint) (*Wallet, error) {
+	return s.repo.RestoreWallet(id)
 }
 
 func (s *walletService) GetWallet(network, address string) (*Wallet, error) {
+	network, address = s.networks.Canonical(network, address)
 	return s.repo.GetWallet(network, address)
 }
+
+// ListWallets returns a page of wallets. A zero Limit and an empty Sort default to DefaultPageSize and SortDesc.
+func (s *walletService) ListWallets(filter WalletFilter) (*WalletPage, error) {
+	if filter.Sort == "" {
+		filter.Sort = SortDesc
+	}
+	if filter.Sort != SortAsc && filter.Sort != SortDesc {
+		return nil, ErrInvalidSort
+	}
+	if filter.Limit == 0 {
+		filter.Limit = DefaultPageSize
+	}
+	if filter.Limit < 0 || filter.Limit > MaxPageSize {
+		return nil, ErrInvalidPageSize
+	}
+	filter.Network = s.networks.CanonicalName(filter.Network)
+
+	// Fetch one more wallet than requested to learn whether there is a next page
+	pageSize := filter.Limit
+	filter.Limit++
+	wallets, err := s.repo.ListWallets(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &WalletPage{Wallets: wallets}
+	if len(wallets) > pageSize {
+		page.Wallets = wallets[:pageSize]
+		last := page.Wallets[pageSize-1]
+		page.NextCursor = Cursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+	}
+	if page.Wallets == nil {
+		page.Wallets = []Wallet{}
+	}
+	return page, nil
+}
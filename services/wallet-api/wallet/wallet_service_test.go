@@ -1,10 +1,14 @@
 package wallet
 
 import (
+	"asset-management/internal/network"
+	"asset-management/services/wallet-api/asset"
 	"errors"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"testing"
+	"time"
 )
 
 // MockWalletRepository is a mock implementation of the WalletRepository interface
@@ -17,16 +21,45 @@ func (m *MockWalletRepository) CreateWallet(wallet *Wallet) error {
 	return args.Error(0)
 }
 
-func (m *MockWalletRepository) GetWallets() ([]Wallet, error) {
-	args := m.Called()
-	return args.Get(0).([]Wallet), args.Error(1)
+func (m *MockWalletRepository) ListWallets(filter WalletFilter) ([]Wallet, error) {
+	args := m.Called(filter)
+	if wallets, ok := args.Get(0).([]Wallet); ok {
+		return wallets, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockWalletRepository) BeginDelete(network, address string) error {
+	args := m.Called(network, address)
+	return args.Error(0)
 }
 
-func (m *MockWalletRepository) DeleteWallet(network, address string) error {
+func (m *MockWalletRepository) CancelDelete(network, address string) error {
 	args := m.Called(network, address)
 	return args.Error(0)
 }
 
+func (m *MockWalletRepository) DeleteWallet(network, address, reason string) error {
+	args := m.Called(network, address, reason)
+	return args.Error(0)
+}
+
+func (m *MockWalletRepository) ListDeletedWallets(filter DeletedWalletFilter) ([]WalletDeleted, error) {
+	args := m.Called(filter)
+	if wallets, ok := args.Get(0).([]WalletDeleted); ok {
+		return wallets, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockWalletRepository) RestoreWallet(id uint) (*Wallet, error) {
+	args := m.Called(id)
+	if wallet, ok := args.Get(0).(*Wallet); ok {
+		return wallet, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
 func (m *MockWalletRepository) GetWallet(network, address string) (*Wallet, error) {
 	args := m.Called(network, address)
 	if wallet, ok := args.Get(0).(*Wallet); ok {
@@ -35,11 +68,44 @@ func (m *MockWalletRepository) GetWallet(network, address string) (*Wallet, erro
 	return nil, args.Error(1)
 }
 
+func (m *MockWalletRepository) UpdateWallet(network, address string, update WalletUpdate) (*Wallet, error) {
+	args := m.Called(network, address, update)
+	if wallet, ok := args.Get(0).(*Wallet); ok {
+		return wallet, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockWalletRepository) SetStatus(network, address, status string, change StatusChange) (*Wallet, error) {
+	args := m.Called(network, address, status, change)
+	if wallet, ok := args.Get(0).(*Wallet); ok {
+		return wallet, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+type MockHoldingsAdapter struct {
+	mock.Mock
+}
+
+func (m *MockHoldingsAdapter) Holdings(walletAddress, network string) (*asset.Holdings, error) {
+	args := m.Called(walletAddress, network)
+	if holdings, ok := args.Get(0).(*asset.Holdings); ok {
+		return holdings, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockHoldingsAdapter) Sweep(walletAddress, network, sweepTo string) error {
+	args := m.Called(walletAddress, network, sweepTo)
+	return args.Error(0)
+}
+
 func TestServiceCreateWallet(t *testing.T) {
 	mockRepo := new(MockWalletRepository)
-	service := NewWalletService(mockRepo)
+	service := NewWalletService(mockRepo, new(MockHoldingsAdapter), network.Default())
 
-	wallet := &Wallet{ /* initialize your wallet */ }
+	wallet := &Wallet{Network: "Ethereum", Address: "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"}
 
 	// Set up expectation
 	mockRepo.On("CreateWallet", wallet).Return(nil)
@@ -54,7 +120,7 @@ func TestServiceCreateWallet(t *testing.T) {
 
 func TestServiceGetWallet(t *testing.T) {
 	mockRepo := new(MockWalletRepository)
-	service := NewWalletService(mockRepo)
+	service := NewWalletService(mockRepo, new(MockHoldingsAdapter), network.Default())
 
 	network := "test-network"
 	address := "test-address"
@@ -74,16 +140,19 @@ func TestServiceGetWallet(t *testing.T) {
 
 func TestServiceDeleteWallet(t *testing.T) {
 	mockRepo := new(MockWalletRepository)
-	service := NewWalletService(mockRepo)
+	mockAssets := new(MockHoldingsAdapter)
+	service := NewWalletService(mockRepo, mockAssets, network.Default())
 
 	network := "test-network"
 	address := "test-address"
 
 	// Set up expectation
-	mockRepo.On("DeleteWallet", network, address).Return(nil)
+	mockRepo.On("BeginDelete", network, address).Return(nil)
+	mockAssets.On("Holdings", address, network).Return(&asset.Holdings{}, nil)
+	mockRepo.On("DeleteWallet", network, address, "Created by mistake").Return(nil)
 
 	// Call the method
-	err := service.DeleteWallet(network, address)
+	err := service.DeleteWallet(network, address, DeleteOptions{Reason: " Created by mistake "})
 
 	// Assert
 	assert.NoError(t, err)
@@ -92,9 +161,9 @@ func TestServiceDeleteWallet(t *testing.T) {
 
 func TestCreateWallet_Failure(t *testing.T) {
 	mockRepo := new(MockWalletRepository)
-	service := NewWalletService(mockRepo)
+	service := NewWalletService(mockRepo, new(MockHoldingsAdapter), network.Default())
 
-	wallet := &Wallet{ /* initialize your wallet */ }
+	wallet := &Wallet{Network: "Ethereum", Address: "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"}
 
 	// Set up expectation with an error
 	mockRepo.On("CreateWallet", wallet).Return(errors.New("database error"))
@@ -110,7 +179,7 @@ func TestCreateWallet_Failure(t *testing.T) {
 
 func TestGetWallet_Failure(t *testing.T) {
 	mockRepo := new(MockWalletRepository)
-	service := NewWalletService(mockRepo)
+	service := NewWalletService(mockRepo, new(MockHoldingsAdapter), network.Default())
 
 	network := "test-network"
 	address := "test-address"
@@ -130,19 +199,321 @@ func TestGetWallet_Failure(t *testing.T) {
 
 func TestDeleteWallet_Failure(t *testing.T) {
 	mockRepo := new(MockWalletRepository)
-	service := NewWalletService(mockRepo)
+	mockAssets := new(MockHoldingsAdapter)
+	service := NewWalletService(mockRepo, mockAssets, network.Default())
 
 	network := "test-network"
 	address := "test-address"
 
 	// Set up expectation with an error
-	mockRepo.On("DeleteWallet", network, address).Return(errors.New("delete failed"))
+	mockRepo.On("BeginDelete", network, address).Return(nil)
+	mockAssets.On("Holdings", address, network).Return(&asset.Holdings{}, nil)
+	mockRepo.On("DeleteWallet", network, address, "").Return(errors.New("delete failed"))
+	mockRepo.On("CancelDelete", network, address).Return(nil)
 
 	// Call the method
-	err := service.DeleteWallet(network, address)
+	err := service.DeleteWallet(network, address, DeleteOptions{})
 
 	// Assert
 	assert.Error(t, err)
 	assert.Equal(t, "delete failed", err.Error())
 	mockRepo.AssertExpectations(t)
 }
+
+func TestServiceListWallets(t *testing.T) {
+	createdAt := time.Date(2024, 10, 29, 10, 15, 0, 0, time.UTC)
+	wallets := []Wallet{
+		{ID: 3, Network: "Ethereum", Address: "0x3", CreatedAt: createdAt.Add(2 * time.Minute)},
+		{ID: 2, Network: "Ethereum", Address: "0x2", CreatedAt: createdAt.Add(time.Minute)},
+		{ID: 1, Network: "Ethereum", Address: "0x1", CreatedAt: createdAt},
+	}
+
+	t.Run("next page", func(t *testing.T) {
+		mockRepo := new(MockWalletRepository)
+		service := NewWalletService(mockRepo, new(MockHoldingsAdapter), network.Default())
+
+		// One more wallet than requested is fetched to detect the next page
+		mockRepo.On("ListWallets", WalletFilter{Network: "Ethereum", Sort: SortDesc, Limit: 3}).Return(wallets, nil)
+
+		page, err := service.ListWallets(WalletFilter{Network: "Ethereum", Limit: 2})
+
+		assert.NoError(t, err)
+		assert.Equal(t, wallets[:2], page.Wallets)
+		cursor, err := DecodeCursor(page.NextCursor)
+		assert.NoError(t, err)
+		assert.Equal(t, uint(2), cursor.ID)
+		assert.True(t, wallets[1].CreatedAt.Equal(cursor.CreatedAt))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("last page", func(t *testing.T) {
+		mockRepo := new(MockWalletRepository)
+		service := NewWalletService(mockRepo, new(MockHoldingsAdapter), network.Default())
+
+		mockRepo.On("ListWallets", WalletFilter{Sort: SortAsc, Limit: DefaultPageSize + 1}).Return(wallets, nil)
+
+		page, err := service.ListWallets(WalletFilter{Sort: SortAsc})
+
+		assert.NoError(t, err)
+		assert.Len(t, page.Wallets, 3)
+		assert.Empty(t, page.NextCursor)
+	})
+
+	t.Run("invalid filter", func(t *testing.T) {
+		service := NewWalletService(new(MockWalletRepository), new(MockHoldingsAdapter), network.Default())
+
+		_, err := service.ListWallets(WalletFilter{Sort: "newest"})
+		assert.ErrorIs(t, err, ErrInvalidSort)
+
+		_, err = service.ListWallets(WalletFilter{Limit: MaxPageSize + 1})
+		assert.ErrorIs(t, err, ErrInvalidPageSize)
+	})
+}
+
+func TestServiceCreateWallet_Defaults(t *testing.T) {
+	mockRepo := new(MockWalletRepository)
+	service := NewWalletService(mockRepo, new(MockHoldingsAdapter), network.Default())
+
+	wallet := &Wallet{Network: "Ethereum", Address: "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", Tags: []string{" hot ", "hot", ""}}
+	mockRepo.On("CreateWallet", wallet).Return(nil)
+
+	err := service.CreateWallet(wallet)
+
+	assert.NoError(t, err)
+	assert.Equal(t, WalletStatusActive, wallet.Status)
+	assert.Equal(t, []string{"hot"}, []string(wallet.Tags))
+
+	err = service.CreateWallet(&Wallet{Network: "Ethereum", Address: "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", Status: "closed"})
+	assert.ErrorIs(t, err, ErrInvalidStatus)
+}
+
+func TestServiceCreateWallet_Address(t *testing.T) {
+	t.Run("normalizes network and address", func(t *testing.T) {
+		mockRepo := new(MockWalletRepository)
+		service := NewWalletService(mockRepo, new(MockHoldingsAdapter), network.Default())
+
+		wallet := &Wallet{Network: "eth", Address: " 0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed "}
+		mockRepo.On("CreateWallet", wallet).Return(nil)
+
+		err := service.CreateWallet(wallet)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Ethereum", wallet.Network)
+		assert.Equal(t, "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", wallet.Address)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects invalid wallets", func(t *testing.T) {
+		service := NewWalletService(new(MockWalletRepository), new(MockHoldingsAdapter), network.Default())
+
+		err := service.CreateWallet(&Wallet{})
+		assert.ErrorIs(t, err, network.ErrUnsupportedNetwork)
+
+		err = service.CreateWallet(&Wallet{Network: "Dogecoin", Address: "DH5yaieqoZN36fDVciNyRueRGvGLR3mr7L"})
+		assert.ErrorIs(t, err, network.ErrUnsupportedNetwork)
+
+		// One character off the checksummed address
+		err = service.CreateWallet(&Wallet{Network: "Ethereum", Address: "0x5aaeb6053F3E94C9b9A09f33669435E7Ef1BeAed"})
+		assert.ErrorIs(t, err, network.ErrInvalidAddress)
+	})
+}
+
+func TestServiceGetWallet_CanonicalLookup(t *testing.T) {
+	mockRepo := new(MockWalletRepository)
+	service := NewWalletService(mockRepo, new(MockHoldingsAdapter), network.Default())
+
+	wallet := &Wallet{Network: "Ethereum", Address: "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"}
+	mockRepo.On("GetWallet", "Ethereum", "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed").Return(wallet, nil)
+
+	result, err := service.GetWallet("ETH", "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed")
+
+	assert.NoError(t, err)
+	assert.Equal(t, wallet, result)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestServiceUpdateWallet(t *testing.T) {
+	t.Run("normalizes tags", func(t *testing.T) {
+		mockRepo := new(MockWalletRepository)
+		service := NewWalletService(mockRepo, new(MockHoldingsAdapter), network.Default())
+
+		tags, normalized := []string{"eu", " eu", "hot"}, []string{"eu", "hot"}
+		updated := &Wallet{Tags: normalized}
+		mockRepo.On("UpdateWallet", "Ethereum", "0x123", WalletUpdate{Tags: &normalized}).Return(updated, nil)
+
+		wallet, err := service.UpdateWallet("Ethereum", "0x123", WalletUpdate{Tags: &tags})
+
+		assert.NoError(t, err)
+		assert.Equal(t, updated, wallet)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("invalid update", func(t *testing.T) {
+		service := NewWalletService(new(MockWalletRepository), new(MockHoldingsAdapter), network.Default())
+
+		_, err := service.UpdateWallet("Ethereum", "0x123", WalletUpdate{})
+		assert.ErrorIs(t, err, ErrNothingToUpdate)
+	})
+}
+
+func TestServiceFreezeWallet(t *testing.T) {
+	mockRepo := new(MockWalletRepository)
+	service := NewWalletService(mockRepo, new(MockHoldingsAdapter), network.Default())
+
+	change := StatusChange{Reason: "Suspected key compromise", Actor: "ops@example.com"}
+	mockRepo.On("SetStatus", "Ethereum", "0x123", WalletStatusFrozen, change).Return(&Wallet{Status: WalletStatusFrozen}, nil)
+	mockRepo.On("SetStatus", "Ethereum", "0x123", WalletStatusActive, change).Return(&Wallet{Status: WalletStatusActive}, nil)
+
+	// Surrounding whitespace is not part of the reason
+	wallet, err := service.FreezeWallet("Ethereum", "0x123", StatusChange{Reason: " Suspected key compromise ", Actor: "ops@example.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, WalletStatusFrozen, wallet.Status)
+
+	wallet, err = service.UnfreezeWallet("Ethereum", "0x123", change)
+	assert.NoError(t, err)
+	assert.Equal(t, WalletStatusActive, wallet.Status)
+
+	_, err = service.FreezeWallet("Ethereum", "0x123", StatusChange{Reason: "  ", Actor: "ops@example.com"})
+	assert.ErrorIs(t, err, ErrReasonRequired)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestServiceListDeletedWallets(t *testing.T) {
+	mockRepo := new(MockWalletRepository)
+	service := NewWalletService(mockRepo, new(MockHoldingsAdapter), network.Default())
+
+	deletedAt := time.Date(2024, 10, 29, 10, 15, 0, 0, time.UTC)
+	wallets := []WalletDeleted{
+		{ID: 3, Address: "0x3", DeletedAt: deletedAt},
+		{ID: 2, Address: "0x2", DeletedAt: deletedAt},
+		{ID: 1, Address: "0x1", DeletedAt: deletedAt},
+	}
+	mockRepo.On("ListDeletedWallets", DeletedWalletFilter{Network: "Ethereum", Limit: 3}).Return(wallets, nil)
+
+	page, err := service.ListDeletedWallets(DeletedWalletFilter{Network: "Ethereum", Limit: 2})
+
+	assert.NoError(t, err)
+	assert.Len(t, page.Wallets, 2)
+	cursor, err := DecodeCursor(page.NextCursor)
+	assert.NoError(t, err)
+	assert.Equal(t, Cursor{CreatedAt: deletedAt, ID: 2}, *cursor)
+
+	_, err = service.ListDeletedWallets(DeletedWalletFilter{Limit: MaxPageSize + 1})
+	assert.ErrorIs(t, err, ErrInvalidPageSize)
+}
+
+func TestServiceDeleteWallet_Holdings(t *testing.T) {
+	funded := &asset.Holdings{
+		Balances:            []asset.Balance{{Asset: "ETH", Amount: decimal.RequireFromString("1.5")}},
+		PendingTransactions: []int{12},
+	}
+
+	setup := func() (*MockWalletRepository, *MockHoldingsAdapter, WalletService) {
+		mockRepo := new(MockWalletRepository)
+		mockAssets := new(MockHoldingsAdapter)
+		mockRepo.On("BeginDelete", "Ethereum", "0x123").Return(nil)
+		mockRepo.On("CancelDelete", "Ethereum", "0x123").Return(nil)
+		mockAssets.On("Holdings", "0x123", "Ethereum").Return(funded, nil).Once()
+		return mockRepo, mockAssets, NewWalletService(mockRepo, mockAssets, network.Default())
+	}
+
+	t.Run("refused without force", func(t *testing.T) {
+		mockRepo, _, service := setup()
+
+		err := service.DeleteWallet("Ethereum", "0x123", DeleteOptions{})
+
+		assert.ErrorIs(t, err, ErrWalletNotEmpty)
+		assert.EqualError(t, err, "wallet cannot be deleted: it holds 1.5 ETH and has 1 pending scheduled transactions")
+		mockRepo.AssertNotCalled(t, "DeleteWallet", "Ethereum", "0x123", "")
+		// Funds may reach the wallet again
+		mockRepo.AssertCalled(t, "CancelDelete", "Ethereum", "0x123")
+	})
+
+	t.Run("force requires a sweep target", func(t *testing.T) {
+		_, mockAssets, service := setup()
+
+		err := service.DeleteWallet("Ethereum", "0x123", DeleteOptions{Force: true})
+
+		assert.ErrorIs(t, err, ErrSweepTargetRequired)
+		mockAssets.AssertNotCalled(t, "Sweep", "0x123", "Ethereum", "")
+	})
+
+	t.Run("force sweeps first", func(t *testing.T) {
+		mockRepo, mockAssets, service := setup()
+		mockAssets.On("Sweep", "0x123", "Ethereum", "0xsafe").Return(nil)
+		mockAssets.On("Holdings", "0x123", "Ethereum").Return(&asset.Holdings{}, nil).Once()
+		mockRepo.On("DeleteWallet", "Ethereum", "0x123", "Compromised").Return(nil)
+
+		err := service.DeleteWallet("Ethereum", "0x123", DeleteOptions{Reason: "Compromised", Force: true, SweepTo: "0xsafe"})
+
+		assert.NoError(t, err)
+		mockAssets.AssertExpectations(t)
+		mockRepo.AssertCalled(t, "DeleteWallet", "Ethereum", "0x123", "Compromised")
+		mockRepo.AssertNotCalled(t, "CancelDelete", "Ethereum", "0x123")
+	})
+
+	t.Run("funds left after the sweep keep the wallet", func(t *testing.T) {
+		mockRepo, mockAssets, service := setup()
+		mockAssets.On("Sweep", "0x123", "Ethereum", "0xsafe").Return(nil)
+		mockAssets.On("Holdings", "0x123", "Ethereum").Return(&asset.Holdings{PendingTransactions: []int{14}}, nil).Once()
+
+		err := service.DeleteWallet("Ethereum", "0x123", DeleteOptions{Force: true, SweepTo: "0xsafe"})
+
+		assert.ErrorIs(t, err, ErrWalletNotEmpty)
+		assert.EqualError(t, err, "wallet cannot be deleted: after the sweep it holds no funds and has 1 pending scheduled transactions")
+		mockRepo.AssertNotCalled(t, "DeleteWallet", "Ethereum", "0x123", "")
+		mockRepo.AssertCalled(t, "CancelDelete", "Ethereum", "0x123")
+	})
+
+	t.Run("failed sweep keeps the wallet", func(t *testing.T) {
+		mockRepo, mockAssets, service := setup()
+		mockAssets.On("Sweep", "0x123", "Ethereum", "0xsafe").Return(asset.ErrUnavailable)
+
+		err := service.DeleteWallet("Ethereum", "0x123", DeleteOptions{Force: true, SweepTo: "0xsafe"})
+
+		assert.ErrorIs(t, err, asset.ErrUnavailable)
+		mockRepo.AssertNotCalled(t, "DeleteWallet", "Ethereum", "0x123", "")
+	})
+
+	t.Run("asset service unavailable", func(t *testing.T) {
+		mockRepo := new(MockWalletRepository)
+		mockAssets := new(MockHoldingsAdapter)
+		mockRepo.On("BeginDelete", "Ethereum", "0x123").Return(nil)
+		mockRepo.On("CancelDelete", "Ethereum", "0x123").Return(nil)
+		mockAssets.On("Holdings", "0x123", "Ethereum").Return(nil, asset.ErrUnavailable)
+
+		err := NewWalletService(mockRepo, mockAssets, network.Default()).DeleteWallet("Ethereum", "0x123", DeleteOptions{})
+
+		assert.ErrorIs(t, err, asset.ErrUnavailable)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("already being deleted", func(t *testing.T) {
+		mockRepo := new(MockWalletRepository)
+		mockAssets := new(MockHoldingsAdapter)
+		mockRepo.On("BeginDelete", "Ethereum", "0x123").Return(ErrDeleteInProgress)
+
+		err := NewWalletService(mockRepo, mockAssets, network.Default()).DeleteWallet("Ethereum", "0x123", DeleteOptions{})
+
+		assert.ErrorIs(t, err, ErrDeleteInProgress)
+		mockAssets.AssertNotCalled(t, "Holdings", "0x123", "Ethereum")
+		mockRepo.AssertNotCalled(t, "CancelDelete", "Ethereum", "0x123")
+	})
+
+	t.Run("canonical identity", func(t *testing.T) {
+		mockRepo := new(MockWalletRepository)
+		mockAssets := new(MockHoldingsAdapter)
+		canonical := "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"
+		mockRepo.On("BeginDelete", "Ethereum", canonical).Return(nil)
+		mockAssets.On("Holdings", canonical, "Ethereum").Return(&asset.Holdings{}, nil)
+		mockRepo.On("DeleteWallet", "Ethereum", canonical, "").Return(nil)
+
+		// asset-api is asked about the wallet however the caller spelled it
+		err := NewWalletService(mockRepo, mockAssets, network.Default()).
+			DeleteWallet("eth", "0x5AAEB6053F3E94C9B9A09F33669435E7EF1BEAED", DeleteOptions{})
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+		mockAssets.AssertExpectations(t)
+	})
+}